@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/infrastructure/cli"
+	"go-manage-hex/internal/infrastructure/db"
+	"go-manage-hex/internal/infrastructure/db/migration"
+	"go-manage-hex/internal/infrastructure/http/server"
+	"go-manage-hex/internal/infrastructure/logging"
+	"go-manage-hex/internal/infrastructure/secret"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	bulkService "go-manage-hex/internal/app/bulk"
+	grantService "go-manage-hex/internal/app/grant"
+	service "go-manage-hex/internal/app/user"
+	grantRepository "go-manage-hex/internal/infrastructure/db/grant"
+	outboxRepository "go-manage-hex/internal/infrastructure/db/outbox"
+	repository "go-manage-hex/internal/infrastructure/db/user"
+)
+
+func main() {
+	cfg, err := config.Load(os.Args[1:], secret.NewDefaultProvider())
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	slog.SetDefault(logging.NewLogger(os.Stderr, cfg.Log.Level))
+
+	if err := run(cfg); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := db.DatabaseConn(cfg.MySQL, func() string { return cfg.MySQL.Password })
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	userRepo := repository.NewUserMysql(conn)
+
+	pwdHasher, err := server.NewPasswordHasher(cfg)
+	if err != nil {
+		return err
+	}
+
+	breachChecker, err := server.NewBreachChecker(cfg)
+	if err != nil {
+		return err
+	}
+
+	userTx := outboxRepository.NewMysqlTransactor(conn)
+
+	loginSources, err := server.NewLoginSources(cfg, userRepo, pwdHasher, service.NewUserProvisioner(userRepo, userTx))
+	if err != nil {
+		return err
+	}
+
+	userService := service.NewUserService(userRepo, pwdHasher, server.NewPasswordPolicy(cfg), breachChecker, userTx, loginSources...)
+
+	passwordLength := min(max(cfg.Password.MinLength, config.GeneratedPasswordLength), cfg.Password.MaxLength)
+
+	userGrants := grantService.NewGrantService(grantRepository.NewGrantMysql(conn), userRepo, cfg.Auth.LoginScopes)
+
+	bulkUsers := bulkService.NewBulkService(userService, cfg.Bulk.BatchSize)
+
+	return cli.NewCLI(userService, userGrants, bulkUsers, migration.NewMigratorMysql(conn), os.Stdout, os.Stderr, passwordLength).Run(ctx, cfg.Args)
+}
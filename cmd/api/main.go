@@ -1,18 +1,77 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"go-manage-hex/cmd/config"
+	secretEntity "go-manage-hex/internal/core/secret"
 	"go-manage-hex/internal/infrastructure/http/server"
+	"go-manage-hex/internal/infrastructure/logging"
+	"go-manage-hex/internal/infrastructure/secret"
+	"go-manage-hex/internal/infrastructure/tracing"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
 
-	config.LoadEnv()
+	secrets := secret.NewDefaultProvider()
 
-	router := server.StartServer()
+	cfg, err := config.Load(os.Args[1:], secrets)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+
+	if cfg != nil && cfg.PrintConfig {
+		if printErr := cfg.Print(os.Stdout); printErr != nil {
+			log.Fatal(printErr)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger := logging.NewLogger(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(logger)
+
+	if err := run(logger, cfg, secrets); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(logger *slog.Logger, cfg *config.Config, secrets secretEntity.Provider) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A second signal during the drain kills the process.
+	context.AfterFunc(ctx, stop)
 
-	if routerErr := router.Run(config.Port); routerErr != nil {
-		log.Fatal(routerErr)
+	tracerProvider, err := tracing.NewTracerProvider(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.FilePath,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return err
 	}
+
+	app, err := server.NewServer(logger, cfg, secrets)
+	if err != nil {
+		tracerProvider.Shutdown(context.Background())
+		return err
+	}
+	app.AddCloser("tracer_provider", tracerProvider.Shutdown)
+
+	return app.Run(ctx)
 }
@@ -1,10 +1,91 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // server params
 const (
-	Port = ":8080"
+	DefaultAddr   = ":8080"
+	ConfigFileEnv = "CONFIG_FILE"
+
+	DefaultMysqlHost = "localhost"
+	DefaultMysqlPort = "3306"
+	DefaultJWTTTL    = time.Hour
+
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultShutdownTimeout   = 20 * time.Second
+)
+
+// secret params
+const (
+	SecretFileSuffix            = "_FILE"
+	SecretsDirEnv               = "SECRETS_DIR"
+	DefaultSecretsWatchInterval = 30 * time.Second
+	MinJWTSecretLength          = 32
+
+	JWTSecretEnv     = "JWT_TOKEN_SECRET"
+	MySQLPasswordEnv = "MYSQL_ROOT_PASSWORD"
+	SigningKeySecret = "oidc_signing_key"
+)
+
+// admin cli params
+const (
+	DefaultAdminListLimit   = 50
+	GeneratedPasswordLength = 20
+)
+
+// migration params
+const (
+	MigrationLockTimeoutSeconds = 60
+	DefaultMigrationDownSteps   = 1
+)
+
+// logging params
+const (
+	RequestIDHeader    = "X-Request-ID"
+	MaxRequestIDLength = 128
+	LogKeyRequestID    = "request_id"
+	RedactedValue      = "[REDACTED]"
+	DefaultLogLevel    = "info"
+)
+
+// metrics params
+const (
+	MetricsPath           = "/metrics"
+	MetricsNamespace      = "go_manage_hex"
+	MetricsUnmatchedRoute = "unmatched"
+	MetricsResultSuccess  = "success"
+	MetricsResultFailure  = "failure"
+)
+
+// health params
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+
+	DefaultHealthCheckTimeout = 2 * time.Second
+	DefaultHealthCacheTTL     = 5 * time.Second
+)
+
+// tracing params
+const (
+	TracerName         = "go-manage-hex"
+	DefaultServiceName = "go-manage-hex"
+	LogKeyTraceID      = "trace_id"
+	LogKeySpanID       = "span_id"
+
+	TraceExporterNone   = "none"
+	TraceExporterStdout = "stdout"
+	TraceExporterFile   = "file"
+	TraceExporterOTLP   = "otlp"
 )
 
 // urls
@@ -12,18 +93,467 @@ const (
 	BaseURL = "/api/go-manage-hex"
 )
 
+// password hashing params
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+
+	DefaultHashAlgorithm     = HashArgon2id
+	DefaultBcryptCost        = 12
+	DefaultArgon2Memory      = 64 * 1024
+	DefaultArgon2Iterations  = 3
+	DefaultArgon2Parallelism = 2
+	DefaultArgon2SaltLength  = 16
+	DefaultArgon2KeyLength   = 32
+
+	// Bounds for parameters read back from stored hashes. Imported hashes
+	// are untrusted, so they must not be able to exhaust memory or CPU.
+	MaxArgon2Memory     = 1024 * 1024
+	MaxArgon2Iterations = 64
+	MinArgon2SaltLength = 8
+	MinArgon2KeyLength  = 16
+
+	DefaultHashQueueTimeout = 2 * time.Second
+	HashBusyRetryAfter      = "1"
+)
+
+// password policy params
+const (
+	DefaultPwdMinLength   = 8
+	DefaultPwdMaxLength   = 72
+	DefaultPwdHistorySize = 5
+	MinUserInfoFragment   = 3
+)
+
+// breached password check params
+const (
+	BreachCheckOff   = "off"
+	BreachCheckIndex = "index"
+	BreachCheckHTTP  = "http"
+
+	DefaultBreachAPIURL  = "https://api.pwnedpasswords.com"
+	DefaultBreachTimeout = 3 * time.Second
+)
+
+// api key params
+const (
+	APIKeyHeader        = "X-API-Key"
+	APIKeyTokenPrefix   = "gmh_"
+	APIKeyPrefixBytes   = 6
+	APIKeySecretBytes   = 32
+	APIKeyTouchInterval = time.Minute
+	MaxAPIKeyNameLength = 64
+)
+
+// oauth2 scopes
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+	ScopeUsersDelete = "users:delete"
+)
+
+// users:delete is never a login default: anyone can register through
+// /create, so deleting accounts must be granted per user.
+var (
+	AllScopes          = []string{ScopeUsersRead, ScopeUsersWrite, ScopeUsersDelete}
+	DefaultLoginScopes = []string{ScopeUsersRead, ScopeUsersWrite}
+)
+
+// oidc provider params
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+
+	OIDCDiscoveryPath = "/.well-known/openid-configuration"
+	OIDCAuthorizePath = "/oauth2/authorize"
+	OIDCTokenPath     = "/oauth2/token"
+	OIDCUserInfoPath  = "/userinfo"
+	OIDCJWKSPath      = "/oauth2/jwks.json"
+	OIDCRegisterPath  = "/oauth2/register"
+
+	ClientAuthBasic = "client_secret_basic"
+	ClientAuthPost  = "client_secret_post"
+	ClientAuthNone  = "none"
+
+	PKCEMethodS256         = "S256"
+	ClientSecretBytes      = 32
+	AuthorizationCodeBytes = 32
+	AuthorizationCodeTTL   = time.Minute
+	DefaultIDTokenTTL      = time.Hour
+
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthInvalidClientMetadata   = "invalid_client_metadata"
+	OAuthInvalidRedirectURI      = "invalid_redirect_uri"
+)
+
+var (
+	OIDCScopes        = []string{ScopeOpenID, ScopeProfile, ScopeEmail}
+	ClientAuthMethods = []string{ClientAuthBasic, ClientAuthPost, ClientAuthNone}
+)
+
+// federated login params
+const (
+	FederatedLoginPath    = "/federated/:provider/login"
+	FederatedCallbackPath = "/federated/:provider/callback"
+	FederatedLinkPath     = "/federated/:provider/link"
+
+	// FederatedStateCookie binds a pending login to the browser that began
+	// it; it holds a hash of the state and only goes to the federated routes.
+	FederatedStateCookie     = "gmh_federated_state"
+	FederatedStateCookiePath = BaseURL + "/federated/"
+
+	FederatedStateTTL    = 10 * time.Minute
+	MaxUsernameLength    = 36
+	UsernameSuffixLength = 7
+	UsernameAttempts     = 5
+)
+
+// authentication sources
+const (
+	AuthSourceLocal = "local"
+	AuthSourceLDAP  = "ldap"
+
+	DefaultLDAPUserFilter   = "(uid=%s)"
+	DefaultLDAPUsernameAttr = "uid"
+	DefaultLDAPNameAttr     = "givenName"
+	DefaultLDAPLastNameAttr = "sn"
+	DefaultLDAPEmailAttr    = "mail"
+	DefaultLDAPTimeout      = 5 * time.Second
+)
+
+var DefaultAuthSources = []string{AuthSourceLocal}
+
+// user list params
+const (
+	UserColumnID       = "id"
+	UserColumnName     = "name"
+	UserColumnLastName = "last_name"
+	UserColumnUsername = "username"
+	UserColumnEmail    = "email"
+
+	FilterEqual      = "eq"
+	FilterStartsWith = "sw"
+	FilterContains   = "co"
+)
+
+var (
+	UserColumns     = []string{UserColumnID, UserColumnName, UserColumnLastName, UserColumnUsername, UserColumnEmail}
+	FilterOperators = []string{FilterEqual, FilterStartsWith, FilterContains}
+)
+
+// scim params
+const (
+	SCIMBasePath        = "/scim/v2"
+	SCIMContentType     = "application/scim+json"
+	SCIMDefaultCount    = 100
+	SCIMMaxResults      = 200
+	SCIMGeneratedPwdLen = 24
+
+	SCIMSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaSPConfig     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaResourceType = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SCIMSchemaSchema       = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+
+	SCIMInvalidFilter = "invalidFilter"
+	SCIMInvalidSyntax = "invalidSyntax"
+	SCIMInvalidPath   = "invalidPath"
+	SCIMInvalidValue  = "invalidValue"
+	SCIMMutability    = "mutability"
+	SCIMUniqueness    = "uniqueness"
+	SCIMNoTarget      = "noTarget"
+)
+
+// domain events
+const (
+	EventUserCreated         = "user.created"
+	EventUserUpdated         = "user.updated"
+	EventUserDeleted         = "user.deleted"
+	EventUserPasswordChanged = "user.password_changed"
+	EventUserRestored        = "user.restored"
+
+	PublisherLog  = "log"
+	PublisherFile = "file"
+	PublisherHTTP = "http"
+
+	DefaultOutboxBatchSize    = 100
+	DefaultOutboxPollInterval = time.Second
+	DefaultOutboxMaxAttempts  = 10
+	DefaultOutboxBackoffBase  = 5 * time.Second
+	DefaultOutboxBackoffMax   = 10 * time.Minute
+	DefaultPublisherTimeout   = 5 * time.Second
+
+	// OutboxClaimMargin is added to the publisher timeout to get how long a
+	// claimed event stays hidden from the relays of other replicas.
+	OutboxClaimMargin = 30 * time.Second
+)
+
+var UserEventTypes = []string{EventUserCreated, EventUserUpdated, EventUserDeleted, EventUserPasswordChanged, EventUserRestored}
+
+// event stream params
+const (
+	EventStreamPath          = "/events"
+	EventStreamReset         = "reset"
+	LastEventIDHeader        = "Last-Event-ID"
+	StreamHeartbeatInterval  = 15 * time.Second
+	DefaultStreamBufferSize  = 1000
+	DefaultStreamClientQueue = 64
+)
+
+// admin params
+const (
+	AdminBasePath = "/admin"
+)
+
+// bulk params
+const (
+	BulkFormatCSV    = "csv"
+	BulkFormatNDJSON = "ndjson"
+
+	DefaultBulkBatchSize  = 500
+	BulkExportPageSize    = 500
+	BulkMaxReportedErrors = 1000
+	BulkMaxLineBytes      = 1 << 20
+
+	DefaultBulkMaxUploadBytes = 100 << 20
+	DefaultBulkFileTTL        = 24 * time.Hour
+	BulkSweepInterval         = 10 * time.Minute
+	BulkImportFilePattern     = "import-*."
+	BulkExportFilePattern     = "export-*."
+)
+
+var BulkFormats = []string{BulkFormatCSV, BulkFormatNDJSON}
+
+// rate limit params
+const (
+	RateLimitPolicyLogin  = "login"
+	RateLimitPolicyCreate = "create"
+	RateLimitPolicyAPI    = "api"
+
+	DefaultRateLimitPeriod = time.Minute
+	DefaultRateLimitLogin  = 10
+	DefaultRateLimitCreate = 5
+	DefaultRateLimitAPI    = 120
+	RateLimitSweepInterval = time.Minute
+
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// idempotency params
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength  = 255
+	DefaultIdempotencyTTL    = 24 * time.Hour
+	IdempotencyLockTimeout   = time.Minute
+	IdempotencySweepInterval = 10 * time.Minute
+	IdempotencyMemoryBody    = 1 << 20
+	IdempotencySpoolPattern  = "idempotency-*"
+)
+
+// IdempotentReplayHeaders are the response headers stored with an idempotent
+// response and sent again on replay.
+var IdempotentReplayHeaders = []string{
+	"Location",
+	RetryAfterHeader,
+	RateLimitLimitHeader,
+	RateLimitRemainingHeader,
+	RateLimitResetHeader,
+	RateLimitPolicyHeader,
+}
+
+// job params
+const (
+	JobTypeUserImport = "users.import"
+	JobTypeUserExport = "users.export"
+
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+
+	DefaultJobWorkers      = 2
+	DefaultJobMaxAttempts  = 3
+	DefaultJobLeaseTTL     = 30 * time.Second
+	DefaultJobPollInterval = time.Second
+	DefaultJobBackoffBase  = 5 * time.Second
+	DefaultJobBackoffMax   = 5 * time.Minute
+	DefaultJobListLimit    = 50
+	MaxJobListLimit        = 500
+)
+
+// webhook params
+const (
+	WebhookAllEvents       = "*"
+	WebhookSecretBytes     = 32
+	WebhookMinSecretLength = 16
+	WebhookDeliveryLimit   = 100
+
+	WebhookHeaderID        = "X-Webhook-ID"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+	WebhookSignaturePrefix = "sha256="
+
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+
+	DefaultWebhookMaxAttempts  = 8
+	DefaultWebhookBackoffBase  = 30 * time.Second
+	DefaultWebhookBackoffMax   = time.Hour
+	DefaultWebhookTimeout      = 10 * time.Second
+	DefaultWebhookPollInterval = 5 * time.Second
+	DefaultWebhookBatchSize    = 50
+
+	// WebhookClaimMargin is added to the send timeout to get how long a
+	// claimed delivery stays hidden from other dispatchers.
+	WebhookClaimMargin = 30 * time.Second
+)
+
+// request context keys
+const (
+	CtxUsername   = "username"
+	CtxAuthMethod = "auth_method"
+	CtxAPIKeyID   = "api_key_id"
+	CtxScopes     = "scopes"
+	CtxRequestID  = "request_id"
+
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
 // service errors
 var (
 	ErrSearchingUser = "error searching user"
+	ErrListingUsers  = "error listing users"
 	ErrCreatingUser  = "error creating user"
 	ErrDeletingUser  = "error deleting user"
+	ErrRestoringUser = "error restoring user"
 	ErrUpdatingUser  = "error updating user"
 	ErrChangingPwd   = "error changing password"
 
+	ErrCreatingAPIKey = "error creating api key"
+	ErrListingAPIKeys = "error listing api keys"
+	ErrRevokingAPIKey = "error revoking api key"
+
+	ErrGrantingScopes = "error granting scopes"
+	ErrRevokingScopes = "error revoking scopes"
+	ErrLoadingScopes  = "error loading scopes"
+
+	ErrRegisteringClient = "error registering client"
+
+	ErrFederatedLogin = "error completing federated login"
+
+	ErrCreatingWebhook   = "error creating webhook"
+	ErrListingWebhooks   = "error listing webhooks"
+	ErrDeletingWebhook   = "error deleting webhook"
+	ErrListingDeliveries = "error listing webhook deliveries"
+	ErrRedelivering      = "error redelivering webhook"
+
+	ErrImportingUsers = "error importing users"
+	ErrExportingUsers = "error exporting users"
+	ErrEnqueuingJob   = "error enqueuing job"
+
 	ErrUserNotFound      = fmt.Errorf("user not found")
 	ErrInvalidEmail      = fmt.Errorf("invalid email address")
 	ErrInvalidPassword   = fmt.Errorf("invalid password")
 	ErrUserAlreadyExists = fmt.Errorf("user already exists")
+	ErrUserDeleted       = fmt.Errorf("user has been deleted")
+
+	ErrInvalidHash          = fmt.Errorf("invalid password hash")
+	ErrUnknownHashAlgorithm = fmt.Errorf("unknown password hash algorithm")
+	ErrHasherBusy           = fmt.Errorf("password hashing is at capacity, retry later")
+
+	ErrUnknownBreachMode  = fmt.Errorf("unknown breached password check mode")
+	ErrInvalidBreachIndex = fmt.Errorf("invalid breached password index")
+	ErrInvalidCorpusLine  = fmt.Errorf("invalid breached password corpus line")
+	ErrCorpusNotSorted    = fmt.Errorf("breached password corpus is not sorted")
+	ErrBreachLookup       = fmt.Errorf("breached password lookup failed")
+
+	ErrAPIKeyNotFound      = fmt.Errorf("api key not found")
+	ErrInvalidAPIKey       = fmt.Errorf("invalid api key")
+	ErrAPIKeyExpired       = fmt.Errorf("api key expired")
+	ErrAPIKeyRevoked       = fmt.Errorf("api key revoked")
+	ErrInvalidAPIKeyName   = fmt.Errorf("invalid api key name")
+	ErrInvalidAPIKeyExpiry = fmt.Errorf("api key expiry must be in the future")
+	ErrInvalidScope        = fmt.Errorf("invalid scope")
+	ErrInsufficientScope   = fmt.Errorf("insufficient scope")
+
+	ErrClientNotFound        = fmt.Errorf("client not found")
+	ErrInvalidClientMetadata = fmt.Errorf("invalid client metadata")
+	ErrInvalidRedirectURI    = fmt.Errorf("invalid redirect uri")
+	ErrInvalidCredentials    = fmt.Errorf("invalid credentials")
+	ErrCodeNotFound          = fmt.Errorf("authorization code not found")
+	ErrInvalidSigningKey     = fmt.Errorf("invalid oidc signing key")
+
+	ErrUnknownProvider       = fmt.Errorf("unknown identity provider")
+	ErrIdentityNotFound      = fmt.Errorf("federated identity not found")
+	ErrInvalidState          = fmt.Errorf("invalid or expired login state")
+	ErrIdentityLinked        = fmt.Errorf("identity already linked to another user")
+	ErrAccountNotProvisioned = fmt.Errorf("no local account for this identity")
+	ErrMissingEmail          = fmt.Errorf("identity provider did not return an email")
+	ErrInvalidIDToken        = fmt.Errorf("invalid id token")
+	ErrProviderDiscovery     = fmt.Errorf("identity provider discovery failed")
+	ErrProviderExchange      = fmt.Errorf("identity provider token exchange failed")
+
+	ErrUnknownAuthSource     = fmt.Errorf("unknown authentication source")
+	ErrAuthSourceUnavailable = fmt.Errorf("authentication source unavailable")
+	ErrAmbiguousLDAPUser     = fmt.Errorf("ldap search returned more than one entry")
+
+	ErrInvalidUserQuery = fmt.Errorf("invalid user query")
+
+	ErrUnknownTraceExporter = fmt.Errorf("unknown trace exporter")
+
+	ErrInvalidConfig       = fmt.Errorf("invalid configuration")
+	ErrWeakJWTSecret       = fmt.Errorf("jwt secret is too weak")
+	ErrSecretNotFound      = fmt.Errorf("secret not found")
+	ErrUnknownConfigFormat = fmt.Errorf("config file must be .yaml, .yml or .toml")
+
+	ErrPendingMigrations = fmt.Errorf("pending migrations")
+	ErrSigningKeyMissing = fmt.Errorf("signing key not loaded")
+
+	ErrMigration      = fmt.Errorf("migration failed")
+	ErrUnknownCommand = fmt.Errorf("unknown command")
+	ErrMissingFlag    = fmt.Errorf("missing required flag")
+	ErrNotConfirmed   = fmt.Errorf("operation not confirmed, pass --yes")
+	ErrNotForced      = fmt.Errorf("rolling back migrations drops data, pass --force")
+	ErrMigrationLock  = fmt.Errorf("another migration holds the schema lock")
+	ErrInvalidFilter  = fmt.Errorf("filter must be \"<field> <operator> <value>\"")
+
+	ErrUnknownBulkFormat = fmt.Errorf("format must be csv or ndjson")
+	ErrMissingColumn     = fmt.Errorf("missing required column")
+	ErrDuplicateInImport = fmt.Errorf("username or email repeated in this import")
+	ErrJobNotFound       = fmt.Errorf("job not found")
+	ErrUnknownJobType    = fmt.Errorf("unknown job type")
+	ErrLeaseLost         = fmt.Errorf("job lease lost")
+	ErrJobExhausted      = fmt.Errorf("job ran out of attempts")
+	ErrJobNotFinished    = fmt.Errorf("job has not finished")
+	ErrJobHasNoResult    = fmt.Errorf("job has no downloadable result")
+	ErrJobResultExpired  = fmt.Errorf("job result has expired")
+
+	ErrUnknownPublisher = fmt.Errorf("unknown event publisher")
+	ErrPublishFailed    = fmt.Errorf("event publish failed")
+	ErrNoTransactor     = fmt.Errorf("no transactor configured, user changes would not record their events")
+
+	ErrWebhookNotFound      = fmt.Errorf("webhook not found")
+	ErrDeliveryNotFound     = fmt.Errorf("webhook delivery not found")
+	ErrInvalidWebhookURL    = fmt.Errorf("invalid webhook url")
+	ErrInvalidEventTypes    = fmt.Errorf("invalid webhook event types")
+	ErrInvalidWebhookSecret = fmt.Errorf("webhook secret is too short")
+	ErrWebhookDelivery      = fmt.Errorf("webhook delivery failed")
 )
 
 //handler messages
@@ -37,6 +567,33 @@ const (
 	InvalidConfirmationValue = "invalid confirmation value"
 	DeleteCancelledMsg       = "delete operation canceled"
 	UserDeletedMsg           = "user deleted successfully"
+	UserRestoredMsg          = "user restored successfully"
+	ScopesGrantedMsg         = "scopes granted successfully"
+	ScopesRevokedMsg         = "scopes revoked successfully"
 	UserUpdatedMsg           = "user updated succesfully"
 	UserPwdChangeMsg         = "password changed successfully"
+	PasswordPolicyMsg        = "password does not meet the password policy"
+	APIKeyCreatedMsg         = "api key created successfully"
+	APIKeysFoundMsg          = "api keys found successfully"
+	APIKeyRevokedMsg         = "api key revoked successfully"
+	APIKeyRequiresLoginMsg   = "api keys can only be managed with a login token"
+	InvalidScopeMsg          = "invalid scope"
+	InsufficientScopeMsg     = "insufficient scope"
+	RateLimitExceededMsg     = "rate limit exceeded"
+	InvalidIdempotencyKeyMsg = "idempotency key must be at most 255 characters"
+	IdempotencyInFlightMsg   = "a request with this idempotency key is still being processed"
+	IdempotencyMismatchMsg   = "idempotency key was already used with a different request"
+	UserLoggedMsg            = "user logged"
+	IdentityLinkStartedMsg   = "continue at the identity provider to link your account"
+	WebhookCreatedMsg        = "webhook created successfully"
+	WebhooksFoundMsg         = "webhooks found successfully"
+	WebhookDeletedMsg        = "webhook deleted successfully"
+	DeliveriesFoundMsg       = "webhook deliveries found successfully"
+	RedeliveryQueuedMsg      = "webhook delivery queued"
+	MigrationUpMsg           = "migrations applied"
+	MigrationDownMsg         = "migrations rolled back"
+	BulkJobQueuedMsg         = "job queued"
+	UploadTooLargeMsg        = "upload exceeds the maximum size"
+	JobFoundMsg              = "job found successfully"
+	JobsFoundMsg             = "jobs found successfully"
 )
@@ -1,38 +1,165 @@
 package config
 
-import "fmt"
-
 // database queries
 const (
 	CheckDBQuery  = "SELECT SCHEMA_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME = ?"
 	CreateDBQuery = "CREATE DATABASE IF NOT EXISTS %s"
 	UseDBQuery    = "USE %s"
+
+	TableExistsQuery = "SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	DropTableQuery   = "DROP TABLE IF EXISTS %s"
+)
+
+// schema migration queries
+const (
+	CreateSchemaVersionTableQuery = "CREATE TABLE IF NOT EXISTS %s (version INT NOT NULL PRIMARY KEY, name VARCHAR(128) NOT NULL, applied_at DATETIME(6) NOT NULL)"
+	AppliedVersionsQuery          = "SELECT version,applied_at FROM %s ORDER BY version"
+	RecordVersionQuery            = "INSERT INTO %s (version,name,applied_at) VALUES (?,?,?)"
+	RemoveVersionQuery            = "DELETE FROM %s WHERE version = ?"
+	AcquireMigrationLockQuery     = "SELECT GET_LOCK(?, ?)"
+	ReleaseMigrationLockQuery     = "SELECT RELEASE_LOCK(?)"
+
+	ColumnExistsQuery      = "SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+	AddColumnQuery         = "ALTER TABLE %s ADD COLUMN %s %s"
+	DropColumnQuery        = "ALTER TABLE %s DROP COLUMN %s"
+	WidenUserPasswordQuery = "ALTER TABLE %s MODIFY password VARCHAR(255) NOT NULL"
+	OIDCClientScopesColumn = "VARCHAR(255) NOT NULL DEFAULT 'openid profile email'"
 )
 
 // mysql queries
 
-var (
-	DBPath = fmt.Sprintf("%s.%s", GetMysqlDBName(), GetMysqlTable())
+const (
+	CreateTableQuery        = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) UNIQUE NOT NULL PRIMARY KEY, name VARCHAR(36) NOT NULL, last_name VARCHAR(36) NOT NULL, username VARCHAR(36) UNIQUE NOT NULL, email VARCHAR(36) UNIQUE NOT NULL, password VARCHAR(255) NOT NULL, deleted_at DATETIME(6) NULL)"
+	CheckExistsQuery        = "SELECT 1 FROM %s WHERE username = ? AND deleted_at IS NULL LIMIT 1"
+	IsDeletedQuery          = "SELECT 1 FROM %s WHERE username = ? AND deleted_at IS NOT NULL LIMIT 1"
+	GetByUsernameQuery      = "SELECT id,name,last_name,username,email,password FROM %s WHERE username = ? AND deleted_at IS NULL"
+	NewUserQuery            = "INSERT INTO %s (id,name,last_name,username,email,password) VALUES (?,?,?,?,?,?)"
+	NewUserValues           = ",(?,?,?,?,?,?)"
+	DeleteQuery             = "UPDATE %s SET deleted_at = ? WHERE username = ? AND deleted_at IS NULL"
+	RestoreQuery            = "UPDATE %s SET deleted_at = NULL WHERE username = ? AND deleted_at IS NOT NULL"
+	UpdateQuery             = "UPDATE %s SET name = ?, last_name = ?, email = ? WHERE username = ? AND deleted_at IS NULL"
+	ChangePwdQuery          = "UPDATE %s SET password = ? WHERE username = ? AND deleted_at IS NULL"
+	GetByCredentialsQuery   = "SELECT 1 FROM %s WHERE username = ? AND password = ? AND deleted_at IS NULL LIMIT 1"
+	GetByEmailQuery         = "SELECT id,name,last_name,username,email,password FROM %s WHERE email = ? AND deleted_at IS NULL"
+	GetByIDQuery            = "SELECT id,name,last_name,username,email,password FROM %s WHERE id = ? AND deleted_at IS NULL"
+	GetByIDWithDeletedQuery = "SELECT id,name,last_name,username,email,password,deleted_at FROM %s WHERE id = ?"
+	ListUsersQuery          = "SELECT id,name,last_name,username,email,password FROM %s WHERE deleted_at IS NULL%s ORDER BY username LIMIT ? OFFSET ?"
+	CountUsersQuery         = "SELECT COUNT(*) FROM %s WHERE deleted_at IS NULL%s"
+	PurgeDeletedUsersQuery  = "DELETE FROM %s WHERE deleted_at IS NOT NULL"
+
+	CreateHistoryTableQuery = "CREATE TABLE IF NOT EXISTS %s (id BIGINT AUTO_INCREMENT PRIMARY KEY, username VARCHAR(36) NOT NULL, password VARCHAR(255) NOT NULL, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, INDEX idx_username (username))"
+	AddPwdHistoryQuery      = "INSERT INTO %s (username,password) VALUES (?,?)"
+	GetPwdHistoryQuery      = "SELECT password FROM %s WHERE username = ? ORDER BY id DESC LIMIT ?"
 )
 
+// api key queries
 const (
-	CreateTableQuery      = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) UNIQUE NOT NULL PRIMARY KEY, name VARCHAR(36) NOT NULL, last_name VARCHAR(36) NOT NULL, username VARCHAR(36) UNIQUE NOT NULL, email VARCHAR(36) UNIQUE NOT NULL, password VARCHAR(100) NOT NULL)"
-	CheckExistsQuery      = "SELECT 1 FROM %s WHERE username = ? LIMIT 1"
-	GetByUsernameQuery    = "SELECT id,name,last_name,username,email,password FROM %s WHERE username = ?"
-	NewUserQuery          = "INSERT INTO %s (id,name,last_name,username,email,password) VALUES (?,?,?,?,?,?)"
-	DeleteQuery           = "DELETE FROM %s WHERE username = ?"
-	UpdateQuery           = "UPDATE %s SET name = ?, last_name = ?, email = ? WHERE username = ?"
-	ChangePwdQuery        = "UPDATE %s SET password = ? WHERE username = ?"
-	GetByCredentialsQuery = "SELECT 1 FROM %s WHERE username = ? AND password = ? LIMIT 1"
+	CreateAPIKeyTableQuery = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) NOT NULL PRIMARY KEY, username VARCHAR(36) NOT NULL, name VARCHAR(64) NOT NULL, prefix VARCHAR(16) UNIQUE NOT NULL, hash CHAR(64) NOT NULL, scopes VARCHAR(255) NOT NULL, expires_at DATETIME NULL, last_used_at DATETIME NULL, created_at DATETIME NOT NULL, revoked_at DATETIME NULL, INDEX idx_username (username))"
+	NewAPIKeyQuery         = "INSERT INTO %s (id,username,name,prefix,hash,scopes,expires_at,created_at) VALUES (?,?,?,?,?,?,?,?)"
+	GetAPIKeyByPrefixQuery = "SELECT id,username,name,prefix,hash,scopes,expires_at,last_used_at,created_at,revoked_at FROM %s WHERE prefix = ?"
+	ListAPIKeysQuery       = "SELECT id,username,name,prefix,hash,scopes,expires_at,last_used_at,created_at,revoked_at FROM %s WHERE username = ? ORDER BY created_at DESC"
+	RevokeAPIKeyQuery      = "UPDATE %s SET revoked_at = ? WHERE id = ? AND username = ? AND revoked_at IS NULL"
+	TouchAPIKeyQuery       = "UPDATE %s SET last_used_at = ? WHERE id = ?"
 )
 
 // mysql test queries
 const (
 	CreateTableTest   = "CREATE TABLE IF NOT EXISTS table_name (id VARCHAR(36) UNIQUE NOT NULL PRIMARY KEY, name VARCHAR(36) NOT NULL, last_name VARCHAR(36) NOT NULL, username VARCHAR(36) UNIQUE NOT NULL, email VARCHAR(36) UNIQUE NOT NULL, password VARCHAR(100) NOT NULL)"
-	CheckExistsTest   = "SELECT 1 FROM %s WHERE username = ? LIMIT 1"
-	GetByUsernameTest = "SELECT id,name,last_name,username,email,password FROM  WHERE username = ?"
+	CheckExistsTest   = "SELECT 1 FROM %s WHERE username = ? AND deleted_at IS NULL LIMIT 1"
+	IsDeletedTest     = "SELECT 1 FROM %s WHERE username = ? AND deleted_at IS NOT NULL LIMIT 1"
+	GetByUsernameTest = "SELECT id,name,last_name,username,email,password FROM  WHERE username = ? AND deleted_at IS NULL"
 	NewUserTest       = "INSERT INTO  (id,name,last_name,username,email,password) VALUES (?,?,?,?,?,?)"
-	DeleteUserTest    = "DELETE FROM WHERE username = ?"
-	UpdateUserTest    = "UPDATE SET name = ?, last_name = ?, email = ? WHERE username = ?"
-	ChangePwdTest     = "UPDATE SET password = ? WHERE username = ?"
+	DeleteUserTest    = "UPDATE  SET deleted_at = ? WHERE username = ? AND deleted_at IS NULL"
+	RestoreUserTest   = "UPDATE  SET deleted_at = NULL WHERE username = ? AND deleted_at IS NOT NULL"
+	UpdateUserTest    = "UPDATE SET name = ?, last_name = ?, email = ? WHERE username = ? AND deleted_at IS NULL"
+	ChangePwdTest     = "UPDATE SET password = ? WHERE username = ? AND deleted_at IS NULL"
+)
+
+// scope grant queries
+const (
+	CreateScopeGrantTableQuery = "CREATE TABLE IF NOT EXISTS %s (username VARCHAR(36) NOT NULL, scope VARCHAR(64) NOT NULL, granted_at DATETIME NOT NULL, PRIMARY KEY (username, scope))"
+	GrantScopeQuery            = "INSERT IGNORE INTO %s (username,scope,granted_at) VALUES (?,?,?)"
+	GrantScopeValues           = ",(?,?,?)"
+	RevokeScopeQuery           = "DELETE FROM %s WHERE username = ? AND scope = ?"
+	ListScopeGrantsQuery       = "SELECT scope FROM %s WHERE username = ? ORDER BY scope"
+
+	UserDeletedAtColumn = "DATETIME(6) NULL"
+)
+
+// oidc client queries
+const (
+	CreateOIDCClientTableQuery = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) NOT NULL PRIMARY KEY, secret_hash CHAR(64) NOT NULL, name VARCHAR(100) NOT NULL, redirect_uris TEXT NOT NULL, auth_method VARCHAR(32) NOT NULL, scopes VARCHAR(255) NOT NULL DEFAULT 'openid profile email', created_at DATETIME NOT NULL)"
+	NewOIDCClientQuery         = "INSERT INTO %s (id,secret_hash,name,redirect_uris,auth_method,scopes,created_at) VALUES (?,?,?,?,?,?,?)"
+	GetOIDCClientQuery         = "SELECT id,secret_hash,name,redirect_uris,auth_method,scopes,created_at FROM %s WHERE id = ?"
+)
+
+// federated identity queries
+const (
+	CreateIdentityTableQuery = "CREATE TABLE IF NOT EXISTS %s (provider VARCHAR(64) NOT NULL, subject VARCHAR(255) NOT NULL, username VARCHAR(36) NOT NULL, email VARCHAR(255) NOT NULL, linked_at DATETIME NOT NULL, PRIMARY KEY (provider, subject), INDEX idx_username (username))"
+	GetIdentityQuery         = "SELECT provider,subject,username,email,linked_at FROM %s WHERE provider = ? AND subject = ?"
+	LinkIdentityQuery        = "INSERT INTO %s (provider,subject,username,email,linked_at) VALUES (?,?,?,?,?)"
+
+	CreateFederatedStateTableQuery = "CREATE TABLE IF NOT EXISTS %s (state_hash CHAR(64) NOT NULL PRIMARY KEY, provider VARCHAR(64) NOT NULL, nonce VARCHAR(64) NOT NULL, code_verifier VARCHAR(64) NOT NULL, link_username VARCHAR(36) NOT NULL DEFAULT '', expires_at DATETIME(6) NOT NULL, INDEX idx_expires (expires_at))"
+	SaveFederatedStateQuery        = "INSERT INTO %s (state_hash,provider,nonce,code_verifier,link_username,expires_at) VALUES (?,?,?,?,?,?)"
+	GetFederatedStateQuery         = "SELECT provider,nonce,code_verifier,link_username,expires_at FROM %s WHERE state_hash = ?"
+	DeleteFederatedStateQuery      = "DELETE FROM %s WHERE state_hash = ?"
+	PurgeFederatedStatesQuery      = "DELETE FROM %s WHERE expires_at <= ?"
+)
+
+// outbox queries
+const (
+	CreateOutboxTableQuery = "CREATE TABLE IF NOT EXISTS %s (sequence BIGINT AUTO_INCREMENT PRIMARY KEY, event_id VARCHAR(36) UNIQUE NOT NULL, event_type VARCHAR(64) NOT NULL, aggregate VARCHAR(36) NOT NULL, payload TEXT NOT NULL, occurred_at DATETIME(6) NOT NULL, published_at DATETIME(6) NULL, attempts INT NOT NULL DEFAULT 0, last_error TEXT NULL, next_attempt_at DATETIME(6) NULL, dead_at DATETIME(6) NULL, INDEX idx_pending (published_at, sequence))"
+	RecordEventQuery       = "INSERT INTO %s (event_id,event_type,aggregate,payload,occurred_at) VALUES (?,?,?,?,?)"
+	// PendingEventsQuery returns only the oldest live event of each
+	// aggregate, so a later event never overtakes one still being retried.
+	PendingEventsQuery = "SELECT o.sequence,o.event_id,o.event_type,o.aggregate,o.payload,o.occurred_at,o.attempts FROM %[1]s o WHERE o.published_at IS NULL AND o.dead_at IS NULL AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= ?) AND NOT EXISTS (SELECT 1 FROM %[1]s p WHERE p.aggregate = o.aggregate AND p.sequence < o.sequence AND p.published_at IS NULL AND p.dead_at IS NULL) ORDER BY o.sequence LIMIT ?"
+	ClaimEventQuery    = "UPDATE %s SET next_attempt_at = ? WHERE sequence = ? AND published_at IS NULL AND dead_at IS NULL AND (next_attempt_at IS NULL OR next_attempt_at <= ?)"
+	MarkPublishedQuery = "UPDATE %s SET published_at = ?, attempts = attempts + 1, last_error = NULL, next_attempt_at = NULL WHERE sequence = ?"
+	MarkFailedQuery    = "UPDATE %s SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE sequence = ?"
+	MarkDeadQuery      = "UPDATE %s SET attempts = attempts + 1, last_error = ?, dead_at = ? WHERE sequence = ?"
+
+	OutboxNextAttemptAtColumn = "DATETIME(6) NULL"
+	OutboxDeadAtColumn        = "DATETIME(6) NULL"
+)
+
+// webhook queries
+const (
+	CreateWebhookTableQuery = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) NOT NULL PRIMARY KEY, url VARCHAR(2048) NOT NULL, event_types VARCHAR(255) NOT NULL, secret VARCHAR(255) NOT NULL, created_at DATETIME NOT NULL)"
+	NewWebhookQuery         = "INSERT INTO %s (id,url,event_types,secret,created_at) VALUES (?,?,?,?,?)"
+	GetWebhookQuery         = "SELECT id,url,event_types,secret,created_at FROM %s WHERE id = ?"
+	ListWebhooksQuery       = "SELECT id,url,event_types,secret,created_at FROM %s ORDER BY created_at"
+	DeleteWebhookQuery      = "DELETE FROM %s WHERE id = ?"
+
+	CreateDeliveryTableQuery = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) NOT NULL PRIMARY KEY, subscription_id VARCHAR(36) NOT NULL, event_id VARCHAR(36) NOT NULL, event_type VARCHAR(64) NOT NULL, payload TEXT NOT NULL, status VARCHAR(16) NOT NULL, attempts INT NOT NULL DEFAULT 0, last_status_code INT NOT NULL DEFAULT 0, last_error TEXT NULL, next_attempt_at DATETIME(6) NOT NULL, created_at DATETIME(6) NOT NULL, delivered_at DATETIME(6) NULL, UNIQUE KEY uq_subscription_event (subscription_id, event_id), INDEX idx_due (status, next_attempt_at))"
+	NewDeliveryQuery         = "INSERT IGNORE INTO %s (id,subscription_id,event_id,event_type,payload,status,attempts,next_attempt_at,created_at) VALUES (?,?,?,?,?,?,?,?,?)"
+	GetDeliveryQuery         = "SELECT id,subscription_id,event_id,event_type,payload,status,attempts,last_status_code,last_error,next_attempt_at,created_at,delivered_at FROM %s WHERE id = ?"
+	ListDeliveriesQuery      = "SELECT id,subscription_id,event_id,event_type,payload,status,attempts,last_status_code,last_error,next_attempt_at,created_at,delivered_at FROM %s WHERE subscription_id = ? ORDER BY created_at DESC LIMIT ?"
+	DueDeliveriesQuery       = "SELECT id,subscription_id,event_id,event_type,payload,status,attempts,last_status_code,last_error,next_attempt_at,created_at,delivered_at FROM %s WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?"
+	ClaimDeliveryQuery       = "UPDATE %s SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?"
+	UpdateDeliveryQuery      = "UPDATE %s SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, next_attempt_at = ?, delivered_at = ? WHERE id = ?"
+)
+
+// job queries
+const (
+	CreateJobTableQuery = "CREATE TABLE IF NOT EXISTS %s (id VARCHAR(36) NOT NULL PRIMARY KEY, type VARCHAR(64) NOT NULL, payload MEDIUMTEXT NOT NULL, status VARCHAR(16) NOT NULL, attempts INT NOT NULL DEFAULT 0, max_attempts INT NOT NULL, progress MEDIUMTEXT NULL, result MEDIUMTEXT NULL, last_error TEXT NULL, run_at DATETIME(6) NOT NULL, lease_owner VARCHAR(128) NULL, lease_expires_at DATETIME(6) NULL, created_at DATETIME(6) NOT NULL, updated_at DATETIME(6) NOT NULL, finished_at DATETIME(6) NULL, INDEX idx_due (status, run_at), INDEX idx_lease (status, lease_expires_at))"
+	NewJobQuery         = "INSERT INTO %s (id,type,payload,status,attempts,max_attempts,run_at,created_at,updated_at) VALUES (?,?,?,?,?,?,?,?,?)"
+	GetJobQuery         = "SELECT id,type,payload,status,attempts,max_attempts,progress,result,last_error,run_at,lease_owner,lease_expires_at,created_at,updated_at,finished_at FROM %s WHERE id = ?"
+	ListJobsQuery       = "SELECT id,type,payload,status,attempts,max_attempts,progress,result,last_error,run_at,lease_owner,lease_expires_at,created_at,updated_at,finished_at FROM %s%s ORDER BY created_at DESC LIMIT ?"
+	DueJobsQuery        = "SELECT id,type,payload,status,attempts,max_attempts,progress,result,last_error,run_at,lease_owner,lease_expires_at,created_at,updated_at,finished_at FROM %s WHERE type IN (%s) AND ((status = ? AND run_at <= ?) OR (status = ? AND lease_expires_at <= ?)) ORDER BY run_at LIMIT ?"
+	ClaimJobQuery       = "UPDATE %s SET status = ?, attempts = attempts + 1, lease_owner = ?, lease_expires_at = ?, updated_at = ? WHERE id = ? AND ((status = ? AND run_at <= ?) OR (status = ? AND lease_expires_at <= ?))"
+	HeartbeatJobQuery   = "UPDATE %s SET progress = ?, lease_expires_at = ?, updated_at = ? WHERE id = ? AND lease_owner = ? AND status = ?"
+	FinishJobQuery      = "UPDATE %s SET status = ?, attempts = ?, progress = ?, result = ?, last_error = ?, run_at = ?, lease_owner = NULL, lease_expires_at = NULL, updated_at = ?, finished_at = ? WHERE id = ? AND lease_owner = ?"
+)
+
+// idempotency queries
+const (
+	CreateIdempotencyTableQuery = "CREATE TABLE IF NOT EXISTS %s (caller VARCHAR(191) NOT NULL, idempotency_key VARCHAR(255) NOT NULL, fingerprint CHAR(64) NOT NULL, status_code INT NULL, content_type VARCHAR(255) NULL, headers TEXT NULL, body MEDIUMBLOB NULL, created_at DATETIME(6) NOT NULL, expires_at DATETIME(6) NOT NULL, PRIMARY KEY (caller, idempotency_key), INDEX idx_expires (expires_at))"
+	ExpireIdempotencyKeyQuery   = "DELETE FROM %s WHERE caller = ? AND idempotency_key = ? AND expires_at <= ?"
+	ReserveIdempotencyKeyQuery  = "INSERT IGNORE INTO %s (caller,idempotency_key,fingerprint,created_at,expires_at) VALUES (?,?,?,?,?)"
+	GetIdempotencyKeyQuery      = "SELECT caller,idempotency_key,fingerprint,status_code,content_type,headers,body,created_at,expires_at FROM %s WHERE caller = ? AND idempotency_key = ?"
+	CompleteIdempotencyKeyQuery = "UPDATE %s SET status_code = ?, content_type = ?, headers = ?, body = ?, expires_at = ? WHERE caller = ? AND idempotency_key = ? AND status_code IS NULL"
+	ReleaseIdempotencyKeyQuery  = "DELETE FROM %s WHERE caller = ? AND idempotency_key = ? AND status_code IS NULL"
+	PurgeIdempotencyKeysQuery   = "DELETE FROM %s WHERE expires_at <= ?"
+
+	IdempotencyHeadersColumn = "TEXT NULL"
 )
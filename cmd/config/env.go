@@ -1,56 +1,56 @@
 package config
 
-import (
-	"fmt"
-	"log"
-	"os"
+// Table names are derived from the loaded configuration because the
+// repositories build their queries per call.
 
-	"github.com/joho/godotenv"
-)
+func GetMysqlTable() string {
+	return current.MySQL.Table
+}
 
-func LoadEnv() {
-	if loadErr := godotenv.Load(); loadErr != nil {
-		log.Fatal(loadErr)
-	}
-	log.Print(".env file loaded successfully")
+func GetPwdHistoryTable() string {
+	return GetMysqlTable() + "_password_history"
 }
 
-func GetMysqlUser() string {
-	return os.Getenv("MYSQL_USER")
+func GetAPIKeyTable() string {
+	return GetMysqlTable() + "_api_keys"
 }
 
-func GetMysqlPwd() string {
-	return os.Getenv("MYSQL_ROOT_PASSWORD")
+func GetOIDCClientTable() string {
+	return GetMysqlTable() + "_oidc_clients"
 }
 
-func GetMysqlDBHost() string {
-	return os.Getenv("MYSQL_DB_HOST")
+func GetIdentityTable() string {
+	return GetMysqlTable() + "_federated_identities"
 }
 
-func GetMysqlDBPort() string {
-	return os.Getenv("MYSQL_DB_PORT")
+func GetFederatedStateTable() string {
+	return GetMysqlTable() + "_federated_states"
 }
 
-func GetMysqlDBName() string {
-	return os.Getenv("MYSQL_DB_NAME")
+func GetOutboxTable() string {
+	return GetMysqlTable() + "_outbox"
 }
 
-func GetMysqlTable() string {
-	return os.Getenv("MYSQL_TABLE_NAME")
+func GetWebhookTable() string {
+	return GetMysqlTable() + "_webhooks"
 }
 
-func GetDSNRoot() string {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/", GetMysqlUser(), GetMysqlPwd(), GetMysqlDBHost(), GetMysqlDBPort())
+func GetDeliveryTable() string {
+	return GetMysqlTable() + "_webhook_deliveries"
+}
 
-	return dsn
+func GetJobTable() string {
+	return GetMysqlTable() + "_jobs"
 }
 
-func GetDSN_DB() string {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", GetMysqlUser(), GetMysqlPwd(), GetMysqlDBHost(), GetMysqlDBPort(), GetMysqlDBName())
+func GetIdempotencyTable() string {
+	return GetMysqlTable() + "_idempotency_keys"
+}
 
-	return dsn
+func GetScopeGrantTable() string {
+	return GetMysqlTable() + "_scope_grants"
 }
 
-func GetJwtSecret() string {
-	return os.Getenv("JWT_TOKEN_SECRET")
+func GetSchemaVersionTable() string {
+	return GetMysqlTable() + "_schema_migrations"
 }
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-manage-hex/internal/core/secret"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var current = Defaults()
+
+type leaf struct {
+	path  string
+	env   string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// Load builds the configuration with precedence flags > env > file > defaults.
+// Fields tagged as secrets are resolved through secrets instead of the plain
+// environment; a nil provider falls back to os.Getenv. A missing .env file is
+// not an error. The returned error lists every problem found; the config is
+// returned alongside it so --print-config can still show what was resolved.
+// Arguments left after the flags are kept in Args for subcommands.
+func Load(args []string, secrets secret.Provider) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	cfg := Defaults()
+
+	flags := flag.NewFlagSet(DefaultServiceName, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(ConfigFileEnv), "path to a YAML or TOML config file")
+	flags.BoolVar(&cfg.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+
+	flagValues := map[string]*string{}
+	walk(reflect.ValueOf(&cfg).Elem(), "", "", func(l leaf) {
+		flagValues[l.path] = flags.String(l.path, "", "overrides "+l.env)
+	})
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	cfg.Args = flags.Args()
+
+	setFlags := map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		if value, ok := flagValues[f.Name]; ok {
+			setFlags[f.Name] = *value
+		}
+	})
+
+	fileValues, err := readFile(*configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	lookup := func(l leaf) (string, bool) {
+		if value, ok := setFlags[l.path]; ok {
+			return value, true
+		}
+		if l.field.Tag.Get("secret") == "true" && secrets != nil {
+			value, found, lookupErr := secrets.Lookup(l.env)
+			if lookupErr != nil {
+				problems = append(problems, fmt.Sprintf("%s (%s): %v", l.path, l.env, lookupErr))
+			}
+			if found {
+				return value, true
+			}
+		} else if value := os.Getenv(l.env); value != "" {
+			return value, true
+		}
+		value, ok := fileValues[l.path]
+		return value, ok
+	}
+
+	known := map[string]bool{}
+	apply := func(l leaf) {
+		known[l.path] = true
+		raw, ok := lookup(l)
+		if !ok {
+			return
+		}
+		if setErr := setValue(l.value, raw); setErr != nil {
+			problems = append(problems, fmt.Sprintf("%s (%s): %v", l.path, l.env, setErr))
+		}
+	}
+
+	walk(reflect.ValueOf(&cfg).Elem(), "", "", apply)
+
+	cfg.Federation.Providers = nil
+	for _, name := range cfg.Federation.IDPs {
+		provider := FederatedProvider{Name: name}
+		walk(reflect.ValueOf(&provider).Elem(), "federation.providers."+name+".", "FEDERATED_"+strings.ToUpper(name)+"_", apply)
+		cfg.Federation.Providers = append(cfg.Federation.Providers, provider)
+	}
+
+	for _, path := range sortedKeys(fileValues) {
+		if !known[path] && !strings.HasPrefix(path, "federation.providers.") {
+			problems = append(problems, fmt.Sprintf("%s: unknown key in %s", path, *configFile))
+		}
+	}
+
+	cfg.derive()
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); errors.As(err, &validationErr) {
+		problems = append(problems, validationErr.Problems...)
+	}
+
+	current = cfg
+
+	if len(problems) > 0 {
+		return &cfg, &ValidationError{Problems: problems}
+	}
+	return &cfg, nil
+}
+
+// Print writes the configuration as YAML with every secret redacted.
+func (c *Config) Print(w io.Writer) error {
+	redacted := *c
+	redacted.Federation.Providers = slices.Clone(c.Federation.Providers)
+
+	hide := func(l leaf) {
+		if l.field.Tag.Get("secret") == "true" && l.value.String() != "" {
+			l.value.SetString(RedactedValue)
+		}
+	}
+	walk(reflect.ValueOf(&redacted).Elem(), "", "", hide)
+	for i := range redacted.Federation.Providers {
+		walk(reflect.ValueOf(&redacted.Federation.Providers[i]).Elem(), "", "", hide)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// walk calls fn for every field tagged with an env name, descending into
+// nested structs.
+func walk(v reflect.Value, pathPrefix, envPrefix string, fn func(leaf)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			walk(v.Field(i), pathPrefix+key+".", envPrefix, fn)
+			continue
+		}
+
+		env := field.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+
+		fn(leaf{path: pathPrefix + key, env: envPrefix + env, field: field, value: v.Field(i)})
+	}
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(parsed))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(parsed)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func readFile(path string) (map[string]string, error) {
+	values := map[string]string{}
+	if path == "" {
+		return values, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownConfigFormat, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	flatten(tree, "", values)
+	return values, nil
+}
+
+func flatten(tree map[string]any, prefix string, values map[string]string) {
+	for key, value := range tree {
+		switch typed := value.(type) {
+		case map[string]any:
+			flatten(typed, prefix+key+".", values)
+		case []any:
+			items := make([]string, len(typed))
+			for i, item := range typed {
+				items[i] = fmt.Sprint(item)
+			}
+			values[prefix+key] = strings.Join(items, ",")
+		default:
+			values[prefix+key] = fmt.Sprint(typed)
+		}
+	}
+}
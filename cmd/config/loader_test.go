@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-jwt-secret-0123456789abcdef"
+
+func setRequired(t *testing.T) {
+	t.Setenv("MYSQL_USER", "root")
+	t.Setenv("MYSQL_DB_NAME", "go_manage_hex")
+	t.Setenv("MYSQL_TABLE_NAME", "users")
+	t.Setenv("JWT_TOKEN_SECRET", testJWTSecret)
+	t.Setenv("OIDC_EPHEMERAL_KEY", "true")
+	t.Cleanup(func() { current = Defaults() })
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	setRequired(t)
+
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":9000"
+  read_timeout: 7s
+mysql:
+  host: db.internal
+log:
+  level: warn
+auth:
+  sources: [local, ldap]
+ldap:
+  url: ldaps://ldap.internal
+`)
+	t.Setenv("MYSQL_DB_HOST", "db.env")
+	t.Setenv("SERVER_ADDR", ":9100")
+
+	cfg, err := Load([]string{"--config", path, "--server.addr", ":9200"}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9200", cfg.Server.Addr)
+	assert.Equal(t, "db.env", cfg.MySQL.Host)
+	assert.Equal(t, 7*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, []string{AuthSourceLocal, AuthSourceLDAP}, cfg.Auth.Sources)
+	assert.Equal(t, DefaultWriteTimeout, cfg.Server.WriteTimeout)
+	assert.Equal(t, DefaultJWTTTL, cfg.Auth.JWTTTL)
+	assert.Equal(t, "http://localhost:9200"+BaseURL, cfg.OIDC.Issuer)
+	assert.Equal(t, "users_outbox", GetOutboxTable())
+}
+
+func TestLoadTOMLAndProviders(t *testing.T) {
+	setRequired(t)
+
+	path := writeFile(t, "config.toml", `
+[federation]
+idps = ["google"]
+
+[federation.providers.google]
+issuer = "https://accounts.google.com"
+client_secret = "from-file"
+`)
+	t.Setenv("FEDERATED_GOOGLE_CLIENT_ID", "client-id")
+
+	cfg, err := Load([]string{"-config", path}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Federation.Providers, 1)
+	provider := cfg.Federation.Providers[0]
+	assert.Equal(t, "google", provider.Name)
+	assert.Equal(t, "https://accounts.google.com", provider.Issuer)
+	assert.Equal(t, "client-id", provider.ClientID)
+	assert.Equal(t, "from-file", provider.ClientSecret)
+	assert.Equal(t, "http://localhost:8080"+BaseURL+"/federated/google/callback", provider.RedirectURL)
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	t.Cleanup(func() { current = Defaults() })
+
+	path := writeFile(t, "config.yaml", `
+server:
+  adress: ":9000"
+`)
+	t.Setenv("SERVER_READ_TIMEOUT", "soon")
+	t.Setenv("PASSWORD_HASH_ALGORITHM", "md5")
+	t.Setenv("EVENT_PUBLISHER", "file")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8,proxy.internal")
+	t.Setenv("RATE_LIMIT_LOGIN", "0")
+	t.Setenv("IDEMPOTENCY_TTL", "-1h")
+	t.Setenv("AUTH_LOGIN_SCOPES", "users:read,users:delete")
+
+	cfg, err := Load([]string{"--config", path}, nil)
+	require.NotNil(t, cfg)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	for _, expected := range []string{
+		`server.read_timeout (SERVER_READ_TIMEOUT): invalid duration "soon"`,
+		"server.adress: unknown key in " + path,
+		"mysql.user is required",
+		"auth.jwt_secret is required",
+		"hashing.algorithm must be one of argon2id, bcrypt",
+		"events.file_path is required when events.publisher is file",
+		`server.trusted_proxies: "proxy.internal" is not an IP or CIDR`,
+		"rate_limit.login must be positive",
+		"idempotency.ttl must be positive",
+		"auth.login_scopes: users:delete can only be granted per user",
+		"oidc.signing_key_path is required unless oidc.ephemeral_key is set for development",
+	} {
+		assert.Contains(t, validationErr.Problems, expected)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		Name string
+		Args []string
+	}{
+		{
+			Name: "UnknownFlag",
+			Args: []string{"--nope"},
+		},
+		{
+			Name: "MissingFile",
+			Args: []string{"--config", "/does/not/exist.yaml"},
+		},
+		{
+			Name: "UnknownFormat",
+			Args: []string{"--config", "config.ini"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			setRequired(t)
+
+			cfg, err := Load(tt.Args, nil)
+			assert.Nil(t, cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	setRequired(t)
+	t.Setenv("MYSQL_ROOT_PASSWORD", "db-password")
+	t.Setenv("FEDERATED_IDPS", "okta")
+	t.Setenv("FEDERATED_OKTA_ISSUER", "https://okta.example.com")
+	t.Setenv("FEDERATED_OKTA_CLIENT_ID", "okta-client")
+	t.Setenv("FEDERATED_OKTA_CLIENT_SECRET", "okta-secret")
+
+	cfg, err := Load([]string{"--print-config"}, nil)
+	require.NoError(t, err)
+	assert.True(t, cfg.PrintConfig)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+
+	printed := out.String()
+	assert.NotContains(t, printed, "db-password")
+	assert.NotContains(t, printed, testJWTSecret)
+	assert.NotContains(t, printed, "okta-secret")
+	assert.Contains(t, printed, RedactedValue)
+	assert.Contains(t, printed, "okta-client")
+	assert.Contains(t, printed, "read_timeout: 15s")
+
+	assert.Equal(t, "db-password", cfg.MySQL.Password)
+	assert.Equal(t, "okta-secret", cfg.Federation.Providers[0].ClientSecret)
+}
+
+type mapProvider map[string]string
+
+func (mp mapProvider) Lookup(key string) (string, bool, error) {
+	value, ok := mp[key]
+	return value, ok, nil
+}
+
+func TestLoadSecretsFromProvider(t *testing.T) {
+	setRequired(t)
+	t.Setenv("MYSQL_ROOT_PASSWORD", "from-env")
+	t.Setenv("FEDERATED_IDPS", "okta")
+	t.Setenv("FEDERATED_OKTA_ISSUER", "https://okta.example.com")
+	t.Setenv("FEDERATED_OKTA_CLIENT_ID", "okta-client")
+
+	secrets := mapProvider{
+		"JWT_TOKEN_SECRET":             "provider-jwt-secret-0123456789abcdef",
+		"MYSQL_ROOT_PASSWORD":          "from-provider",
+		"FEDERATED_OKTA_CLIENT_SECRET": "okta-secret",
+	}
+
+	cfg, err := Load([]string{"--admin.token", "from-flag"}, secrets)
+	require.NoError(t, err)
+
+	assert.Equal(t, "provider-jwt-secret-0123456789abcdef", cfg.Auth.JWTSecret)
+	assert.Equal(t, "from-provider", cfg.MySQL.Password)
+	assert.Equal(t, "okta-secret", cfg.Federation.Providers[0].ClientSecret)
+	assert.Equal(t, "from-flag", cfg.Admin.Token)
+}
+
+func TestCheckJWTSecret(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Secret   string
+		Expected error
+	}{
+		{
+			Name:     "Strong",
+			Secret:   testJWTSecret,
+			Expected: nil,
+		},
+		{
+			Name:     "TooShort",
+			Secret:   "jwt-secret",
+			Expected: ErrWeakJWTSecret,
+		},
+		{
+			Name:     "RepeatedCharacter",
+			Secret:   strings.Repeat("a", MinJWTSecretLength),
+			Expected: ErrWeakJWTSecret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.ErrorIs(t, CheckJWTSecret(tt.Secret), tt.Expected)
+		})
+	}
+}
+
+func TestLoadRejectsWeakJWTSecret(t *testing.T) {
+	setRequired(t)
+	t.Setenv("JWT_TOKEN_SECRET", "changeme")
+
+	_, err := Load(nil, nil)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Contains(t, validationErr.Problems, "auth.jwt_secret: jwt secret is too weak: must be at least 32 bytes")
+}
@@ -0,0 +1,617 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	MySQL       MySQLConfig       `yaml:"mysql"`
+	Auth        AuthConfig        `yaml:"auth"`
+	Password    PasswordConfig    `yaml:"password"`
+	Hashing     HashingConfig     `yaml:"hashing"`
+	Breach      BreachConfig      `yaml:"breach"`
+	OIDC        OIDCConfig        `yaml:"oidc"`
+	Federation  FederationConfig  `yaml:"federation"`
+	LDAP        LDAPConfig        `yaml:"ldap"`
+	SCIM        SCIMConfig        `yaml:"scim"`
+	Events      EventsConfig      `yaml:"events"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Log         LogConfig         `yaml:"log"`
+	Metrics     MetricsConfig     `yaml:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing"`
+	Health      HealthConfig      `yaml:"health"`
+	Admin       AdminConfig       `yaml:"admin"`
+	Secrets     SecretsConfig     `yaml:"secrets"`
+	Bulk        BulkConfig        `yaml:"bulk"`
+	Jobs        JobsConfig        `yaml:"jobs"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+
+	PrintConfig bool     `yaml:"-"`
+	Args        []string `yaml:"-"`
+}
+
+type ServerConfig struct {
+	Addr            string        `yaml:"addr" env:"SERVER_ADDR"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	TrustedProxies  []string      `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+type MySQLConfig struct {
+	User     string `yaml:"user" env:"MYSQL_USER"`
+	Password string `yaml:"password" env:"MYSQL_ROOT_PASSWORD" secret:"true"`
+	Host     string `yaml:"host" env:"MYSQL_DB_HOST"`
+	Port     string `yaml:"port" env:"MYSQL_DB_PORT"`
+	Database string `yaml:"database" env:"MYSQL_DB_NAME"`
+	Table    string `yaml:"table" env:"MYSQL_TABLE_NAME"`
+}
+
+type AuthConfig struct {
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_TOKEN_SECRET" secret:"true"`
+	JWTTTL    time.Duration `yaml:"jwt_ttl" env:"JWT_TTL"`
+	Sources   []string      `yaml:"sources" env:"AUTH_SOURCES"`
+	// LoginScopes are issued to every user on login; scopes granted with
+	// role grant are added on top of them. users:delete is only granted.
+	LoginScopes []string `yaml:"login_scopes" env:"AUTH_LOGIN_SCOPES"`
+}
+
+type PasswordConfig struct {
+	MinLength        int  `yaml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+	MaxLength        int  `yaml:"max_length" env:"PASSWORD_MAX_LENGTH"`
+	RequireUpper     bool `yaml:"require_upper" env:"PASSWORD_REQUIRE_UPPER"`
+	RequireLower     bool `yaml:"require_lower" env:"PASSWORD_REQUIRE_LOWER"`
+	RequireDigit     bool `yaml:"require_digit" env:"PASSWORD_REQUIRE_DIGIT"`
+	RequireSymbol    bool `yaml:"require_symbol" env:"PASSWORD_REQUIRE_SYMBOL"`
+	HistorySize      int  `yaml:"history_size" env:"PASSWORD_HISTORY_SIZE"`
+	DisallowUserInfo bool `yaml:"disallow_user_info" env:"PASSWORD_DISALLOW_USER_INFO"`
+}
+
+type HashingConfig struct {
+	Algorithm         string        `yaml:"algorithm" env:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost        int           `yaml:"bcrypt_cost" env:"BCRYPT_COST"`
+	Argon2MemoryKB    int           `yaml:"argon2_memory_kb" env:"ARGON2_MEMORY_KB"`
+	Argon2Iterations  int           `yaml:"argon2_iterations" env:"ARGON2_ITERATIONS"`
+	Argon2Parallelism int           `yaml:"argon2_parallelism" env:"ARGON2_PARALLELISM"`
+	PoolSize          int           `yaml:"pool_size" env:"HASH_POOL_SIZE"`
+	QueueTimeout      time.Duration `yaml:"queue_timeout" env:"HASH_QUEUE_TIMEOUT"`
+}
+
+type BreachConfig struct {
+	Mode       string        `yaml:"mode" env:"BREACH_CHECK_MODE"`
+	CorpusPath string        `yaml:"corpus_path" env:"BREACH_CORPUS_PATH"`
+	IndexPath  string        `yaml:"index_path" env:"BREACH_INDEX_PATH"`
+	APIURL     string        `yaml:"api_url" env:"BREACH_API_URL"`
+	APITimeout time.Duration `yaml:"api_timeout" env:"BREACH_API_TIMEOUT"`
+}
+
+type OIDCConfig struct {
+	Issuer         string `yaml:"issuer" env:"OIDC_ISSUER"`
+	SigningKeyPath string `yaml:"signing_key_path" env:"OIDC_SIGNING_KEY_PATH"`
+	// EphemeralKey lets development run without a key file; every restart
+	// then invalidates the ID tokens already issued.
+	EphemeralKey      bool   `yaml:"ephemeral_key" env:"OIDC_EPHEMERAL_KEY"`
+	RegistrationToken string `yaml:"registration_token" env:"OIDC_REGISTRATION_TOKEN" secret:"true"`
+}
+
+type FederationConfig struct {
+	IDPs          []string            `yaml:"idps" env:"FEDERATED_IDPS"`
+	AutoProvision bool                `yaml:"auto_provision" env:"FEDERATED_AUTO_PROVISION"`
+	LinkByEmail   bool                `yaml:"link_by_email" env:"FEDERATED_LINK_BY_EMAIL"`
+	Providers     []FederatedProvider `yaml:"providers"`
+}
+
+// FederatedProvider is resolved per name in IDPs from federation.providers.<name>.*
+// or FEDERATED_<NAME>_*.
+type FederatedProvider struct {
+	Name         string `yaml:"name"`
+	Issuer       string `yaml:"issuer" env:"ISSUER"`
+	ClientID     string `yaml:"client_id" env:"CLIENT_ID"`
+	ClientSecret string `yaml:"client_secret" env:"CLIENT_SECRET" secret:"true"`
+	RedirectURL  string `yaml:"redirect_url" env:"REDIRECT_URL"`
+}
+
+type LDAPConfig struct {
+	URL          string        `yaml:"url" env:"LDAP_URL"`
+	BindDN       string        `yaml:"bind_dn" env:"LDAP_BIND_DN"`
+	BindPassword string        `yaml:"bind_password" env:"LDAP_BIND_PASSWORD" secret:"true"`
+	BaseDN       string        `yaml:"base_dn" env:"LDAP_BASE_DN"`
+	UserFilter   string        `yaml:"user_filter" env:"LDAP_USER_FILTER"`
+	GroupFilter  string        `yaml:"group_filter" env:"LDAP_GROUP_FILTER"`
+	UsernameAttr string        `yaml:"attr_username" env:"LDAP_ATTR_USERNAME"`
+	NameAttr     string        `yaml:"attr_name" env:"LDAP_ATTR_NAME"`
+	LastNameAttr string        `yaml:"attr_last_name" env:"LDAP_ATTR_LAST_NAME"`
+	EmailAttr    string        `yaml:"attr_email" env:"LDAP_ATTR_EMAIL"`
+	StartTLS     bool          `yaml:"start_tls" env:"LDAP_START_TLS"`
+	Timeout      time.Duration `yaml:"timeout" env:"LDAP_TIMEOUT"`
+	Provision    bool          `yaml:"provision" env:"LDAP_PROVISION"`
+}
+
+type SCIMConfig struct {
+	Token   string `yaml:"token" env:"SCIM_TOKEN" secret:"true"`
+	BaseURL string `yaml:"base_url" env:"SCIM_BASE_URL"`
+}
+
+type EventsConfig struct {
+	Publisher          string        `yaml:"publisher" env:"EVENT_PUBLISHER"`
+	FilePath           string        `yaml:"file_path" env:"EVENT_FILE_PATH"`
+	WebhookURL         string        `yaml:"webhook_url" env:"EVENT_WEBHOOK_URL"`
+	PublisherTimeout   time.Duration `yaml:"publisher_timeout" env:"EVENT_PUBLISHER_TIMEOUT"`
+	OutboxBatchSize    int           `yaml:"outbox_batch_size" env:"OUTBOX_BATCH_SIZE"`
+	OutboxPollInterval time.Duration `yaml:"outbox_poll_interval" env:"OUTBOX_POLL_INTERVAL"`
+	OutboxMaxAttempts  int           `yaml:"outbox_max_attempts" env:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxBackoffBase  time.Duration `yaml:"outbox_backoff_base" env:"OUTBOX_BACKOFF_BASE"`
+	OutboxBackoffMax   time.Duration `yaml:"outbox_backoff_max" env:"OUTBOX_BACKOFF_MAX"`
+	StreamBufferSize   int           `yaml:"stream_buffer" env:"EVENT_STREAM_BUFFER"`
+	StreamClientQueue  int           `yaml:"stream_client_queue" env:"EVENT_STREAM_CLIENT_QUEUE"`
+}
+
+type WebhooksConfig struct {
+	MaxAttempts  int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase  time.Duration `yaml:"backoff_base" env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax   time.Duration `yaml:"backoff_max" env:"WEBHOOK_BACKOFF_MAX"`
+	Timeout      time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+}
+
+type MetricsConfig struct {
+	Token string `yaml:"token" env:"METRICS_TOKEN" secret:"true"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	FilePath    string  `yaml:"file_path" env:"OTEL_TRACES_FILE_PATH"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG"`
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT"`
+	CacheTTL     time.Duration `yaml:"cache_ttl" env:"HEALTH_CACHE_TTL"`
+}
+
+type AdminConfig struct {
+	Token string `yaml:"token" env:"ADMIN_TOKEN" secret:"true"`
+}
+
+type SecretsConfig struct {
+	WatchInterval time.Duration `yaml:"watch_interval" env:"SECRETS_WATCH_INTERVAL"`
+}
+
+// BulkConfig sets where import uploads and export results are spooled; an
+// empty Dir uses the system temp directory. Spooled files older than FileTTL
+// are deleted, whether or not their job finished.
+type BulkConfig struct {
+	Dir            string        `yaml:"dir" env:"BULK_DIR"`
+	BatchSize      int           `yaml:"batch_size" env:"BULK_BATCH_SIZE"`
+	MaxUploadBytes int           `yaml:"max_upload_bytes" env:"BULK_MAX_UPLOAD_BYTES"`
+	FileTTL        time.Duration `yaml:"file_ttl" env:"BULK_FILE_TTL"`
+}
+
+type JobsConfig struct {
+	Workers      int           `yaml:"workers" env:"JOB_WORKERS"`
+	MaxAttempts  int           `yaml:"max_attempts" env:"JOB_MAX_ATTEMPTS"`
+	LeaseTTL     time.Duration `yaml:"lease_ttl" env:"JOB_LEASE_TTL"`
+	PollInterval time.Duration `yaml:"poll_interval" env:"JOB_POLL_INTERVAL"`
+	BackoffBase  time.Duration `yaml:"backoff_base" env:"JOB_BACKOFF_BASE"`
+	BackoffMax   time.Duration `yaml:"backoff_max" env:"JOB_BACKOFF_MAX"`
+}
+
+// RateLimitConfig sets how many requests each policy allows per Period. The
+// bucket refills continuously, so a client that used its whole budget gets a
+// new request every Period/limit.
+type RateLimitConfig struct {
+	Enabled bool          `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Period  time.Duration `yaml:"period" env:"RATE_LIMIT_PERIOD"`
+	Login   int           `yaml:"login" env:"RATE_LIMIT_LOGIN"`
+	Create  int           `yaml:"create" env:"RATE_LIMIT_CREATE"`
+	API     int           `yaml:"api" env:"RATE_LIMIT_API"`
+}
+
+// IdempotencyConfig sets how long the response to a request sent with an
+// Idempotency-Key is kept for replay.
+type IdempotencyConfig struct {
+	Enabled bool          `yaml:"enabled" env:"IDEMPOTENCY_ENABLED"`
+	TTL     time.Duration `yaml:"ttl" env:"IDEMPOTENCY_TTL"`
+}
+
+func Defaults() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            DefaultAddr,
+			ReadTimeout:     DefaultReadTimeout,
+			WriteTimeout:    DefaultWriteTimeout,
+			IdleTimeout:     DefaultIdleTimeout,
+			ShutdownTimeout: DefaultShutdownTimeout,
+		},
+		MySQL: MySQLConfig{
+			Host: DefaultMysqlHost,
+			Port: DefaultMysqlPort,
+		},
+		Auth: AuthConfig{
+			JWTTTL:      DefaultJWTTTL,
+			Sources:     DefaultAuthSources,
+			LoginScopes: DefaultLoginScopes,
+		},
+		Password: PasswordConfig{
+			MinLength:        DefaultPwdMinLength,
+			MaxLength:        DefaultPwdMaxLength,
+			RequireUpper:     true,
+			RequireLower:     true,
+			RequireDigit:     true,
+			HistorySize:      DefaultPwdHistorySize,
+			DisallowUserInfo: true,
+		},
+		Hashing: HashingConfig{
+			Algorithm:         DefaultHashAlgorithm,
+			BcryptCost:        DefaultBcryptCost,
+			Argon2MemoryKB:    DefaultArgon2Memory,
+			Argon2Iterations:  DefaultArgon2Iterations,
+			Argon2Parallelism: DefaultArgon2Parallelism,
+			PoolSize:          runtime.NumCPU(),
+			QueueTimeout:      DefaultHashQueueTimeout,
+		},
+		Breach: BreachConfig{
+			Mode:       BreachCheckOff,
+			APIURL:     DefaultBreachAPIURL,
+			APITimeout: DefaultBreachTimeout,
+		},
+		Federation: FederationConfig{
+			AutoProvision: true,
+		},
+		LDAP: LDAPConfig{
+			UserFilter:   DefaultLDAPUserFilter,
+			UsernameAttr: DefaultLDAPUsernameAttr,
+			NameAttr:     DefaultLDAPNameAttr,
+			LastNameAttr: DefaultLDAPLastNameAttr,
+			EmailAttr:    DefaultLDAPEmailAttr,
+			Timeout:      DefaultLDAPTimeout,
+		},
+		Events: EventsConfig{
+			Publisher:          PublisherLog,
+			PublisherTimeout:   DefaultPublisherTimeout,
+			OutboxBatchSize:    DefaultOutboxBatchSize,
+			OutboxPollInterval: DefaultOutboxPollInterval,
+			OutboxMaxAttempts:  DefaultOutboxMaxAttempts,
+			OutboxBackoffBase:  DefaultOutboxBackoffBase,
+			OutboxBackoffMax:   DefaultOutboxBackoffMax,
+			StreamBufferSize:   DefaultStreamBufferSize,
+			StreamClientQueue:  DefaultStreamClientQueue,
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:  DefaultWebhookMaxAttempts,
+			BackoffBase:  DefaultWebhookBackoffBase,
+			BackoffMax:   DefaultWebhookBackoffMax,
+			Timeout:      DefaultWebhookTimeout,
+			PollInterval: DefaultWebhookPollInterval,
+		},
+		Log: LogConfig{
+			Level: DefaultLogLevel,
+		},
+		Tracing: TracingConfig{
+			Exporter:    TraceExporterNone,
+			ServiceName: DefaultServiceName,
+			SampleRatio: 1,
+		},
+		Health: HealthConfig{
+			CheckTimeout: DefaultHealthCheckTimeout,
+			CacheTTL:     DefaultHealthCacheTTL,
+		},
+		Secrets: SecretsConfig{
+			WatchInterval: DefaultSecretsWatchInterval,
+		},
+		Bulk: BulkConfig{
+			BatchSize:      DefaultBulkBatchSize,
+			MaxUploadBytes: DefaultBulkMaxUploadBytes,
+			FileTTL:        DefaultBulkFileTTL,
+		},
+		Jobs: JobsConfig{
+			Workers:      DefaultJobWorkers,
+			MaxAttempts:  DefaultJobMaxAttempts,
+			LeaseTTL:     DefaultJobLeaseTTL,
+			PollInterval: DefaultJobPollInterval,
+			BackoffBase:  DefaultJobBackoffBase,
+			BackoffMax:   DefaultJobBackoffMax,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled: true,
+			Period:  DefaultRateLimitPeriod,
+			Login:   DefaultRateLimitLogin,
+			Create:  DefaultRateLimitCreate,
+			API:     DefaultRateLimitAPI,
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled: true,
+			TTL:     DefaultIdempotencyTTL,
+		},
+	}
+}
+
+func (c MySQLConfig) DSNRoot() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/", c.User, c.Password, c.Host, c.Port)
+}
+
+func (c MySQLConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", c.User, c.Password, c.Host, c.Port, c.Database)
+}
+
+func (c *Config) publicURL() string {
+	host := c.Server.Addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	return "http://" + host + BaseURL
+}
+
+// derive fills the values whose defaults depend on other settings.
+func (c *Config) derive() {
+	if c.OIDC.Issuer == "" {
+		c.OIDC.Issuer = c.publicURL()
+	}
+	if c.SCIM.BaseURL == "" {
+		c.SCIM.BaseURL = c.publicURL() + SCIMBasePath
+	}
+	for i, provider := range c.Federation.Providers {
+		if provider.RedirectURL == "" {
+			c.Federation.Providers[i].RedirectURL = c.publicURL() + "/federated/" + provider.Name + "/callback"
+		}
+	}
+}
+
+// Validate reports every problem at once so a bad deploy can be fixed in one pass.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	required := map[string]string{
+		"mysql.user":        c.MySQL.User,
+		"mysql.host":        c.MySQL.Host,
+		"mysql.port":        c.MySQL.Port,
+		"mysql.database":    c.MySQL.Database,
+		"mysql.table":       c.MySQL.Table,
+		"auth.jwt_secret":   c.Auth.JWTSecret,
+		"server.addr":       c.Server.Addr,
+		"log.level":         c.Log.Level,
+		"hashing.algorithm": c.Hashing.Algorithm,
+	}
+	for _, key := range sortedKeys(required) {
+		if required[key] == "" {
+			add("%s is required", key)
+		}
+	}
+
+	if c.Auth.JWTSecret != "" {
+		if err := CheckJWTSecret(c.Auth.JWTSecret); err != nil {
+			add("auth.jwt_secret: %v", err)
+		}
+	}
+
+	positive := map[string]time.Duration{
+		"server.read_timeout":         c.Server.ReadTimeout,
+		"server.write_timeout":        c.Server.WriteTimeout,
+		"server.idle_timeout":         c.Server.IdleTimeout,
+		"server.shutdown_timeout":     c.Server.ShutdownTimeout,
+		"auth.jwt_ttl":                c.Auth.JWTTTL,
+		"breach.api_timeout":          c.Breach.APITimeout,
+		"hashing.queue_timeout":       c.Hashing.QueueTimeout,
+		"ldap.timeout":                c.LDAP.Timeout,
+		"events.publisher_timeout":    c.Events.PublisherTimeout,
+		"events.outbox_poll_interval": c.Events.OutboxPollInterval,
+		"events.outbox_backoff_base":  c.Events.OutboxBackoffBase,
+		"events.outbox_backoff_max":   c.Events.OutboxBackoffMax,
+		"webhooks.backoff_base":       c.Webhooks.BackoffBase,
+		"webhooks.backoff_max":        c.Webhooks.BackoffMax,
+		"webhooks.timeout":            c.Webhooks.Timeout,
+		"webhooks.poll_interval":      c.Webhooks.PollInterval,
+		"health.check_timeout":        c.Health.CheckTimeout,
+		"health.cache_ttl":            c.Health.CacheTTL,
+		"secrets.watch_interval":      c.Secrets.WatchInterval,
+		"bulk.file_ttl":               c.Bulk.FileTTL,
+		"jobs.lease_ttl":              c.Jobs.LeaseTTL,
+		"jobs.poll_interval":          c.Jobs.PollInterval,
+		"jobs.backoff_base":           c.Jobs.BackoffBase,
+		"jobs.backoff_max":            c.Jobs.BackoffMax,
+		"rate_limit.period":           c.RateLimit.Period,
+		"idempotency.ttl":             c.Idempotency.TTL,
+	}
+	for _, key := range sortedKeys(positive) {
+		if positive[key] <= 0 {
+			add("%s must be positive", key)
+		}
+	}
+
+	counts := map[string]int{
+		"password.min_length":        c.Password.MinLength,
+		"password.max_length":        c.Password.MaxLength,
+		"hashing.argon2_memory_kb":   c.Hashing.Argon2MemoryKB,
+		"hashing.argon2_iterations":  c.Hashing.Argon2Iterations,
+		"hashing.argon2_parallelism": c.Hashing.Argon2Parallelism,
+		"hashing.pool_size":          c.Hashing.PoolSize,
+		"events.outbox_batch_size":   c.Events.OutboxBatchSize,
+		"events.outbox_max_attempts": c.Events.OutboxMaxAttempts,
+		"events.stream_buffer":       c.Events.StreamBufferSize,
+		"events.stream_client_queue": c.Events.StreamClientQueue,
+		"webhooks.max_attempts":      c.Webhooks.MaxAttempts,
+		"bulk.batch_size":            c.Bulk.BatchSize,
+		"bulk.max_upload_bytes":      c.Bulk.MaxUploadBytes,
+		"jobs.workers":               c.Jobs.Workers,
+		"jobs.max_attempts":          c.Jobs.MaxAttempts,
+		"rate_limit.login":           c.RateLimit.Login,
+		"rate_limit.create":          c.RateLimit.Create,
+		"rate_limit.api":             c.RateLimit.API,
+	}
+	for _, key := range sortedKeys(counts) {
+		if counts[key] <= 0 {
+			add("%s must be positive", key)
+		}
+	}
+
+	if c.Password.MinLength > c.Password.MaxLength {
+		add("password.min_length must not exceed password.max_length")
+	}
+	if c.Password.HistorySize < 0 {
+		add("password.history_size must not be negative")
+	}
+
+	if !slices.Contains([]string{HashArgon2id, HashBcrypt}, c.Hashing.Algorithm) {
+		add("hashing.algorithm must be one of %s, %s", HashArgon2id, HashBcrypt)
+	}
+	if c.Hashing.BcryptCost < 4 || c.Hashing.BcryptCost > 31 {
+		add("hashing.bcrypt_cost must be between 4 and 31")
+	}
+	if c.Hashing.Argon2Parallelism > 255 {
+		add("hashing.argon2_parallelism must not exceed 255")
+	}
+	if c.Hashing.Argon2MemoryKB > MaxArgon2Memory {
+		add("hashing.argon2_memory_kb must not exceed %d", MaxArgon2Memory)
+	}
+	if c.Hashing.Argon2Iterations > MaxArgon2Iterations {
+		add("hashing.argon2_iterations must not exceed %d", MaxArgon2Iterations)
+	}
+
+	switch c.Breach.Mode {
+	case BreachCheckOff, BreachCheckHTTP:
+	case BreachCheckIndex:
+		if c.Breach.IndexPath == "" && c.Breach.CorpusPath == "" {
+			add("breach.index_path or breach.corpus_path is required when breach.mode is %s", BreachCheckIndex)
+		}
+	default:
+		add("breach.mode must be one of %s, %s, %s", BreachCheckOff, BreachCheckIndex, BreachCheckHTTP)
+	}
+
+	for _, source := range c.Auth.Sources {
+		switch source {
+		case AuthSourceLocal:
+		case AuthSourceLDAP:
+			if c.LDAP.URL == "" {
+				add("ldap.url is required when auth.sources includes %s", AuthSourceLDAP)
+			}
+		default:
+			add("auth.sources: unknown source %q", source)
+		}
+	}
+
+	for _, s := range c.Auth.LoginScopes {
+		switch {
+		case s == ScopeUsersDelete:
+			add("auth.login_scopes: %s can only be granted per user", s)
+		case !slices.Contains(AllScopes, s):
+			add("auth.login_scopes: unknown scope %q", s)
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if !validProxy(proxy) {
+			add("server.trusted_proxies: %q is not an IP or CIDR", proxy)
+		}
+	}
+
+	if c.OIDC.SigningKeyPath == "" && !c.OIDC.EphemeralKey {
+		add("oidc.signing_key_path is required unless oidc.ephemeral_key is set for development")
+	}
+
+	for _, provider := range c.Federation.Providers {
+		if provider.Issuer == "" || provider.ClientID == "" {
+			add("federation.providers.%s needs issuer and client_id", provider.Name)
+		}
+	}
+
+	switch c.Events.Publisher {
+	case PublisherLog:
+	case PublisherFile:
+		if c.Events.FilePath == "" {
+			add("events.file_path is required when events.publisher is %s", PublisherFile)
+		}
+	case PublisherHTTP:
+		if !validURL(c.Events.WebhookURL) {
+			add("events.webhook_url must be an http(s) url when events.publisher is %s", PublisherHTTP)
+		}
+	default:
+		add("events.publisher must be one of %s, %s, %s", PublisherLog, PublisherFile, PublisherHTTP)
+	}
+
+	switch c.Tracing.Exporter {
+	case TraceExporterNone, TraceExporterStdout, TraceExporterOTLP:
+	case TraceExporterFile:
+		if c.Tracing.FilePath == "" {
+			add("tracing.file_path is required when tracing.exporter is %s", TraceExporterFile)
+		}
+	default:
+		add("tracing.exporter must be one of %s, %s, %s, %s", TraceExporterNone, TraceExporterStdout, TraceExporterFile, TraceExporterOTLP)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	var level slog.Level
+	if c.Log.Level != "" && level.UnmarshalText([]byte(c.Log.Level)) != nil {
+		add("log.level must be one of debug, info, warn, error")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// CheckJWTSecret rejects secrets that are too short to resist brute force for
+// HS256. It also guards rotated secrets at runtime.
+func CheckJWTSecret(secret string) error {
+	if len(secret) < MinJWTSecretLength {
+		return fmt.Errorf("%w: must be at least %d bytes", ErrWeakJWTSecret, MinJWTSecretLength)
+	}
+	if strings.Count(secret, secret[:1]) == len(secret) {
+		return fmt.Errorf("%w: single repeated character", ErrWeakJWTSecret)
+	}
+	return nil
+}
+
+type ValidationError struct {
+	Problems []string
+}
+
+func (ve *ValidationError) Error() string {
+	return fmt.Sprintf("%v:\n  - %s", ErrInvalidConfig, strings.Join(ve.Problems, "\n  - "))
+}
+
+func (ve *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+func validURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func validProxy(raw string) bool {
+	if _, _, err := net.ParseCIDR(raw); err == nil {
+		return true
+	}
+	return net.ParseIP(raw) != nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,176 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/apikey"
+	"go-manage-hex/internal/core/scope"
+	user "go-manage-hex/internal/core/user"
+
+	"github.com/google/uuid"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+// APIKeyServices checks the owner of a key on every use, so deleting a user
+// disables its keys and restoring it enables them again.
+type APIKeyServices struct {
+	Repo  entity.Repository
+	Users user.MysqlRepository
+	Now   func() time.Time
+}
+
+func NewAPIKeyService(repo entity.Repository, users user.MysqlRepository) Usecases {
+	return &APIKeyServices{Repo: repo, Users: users, Now: time.Now}
+}
+
+// CreateAPIKey mints a key for username. scopes must be a subset of granted,
+// the scopes of the credential used to ask for it, so a narrowed token cannot
+// mint a key with more rights than it has.
+func (as *APIKeyServices) CreateAPIKey(ctx context.Context, username, name string, scopes, granted []string, expiresAt *time.Time) (entity.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > config.MaxAPIKeyNameLength {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, config.ErrInvalidAPIKeyName)
+	}
+
+	if scopeErr := scope.Validate(scopes); scopeErr != nil {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, scopeErr)
+	}
+
+	scopes, scopeErr := scope.Narrow(scopes, granted)
+	if scopeErr != nil {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, config.ErrInsufficientScope)
+	}
+
+	now := as.Now().UTC()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, config.ErrInvalidAPIKeyExpiry)
+	}
+
+	if !as.Users.CheckExists(ctx, username) {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, config.ErrUserNotFound)
+	}
+
+	prefix, secret, err := generateKey()
+	if err != nil {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, err)
+	}
+
+	rawKey := config.APIKeyTokenPrefix + prefix + "_" + secret
+
+	key := entity.APIKey{
+		ID:        uuid.NewString(),
+		Username:  username,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashKey(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+
+	if createErr := as.Repo.NewAPIKey(key); createErr != nil {
+		return entity.APIKey{}, "", apperror.AppError(config.ErrCreatingAPIKey, createErr)
+	}
+
+	return key, rawKey, nil
+}
+
+func (as *APIKeyServices) ListAPIKeys(ctx context.Context, username string) ([]entity.APIKey, error) {
+	keys, err := as.Repo.ListByUsername(username)
+	if err != nil {
+		return nil, apperror.AppError(config.ErrListingAPIKeys, err)
+	}
+	return keys, nil
+}
+
+func (as *APIKeyServices) RevokeAPIKey(ctx context.Context, username, id string) error {
+	if revokeErr := as.Repo.Revoke(username, id, as.Now().UTC()); revokeErr != nil {
+		return apperror.AppError(config.ErrRevokingAPIKey, revokeErr)
+	}
+	return nil
+}
+
+func (as *APIKeyServices) Authenticate(ctx context.Context, rawKey string) (entity.APIKey, error) {
+	prefix, ok := parsePrefix(rawKey)
+	if !ok {
+		return entity.APIKey{}, config.ErrInvalidAPIKey
+	}
+
+	key, err := as.Repo.GetByPrefix(prefix)
+	if err != nil {
+		return entity.APIKey{}, config.ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashKey(rawKey))) != 1 {
+		return entity.APIKey{}, config.ErrInvalidAPIKey
+	}
+
+	now := as.Now().UTC()
+
+	if key.RevokedAt != nil {
+		return entity.APIKey{}, config.ErrAPIKeyRevoked
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return entity.APIKey{}, config.ErrAPIKeyExpired
+	}
+
+	if !as.Users.CheckExists(ctx, key.Username) {
+		return entity.APIKey{}, config.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= config.APIKeyTouchInterval {
+		if touchErr := as.Repo.TouchLastUsed(key.ID, now); touchErr != nil {
+			slog.WarnContext(ctx, "error recording api key usage", "prefix", key.Prefix, "error", touchErr)
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, config.APIKeyTokenPrefix)
+}
+
+func generateKey() (string, string, error) {
+	prefix := make([]byte, config.APIKeyPrefixBytes)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", "", err
+	}
+
+	secret := make([]byte, config.APIKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(prefix), base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+func parsePrefix(rawKey string) (string, bool) {
+	if !IsAPIKey(rawKey) {
+		return "", false
+	}
+
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(rawKey, config.APIKeyTokenPrefix), "_")
+	if !found || len(prefix) != 2*config.APIKeyPrefixBytes || secret == "" {
+		return "", false
+	}
+
+	return prefix, true
+}
+
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
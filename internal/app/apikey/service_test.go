@@ -0,0 +1,289 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/apikey"
+	user "go-manage-hex/internal/core/user"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRepository struct {
+	keys            map[string]entity.APIKey
+	NewAPIKeyFn     func(key entity.APIKey) error
+	ListFn          func(username string) ([]entity.APIKey, error)
+	RevokeFn        func(username, id string, revokedAt time.Time) error
+	TouchLastUsedFn func(id string, usedAt time.Time) error
+}
+
+func (m *mockRepository) CreateTable(tableName string) error {
+	return nil
+}
+
+func (m *mockRepository) NewAPIKey(key entity.APIKey) error {
+	if m.NewAPIKeyFn != nil {
+		return m.NewAPIKeyFn(key)
+	}
+	if m.keys == nil {
+		m.keys = map[string]entity.APIKey{}
+	}
+	m.keys[key.Prefix] = key
+	return nil
+}
+
+func (m *mockRepository) GetByPrefix(prefix string) (entity.APIKey, error) {
+	key, ok := m.keys[prefix]
+	if !ok {
+		return entity.APIKey{}, config.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+func (m *mockRepository) ListByUsername(username string) ([]entity.APIKey, error) {
+	if m.ListFn != nil {
+		return m.ListFn(username)
+	}
+	return nil, nil
+}
+
+func (m *mockRepository) Revoke(username, id string, revokedAt time.Time) error {
+	if m.RevokeFn != nil {
+		return m.RevokeFn(username, id, revokedAt)
+	}
+	return nil
+}
+
+func (m *mockRepository) TouchLastUsed(id string, usedAt time.Time) error {
+	if m.TouchLastUsedFn != nil {
+		return m.TouchLastUsedFn(id, usedAt)
+	}
+	return nil
+}
+
+type existingUsers struct {
+	user.MysqlRepository
+	usernames []string
+}
+
+func (e *existingUsers) CheckExists(ctx context.Context, username string) bool {
+	return slices.Contains(e.usernames, username)
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	granted := []string{"users:read", "users:write"}
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	test := []struct {
+		Name        string
+		KeyName     string
+		Scopes      []string
+		ExpiresAt   *time.Time
+		Deleted     bool
+		RepoErr     error
+		ExpectedErr error
+	}{
+		{
+			Name:      "CreateAPIKey_Success",
+			KeyName:   "ci",
+			Scopes:    []string{"users:read"},
+			ExpiresAt: &future,
+		},
+		{
+			Name:        "CreateAPIKey_EmptyName",
+			KeyName:     "  ",
+			ExpectedErr: config.ErrInvalidAPIKeyName,
+		},
+		{
+			Name:        "CreateAPIKey_InvalidScope",
+			KeyName:     "ci",
+			Scopes:      []string{"users:read,users:write"},
+			ExpectedErr: config.ErrInvalidScope,
+		},
+		{
+			Name:        "CreateAPIKey_ScopeNotGranted",
+			KeyName:     "ci",
+			Scopes:      []string{"users:delete"},
+			ExpectedErr: config.ErrInsufficientScope,
+		},
+		{
+			Name:        "CreateAPIKey_NoScopes",
+			KeyName:     "ci",
+			ExpectedErr: config.ErrInvalidScope,
+		},
+		{
+			Name:        "CreateAPIKey_ExpiredAlready",
+			KeyName:     "ci",
+			Scopes:      []string{"users:read"},
+			ExpiresAt:   &past,
+			ExpectedErr: config.ErrInvalidAPIKeyExpiry,
+		},
+		{
+			Name:        "CreateAPIKey_OwnerDeleted",
+			KeyName:     "ci",
+			Scopes:      []string{"users:read"},
+			Deleted:     true,
+			ExpectedErr: config.ErrUserNotFound,
+		},
+		{
+			Name:        "CreateAPIKey_RepoErr",
+			KeyName:     "ci",
+			Scopes:      []string{"users:read"},
+			RepoErr:     errors.New("db error"),
+			ExpectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var stored entity.APIKey
+			repo := mockRepository{
+				NewAPIKeyFn: func(key entity.APIKey) error {
+					stored = key
+					return tt.RepoErr
+				},
+			}
+			users := &existingUsers{usernames: []string{"johndoe"}}
+			if tt.Deleted {
+				users.usernames = nil
+			}
+			service := &APIKeyServices{Repo: &repo, Users: users, Now: func() time.Time { return now }}
+
+			created, rawKey, err := service.CreateAPIKey(context.Background(), "johndoe", tt.KeyName, tt.Scopes, granted, tt.ExpiresAt)
+
+			if tt.ExpectedErr != nil {
+				assert.Error(t, err)
+				if tt.RepoErr == nil {
+					assert.ErrorIs(t, err, tt.ExpectedErr)
+				}
+				assert.Empty(t, rawKey)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(rawKey, config.APIKeyTokenPrefix+created.Prefix+"_"))
+			assert.NotContains(t, stored.Hash, rawKey)
+			assert.Len(t, stored.Hash, 64)
+			assert.Equal(t, "johndoe", stored.Username)
+			assert.Equal(t, tt.Scopes, stored.Scopes)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recently := now.Add(-10 * time.Second)
+	expired := now.Add(-time.Minute)
+
+	test := []struct {
+		Name        string
+		Mutate      func(key *entity.APIKey)
+		RawKey      func(raw string) string
+		Deleted     bool
+		ExpectedErr error
+		ExpectTouch bool
+	}{
+		{
+			Name:        "Authenticate_Success",
+			ExpectTouch: true,
+		},
+		{
+			Name:   "Authenticate_RecentlyUsedSkipsTouch",
+			Mutate: func(key *entity.APIKey) { key.LastUsedAt = &recently },
+		},
+		{
+			Name:        "Authenticate_WrongSecret",
+			RawKey:      func(raw string) string { return raw[:len(raw)-2] + "xx" },
+			ExpectedErr: config.ErrInvalidAPIKey,
+		},
+		{
+			Name:        "Authenticate_UnknownPrefix",
+			RawKey:      func(raw string) string { return config.APIKeyTokenPrefix + "000000000000_secret" },
+			ExpectedErr: config.ErrInvalidAPIKey,
+		},
+		{
+			Name:        "Authenticate_Malformed",
+			RawKey:      func(raw string) string { return "not-a-key" },
+			ExpectedErr: config.ErrInvalidAPIKey,
+		},
+		{
+			Name:        "Authenticate_Revoked",
+			Mutate:      func(key *entity.APIKey) { key.RevokedAt = &expired },
+			ExpectedErr: config.ErrAPIKeyRevoked,
+		},
+		{
+			Name:        "Authenticate_Expired",
+			Mutate:      func(key *entity.APIKey) { key.ExpiresAt = &expired },
+			ExpectedErr: config.ErrAPIKeyExpired,
+		},
+		{
+			Name:        "Authenticate_OwnerDeleted",
+			Deleted:     true,
+			ExpectedErr: config.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			touched := false
+			repo := mockRepository{
+				TouchLastUsedFn: func(id string, usedAt time.Time) error {
+					touched = true
+					assert.Equal(t, now, usedAt)
+					return nil
+				},
+			}
+			users := &existingUsers{usernames: []string{"johndoe"}}
+			service := &APIKeyServices{Repo: &repo, Users: users, Now: func() time.Time { return now.Add(-time.Hour) }}
+
+			created, rawKey, err := service.CreateAPIKey(context.Background(), "johndoe", "ci", []string{"users:read"}, config.AllScopes, nil)
+			assert.NoError(t, err)
+
+			if tt.Mutate != nil {
+				key := repo.keys[created.Prefix]
+				tt.Mutate(&key)
+				repo.keys[created.Prefix] = key
+			}
+			if tt.RawKey != nil {
+				rawKey = tt.RawKey(rawKey)
+			}
+			if tt.Deleted {
+				users.usernames = nil
+			}
+
+			service.Now = func() time.Time { return now }
+
+			key, err := service.Authenticate(context.Background(), rawKey)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "johndoe", key.Username)
+				assert.Equal(t, []string{"users:read"}, key.Scopes)
+			}
+			assert.Equal(t, tt.ExpectTouch, touched)
+		})
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	repo := mockRepository{
+		RevokeFn: func(username, id string, revokedAt time.Time) error {
+			if id == "missing" {
+				return config.ErrAPIKeyNotFound
+			}
+			return nil
+		},
+	}
+	service := NewAPIKeyService(&repo, &existingUsers{})
+
+	assert.NoError(t, service.RevokeAPIKey(context.Background(), "johndoe", "key-1"))
+	assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), "johndoe", "missing"), config.ErrAPIKeyNotFound)
+}
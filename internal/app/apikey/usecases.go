@@ -0,0 +1,14 @@
+package apikey
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/apikey"
+	"time"
+)
+
+type Usecases interface {
+	CreateAPIKey(ctx context.Context, username, name string, scopes, granted []string, expiresAt *time.Time) (created entity.APIKey, rawKey string, err error)
+	ListAPIKeys(ctx context.Context, username string) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, username, id string) error
+	Authenticate(ctx context.Context, rawKey string) (entity.APIKey, error)
+}
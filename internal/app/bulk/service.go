@@ -0,0 +1,224 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/user"
+	entity "go-manage-hex/internal/core/bulk"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+type BulkServices struct {
+	Users     user.Usecases
+	BatchSize int
+}
+
+func NewBulkService(users user.Usecases, batchSize int) Usecases {
+	if batchSize <= 0 {
+		batchSize = config.DefaultBulkBatchSize
+	}
+	return &BulkServices{Users: users, BatchSize: batchSize}
+}
+
+type pending struct {
+	line int
+	user mysqlUser.User
+}
+
+type importRun struct {
+	report   entity.ImportReport
+	seen     map[string]int
+	batch    []pending
+	progress func(entity.ImportReport)
+}
+
+func (ir *importRun) fail(line int, username string, err error) {
+	ir.report.Failed++
+	if len(ir.report.Errors) < config.BulkMaxReportedErrors {
+		ir.report.Errors = append(ir.report.Errors, entity.RowError{Line: line, Username: username, Error: err.Error()})
+	}
+}
+
+// Import validates every row exactly like CreateUser and inserts the valid
+// ones in batches. Invalid rows are reported and skipped; only read or
+// database failures stop the import.
+func (bs *BulkServices) Import(ctx context.Context, records entity.RecordReader, options entity.ImportOptions, progress func(entity.ImportReport)) (entity.ImportReport, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = bs.BatchSize
+	}
+	if progress == nil {
+		progress = func(entity.ImportReport) {}
+	}
+
+	run := &importRun{
+		report:   entity.ImportReport{DryRun: options.DryRun, LastLine: options.ResumeAfter},
+		seen:     map[string]int{},
+		progress: progress,
+	}
+
+	lastRead := options.ResumeAfter
+	for {
+		if err := ctx.Err(); err != nil {
+			return run.report, err
+		}
+
+		record, err := records.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var parseErr *entity.ParseError
+		if errors.As(err, &parseErr) {
+			if parseErr.Line > options.ResumeAfter {
+				run.report.Processed++
+				run.fail(parseErr.Line, "", err)
+				lastRead = parseErr.Line
+			}
+			continue
+		}
+		if err != nil {
+			return run.report, apperror.AppError(config.ErrImportingUsers, err)
+		}
+
+		if record.Line <= options.ResumeAfter {
+			continue
+		}
+		run.report.Processed++
+		lastRead = record.Line
+
+		if duplicate := run.remember(record); duplicate {
+			run.fail(record.Line, record.User.Username, config.ErrDuplicateInImport)
+			continue
+		}
+
+		prepared, prepareErr := bs.prepare(ctx, record)
+		if prepareErr != nil && ctx.Err() != nil {
+			return run.report, ctx.Err()
+		}
+		if prepareErr != nil {
+			run.fail(record.Line, record.User.Username, prepareErr)
+			continue
+		}
+
+		if options.DryRun {
+			run.report.Created++
+			run.report.LastLine = lastRead
+			if run.report.Created%options.BatchSize == 0 {
+				progress(run.report)
+			}
+			continue
+		}
+
+		run.batch = append(run.batch, pending{line: record.Line, user: prepared})
+		if len(run.batch) >= options.BatchSize {
+			if err := bs.flush(ctx, run, lastRead); err != nil {
+				return run.report, err
+			}
+		}
+	}
+
+	if err := bs.flush(ctx, run, lastRead); err != nil {
+		return run.report, err
+	}
+	run.report.LastLine = lastRead
+	progress(run.report)
+
+	return run.report, nil
+}
+
+// prepare hashes as background work: the pool makes an import wait for one
+// of its background slots instead of failing the row or taking the slots
+// logins need.
+func (bs *BulkServices) prepare(ctx context.Context, record entity.Record) (mysqlUser.User, error) {
+	return bs.Users.PrepareUser(mysqlUser.WithBackground(ctx), record.User, record.PreHashed)
+}
+
+// remember tracks usernames and emails so repeats inside the same file are
+// rejected before they reach the unique indexes.
+func (ir *importRun) remember(record entity.Record) bool {
+	keys := []string{"u:" + strings.ToLower(record.User.Username), "e:" + strings.ToLower(record.User.Email)}
+	for _, key := range keys {
+		if _, ok := ir.seen[key]; ok {
+			return true
+		}
+	}
+	for _, key := range keys {
+		ir.seen[key] = record.Line
+	}
+	return false
+}
+
+// flush inserts the batch in one transaction. When the batch is rejected it
+// retries row by row so the failure is reported against the right line.
+func (bs *BulkServices) flush(ctx context.Context, run *importRun, lastRead int) error {
+	if len(run.batch) == 0 {
+		return nil
+	}
+
+	users := make([]mysqlUser.User, len(run.batch))
+	for i, p := range run.batch {
+		users[i] = p.user
+	}
+
+	if err := bs.Users.CreateUsers(ctx, users); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		slog.WarnContext(ctx, "import batch rejected, retrying row by row", "rows", len(users), "error", err)
+
+		for _, p := range run.batch {
+			if rowErr := bs.Users.CreateUsers(ctx, []mysqlUser.User{p.user}); rowErr != nil {
+				run.fail(p.line, p.user.Username, rowErr)
+				continue
+			}
+			run.report.Created++
+		}
+	} else {
+		run.report.Created += len(users)
+	}
+
+	run.batch = run.batch[:0]
+	run.report.LastLine = lastRead
+	run.progress(run.report)
+	return nil
+}
+
+// Export pages through the users and writes them as they are read, so memory
+// stays flat regardless of the table size.
+func (bs *BulkServices) Export(ctx context.Context, records entity.RecordWriter, options entity.ExportOptions) (int, error) {
+	exported := 0
+	for {
+		users, _, err := bs.Users.ListUsers(ctx, mysqlUser.UserQuery{
+			Filter: options.Filter,
+			Offset: exported,
+			Limit:  config.BulkExportPageSize,
+		})
+		if err != nil {
+			return exported, apperror.AppError(config.ErrExportingUsers, err)
+		}
+
+		for _, u := range users {
+			u.Password = ""
+			if err := records.Write(u); err != nil {
+				return exported, apperror.AppError(config.ErrExportingUsers, err)
+			}
+			exported++
+		}
+
+		if len(users) < config.BulkExportPageSize {
+			break
+		}
+	}
+
+	if err := records.Flush(); err != nil {
+		return exported, apperror.AppError(config.ErrExportingUsers, err)
+	}
+	return exported, nil
+}
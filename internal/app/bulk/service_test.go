@@ -0,0 +1,289 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/bulk"
+	userEntity "go-manage-hex/internal/core/user"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryUsers struct {
+	mu      sync.Mutex
+	users   []userEntity.User
+	batches int
+	reject  string
+	// foreground counts PrepareUser calls not marked as background work.
+	foreground int
+}
+
+func (m *memoryUsers) exists(username string) bool {
+	for _, u := range m.users {
+		if u.Username == username {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memoryUsers) SearchUser(ctx context.Context, username string) (userEntity.User, error) {
+	return userEntity.User{}, config.ErrUserNotFound
+}
+
+func (m *memoryUsers) SearchUserByID(ctx context.Context, id string) (userEntity.User, error) {
+	return userEntity.User{}, config.ErrUserNotFound
+}
+
+func (m *memoryUsers) SearchUserByIDWithDeleted(ctx context.Context, id string) (userEntity.User, error) {
+	return userEntity.User{}, config.ErrUserNotFound
+}
+
+func (m *memoryUsers) ListUsers(ctx context.Context, query userEntity.UserQuery) ([]userEntity.User, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	end := min(query.Offset+query.Limit, len(m.users))
+	if query.Offset >= end {
+		return nil, len(m.users), nil
+	}
+	return append([]userEntity.User(nil), m.users[query.Offset:end]...), len(m.users), nil
+}
+
+func (m *memoryUsers) CreateUser(ctx context.Context, u userEntity.User) (userEntity.User, error) {
+	return u, nil
+}
+
+func (m *memoryUsers) PrepareUser(ctx context.Context, u userEntity.User, preHashed bool) (userEntity.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !userEntity.IsBackground(ctx) {
+		m.foreground++
+	}
+
+	switch {
+	case m.exists(u.Username):
+		return userEntity.User{}, config.ErrUserAlreadyExists
+	case !strings.Contains(u.Email, "@"):
+		return userEntity.User{}, config.ErrInvalidEmail
+	case preHashed && !strings.HasPrefix(u.Password, "$"):
+		return userEntity.User{}, config.ErrInvalidHash
+	case !preHashed:
+		u.Password = "$hashed$" + u.Password
+	}
+	u.ID = "id-" + u.Username
+	return u, nil
+}
+
+func (m *memoryUsers) CreateUsers(ctx context.Context, users []userEntity.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.batches++
+	for _, u := range users {
+		if u.Username == m.reject {
+			return errors.New("duplicate entry")
+		}
+	}
+	m.users = append(m.users, users...)
+	return nil
+}
+
+func (m *memoryUsers) DeleteUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *memoryUsers) RestoreUser(ctx context.Context, username string) error {
+	return nil
+}
+
+func (m *memoryUsers) UpdateUser(ctx context.Context, username string, u userEntity.User) (userEntity.User, error) {
+	return u, nil
+}
+
+func (m *memoryUsers) UpdateUserAndPwd(ctx context.Context, username string, u userEntity.User, newPwd string) (userEntity.User, error) {
+	return u, nil
+}
+
+func (m *memoryUsers) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
+	return nil
+}
+
+func (m *memoryUsers) Login(ctx context.Context, username, password string) error {
+	return nil
+}
+
+type sliceRecords struct {
+	rows []any
+}
+
+// Next returns the next row; an error entry becomes a parse error on that line.
+func (s *sliceRecords) Next() (entity.Record, error) {
+	if len(s.rows) == 0 {
+		return entity.Record{}, io.EOF
+	}
+	row := s.rows[0]
+	s.rows = s.rows[1:]
+
+	if err, ok := row.(*entity.ParseError); ok {
+		return entity.Record{}, err
+	}
+	return row.(entity.Record), nil
+}
+
+type memoryWriter struct {
+	users   []userEntity.User
+	flushed bool
+}
+
+func (m *memoryWriter) Write(u userEntity.User) error {
+	m.users = append(m.users, u)
+	return nil
+}
+
+func (m *memoryWriter) Flush() error {
+	m.flushed = true
+	return nil
+}
+
+func row(line int, username, email string) entity.Record {
+	return entity.Record{Line: line, User: userEntity.User{Username: username, Email: email, Password: "Str0ngPassw0rd"}}
+}
+
+func testRows() []any {
+	return []any{
+		row(2, "janedoe", "jane@doe.com"),
+		row(3, "johndoe", "john-at-doe.com"),
+		row(4, "JaneDoe", "other@doe.com"),
+		&entity.ParseError{Line: 5, Err: errors.New("bad quote")},
+		row(6, "alice", "alice@doe.com"),
+		row(7, "bob", "bob@doe.com"),
+		entity.Record{Line: 8, PreHashed: true, User: userEntity.User{Username: "carol", Email: "carol@doe.com", Password: "$argon2id$v=19$hash"}},
+		entity.Record{Line: 9, PreHashed: true, User: userEntity.User{Username: "dave", Email: "dave@doe.com", Password: "plain"}},
+	}
+}
+
+func TestImport(t *testing.T) {
+	test := []struct {
+		Name            string
+		Options         entity.ImportOptions
+		Reject          string
+		ExpectedReport  entity.ImportReport
+		ExpectedUsers   []string
+		ExpectedBatches int
+		ExpectedLines   []int
+	}{
+		{
+			Name:            "Import_ReportsRowErrorsAndBatches",
+			Options:         entity.ImportOptions{BatchSize: 2},
+			ExpectedReport:  entity.ImportReport{Processed: 8, Created: 4, Failed: 4, LastLine: 9},
+			ExpectedUsers:   []string{"janedoe", "alice", "bob", "carol"},
+			ExpectedBatches: 2,
+			ExpectedLines:   []int{3, 4, 5, 9},
+		},
+		{
+			Name:            "Import_DryRunCreatesNothing",
+			Options:         entity.ImportOptions{DryRun: true},
+			ExpectedReport:  entity.ImportReport{DryRun: true, Processed: 8, Created: 4, Failed: 4, LastLine: 9},
+			ExpectedBatches: 0,
+			ExpectedLines:   []int{3, 4, 5, 9},
+		},
+		{
+			Name:            "Import_FallsBackToSingleRows",
+			Options:         entity.ImportOptions{BatchSize: 10},
+			Reject:          "alice",
+			ExpectedReport:  entity.ImportReport{Processed: 8, Created: 3, Failed: 5, LastLine: 9},
+			ExpectedUsers:   []string{"janedoe", "bob", "carol"},
+			ExpectedBatches: 5,
+			ExpectedLines:   []int{3, 4, 5, 9, 6},
+		},
+		{
+			Name:            "Import_ResumesAfterLine",
+			Options:         entity.ImportOptions{ResumeAfter: 5},
+			ExpectedReport:  entity.ImportReport{Processed: 4, Created: 3, Failed: 1, LastLine: 9},
+			ExpectedUsers:   []string{"alice", "bob", "carol"},
+			ExpectedBatches: 1,
+			ExpectedLines:   []int{9},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			users := &memoryUsers{reject: tt.Reject}
+			service := NewBulkService(users, 0)
+
+			var progress []entity.ImportReport
+			report, err := service.Import(context.Background(), &sliceRecords{rows: testRows()}, tt.Options, func(r entity.ImportReport) {
+				progress = append(progress, r)
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.ExpectedBatches, users.batches)
+
+			var lines []int
+			for _, rowErr := range report.Errors {
+				lines = append(lines, rowErr.Line)
+			}
+			assert.Equal(t, tt.ExpectedLines, lines)
+
+			report.Errors = nil
+			assert.Equal(t, tt.ExpectedReport, report)
+
+			var created []string
+			for _, u := range users.users {
+				created = append(created, u.Username)
+				assert.True(t, strings.HasPrefix(u.Password, "$"))
+			}
+			assert.Equal(t, tt.ExpectedUsers, created)
+
+			require.NotEmpty(t, progress)
+			assert.Equal(t, report.LastLine, progress[len(progress)-1].LastLine)
+		})
+	}
+}
+
+func TestImport_HashesAsBackgroundWork(t *testing.T) {
+	users := &memoryUsers{}
+
+	report, err := NewBulkService(users, 0).Import(context.Background(), &sliceRecords{rows: testRows()}, entity.ImportOptions{}, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Created)
+	assert.Equal(t, 4, report.Failed)
+	assert.Zero(t, users.foreground)
+}
+
+func TestImport_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := NewBulkService(&memoryUsers{}, 0).Import(ctx, &sliceRecords{rows: testRows()}, entity.ImportOptions{ResumeAfter: 4}, nil)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 4, report.LastLine)
+}
+
+func TestExport(t *testing.T) {
+	users := &memoryUsers{}
+	for i := 0; i < config.BulkExportPageSize+3; i++ {
+		users.users = append(users.users, userEntity.User{Username: "user", Password: "$argon2id$hash"})
+	}
+	writer := &memoryWriter{}
+
+	exported, err := NewBulkService(users, 0).Export(context.Background(), writer, entity.ExportOptions{})
+
+	require.NoError(t, err)
+	assert.Equal(t, config.BulkExportPageSize+3, exported)
+	assert.Len(t, writer.users, exported)
+	assert.True(t, writer.flushed)
+	for _, u := range writer.users {
+		assert.Empty(t, u.Password)
+	}
+}
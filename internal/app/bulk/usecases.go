@@ -0,0 +1,11 @@
+package bulk
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/bulk"
+)
+
+type Usecases interface {
+	Import(ctx context.Context, records entity.RecordReader, options entity.ImportOptions, progress func(entity.ImportReport)) (entity.ImportReport, error)
+	Export(ctx context.Context, records entity.RecordWriter, options entity.ExportOptions) (int, error)
+}
@@ -0,0 +1,137 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	entity "go-manage-hex/internal/core/event"
+)
+
+// Relay publishes outbox events. Each replica runs one; an event is claimed
+// before it is published so only one relay sends it, and events of the same
+// aggregate go out one at a time, in order.
+type Relay struct {
+	Outbox      entity.OutboxRepository
+	Publisher   entity.Publisher
+	BatchSize   int
+	Interval    time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	Lease       time.Duration
+	Now         func() time.Time
+}
+
+func NewRelay(outbox entity.OutboxRepository, publisher entity.Publisher, batchSize int, interval time.Duration, maxAttempts int, backoffBase, backoffMax, lease time.Duration) *Relay {
+	return &Relay{
+		Outbox:      outbox,
+		Publisher:   publisher,
+		BatchSize:   batchSize,
+		Interval:    interval,
+		MaxAttempts: maxAttempts,
+		BackoffBase: backoffBase,
+		BackoffMax:  backoffMax,
+		Lease:       lease,
+		Now:         time.Now,
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil {
+			slog.ErrorContext(ctx, "error relaying outbox events", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush publishes what is due. Each round only sees the head event of every
+// aggregate, so it keeps going while rounds make progress.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		n, err := r.flushHeads(ctx)
+		published += n
+		if err != nil || n == 0 {
+			return published, err
+		}
+	}
+}
+
+func (r *Relay) flushHeads(ctx context.Context) (int, error) {
+	pending, err := r.Outbox.Pending(r.Now().UTC(), r.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range pending {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+
+		now := r.Now().UTC()
+		claimed, claimErr := r.Outbox.Claim(e.Sequence, now, now.Add(r.Lease))
+		if claimErr != nil {
+			slog.ErrorContext(ctx, "error claiming outbox event", "event_id", e.ID, "error", claimErr)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if publishErr := r.Publisher.Publish(ctx, e); publishErr != nil {
+			r.fail(ctx, e, publishErr)
+			continue
+		}
+
+		// The claim expires if this fails, so the event goes out again.
+		if markErr := r.Outbox.MarkPublished(e.Sequence, r.Now().UTC()); markErr != nil {
+			slog.ErrorContext(ctx, "error marking event as published", "event_id", e.ID, "error", markErr)
+			continue
+		}
+
+		published++
+	}
+
+	return published, nil
+}
+
+// fail schedules the next attempt, or dead-letters the event once it ran
+// out of attempts so the events behind it are no longer held up.
+func (r *Relay) fail(ctx context.Context, e entity.Event, publishErr error) {
+	now := r.Now().UTC()
+	attempts := e.Attempts + 1
+
+	if attempts >= r.MaxAttempts {
+		slog.ErrorContext(ctx, "outbox event dead-lettered", "event_id", e.ID, "attempts", attempts, "error", publishErr)
+		if markErr := r.Outbox.MarkDead(e.Sequence, publishErr.Error(), now); markErr != nil {
+			slog.ErrorContext(ctx, "error dead-lettering event", "event_id", e.ID, "error", markErr)
+		}
+		return
+	}
+
+	if markErr := r.Outbox.MarkFailed(e.Sequence, publishErr.Error(), now.Add(r.backoff(attempts))); markErr != nil {
+		slog.ErrorContext(ctx, "error recording failed event", "event_id", e.ID, "error", markErr)
+	}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.BackoffBase
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= r.BackoffMax {
+			return r.BackoffMax
+		}
+	}
+	return min(wait, r.BackoffMax)
+}
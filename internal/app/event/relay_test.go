@@ -0,0 +1,249 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	entity "go-manage-hex/internal/core/event"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryOutbox struct {
+	mu          sync.Mutex
+	events      []entity.Event
+	published   map[int64]bool
+	dead        map[int64]bool
+	failures    map[int64]int
+	nextAttempt map[int64]time.Time
+}
+
+func newMemoryOutbox(events ...entity.Event) *memoryOutbox {
+	outbox := &memoryOutbox{
+		published:   map[int64]bool{},
+		dead:        map[int64]bool{},
+		failures:    map[int64]int{},
+		nextAttempt: map[int64]time.Time{},
+	}
+	outbox.Record(events...)
+	return outbox
+}
+
+func (m *memoryOutbox) CreateTable(tableName string) error {
+	return nil
+}
+
+func (m *memoryOutbox) Record(events ...entity.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range events {
+		e.Sequence = int64(len(m.events) + 1)
+		m.events = append(m.events, e)
+	}
+	return nil
+}
+
+func (m *memoryOutbox) Pending(now time.Time, limit int) ([]entity.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pending []entity.Event
+	seen := map[string]bool{}
+	for _, e := range m.events {
+		if m.published[e.Sequence] || m.dead[e.Sequence] || seen[e.Aggregate] {
+			continue
+		}
+		seen[e.Aggregate] = true
+		if m.nextAttempt[e.Sequence].After(now) || len(pending) == limit {
+			continue
+		}
+		e.Attempts = m.failures[e.Sequence]
+		pending = append(pending, e)
+	}
+	return pending, nil
+}
+
+func (m *memoryOutbox) Claim(sequence int64, now, leaseUntil time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.published[sequence] || m.dead[sequence] || m.nextAttempt[sequence].After(now) {
+		return false, nil
+	}
+	m.nextAttempt[sequence] = leaseUntil
+	return true, nil
+}
+
+func (m *memoryOutbox) MarkPublished(sequence int64, publishedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.published[sequence] = true
+	return nil
+}
+
+func (m *memoryOutbox) MarkFailed(sequence int64, reason string, nextAttemptAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures[sequence]++
+	m.nextAttempt[sequence] = nextAttemptAt
+	return nil
+}
+
+func (m *memoryOutbox) MarkDead(sequence int64, reason string, deadAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failures[sequence]++
+	m.dead[sequence] = true
+	return nil
+}
+
+type flakyPublisher struct {
+	mu       sync.Mutex
+	failing  map[string]bool
+	received []string
+}
+
+func (fp *flakyPublisher) Publish(ctx context.Context, e entity.Event) error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	if fp.failing[e.ID] {
+		return errors.New("receiver down")
+	}
+	fp.received = append(fp.received, e.ID)
+	return nil
+}
+
+func events(specs ...string) []entity.Event {
+	var out []entity.Event
+	for i := 0; i < len(specs); i += 2 {
+		out = append(out, entity.Event{ID: specs[i], Aggregate: specs[i+1]})
+	}
+	return out
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestRelay(outbox *memoryOutbox, publisher entity.Publisher, at *clock) *Relay {
+	relay := NewRelay(outbox, publisher, 10, time.Second, 3, time.Second, 4*time.Second, time.Minute)
+	relay.Now = at.Now
+	return relay
+}
+
+func TestRelayFlush_OrderPerAggregate(t *testing.T) {
+	outbox := newMemoryOutbox(events("a1", "alice", "b1", "bob", "a2", "alice", "b2", "bob")...)
+	publisher := &flakyPublisher{failing: map[string]bool{"a1": true}}
+	at := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	relay := newTestRelay(outbox, publisher, at)
+
+	published, err := relay.Flush(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{"b1", "b2"}, publisher.received)
+	assert.Equal(t, 1, outbox.failures[1])
+
+	publisher.failing = nil
+	published, err = relay.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published, "a1 is backing off")
+
+	at.now = at.now.Add(time.Second)
+	published, err = relay.Flush(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{"b1", "b2", "a1", "a2"}, publisher.received)
+
+	published, err = relay.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestRelayFlush_BackoffAndDeadLetter(t *testing.T) {
+	outbox := newMemoryOutbox(events("a1", "alice", "a2", "alice")...)
+	publisher := &flakyPublisher{failing: map[string]bool{"a1": true}}
+	at := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	relay := newTestRelay(outbox, publisher, at)
+
+	waits := []time.Duration{time.Second, 2 * time.Second}
+	for i, wait := range waits {
+		_, err := relay.Flush(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, i+1, outbox.failures[1])
+		assert.Equal(t, at.now.Add(wait), outbox.nextAttempt[1])
+		at.now = at.now.Add(wait)
+	}
+
+	_, err := relay.Flush(context.Background())
+	require.NoError(t, err)
+	assert.True(t, outbox.dead[1])
+
+	published, err := relay.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"a2"}, publisher.received)
+}
+
+func TestRelayFlush_ClaimedOnce(t *testing.T) {
+	outbox := newMemoryOutbox(events("a1", "alice", "b1", "bob")...)
+	publisher := &flakyPublisher{}
+	at := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	first := newTestRelay(outbox, publisher, at)
+	second := newTestRelay(outbox, publisher, at)
+
+	heads, err := outbox.Pending(at.now, 10)
+	require.NoError(t, err)
+	claimed, err := outbox.Claim(heads[0].Sequence, at.now, at.now.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	published, err := first.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	published, err = second.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Equal(t, []string{"b1"}, publisher.received)
+}
+
+func TestRelayRun_StopsWithContext(t *testing.T) {
+	outbox := newMemoryOutbox(events("a1", "alice")...)
+	publisher := &flakyPublisher{}
+	relay := NewRelay(outbox, publisher, 10, 10*time.Millisecond, 3, time.Second, time.Second, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return len(publisher.received) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
@@ -0,0 +1,127 @@
+package event
+
+import (
+	"context"
+	"sync"
+
+	entity "go-manage-hex/internal/core/event"
+)
+
+type StreamEvent struct {
+	ID    int64
+	Event entity.Event
+}
+
+type StreamClient struct {
+	Events chan StreamEvent
+}
+
+type Streamer interface {
+	Subscribe(lastID int64, resume bool) (backlog []StreamEvent, reset bool, client *StreamClient)
+	Unsubscribe(client *StreamClient)
+}
+
+// Broadcaster keeps the latest events in a ring buffer and fans them out to
+// connected clients. A client whose queue is full is disconnected instead of
+// blocking the publisher; it can resume from the buffer with its last id.
+type Broadcaster struct {
+	mu          sync.Mutex
+	ring        []StreamEvent
+	start       int
+	size        int
+	lastID      int64
+	seen        map[string]struct{}
+	clients     map[*StreamClient]struct{}
+	clientQueue int
+}
+
+func NewBroadcaster(bufferSize, clientQueue int) *Broadcaster {
+	return &Broadcaster{
+		ring:        make([]StreamEvent, bufferSize),
+		seen:        map[string]struct{}{},
+		clients:     map[*StreamClient]struct{}{},
+		clientQueue: clientQueue,
+	}
+}
+
+func (b *Broadcaster) Publish(ctx context.Context, e entity.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.seen[e.ID]; ok {
+		return nil
+	}
+
+	b.lastID++
+	streamed := StreamEvent{ID: b.lastID, Event: e}
+
+	if b.size == len(b.ring) {
+		delete(b.seen, b.ring[b.start].Event.ID)
+		b.ring[b.start] = streamed
+		b.start = (b.start + 1) % len(b.ring)
+	} else {
+		b.ring[(b.start+b.size)%len(b.ring)] = streamed
+		b.size++
+	}
+	b.seen[e.ID] = struct{}{}
+
+	for client := range b.clients {
+		select {
+		case client.Events <- streamed:
+		default:
+			delete(b.clients, client)
+			close(client.Events)
+		}
+	}
+
+	return nil
+}
+
+func (b *Broadcaster) Subscribe(lastID int64, resume bool) ([]StreamEvent, bool, *StreamClient) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	client := &StreamClient{Events: make(chan StreamEvent, b.clientQueue)}
+	b.clients[client] = struct{}{}
+
+	if !resume {
+		return nil, false, client
+	}
+
+	oldest := b.lastID - int64(b.size) + 1
+	reset := lastID > b.lastID || lastID < oldest-1
+	if reset {
+		lastID = oldest - 1
+	}
+
+	var backlog []StreamEvent
+	for i := 0; i < b.size; i++ {
+		streamed := b.ring[(b.start+i)%len(b.ring)]
+		if streamed.ID > lastID {
+			backlog = append(backlog, streamed)
+		}
+	}
+
+	return backlog, reset, client
+}
+
+func (b *Broadcaster) Unsubscribe(client *StreamClient) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.clients[client]; ok {
+		delete(b.clients, client)
+		close(client.Events)
+	}
+}
+
+// Close disconnects every client so open streams end and the server can drain.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for client := range b.clients {
+		delete(b.clients, client)
+		close(client.Events)
+	}
+}
@@ -0,0 +1,143 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	entity "go-manage-hex/internal/core/event"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func publishN(b *Broadcaster, from, to int) {
+	for i := from; i <= to; i++ {
+		b.Publish(context.Background(), entity.Event{ID: fmt.Sprintf("evt-%d", i), Type: "user.created"})
+	}
+}
+
+func ids(events []StreamEvent) []int64 {
+	var out []int64
+	for _, e := range events {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestBroadcasterResume(t *testing.T) {
+	b := NewBroadcaster(3, 8)
+	publishN(b, 1, 5)
+
+	test := []struct {
+		Name          string
+		LastID        int64
+		Resume        bool
+		ExpectedIDs   []int64
+		ExpectedReset bool
+	}{
+		{
+			Name: "Subscribe_LiveOnly",
+		},
+		{
+			Name:        "Subscribe_ResumeWithinBuffer",
+			LastID:      3,
+			Resume:      true,
+			ExpectedIDs: []int64{4, 5},
+		},
+		{
+			Name:        "Subscribe_ResumeUpToDate",
+			LastID:      5,
+			Resume:      true,
+			ExpectedIDs: nil,
+		},
+		{
+			Name:        "Subscribe_ResumeAtBufferEdge",
+			LastID:      2,
+			Resume:      true,
+			ExpectedIDs: []int64{3, 4, 5},
+		},
+		{
+			Name:          "Subscribe_ResumeTooOld",
+			LastID:        1,
+			Resume:        true,
+			ExpectedIDs:   []int64{3, 4, 5},
+			ExpectedReset: true,
+		},
+		{
+			Name:          "Subscribe_ResumeFromFuture",
+			LastID:        42,
+			Resume:        true,
+			ExpectedIDs:   []int64{3, 4, 5},
+			ExpectedReset: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			backlog, reset, client := b.Subscribe(tt.LastID, tt.Resume)
+			defer b.Unsubscribe(client)
+
+			assert.Equal(t, tt.ExpectedIDs, ids(backlog))
+			assert.Equal(t, tt.ExpectedReset, reset)
+		})
+	}
+}
+
+func TestBroadcasterFanOut(t *testing.T) {
+	b := NewBroadcaster(10, 8)
+	_, _, client := b.Subscribe(0, false)
+
+	publishN(b, 1, 2)
+	b.Publish(context.Background(), entity.Event{ID: "evt-1", Type: "user.created"})
+
+	assert.Equal(t, int64(1), (<-client.Events).ID)
+	assert.Equal(t, int64(2), (<-client.Events).ID)
+	assert.Empty(t, client.Events)
+
+	b.Unsubscribe(client)
+	_, open := <-client.Events
+	assert.False(t, open)
+	b.Unsubscribe(client)
+}
+
+func TestBroadcasterDropsSlowClient(t *testing.T) {
+	b := NewBroadcaster(10, 2)
+	_, _, slow := b.Subscribe(0, false)
+	_, _, fast := b.Subscribe(0, false)
+	defer b.Unsubscribe(fast)
+
+	var received []int64
+	for i := 1; i <= 5; i++ {
+		publishN(b, i, i)
+		received = append(received, (<-fast.Events).ID)
+	}
+
+	var buffered []int64
+	for e := range slow.Events {
+		buffered = append(buffered, e.ID)
+	}
+
+	assert.Equal(t, []int64{1, 2}, buffered)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, received)
+
+	backlog, reset, resumed := b.Subscribe(2, true)
+	defer b.Unsubscribe(resumed)
+	assert.False(t, reset)
+	assert.Equal(t, []int64{3, 4, 5}, ids(backlog))
+}
+
+func TestBroadcasterClose(t *testing.T) {
+	b := NewBroadcaster(10, 8)
+	_, _, first := b.Subscribe(0, false)
+	_, _, second := b.Subscribe(0, false)
+
+	b.Close()
+
+	_, open := <-first.Events
+	assert.False(t, open)
+	_, open = <-second.Events
+	assert.False(t, open)
+
+	b.Unsubscribe(first)
+	assert.NoError(t, b.Publish(context.Background(), entity.Event{ID: "evt-1", Type: "user.created"}))
+}
@@ -0,0 +1,237 @@
+package federation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+var invalidUsernameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+type FederationServices struct {
+	Providers     map[string]entity.IdentityProvider
+	Identities    entity.IdentityRepository
+	States        entity.StateStore
+	Users         mysqlUser.MysqlRepository
+	Provisioner   mysqlUser.Provisioner
+	AutoProvision bool
+	LinkByEmail   bool
+	Now           func() time.Time
+}
+
+func NewFederationService(providers []entity.IdentityProvider, identities entity.IdentityRepository, states entity.StateStore, users mysqlUser.MysqlRepository, provisioner mysqlUser.Provisioner, autoProvision, linkByEmail bool) Usecases {
+	byName := map[string]entity.IdentityProvider{}
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	return &FederationServices{
+		Providers:     byName,
+		Identities:    identities,
+		States:        states,
+		Users:         users,
+		Provisioner:   provisioner,
+		AutoProvision: autoProvision,
+		LinkByEmail:   linkByEmail,
+		Now:           time.Now,
+	}
+}
+
+// Begin starts a login or link at the IdP. The returned binding has to come
+// back from the same browser on the callback, so a callback URL an attacker
+// got from their own login cannot be completed in someone else's session.
+func (fs *FederationServices) Begin(ctx context.Context, provider, linkUsername string) (string, string, error) {
+	idp, ok := fs.Providers[provider]
+	if !ok {
+		return "", "", config.ErrUnknownProvider
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	saveErr := fs.States.Save(entity.PendingLogin{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUsername: linkUsername,
+		ExpiresAt:    fs.Now().Add(config.FederatedStateTTL),
+	})
+	if saveErr != nil {
+		return "", "", saveErr
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	redirectURL, err := idp.AuthCodeURL(ctx, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	if err != nil {
+		return "", "", err
+	}
+
+	return redirectURL, stateBinding(state), nil
+}
+
+func (fs *FederationServices) Complete(ctx context.Context, provider, code, state, binding string) (string, error) {
+	idp, ok := fs.Providers[provider]
+	if !ok {
+		return "", config.ErrUnknownProvider
+	}
+
+	// Checked before consuming, so a forged callback cannot burn the state
+	// of the real one.
+	if subtle.ConstantTimeCompare([]byte(binding), []byte(stateBinding(state))) != 1 {
+		return "", config.ErrInvalidState
+	}
+
+	pending, err := fs.States.Consume(state)
+	if err != nil || pending.Provider != provider || !fs.Now().Before(pending.ExpiresAt) {
+		return "", config.ErrInvalidState
+	}
+
+	claims, err := idp.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return "", apperror.AppError(config.ErrFederatedLogin, err)
+	}
+
+	if identity, err := fs.Identities.GetIdentity(provider, claims.Subject); err == nil {
+		if pending.LinkUsername != "" && pending.LinkUsername != identity.Username {
+			return "", config.ErrIdentityLinked
+		}
+		// Deleting a user keeps the link, so it is restored with the user.
+		if !fs.Users.CheckExists(ctx, identity.Username) {
+			return "", config.ErrAccountNotProvisioned
+		}
+		return identity.Username, nil
+	}
+
+	if pending.LinkUsername != "" {
+		return pending.LinkUsername, fs.link(provider, claims, pending.LinkUsername)
+	}
+
+	if fs.LinkByEmail && claims.EmailVerified && claims.Email != "" {
+		if existing, err := fs.Users.GetByEmail(ctx, claims.Email); err == nil {
+			return existing.Username, fs.link(provider, claims, existing.Username)
+		}
+	}
+
+	if !fs.AutoProvision {
+		return "", config.ErrAccountNotProvisioned
+	}
+
+	username, err := fs.provision(ctx, claims)
+	if err != nil {
+		return "", apperror.AppError(config.ErrFederatedLogin, err)
+	}
+
+	return username, fs.link(provider, claims, username)
+}
+
+func (fs *FederationServices) link(provider string, claims entity.Claims, username string) error {
+	linkErr := fs.Identities.LinkIdentity(entity.Identity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Username: username,
+		Email:    claims.Email,
+		LinkedAt: fs.Now().UTC(),
+	})
+	if linkErr != nil {
+		return apperror.AppError(config.ErrFederatedLogin, linkErr)
+	}
+	return nil
+}
+
+func (fs *FederationServices) provision(ctx context.Context, claims entity.Claims) (string, error) {
+	if claims.Email == "" {
+		return "", config.ErrMissingEmail
+	}
+
+	username, err := fs.uniqueUsername(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	name := claims.GivenName
+	if name == "" {
+		name = username
+	}
+
+	created, createErr := fs.Provisioner.ProvisionUser(ctx, mysqlUser.User{
+		Name:     name,
+		LastName: claims.FamilyName,
+		Username: username,
+		Email:    claims.Email,
+	})
+	if createErr != nil {
+		return "", createErr
+	}
+
+	return created.Username, nil
+}
+
+func (fs *FederationServices) uniqueUsername(ctx context.Context, claims entity.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	base = invalidUsernameChars.ReplaceAllString(base, "")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > config.MaxUsernameLength-config.UsernameSuffixLength {
+		base = base[:config.MaxUsernameLength-config.UsernameSuffixLength]
+	}
+
+	if !fs.Users.CheckExists(ctx, base) {
+		return base, nil
+	}
+
+	for i := 0; i < config.UsernameAttempts; i++ {
+		suffix := make([]byte, (config.UsernameSuffixLength-1)/2)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+
+		candidate := base + "-" + hex.EncodeToString(suffix)
+		if !fs.Users.CheckExists(ctx, candidate) {
+			return candidate, nil
+		}
+	}
+
+	return "", config.ErrUserAlreadyExists
+}
+
+func stateBinding(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
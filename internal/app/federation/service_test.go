@@ -0,0 +1,274 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+	mysqlUser "go-manage-hex/internal/core/user"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	claims      entity.Claims
+	exchangeErr error
+	gotVerifier string
+	gotNonce    string
+}
+
+func (fp *fakeProvider) Name() string {
+	return "acme"
+}
+
+func (fp *fakeProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	return "https://idp.example/authorize?state=" + url.QueryEscape(state), nil
+}
+
+func (fp *fakeProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (entity.Claims, error) {
+	fp.gotVerifier, fp.gotNonce = codeVerifier, nonce
+	return fp.claims, fp.exchangeErr
+}
+
+type memoryIdentities struct {
+	identities map[string]entity.Identity
+}
+
+func (mi *memoryIdentities) CreateTable(tableName string) error {
+	return nil
+}
+
+func (mi *memoryIdentities) GetIdentity(provider, subject string) (entity.Identity, error) {
+	identity, ok := mi.identities[provider+"|"+subject]
+	if !ok {
+		return entity.Identity{}, config.ErrIdentityNotFound
+	}
+	return identity, nil
+}
+
+func (mi *memoryIdentities) LinkIdentity(identity entity.Identity) error {
+	mi.identities[identity.Provider+"|"+identity.Subject] = identity
+	return nil
+}
+
+type memoryStates struct {
+	pending map[string]entity.PendingLogin
+}
+
+func (ms *memoryStates) Save(pending entity.PendingLogin) error {
+	ms.pending[pending.State] = pending
+	return nil
+}
+
+func (ms *memoryStates) Consume(state string) (entity.PendingLogin, error) {
+	pending, ok := ms.pending[state]
+	if !ok {
+		return entity.PendingLogin{}, config.ErrInvalidState
+	}
+	delete(ms.pending, state)
+	return pending, nil
+}
+
+type memoryUsers struct {
+	mysqlUser.MysqlRepository
+	users map[string]mysqlUser.User
+}
+
+func (mu *memoryUsers) CheckExists(ctx context.Context, username string) bool {
+	_, ok := mu.users[username]
+	return ok
+}
+
+func (mu *memoryUsers) GetByEmail(ctx context.Context, email string) (mysqlUser.User, error) {
+	for _, u := range mu.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return mysqlUser.User{}, config.ErrUserNotFound
+}
+
+func (mu *memoryUsers) ProvisionUser(ctx context.Context, user mysqlUser.User) (mysqlUser.User, error) {
+	if mu.CheckExists(ctx, user.Username) {
+		return mysqlUser.User{}, config.ErrUserAlreadyExists
+	}
+	user.ID = uuid.NewString()
+	mu.users[user.Username] = user
+	return user, nil
+}
+
+func newTestService(provider *fakeProvider, users *memoryUsers, autoProvision, linkByEmail bool) (*FederationServices, *memoryIdentities) {
+	identities := &memoryIdentities{identities: map[string]entity.Identity{}}
+	svc := NewFederationService([]entity.IdentityProvider{provider}, identities, &memoryStates{pending: map[string]entity.PendingLogin{}}, users, users, autoProvision, linkByEmail)
+	return svc.(*FederationServices), identities
+}
+
+func begin(t *testing.T, svc *FederationServices, linkUsername string) (string, string) {
+	redirect, binding, err := svc.Begin(context.Background(), "acme", linkUsername)
+	require.NoError(t, err)
+	require.NotEmpty(t, binding)
+
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+	return parsed.Query().Get("state"), binding
+}
+
+func TestBegin_UnknownProvider(t *testing.T) {
+	svc, _ := newTestService(&fakeProvider{}, &memoryUsers{users: map[string]mysqlUser.User{}}, true, false)
+
+	_, _, err := svc.Begin(context.Background(), "other", "")
+
+	assert.ErrorIs(t, err, config.ErrUnknownProvider)
+}
+
+func TestComplete(t *testing.T) {
+	existing := mysqlUser.User{Username: "johndoe", Email: "john@example.com"}
+	verified := entity.Claims{Subject: "sub-1", Email: "john@example.com", EmailVerified: true, PreferredUsername: "John Doe"}
+
+	test := []struct {
+		Name             string
+		Claims           entity.Claims
+		ExchangeErr      error
+		Linked           *entity.Identity
+		LinkUsername     string
+		AutoProvision    bool
+		LinkByEmail      bool
+		ExpectedUsername string
+		ExpectedErr      error
+	}{
+		{
+			Name:             "Complete_ExistingLink",
+			Claims:           verified,
+			Linked:           &entity.Identity{Provider: "acme", Subject: "sub-1", Username: "johndoe"},
+			ExpectedUsername: "johndoe",
+		},
+		{
+			Name:             "Complete_LinkByVerifiedEmail",
+			Claims:           verified,
+			LinkByEmail:      true,
+			ExpectedUsername: "johndoe",
+		},
+		{
+			Name:             "Complete_UnverifiedEmailProvisions",
+			Claims:           entity.Claims{Subject: "sub-1", Email: "jd@other.example", PreferredUsername: "jd"},
+			AutoProvision:    true,
+			LinkByEmail:      true,
+			ExpectedUsername: "jd",
+		},
+		{
+			Name:             "Complete_ProvisionWithUniqueUsername",
+			Claims:           entity.Claims{Subject: "sub-2", Email: "johndoe@other.example"},
+			AutoProvision:    true,
+			ExpectedUsername: "johndoe-",
+		},
+		{
+			Name:             "Complete_ExplicitLink",
+			Claims:           verified,
+			LinkUsername:     "johndoe",
+			ExpectedUsername: "johndoe",
+		},
+		{
+			Name:         "Complete_LinkedToAnotherUser",
+			Claims:       verified,
+			Linked:       &entity.Identity{Provider: "acme", Subject: "sub-1", Username: "janedoe"},
+			LinkUsername: "johndoe",
+			ExpectedErr:  config.ErrIdentityLinked,
+		},
+		{
+			Name:        "Complete_LinkedUserDeleted",
+			Claims:      verified,
+			Linked:      &entity.Identity{Provider: "acme", Subject: "sub-1", Username: "janedoe"},
+			ExpectedErr: config.ErrAccountNotProvisioned,
+		},
+		{
+			Name:        "Complete_NotProvisioned",
+			Claims:      verified,
+			ExpectedErr: config.ErrAccountNotProvisioned,
+		},
+		{
+			Name:          "Complete_MissingEmail",
+			Claims:        entity.Claims{Subject: "sub-3"},
+			AutoProvision: true,
+			ExpectedErr:   config.ErrMissingEmail,
+		},
+		{
+			Name:        "Complete_ExchangeError",
+			ExchangeErr: config.ErrInvalidIDToken,
+			ExpectedErr: config.ErrInvalidIDToken,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			provider := &fakeProvider{claims: tt.Claims, exchangeErr: tt.ExchangeErr}
+			users := &memoryUsers{users: map[string]mysqlUser.User{existing.Username: existing}}
+			svc, identities := newTestService(provider, users, tt.AutoProvision, tt.LinkByEmail)
+			if tt.Linked != nil {
+				identities.LinkIdentity(*tt.Linked)
+			}
+
+			state, binding := begin(t, svc, tt.LinkUsername)
+			username, err := svc.Complete(context.Background(), "acme", "code", state, binding)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, username, tt.ExpectedUsername)
+			assert.LessOrEqual(t, len(username), config.MaxUsernameLength)
+			assert.NotEmpty(t, provider.gotVerifier)
+			assert.NotEmpty(t, provider.gotNonce)
+
+			identity, err := identities.GetIdentity("acme", tt.Claims.Subject)
+			assert.NoError(t, err)
+			assert.Equal(t, username, identity.Username)
+			assert.True(t, users.CheckExists(context.Background(), username))
+		})
+	}
+}
+
+func TestComplete_State(t *testing.T) {
+	users := &memoryUsers{users: map[string]mysqlUser.User{}}
+	claims := entity.Claims{Subject: "sub-1", Email: "john@example.com"}
+
+	t.Run("Complete_StateReused", func(t *testing.T) {
+		svc, _ := newTestService(&fakeProvider{claims: claims}, users, true, false)
+		state, binding := begin(t, svc, "")
+
+		_, err := svc.Complete(context.Background(), "acme", "code", state, binding)
+		require.NoError(t, err)
+
+		_, err = svc.Complete(context.Background(), "acme", "code", state, binding)
+		assert.ErrorIs(t, err, config.ErrInvalidState)
+	})
+
+	t.Run("Complete_OtherBrowser", func(t *testing.T) {
+		svc, _ := newTestService(&fakeProvider{claims: claims}, users, true, false)
+		state, binding := begin(t, svc, "")
+		_, otherBinding := begin(t, svc, "")
+
+		for _, forged := range []string{"", otherBinding} {
+			_, err := svc.Complete(context.Background(), "acme", "code", state, forged)
+			assert.ErrorIs(t, err, config.ErrInvalidState)
+		}
+
+		_, err := svc.Complete(context.Background(), "acme", "code", state, binding)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Complete_StateExpired", func(t *testing.T) {
+		svc, _ := newTestService(&fakeProvider{claims: claims}, users, true, false)
+		state, binding := begin(t, svc, "")
+		svc.Now = func() time.Time { return time.Now().Add(config.FederatedStateTTL + time.Second) }
+
+		_, err := svc.Complete(context.Background(), "acme", "code", state, binding)
+		assert.True(t, errors.Is(err, config.ErrInvalidState))
+	})
+}
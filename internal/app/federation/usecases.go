@@ -0,0 +1,8 @@
+package federation
+
+import "context"
+
+type Usecases interface {
+	Begin(ctx context.Context, provider, linkUsername string) (redirectURL, binding string, err error)
+	Complete(ctx context.Context, provider, code, state, binding string) (username string, err error)
+}
@@ -0,0 +1,83 @@
+package grant
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/grant"
+	"go-manage-hex/internal/core/scope"
+	user "go-manage-hex/internal/core/user"
+
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+// GrantServices manages per-user scope grants. The model has no roles: a
+// "role grant" gives a user API scopes beyond the configured login defaults.
+type GrantServices struct {
+	Repo     entity.Repository
+	Users    user.MysqlRepository
+	Defaults []string
+	Now      func() time.Time
+}
+
+func NewGrantService(repo entity.Repository, users user.MysqlRepository, defaults []string) Usecases {
+	return &GrantServices{Repo: repo, Users: users, Defaults: defaults, Now: time.Now}
+}
+
+func (gs *GrantServices) Grant(ctx context.Context, username string, scopes []string) error {
+	if err := scope.Validate(scopes); err != nil {
+		return apperror.AppError(config.ErrGrantingScopes, err)
+	}
+
+	if !gs.Users.CheckExists(ctx, username) {
+		return apperror.AppError(config.ErrGrantingScopes, config.ErrUserNotFound)
+	}
+
+	if err := gs.Repo.Grant(ctx, username, scopes, gs.Now().UTC()); err != nil {
+		return apperror.AppError(config.ErrGrantingScopes, err)
+	}
+
+	return nil
+}
+
+// Revoke removes explicit grants only; scopes in the login defaults are
+// still issued, so revoking one of them has no effect on logins.
+func (gs *GrantServices) Revoke(ctx context.Context, username string, scopes []string) error {
+	if err := scope.Validate(scopes); err != nil {
+		return apperror.AppError(config.ErrRevokingScopes, err)
+	}
+
+	if err := gs.Repo.Revoke(ctx, username, scopes); err != nil {
+		return apperror.AppError(config.ErrRevokingScopes, err)
+	}
+
+	return nil
+}
+
+func (gs *GrantServices) Granted(ctx context.Context, username string) ([]string, error) {
+	granted, err := gs.Repo.List(ctx, username)
+	if err != nil {
+		return nil, apperror.AppError(config.ErrLoadingScopes, err)
+	}
+
+	return granted, nil
+}
+
+// LoginScopes returns the login defaults plus the user's grants, in the
+// order of config.AllScopes.
+func (gs *GrantServices) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	granted, err := gs.Granted(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{}
+	for _, s := range config.AllScopes {
+		if slices.Contains(gs.Defaults, s) || slices.Contains(granted, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,119 @@
+package grant
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	user "go-manage-hex/internal/core/user"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryGrants struct {
+	scopes map[string][]string
+}
+
+func (m *memoryGrants) Grant(ctx context.Context, username string, scopes []string, grantedAt time.Time) error {
+	for _, s := range scopes {
+		if !slices.Contains(m.scopes[username], s) {
+			m.scopes[username] = append(m.scopes[username], s)
+		}
+	}
+	return nil
+}
+
+func (m *memoryGrants) Revoke(ctx context.Context, username string, scopes []string) error {
+	m.scopes[username] = slices.DeleteFunc(m.scopes[username], func(s string) bool {
+		return slices.Contains(scopes, s)
+	})
+	return nil
+}
+
+func (m *memoryGrants) List(ctx context.Context, username string) ([]string, error) {
+	return m.scopes[username], nil
+}
+
+type existingUsers struct {
+	user.MysqlRepository
+	usernames []string
+}
+
+func (e *existingUsers) CheckExists(ctx context.Context, username string) bool {
+	return slices.Contains(e.usernames, username)
+}
+
+func newTestService() *GrantServices {
+	return &GrantServices{
+		Repo:     &memoryGrants{scopes: map[string][]string{}},
+		Users:    &existingUsers{usernames: []string{"johndoe"}},
+		Defaults: []string{config.ScopeUsersRead},
+		Now:      time.Now,
+	}
+}
+
+func TestGrant(t *testing.T) {
+	test := []struct {
+		Name        string
+		Username    string
+		Scopes      []string
+		ExpectedErr error
+	}{
+		{
+			Name:     "Grant_Success",
+			Username: "johndoe",
+			Scopes:   []string{config.ScopeUsersWrite},
+		},
+		{
+			Name:        "Grant_UnknownScope",
+			Username:    "johndoe",
+			Scopes:      []string{"admin"},
+			ExpectedErr: config.ErrInvalidScope,
+		},
+		{
+			Name:        "Grant_NoScopes",
+			Username:    "johndoe",
+			ExpectedErr: config.ErrInvalidScope,
+		},
+		{
+			Name:        "Grant_UnknownUser",
+			Username:    "janedoe",
+			Scopes:      []string{config.ScopeUsersWrite},
+			ExpectedErr: config.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := newTestService().Grant(context.Background(), tt.Username, tt.Scopes)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoginScopes(t *testing.T) {
+	service := newTestService()
+	ctx := context.Background()
+
+	scopes, err := service.LoginScopes(ctx, "johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.ScopeUsersRead}, scopes)
+
+	require.NoError(t, service.Grant(ctx, "johndoe", []string{config.ScopeUsersDelete, config.ScopeUsersRead}))
+
+	scopes, err = service.LoginScopes(ctx, "johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.ScopeUsersRead, config.ScopeUsersDelete}, scopes)
+
+	require.NoError(t, service.Revoke(ctx, "johndoe", []string{config.ScopeUsersDelete, config.ScopeUsersRead}))
+
+	scopes, err = service.LoginScopes(ctx, "johndoe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.ScopeUsersRead}, scopes, "defaults survive a revoke")
+}
@@ -0,0 +1,10 @@
+package grant
+
+import "context"
+
+type Usecases interface {
+	Grant(ctx context.Context, username string, scopes []string) error
+	Revoke(ctx context.Context, username string, scopes []string) error
+	Granted(ctx context.Context, username string) ([]string, error)
+	LoginScopes(ctx context.Context, username string) ([]string, error)
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/health"
+)
+
+type HealthServices struct {
+	Checks   []entity.Checker
+	Timeout  time.Duration
+	CacheTTL time.Duration
+	Now      func() time.Time
+
+	mu     sync.Mutex
+	cached *entity.Report
+}
+
+func NewHealthService(timeout, cacheTTL time.Duration, checks ...entity.Checker) Usecases {
+	return &HealthServices{Checks: checks, Timeout: timeout, CacheTTL: cacheTTL, Now: time.Now}
+}
+
+func (hs *HealthServices) Liveness(ctx context.Context) entity.Report {
+	return entity.Report{Status: config.HealthStatusUp, CheckedAt: hs.Now().UTC()}
+}
+
+// Readiness holds the lock while checking so concurrent probes share one run instead of piling up on the database.
+func (hs *HealthServices) Readiness(ctx context.Context) entity.Report {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if hs.cached != nil && hs.Now().Sub(hs.cached.CheckedAt) < hs.CacheTTL {
+		return *hs.cached
+	}
+
+	report := hs.run(ctx)
+	hs.cached = &report
+
+	return report
+}
+
+func (hs *HealthServices) run(ctx context.Context) entity.Report {
+	results := make([]entity.CheckResult, len(hs.Checks))
+
+	var wg sync.WaitGroup
+	for i, check := range hs.Checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = hs.check(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	status := config.HealthStatusUp
+	for _, result := range results {
+		if result.Status != config.HealthStatusUp {
+			status = config.HealthStatusDown
+		}
+	}
+
+	return entity.Report{Status: status, Checks: results, CheckedAt: hs.Now().UTC()}
+}
+
+func (hs *HealthServices) check(ctx context.Context, check entity.Checker) entity.CheckResult {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), hs.Timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	done := make(chan error, 1)
+	go func() { done <- check.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := entity.CheckResult{
+		Name:      check.Name(),
+		Status:    config.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = config.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
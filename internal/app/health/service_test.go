@@ -0,0 +1,122 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCheck struct {
+	name  string
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (f *fakeCheck) Name() string {
+	return f.name
+}
+
+func (f *fakeCheck) Check(ctx context.Context) error {
+	f.calls.Add(1)
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+	return f.err
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		Name            string
+		Checks          []*fakeCheck
+		ExpectedStatus  string
+		ExpectedResults map[string]string
+	}{
+		{
+			Name:           "AllUp",
+			Checks:         []*fakeCheck{{name: "mysql"}, {name: "signing_key"}},
+			ExpectedStatus: config.HealthStatusUp,
+			ExpectedResults: map[string]string{
+				"mysql":       config.HealthStatusUp,
+				"signing_key": config.HealthStatusUp,
+			},
+		},
+		{
+			Name:           "OneDown",
+			Checks:         []*fakeCheck{{name: "mysql", err: errors.New("connection refused")}, {name: "signing_key"}},
+			ExpectedStatus: config.HealthStatusDown,
+			ExpectedResults: map[string]string{
+				"mysql":       config.HealthStatusDown,
+				"signing_key": config.HealthStatusUp,
+			},
+		},
+		{
+			Name:           "Timeout",
+			Checks:         []*fakeCheck{{name: "mysql", delay: 200 * time.Millisecond}},
+			ExpectedStatus: config.HealthStatusDown,
+			ExpectedResults: map[string]string{
+				"mysql": config.HealthStatusDown,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			checks := make([]entity.Checker, len(tt.Checks))
+			for i, check := range tt.Checks {
+				checks[i] = check
+			}
+
+			service := NewHealthService(20*time.Millisecond, time.Second, checks...)
+
+			report := service.Readiness(context.Background())
+
+			assert.Equal(t, tt.ExpectedStatus, report.Status)
+			require.Len(t, report.Checks, len(tt.ExpectedResults))
+			for _, result := range report.Checks {
+				assert.Equal(t, tt.ExpectedResults[result.Name], result.Status, result.Name)
+				if result.Status == config.HealthStatusDown {
+					assert.NotEmpty(t, result.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessCache(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	check := &fakeCheck{name: "mysql"}
+
+	service := &HealthServices{
+		Checks:   []entity.Checker{check},
+		Timeout:  time.Second,
+		CacheTTL: 5 * time.Second,
+		Now:      func() time.Time { return now },
+	}
+
+	service.Readiness(context.Background())
+	service.Readiness(context.Background())
+	assert.Equal(t, int32(1), check.calls.Load())
+
+	now = now.Add(6 * time.Second)
+	service.Readiness(context.Background())
+	assert.Equal(t, int32(2), check.calls.Load())
+}
+
+func TestLiveness(t *testing.T) {
+	check := &fakeCheck{name: "mysql", err: errors.New("down")}
+	service := NewHealthService(time.Second, time.Second, check)
+
+	report := service.Liveness(context.Background())
+
+	assert.Equal(t, config.HealthStatusUp, report.Status)
+	assert.Zero(t, check.calls.Load())
+}
@@ -0,0 +1,11 @@
+package health
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/health"
+)
+
+type Usecases interface {
+	Liveness(ctx context.Context) entity.Report
+	Readiness(ctx context.Context) entity.Report
+}
@@ -0,0 +1,327 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/job"
+
+	"github.com/google/uuid"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+// Handler runs one attempt of a job. progress can be called at any time; the
+// latest value is saved with the next heartbeat and handed back in
+// job.Progress if the job is retried. An error schedules a retry until the
+// job runs out of attempts.
+type Handler func(ctx context.Context, job entity.Job, progress func(v any)) (result any, err error)
+
+// Queue is a durable job queue on top of the repository. Any number of
+// replicas can run it against the same table: a job is only executed by the
+// worker holding its lease, and a lease that is not renewed expires so
+// another worker picks the job up.
+type Queue struct {
+	Repo         entity.Repository
+	Owner        string
+	Workers      int
+	MaxAttempts  int
+	LeaseTTL     time.Duration
+	PollInterval time.Duration
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	Now          func() time.Time
+
+	handlers map[string]Handler
+}
+
+func NewQueue(repo entity.Repository, owner string, workers, maxAttempts int, leaseTTL, pollInterval, backoffBase, backoffMax time.Duration) *Queue {
+	return &Queue{
+		Repo:         repo,
+		Owner:        owner,
+		Workers:      workers,
+		MaxAttempts:  maxAttempts,
+		LeaseTTL:     leaseTTL,
+		PollInterval: pollInterval,
+		BackoffBase:  backoffBase,
+		BackoffMax:   backoffMax,
+		Now:          time.Now,
+		handlers:     map[string]Handler{},
+	}
+}
+
+// DefaultOwner identifies this process in the lease columns.
+func DefaultOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = config.DefaultServiceName
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any) (entity.Job, error) {
+	if _, ok := q.handlers[jobType]; !ok {
+		return entity.Job{}, apperror.AppError(config.ErrEnqueuingJob, fmt.Errorf("%w: %s", config.ErrUnknownJobType, jobType))
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return entity.Job{}, apperror.AppError(config.ErrEnqueuingJob, err)
+	}
+
+	now := q.Now().UTC()
+	job := entity.Job{
+		ID:          uuid.NewString(),
+		Type:        jobType,
+		Payload:     encoded,
+		Status:      config.JobPending,
+		MaxAttempts: q.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := q.Repo.NewJob(ctx, job); err != nil {
+		return entity.Job{}, apperror.AppError(config.ErrEnqueuingJob, err)
+	}
+
+	return job, nil
+}
+
+func (q *Queue) GetJob(ctx context.Context, id string) (entity.Job, error) {
+	return q.Repo.GetJob(ctx, id)
+}
+
+func (q *Queue) ListJobs(ctx context.Context, query entity.JobQuery) ([]entity.Job, error) {
+	if query.Limit <= 0 {
+		query.Limit = config.DefaultJobListLimit
+	}
+	query.Limit = min(query.Limit, config.MaxJobListLimit)
+
+	return q.Repo.ListJobs(ctx, query)
+}
+
+// Run starts the worker pool and blocks until ctx is cancelled and every
+// worker has handed back its job.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < q.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		ran, err := q.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "error running job", "error", err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.PollInterval):
+		}
+	}
+}
+
+// RunOnce claims at most one due job and runs it, reporting whether a job
+// was claimed.
+func (q *Queue) RunOnce(ctx context.Context) (bool, error) {
+	job, claimed, err := q.claim(ctx)
+	if err != nil || !claimed {
+		return false, err
+	}
+	return true, q.execute(ctx, job)
+}
+
+func (q *Queue) types() []string {
+	types := make([]string, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+func (q *Queue) claim(ctx context.Context) (entity.Job, bool, error) {
+	now := q.Now().UTC()
+	due, err := q.Repo.Due(ctx, q.types(), now, q.Workers)
+	if err != nil {
+		return entity.Job{}, false, err
+	}
+
+	for _, job := range due {
+		leaseUntil := now.Add(q.LeaseTTL)
+		claimed, err := q.Repo.Claim(ctx, job.ID, q.Owner, now, leaseUntil)
+		if err != nil {
+			return entity.Job{}, false, err
+		}
+		if claimed {
+			job.Status = config.JobRunning
+			job.Attempts++
+			job.LeaseOwner = q.Owner
+			job.LeaseExpiresAt = &leaseUntil
+			return job, true, nil
+		}
+	}
+
+	return entity.Job{}, false, nil
+}
+
+func (q *Queue) execute(ctx context.Context, job entity.Job) error {
+	if job.Attempts > job.MaxAttempts {
+		return q.finish(ctx, job, nil, config.ErrJobExhausted)
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	progress := job.Progress
+	report := func(v any) {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			slog.WarnContext(ctx, "job progress is not JSON encodable", "job_id", job.ID, "error", err)
+			return
+		}
+		mu.Lock()
+		progress = encoded
+		mu.Unlock()
+	}
+	snapshot := func() json.RawMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return progress
+	}
+
+	stop := make(chan struct{})
+	lease := make(chan error, 1)
+	go func() { lease <- q.heartbeat(ctx, job.ID, snapshot, stop, cancel) }()
+
+	result, runErr := q.run(runCtx, job, report)
+	close(stop)
+	leaseErr := <-lease
+
+	job.Progress = snapshot()
+	switch {
+	case leaseErr != nil:
+		slog.WarnContext(ctx, "job lease lost, leaving it to the new owner", "job_id", job.ID, "type", job.Type)
+		return leaseErr
+	case ctx.Err() != nil:
+		return q.release(ctx, job)
+	default:
+		return q.finish(ctx, job, result, runErr)
+	}
+}
+
+// heartbeat renews the lease and saves progress until stop is closed. When
+// the lease has been taken over it cancels the running handler.
+func (q *Queue) heartbeat(ctx context.Context, id string, snapshot func() json.RawMessage, stop <-chan struct{}, cancel context.CancelFunc) error {
+	ticker := time.NewTicker(q.LeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		now := q.Now().UTC()
+		err := q.Repo.Heartbeat(context.WithoutCancel(ctx), id, q.Owner, snapshot(), now, now.Add(q.LeaseTTL))
+		if errors.Is(err, config.ErrLeaseLost) {
+			cancel()
+			return err
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "error renewing job lease", "job_id", id, "error", err)
+		}
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job entity.Job, progress func(v any)) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return q.handlers[job.Type](ctx, job, progress)
+}
+
+func (q *Queue) finish(ctx context.Context, job entity.Job, result any, runErr error) error {
+	now := q.Now().UTC()
+	job.UpdatedAt = now
+
+	if runErr == nil && result != nil {
+		encoded, err := json.Marshal(result)
+		if err != nil {
+			runErr = err
+		}
+		job.Result = encoded
+	}
+
+	switch {
+	case runErr == nil:
+		job.Status = config.JobSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	case job.Attempts < job.MaxAttempts:
+		job.Status = config.JobPending
+		job.LastError = runErr.Error()
+		job.RunAt = now.Add(q.backoff(job.Attempts))
+	default:
+		job.Status = config.JobFailed
+		job.LastError = runErr.Error()
+		job.FinishedAt = &now
+	}
+
+	if job.Status != config.JobPending {
+		slog.InfoContext(ctx, "job finished", "job_id", job.ID, "type", job.Type, "status", job.Status, "attempts", job.Attempts)
+	}
+
+	return q.Repo.Finish(context.WithoutCancel(ctx), job, q.Owner)
+}
+
+// release hands an interrupted job back to the queue without counting the
+// attempt, so a shutdown never makes a job fail.
+func (q *Queue) release(ctx context.Context, job entity.Job) error {
+	now := q.Now().UTC()
+	job.Status = config.JobPending
+	job.Attempts--
+	job.RunAt = now
+	job.UpdatedAt = now
+
+	return q.Repo.Finish(context.WithoutCancel(ctx), job, q.Owner)
+}
+
+func (q *Queue) backoff(attempts int) time.Duration {
+	wait := q.BackoffBase
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= q.BackoffMax {
+			return q.BackoffMax
+		}
+	}
+	return min(wait, q.BackoffMax)
+}
@@ -0,0 +1,339 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/job"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryJobs mirrors the lease conditions of the SQL queries.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs map[string]entity.Job
+}
+
+func (m *memoryJobs) NewJob(ctx context.Context, job entity.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[job.ID] = job
+	return nil
+}
+
+func (m *memoryJobs) GetJob(ctx context.Context, id string) (entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return entity.Job{}, config.ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (m *memoryJobs) ListJobs(ctx context.Context, query entity.JobQuery) ([]entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := []entity.Job{}
+	for _, job := range m.jobs {
+		if len(jobs) < query.Limit {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func due(job entity.Job, now time.Time) bool {
+	return (job.Status == config.JobPending && !job.RunAt.After(now)) ||
+		(job.Status == config.JobRunning && !job.LeaseExpiresAt.After(now))
+}
+
+func (m *memoryJobs) Due(ctx context.Context, types []string, now time.Time, limit int) ([]entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jobs := []entity.Job{}
+	for _, job := range m.jobs {
+		if slices.Contains(types, job.Type) && due(job, now) && len(jobs) < limit {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *memoryJobs) Claim(ctx context.Context, id, owner string, now, leaseUntil time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.jobs[id]
+	if !due(job, now) {
+		return false, nil
+	}
+	job.Status = config.JobRunning
+	job.Attempts++
+	job.LeaseOwner = owner
+	job.LeaseExpiresAt = &leaseUntil
+	m.jobs[id] = job
+	return true, nil
+}
+
+func (m *memoryJobs) Heartbeat(ctx context.Context, id, owner string, progress json.RawMessage, now, leaseUntil time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.jobs[id]
+	if job.LeaseOwner != owner || job.Status != config.JobRunning {
+		return config.ErrLeaseLost
+	}
+	job.Progress = progress
+	job.LeaseExpiresAt = &leaseUntil
+	m.jobs[id] = job
+	return nil
+}
+
+func (m *memoryJobs) Finish(ctx context.Context, job entity.Job, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.jobs[job.ID].LeaseOwner != owner {
+		return config.ErrLeaseLost
+	}
+	job.LeaseOwner = ""
+	job.LeaseExpiresAt = nil
+	m.jobs[job.ID] = job
+	return nil
+}
+
+// steal hands the lease of a job to another worker.
+func (m *memoryJobs) steal(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job := m.jobs[id]
+	job.LeaseOwner = "other-worker"
+	m.jobs[id] = job
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestQueue(maxAttempts int) (*Queue, *memoryJobs, *clock) {
+	repo := &memoryJobs{jobs: map[string]entity.Job{}}
+	now := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	queue := NewQueue(repo, "worker-a", 1, maxAttempts, 30*time.Millisecond, time.Millisecond, time.Second, 4*time.Second)
+	queue.Now = now.Now
+	return queue, repo, now
+}
+
+func TestQueue_RunsJob(t *testing.T) {
+	queue, repo, _ := newTestQueue(3)
+	ctx := context.Background()
+
+	queue.Register("echo", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		var payload map[string]string
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, err
+		}
+		progress(map[string]int{"done": 1})
+		return payload, nil
+	})
+
+	queued, err := queue.Enqueue(ctx, "echo", map[string]string{"hello": "world"})
+	require.NoError(t, err)
+	assert.Equal(t, config.JobPending, queued.Status)
+
+	ran, err := queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	job, err := repo.GetJob(ctx, queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, config.JobSucceeded, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.JSONEq(t, `{"hello":"world"}`, string(job.Result))
+	assert.JSONEq(t, `{"done":1}`, string(job.Progress))
+	assert.Empty(t, job.LeaseOwner)
+	assert.NotNil(t, job.FinishedAt)
+
+	ran, err = queue.RunOnce(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ran)
+}
+
+func TestQueue_RetriesWithBackoff(t *testing.T) {
+	queue, repo, now := newTestQueue(3)
+	ctx := context.Background()
+
+	var seen []string
+	queue.Register("flaky", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		seen = append(seen, string(job.Progress))
+		progress(job.Attempts)
+		if job.Attempts < 3 {
+			return nil, errors.New("upstream unavailable")
+		}
+		return nil, nil
+	})
+
+	queued, err := queue.Enqueue(ctx, "flaky", nil)
+	require.NoError(t, err)
+
+	_, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+
+	job, _ := repo.GetJob(ctx, queued.ID)
+	assert.Equal(t, config.JobPending, job.Status)
+	assert.Equal(t, "upstream unavailable", job.LastError)
+	assert.Equal(t, now.Now().Add(time.Second), job.RunAt)
+
+	ran, err := queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.False(t, ran, "job must wait for its backoff")
+
+	now.Add(time.Second)
+	_, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+
+	job, _ = repo.GetJob(ctx, queued.ID)
+	assert.Equal(t, now.Now().Add(2*time.Second), job.RunAt)
+
+	now.Add(2 * time.Second)
+	_, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+
+	job, _ = repo.GetJob(ctx, queued.ID)
+	assert.Equal(t, config.JobSucceeded, job.Status)
+	assert.Empty(t, job.LastError)
+	assert.Equal(t, []string{"", "1", "2"}, seen)
+}
+
+func TestQueue_FailsAfterMaxAttempts(t *testing.T) {
+	queue, repo, _ := newTestQueue(1)
+	ctx := context.Background()
+
+	queue.Register("broken", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		panic("boom")
+	})
+
+	queued, err := queue.Enqueue(ctx, "broken", nil)
+	require.NoError(t, err)
+
+	_, err = queue.RunOnce(ctx)
+	require.NoError(t, err)
+
+	job, _ := repo.GetJob(ctx, queued.ID)
+	assert.Equal(t, config.JobFailed, job.Status)
+	assert.Contains(t, job.LastError, "boom")
+	assert.NotNil(t, job.FinishedAt)
+}
+
+func TestQueue_TakesOverExpiredLease(t *testing.T) {
+	queue, repo, now := newTestQueue(3)
+	ctx := context.Background()
+
+	var ran bool
+	queue.Register("echo", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		ran = true
+		return nil, nil
+	})
+
+	expired := now.Now().Add(-time.Second)
+	require.NoError(t, repo.NewJob(ctx, entity.Job{
+		ID:             "job-1",
+		Type:           "echo",
+		Status:         config.JobRunning,
+		Attempts:       3,
+		MaxAttempts:    3,
+		LeaseOwner:     "dead-worker",
+		LeaseExpiresAt: &expired,
+	}))
+
+	claimed, err := queue.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.False(t, ran, "a job that crashed its worker on every attempt must not run again")
+
+	job, _ := repo.GetJob(ctx, "job-1")
+	assert.Equal(t, config.JobFailed, job.Status)
+	assert.Equal(t, config.ErrJobExhausted.Error(), job.LastError)
+}
+
+func TestQueue_StopsOnLeaseLost(t *testing.T) {
+	queue, repo, _ := newTestQueue(3)
+	ctx := context.Background()
+
+	queue.Register("slow", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		repo.steal(job.ID)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	queued, err := queue.Enqueue(ctx, "slow", nil)
+	require.NoError(t, err)
+
+	_, err = queue.RunOnce(ctx)
+	assert.ErrorIs(t, err, config.ErrLeaseLost)
+
+	job, _ := repo.GetJob(ctx, queued.ID)
+	assert.Equal(t, config.JobRunning, job.Status)
+	assert.Equal(t, "other-worker", job.LeaseOwner)
+}
+
+func TestQueue_ReleasesOnShutdown(t *testing.T) {
+	queue, repo, _ := newTestQueue(3)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	queue.Register("slow", func(ctx context.Context, job entity.Job, progress func(v any)) (any, error) {
+		progress("halfway")
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	queued, err := queue.Enqueue(ctx, "slow", nil)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("queue did not stop")
+	}
+
+	job, _ := repo.GetJob(context.Background(), queued.ID)
+	assert.Equal(t, config.JobPending, job.Status)
+	assert.Equal(t, 0, job.Attempts)
+	assert.JSONEq(t, `"halfway"`, string(job.Progress))
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	queue, _, _ := newTestQueue(3)
+	ctx := context.Background()
+
+	_, err := queue.Enqueue(ctx, "missing", nil)
+	assert.ErrorIs(t, err, config.ErrUnknownJobType)
+
+	jobs, err := queue.ListJobs(ctx, entity.JobQuery{})
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
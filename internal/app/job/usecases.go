@@ -0,0 +1,12 @@
+package job
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/job"
+)
+
+type Usecases interface {
+	Enqueue(ctx context.Context, jobType string, payload any) (entity.Job, error)
+	GetJob(ctx context.Context, id string) (entity.Job, error)
+	ListJobs(ctx context.Context, query entity.JobQuery) ([]entity.Job, error)
+}
@@ -0,0 +1,383 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/grant"
+	user "go-manage-hex/internal/app/user"
+	entity "go-manage-hex/internal/core/oidc"
+	"go-manage-hex/internal/core/scope"
+	auth "go-manage-hex/internal/core/user"
+
+	"github.com/google/uuid"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+type OIDCServices struct {
+	Clients  entity.ClientRepository
+	Codes    entity.CodeStore
+	Users    user.Usecases
+	Grants   grant.Usecases
+	Tokens   auth.Authorization
+	Signer   entity.IDTokenSigner
+	Issuer   string
+	TokenTTL time.Duration
+	// AccessTTL is the lifetime of the JWTs handed out as access tokens,
+	// reported as expires_in.
+	AccessTTL time.Duration
+	Now       func() time.Time
+}
+
+func NewOIDCService(clients entity.ClientRepository, codes entity.CodeStore, users user.Usecases, grants grant.Usecases, tokens auth.Authorization, signer entity.IDTokenSigner, issuer string, tokenTTL, accessTTL time.Duration) Usecases {
+	return &OIDCServices{
+		Clients:   clients,
+		Codes:     codes,
+		Users:     users,
+		Grants:    grants,
+		Tokens:    tokens,
+		Signer:    signer,
+		Issuer:    strings.TrimRight(issuer, "/"),
+		TokenTTL:  tokenTTL,
+		AccessTTL: accessTTL,
+		Now:       time.Now,
+	}
+}
+
+// RegisterClient registers a relying party. Every client may ask for the
+// OIDC scopes; API scopes are only granted to it when listed in scopes, so a
+// client cannot obtain users:write or users:delete tokens unless the admin
+// who registered it allowed them.
+func (oc *OIDCServices) RegisterClient(ctx context.Context, name string, redirectURIs []string, authMethod string, scopes []string) (entity.Client, string, error) {
+	if strings.TrimSpace(name) == "" || len(redirectURIs) == 0 {
+		return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, config.ErrInvalidClientMetadata)
+	}
+
+	for _, redirectURI := range redirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" {
+			return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, config.ErrInvalidRedirectURI)
+		}
+	}
+
+	if authMethod == "" {
+		authMethod = config.ClientAuthBasic
+	}
+	if !slices.Contains(config.ClientAuthMethods, authMethod) {
+		return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, config.ErrInvalidClientMetadata)
+	}
+
+	allowed := slices.Clone(config.OIDCScopes)
+	for _, s := range scopes {
+		if !slices.Contains(config.OIDCScopes, s) && !slices.Contains(config.AllScopes, s) {
+			return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, config.ErrInvalidClientMetadata)
+		}
+		if !slices.Contains(allowed, s) {
+			allowed = append(allowed, s)
+		}
+	}
+
+	client := entity.Client{
+		ID:           uuid.NewString(),
+		Name:         name,
+		RedirectURIs: redirectURIs,
+		AuthMethod:   authMethod,
+		Scopes:       allowed,
+		CreatedAt:    oc.Now().UTC(),
+	}
+
+	var secret string
+	if authMethod != config.ClientAuthNone {
+		generated, err := randomToken(config.ClientSecretBytes)
+		if err != nil {
+			return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, err)
+		}
+		secret = generated
+		client.SecretHash = sha256Hex(secret)
+	}
+
+	if createErr := oc.Clients.NewClient(client); createErr != nil {
+		return entity.Client{}, "", apperror.AppError(config.ErrRegisteringClient, createErr)
+	}
+
+	return client, secret, nil
+}
+
+func (oc *OIDCServices) ValidateAuthorize(ctx context.Context, req AuthorizeRequest) error {
+	_, err := oc.validateAuthorize(req)
+	return err
+}
+
+// validateAuthorize checks the request and returns the scopes to grant.
+func (oc *OIDCServices) validateAuthorize(req AuthorizeRequest) ([]string, error) {
+	client, err := oc.Clients.GetClient(req.ClientID)
+	if err != nil {
+		return nil, oauthError(config.OAuthInvalidClient, "unknown client")
+	}
+
+	if !slices.Contains(client.RedirectURIs, req.RedirectURI) {
+		return nil, oauthError(config.OAuthInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, redirectError(config.OAuthUnsupportedResponseType, "only the code response type is supported")
+	}
+
+	scopes, scopeErr := requestedScopes(req.Scope, client.Scopes)
+	if scopeErr != nil {
+		return nil, scopeErr
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != config.PKCEMethodS256 {
+		return nil, redirectError(config.OAuthInvalidRequest, "code_challenge with method S256 is required")
+	}
+
+	return scopes, nil
+}
+
+func (oc *OIDCServices) Authorize(ctx context.Context, req AuthorizeRequest, username, password string) (string, error) {
+	scopes, err := oc.validateAuthorize(req)
+	if err != nil {
+		return "", err
+	}
+
+	if loginErr := oc.Users.Login(ctx, username, password); loginErr != nil {
+		if errors.Is(loginErr, config.ErrHasherBusy) {
+			return "", loginErr
+		}
+		return "", config.ErrInvalidCredentials
+	}
+
+	// API scopes the user does not hold are left out of the grant rather
+	// than failing the request; the token response reports what was issued.
+	allowed, err := oc.Grants.LoginScopes(ctx, username)
+	if err != nil {
+		return "", err
+	}
+	scopes = slices.DeleteFunc(scopes, func(s string) bool {
+		return slices.Contains(config.AllScopes, s) && !slices.Contains(allowed, s)
+	})
+
+	code, err := randomToken(config.AuthorizationCodeBytes)
+	if err != nil {
+		return "", err
+	}
+
+	now := oc.Now().UTC()
+
+	saveErr := oc.Codes.Save(entity.AuthorizationCode{
+		Code:          code,
+		ClientID:      req.ClientID,
+		Username:      username,
+		RedirectURI:   req.RedirectURI,
+		Scopes:        scopes,
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		AuthTime:      now,
+		ExpiresAt:     now.Add(config.AuthorizationCodeTTL),
+	})
+	if saveErr != nil {
+		return "", saveErr
+	}
+
+	return RedirectWith(req.RedirectURI, url.Values{"code": {code}, "state": {req.State}}), nil
+}
+
+func (oc *OIDCServices) Exchange(ctx context.Context, req TokenRequest) (TokenResponse, error) {
+	if req.GrantType != "authorization_code" {
+		return TokenResponse{}, oauthError(config.OAuthUnsupportedGrantType, "only authorization_code is supported")
+	}
+
+	client, err := oc.Clients.GetClient(req.ClientID)
+	if err != nil {
+		return TokenResponse{}, oauthError(config.OAuthInvalidClient, "client authentication failed")
+	}
+
+	if client.AuthMethod != config.ClientAuthNone &&
+		subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(sha256Hex(req.ClientSecret))) != 1 {
+		return TokenResponse{}, oauthError(config.OAuthInvalidClient, "client authentication failed")
+	}
+
+	// The store only hands out codes issued to this client, so a code sent by
+	// the wrong client stays valid for its owner.
+	code, err := oc.Codes.Consume(req.Code, client.ID)
+	if err != nil {
+		return TokenResponse{}, oauthError(config.OAuthInvalidGrant, "authorization code is invalid or was already used")
+	}
+
+	now := oc.Now().UTC()
+
+	switch {
+	case code.RedirectURI != req.RedirectURI:
+		return TokenResponse{}, oauthError(config.OAuthInvalidGrant, "redirect_uri does not match")
+	case !now.Before(code.ExpiresAt):
+		return TokenResponse{}, oauthError(config.OAuthInvalidGrant, "authorization code expired")
+	case !verifyPKCE(code.CodeChallenge, req.CodeVerifier):
+		return TokenResponse{}, oauthError(config.OAuthInvalidGrant, "code_verifier does not match the code_challenge")
+	}
+
+	found, err := oc.Users.SearchUser(ctx, code.Username)
+	if err != nil {
+		return TokenResponse{}, oauthError(config.OAuthInvalidGrant, "user no longer exists")
+	}
+
+	accessToken, err := oc.Tokens.GenerateJWT(found.Username, code.Scopes)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	claims := map[string]any{
+		"iss":       oc.Issuer,
+		"sub":       found.ID,
+		"aud":       client.ID,
+		"iat":       now.Unix(),
+		"exp":       now.Add(oc.TokenTTL).Unix(),
+		"auth_time": code.AuthTime.Unix(),
+	}
+	if code.Nonce != "" {
+		claims["nonce"] = code.Nonce
+	}
+	for k, v := range profileClaims(found, code.Scopes) {
+		claims[k] = v
+	}
+
+	idToken, err := oc.Signer.Sign(claims)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(oc.AccessTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       scope.String(code.Scopes),
+	}, nil
+}
+
+func (oc *OIDCServices) UserInfo(ctx context.Context, username string, scopes []string) (map[string]any, error) {
+	found, err := oc.Users.SearchUser(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := profileClaims(found, scopes)
+	claims["sub"] = found.ID
+
+	return claims, nil
+}
+
+func (oc *OIDCServices) Discovery() map[string]any {
+	return map[string]any{
+		"issuer":                                oc.Issuer,
+		"authorization_endpoint":                oc.Issuer + config.OIDCAuthorizePath,
+		"token_endpoint":                        oc.Issuer + config.OIDCTokenPath,
+		"userinfo_endpoint":                     oc.Issuer + config.OIDCUserInfoPath,
+		"jwks_uri":                              oc.Issuer + config.OIDCJWKSPath,
+		"registration_endpoint":                 oc.Issuer + config.OIDCRegisterPath,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      append(slices.Clone(config.OIDCScopes), config.AllScopes...),
+		"token_endpoint_auth_methods_supported": config.ClientAuthMethods,
+		"code_challenge_methods_supported":      []string{config.PKCEMethodS256},
+		"claims_supported":                      []string{"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce", "name", "given_name", "family_name", "preferred_username", "email"},
+	}
+}
+
+func (oc *OIDCServices) JWKS() map[string]any {
+	return oc.Signer.JWKS()
+}
+
+func RedirectWith(redirectURI string, params url.Values) string {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := parsed.Query()
+	for k, values := range params {
+		for _, v := range values {
+			if v != "" {
+				query.Add(k, v)
+			}
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+func requestedScopes(raw string, allowed []string) ([]string, error) {
+	scopes := scope.Parse(raw)
+	if !slices.Contains(scopes, config.ScopeOpenID) {
+		return nil, redirectError(config.OAuthInvalidScope, "the openid scope is required")
+	}
+
+	for _, s := range scopes {
+		if !slices.Contains(config.OIDCScopes, s) && !slices.Contains(config.AllScopes, s) {
+			return nil, redirectError(config.OAuthInvalidScope, "unknown scope "+s)
+		}
+		if !slices.Contains(allowed, s) {
+			return nil, redirectError(config.OAuthInvalidScope, "scope "+s+" is not allowed for this client")
+		}
+	}
+
+	return scopes, nil
+}
+
+func profileClaims(found auth.User, scopes []string) map[string]any {
+	claims := map[string]any{}
+
+	if slices.Contains(scopes, config.ScopeProfile) {
+		claims["preferred_username"] = found.Username
+		claims["given_name"] = found.Name
+		claims["family_name"] = found.LastName
+		claims["name"] = strings.TrimSpace(found.Name + " " + found.LastName)
+	}
+
+	if slices.Contains(scopes, config.ScopeEmail) {
+		claims["email"] = found.Email
+	}
+
+	return claims
+}
+
+func verifyPKCE(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func IsOAuthError(err error) (*OAuthError, bool) {
+	var oauthErr *OAuthError
+	ok := errors.As(err, &oauthErr)
+	return oauthErr, ok
+}
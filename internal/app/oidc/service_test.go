@@ -0,0 +1,332 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/grant"
+	user "go-manage-hex/internal/app/user"
+	entity "go-manage-hex/internal/core/oidc"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClients struct {
+	client entity.Client
+	saved  []entity.Client
+}
+
+func (m *mockClients) CreateTable(tableName string) error { return nil }
+
+func (m *mockClients) NewClient(client entity.Client) error {
+	m.saved = append(m.saved, client)
+	return nil
+}
+
+func (m *mockClients) GetClient(id string) (entity.Client, error) {
+	if id != m.client.ID {
+		return entity.Client{}, config.ErrClientNotFound
+	}
+	return m.client, nil
+}
+
+type mockCodes struct {
+	code entity.AuthorizationCode
+}
+
+func (m *mockCodes) Save(code entity.AuthorizationCode) error {
+	m.code = code
+	return nil
+}
+
+func (m *mockCodes) Consume(code, clientID string) (entity.AuthorizationCode, error) {
+	if code != m.code.Code || clientID != m.code.ClientID {
+		return entity.AuthorizationCode{}, config.ErrCodeNotFound
+	}
+	return m.code, nil
+}
+
+type loginUsers struct {
+	user.Usecases
+}
+
+func (l *loginUsers) Login(ctx context.Context, username, password string) error {
+	return nil
+}
+
+type staticGrants struct {
+	grant.Usecases
+	scopes []string
+}
+
+func (s *staticGrants) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	return s.scopes, nil
+}
+
+func TestRegisterClient(t *testing.T) {
+	test := []struct {
+		Name           string
+		RedirectURIs   []string
+		AuthMethod     string
+		Scopes         []string
+		ExpectSecret   bool
+		ExpectedScopes []string
+		ExpectedErr    error
+	}{
+		{
+			Name:           "RegisterClient_Confidential",
+			RedirectURIs:   []string{"https://rp.example/cb"},
+			ExpectSecret:   true,
+			ExpectedScopes: config.OIDCScopes,
+		},
+		{
+			Name:           "RegisterClient_Public",
+			RedirectURIs:   []string{"http://localhost:3000/cb"},
+			AuthMethod:     config.ClientAuthNone,
+			ExpectedScopes: config.OIDCScopes,
+		},
+		{
+			Name:           "RegisterClient_APIScopes",
+			RedirectURIs:   []string{"https://rp.example/cb"},
+			Scopes:         []string{"openid", "users:read"},
+			ExpectSecret:   true,
+			ExpectedScopes: []string{"openid", "profile", "email", "users:read"},
+		},
+		{
+			Name:         "RegisterClient_UnknownScope",
+			RedirectURIs: []string{"https://rp.example/cb"},
+			Scopes:       []string{"admin"},
+			ExpectedErr:  config.ErrInvalidClientMetadata,
+		},
+		{
+			Name:         "RegisterClient_RelativeRedirect",
+			RedirectURIs: []string{"/cb"},
+			ExpectedErr:  config.ErrInvalidRedirectURI,
+		},
+		{
+			Name:         "RegisterClient_FragmentRedirect",
+			RedirectURIs: []string{"https://rp.example/cb#x"},
+			ExpectedErr:  config.ErrInvalidRedirectURI,
+		},
+		{
+			Name:         "RegisterClient_UnknownAuthMethod",
+			RedirectURIs: []string{"https://rp.example/cb"},
+			AuthMethod:   "private_key_jwt",
+			ExpectedErr:  config.ErrInvalidClientMetadata,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			clients := mockClients{}
+			service := NewOIDCService(&clients, &mockCodes{}, nil, nil, nil, nil, "https://id.example", time.Hour, 15*time.Minute)
+
+			client, secret, err := service.RegisterClient(context.Background(), "wiki", tt.RedirectURIs, tt.AuthMethod, tt.Scopes)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				assert.Empty(t, clients.saved)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectSecret, secret != "")
+			if tt.ExpectSecret {
+				assert.Equal(t, sha256Hex(secret), clients.saved[0].SecretHash)
+				assert.NotContains(t, clients.saved[0].SecretHash, secret)
+			}
+			assert.NotEmpty(t, client.ID)
+			assert.Equal(t, tt.ExpectedScopes, clients.saved[0].Scopes)
+		})
+	}
+}
+
+func TestExchange_Errors(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	verifier := strings.Repeat("a", 43)
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	client := entity.Client{ID: "client-1", AuthMethod: config.ClientAuthNone, RedirectURIs: []string{"https://rp.example/cb"}}
+	valid := entity.AuthorizationCode{
+		Code:          "code-1",
+		ClientID:      "client-1",
+		Username:      "johndoe",
+		RedirectURI:   "https://rp.example/cb",
+		Scopes:        []string{config.ScopeOpenID},
+		CodeChallenge: challenge,
+		ExpiresAt:     now.Add(time.Minute),
+	}
+
+	test := []struct {
+		Name         string
+		Mutate       func(code *entity.AuthorizationCode, req *TokenRequest)
+		ExpectedCode string
+	}{
+		{
+			Name:         "Exchange_UnsupportedGrant",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { req.GrantType = "password" },
+			ExpectedCode: config.OAuthUnsupportedGrantType,
+		},
+		{
+			Name:         "Exchange_UnknownClient",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { req.ClientID = "other" },
+			ExpectedCode: config.OAuthInvalidClient,
+		},
+		{
+			Name:         "Exchange_Expired",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { code.ExpiresAt = now },
+			ExpectedCode: config.OAuthInvalidGrant,
+		},
+		{
+			Name:         "Exchange_RedirectMismatch",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { req.RedirectURI = "https://rp.example/other" },
+			ExpectedCode: config.OAuthInvalidGrant,
+		},
+		{
+			Name:         "Exchange_OtherClientsCode",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { code.ClientID = "client-2" },
+			ExpectedCode: config.OAuthInvalidGrant,
+		},
+		{
+			Name:         "Exchange_ShortVerifier",
+			Mutate:       func(code *entity.AuthorizationCode, req *TokenRequest) { req.CodeVerifier = "short" },
+			ExpectedCode: config.OAuthInvalidGrant,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			code := valid
+			req := TokenRequest{
+				GrantType:    "authorization_code",
+				Code:         "code-1",
+				RedirectURI:  "https://rp.example/cb",
+				ClientID:     "client-1",
+				CodeVerifier: verifier,
+			}
+			tt.Mutate(&code, &req)
+
+			service := &OIDCServices{
+				Clients: &mockClients{client: client},
+				Codes:   &mockCodes{code: code},
+				Now:     func() time.Time { return now },
+			}
+
+			_, err := service.Exchange(context.Background(), req)
+
+			oauthErr, ok := IsOAuthError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.ExpectedCode, oauthErr.Code)
+		})
+	}
+}
+
+func TestValidateAuthorize(t *testing.T) {
+	client := entity.Client{ID: "client-1", RedirectURIs: []string{"https://rp.example/cb"}, Scopes: []string{"openid", "users:read"}}
+	service := &OIDCServices{Clients: &mockClients{client: client}, Now: time.Now}
+
+	base := AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "client-1",
+		RedirectURI:         "https://rp.example/cb",
+		Scope:               "openid users:read",
+		CodeChallenge:       "challenge",
+		CodeChallengeMethod: config.PKCEMethodS256,
+	}
+
+	test := []struct {
+		Name           string
+		Mutate         func(req *AuthorizeRequest)
+		ExpectedCode   string
+		ExpectRedirect bool
+	}{
+		{
+			Name:   "Valid",
+			Mutate: func(req *AuthorizeRequest) {},
+		},
+		{
+			Name:         "UnknownClient",
+			Mutate:       func(req *AuthorizeRequest) { req.ClientID = "nope" },
+			ExpectedCode: config.OAuthInvalidClient,
+		},
+		{
+			Name:         "UnregisteredRedirect",
+			Mutate:       func(req *AuthorizeRequest) { req.RedirectURI = "https://evil.example/cb" },
+			ExpectedCode: config.OAuthInvalidRequest,
+		},
+		{
+			Name:           "PlainPKCE",
+			Mutate:         func(req *AuthorizeRequest) { req.CodeChallengeMethod = "plain" },
+			ExpectedCode:   config.OAuthInvalidRequest,
+			ExpectRedirect: true,
+		},
+		{
+			Name:           "TokenResponseType",
+			Mutate:         func(req *AuthorizeRequest) { req.ResponseType = "token" },
+			ExpectedCode:   config.OAuthUnsupportedResponseType,
+			ExpectRedirect: true,
+		},
+		{
+			Name:           "ScopeNotAllowedForClient",
+			Mutate:         func(req *AuthorizeRequest) { req.Scope = "openid users:delete" },
+			ExpectedCode:   config.OAuthInvalidScope,
+			ExpectRedirect: true,
+		},
+		{
+			Name:           "UnknownScope",
+			Mutate:         func(req *AuthorizeRequest) { req.Scope = "openid admin" },
+			ExpectedCode:   config.OAuthInvalidScope,
+			ExpectRedirect: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := base
+			tt.Mutate(&req)
+
+			err := service.ValidateAuthorize(context.Background(), req)
+			if tt.ExpectedCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			oauthErr, ok := IsOAuthError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.ExpectedCode, oauthErr.Code)
+			assert.Equal(t, tt.ExpectRedirect, oauthErr.Redirect)
+		})
+	}
+}
+
+func TestRedirectWith(t *testing.T) {
+	assert.Equal(t, "https://rp.example/cb?code=abc&foo=bar", RedirectWith("https://rp.example/cb?foo=bar", map[string][]string{"code": {"abc"}, "state": {""}}))
+}
+
+func TestAuthorize_DropsUngrantedScopes(t *testing.T) {
+	client := entity.Client{ID: "client-1", RedirectURIs: []string{"https://rp.example/cb"}, Scopes: []string{"openid", "users:read", "users:write"}}
+	codes := &mockCodes{}
+	service := &OIDCServices{
+		Clients: &mockClients{client: client},
+		Codes:   codes,
+		Users:   &loginUsers{},
+		Grants:  &staticGrants{scopes: []string{config.ScopeUsersRead}},
+		Now:     time.Now,
+	}
+
+	_, err := service.Authorize(context.Background(), AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            "client-1",
+		RedirectURI:         "https://rp.example/cb",
+		Scope:               "openid users:read users:write",
+		CodeChallenge:       strings.Repeat("c", 43),
+		CodeChallengeMethod: config.PKCEMethodS256,
+	}, "johndoe", "Password1234")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"openid", "users:read"}, codes.code.Scopes)
+}
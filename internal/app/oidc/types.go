@@ -0,0 +1,51 @@
+package oidc
+
+import "fmt"
+
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	ClientID     string
+	ClientSecret string
+	CodeVerifier string
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+// OAuthError carries an RFC 6749 error code. Redirectable errors are sent
+// back to the client's redirect URI, the rest are shown to the user agent.
+type OAuthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	Redirect    bool   `json:"-"`
+}
+
+func (oe *OAuthError) Error() string {
+	return fmt.Sprintf("%s: %s", oe.Code, oe.Description)
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+func redirectError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description, Redirect: true}
+}
@@ -0,0 +1,16 @@
+package oidc
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/oidc"
+)
+
+type Usecases interface {
+	RegisterClient(ctx context.Context, name string, redirectURIs []string, authMethod string, scopes []string) (client entity.Client, secret string, err error)
+	ValidateAuthorize(ctx context.Context, req AuthorizeRequest) error
+	Authorize(ctx context.Context, req AuthorizeRequest, username, password string) (redirectURL string, err error)
+	Exchange(ctx context.Context, req TokenRequest) (TokenResponse, error)
+	UserInfo(ctx context.Context, username string, scopes []string) (map[string]any, error)
+	Discovery() map[string]any
+	JWKS() map[string]any
+}
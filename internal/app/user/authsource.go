@@ -0,0 +1,56 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"go-manage-hex/cmd/config"
+	mysqlUser "go-manage-hex/internal/core/user"
+)
+
+type LocalPasswordSource struct {
+	Repo   mysqlUser.MysqlRepository
+	Hasher mysqlUser.PasswordHasher
+}
+
+func NewLocalPasswordSource(repo mysqlUser.MysqlRepository, hasher mysqlUser.PasswordHasher) mysqlUser.AuthSource {
+	return &LocalPasswordSource{Repo: repo, Hasher: hasher}
+}
+
+func (ls *LocalPasswordSource) Name() string {
+	return config.AuthSourceLocal
+}
+
+func (ls *LocalPasswordSource) Authenticate(ctx context.Context, username, password string) (mysqlUser.User, error) {
+	user, err := ls.Repo.GetByUsername(ctx, username)
+	if err != nil || user.Password == "" {
+		return mysqlUser.User{}, config.ErrUserNotFound
+	}
+
+	valid, verifyErr := ls.Hasher.Verify(ctx, user.Password, password)
+	if errors.Is(verifyErr, config.ErrHasherBusy) || (verifyErr != nil && ctx.Err() != nil) {
+		return mysqlUser.User{}, verifyErr
+	}
+	if verifyErr != nil || !valid {
+		return mysqlUser.User{}, config.ErrInvalidCredentials
+	}
+
+	if ls.Hasher.NeedsRehash(user.Password) {
+		ls.rehash(ctx, username, password)
+	}
+
+	return user, nil
+}
+
+func (ls *LocalPasswordSource) rehash(ctx context.Context, username, password string) {
+	hash, hashErr := ls.Hasher.Hash(ctx, password)
+	if hashErr != nil {
+		slog.ErrorContext(ctx, "error rehashing password", "username", username, "error", hashErr)
+		return
+	}
+
+	if changePwdErr := ls.Repo.ChangePwd(ctx, hash, username); changePwdErr != nil {
+		slog.ErrorContext(ctx, "error storing rehashed password", "username", username, "error", changePwdErr)
+	}
+}
@@ -0,0 +1,63 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/google/uuid"
+)
+
+type userSnapshot struct {
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name"`
+	LastName string `json:"last_name"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type userRef struct {
+	Username string `json:"username"`
+}
+
+func (us *UserServices) withinTx(ctx context.Context, fn func(repo mysqlUser.MysqlRepository, events event.Recorder) error) error {
+	return withinTx(ctx, us.Tx, fn)
+}
+
+// withinTx refuses to run without a transactor: writing the change without
+// its outbox event would silently drop it for every subscriber.
+func withinTx(ctx context.Context, tx mysqlUser.Transactor, fn func(repo mysqlUser.MysqlRepository, events event.Recorder) error) error {
+	if tx == nil {
+		return config.ErrNoTransactor
+	}
+	return tx.WithinTx(ctx, fn)
+}
+
+func snapshot(user mysqlUser.User) userSnapshot {
+	return userSnapshot{
+		ID:       user.ID,
+		Name:     user.Name,
+		LastName: user.LastName,
+		Username: user.Username,
+		Email:    user.Email,
+	}
+}
+
+func record(events event.Recorder, eventType, username string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return events.Record(event.Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		Aggregate:  username,
+		Payload:    data,
+		OccurredAt: time.Now().UTC(),
+	})
+}
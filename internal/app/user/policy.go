@@ -0,0 +1,124 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-manage-hex/cmd/config"
+	mysqlUser "go-manage-hex/internal/core/user"
+)
+
+const (
+	RuleMinLength        = "min_length"
+	RuleMaxLength        = "max_length"
+	RuleUppercase        = "uppercase"
+	RuleLowercase        = "lowercase"
+	RuleDigit            = "digit"
+	RuleSymbol           = "symbol"
+	RuleContainsUsername = "contains_username"
+	RuleContainsEmail    = "contains_email"
+	RuleReused           = "reused"
+	RuleBreached         = "breached"
+)
+
+type PasswordPolicy struct {
+	MinLength        int
+	MaxLength        int
+	RequireUpper     bool
+	RequireLower     bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	HistorySize      int
+	DisallowUserInfo bool
+}
+
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (pe *PolicyError) Error() string {
+	rules := make([]string, 0, len(pe.Violations))
+	for _, v := range pe.Violations {
+		rules = append(rules, v.Rule)
+	}
+	return fmt.Sprintf("%s: %s", config.ErrInvalidPassword, strings.Join(rules, ", "))
+}
+
+func (pe *PolicyError) Unwrap() error {
+	return config.ErrInvalidPassword
+}
+
+func (p PasswordPolicy) Validate(password string, user mysqlUser.User) []PolicyViolation {
+	var violations []PolicyViolation
+
+	if p.MinLength > 0 && utf8.RuneCountInString(password) < p.MinLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleMinLength,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("password must be at most %d bytes long", p.MaxLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, PolicyViolation{Rule: RuleUppercase, Message: "password must contain an uppercase letter"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, PolicyViolation{Rule: RuleLowercase, Message: "password must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, PolicyViolation{Rule: RuleDigit, Message: "password must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, PolicyViolation{Rule: RuleSymbol, Message: "password must contain a symbol"})
+	}
+
+	if p.DisallowUserInfo {
+		lowered := strings.ToLower(password)
+
+		if containsFragment(lowered, user.Username) {
+			violations = append(violations, PolicyViolation{Rule: RuleContainsUsername, Message: "password must not contain the username"})
+		}
+
+		localPart, _, _ := strings.Cut(user.Email, "@")
+		if containsFragment(lowered, user.Email) || containsFragment(lowered, localPart) {
+			violations = append(violations, PolicyViolation{Rule: RuleContainsEmail, Message: "password must not contain the email address"})
+		}
+	}
+
+	return violations
+}
+
+func containsFragment(lowered, fragment string) bool {
+	fragment = strings.ToLower(strings.TrimSpace(fragment))
+	if len(fragment) < config.MinUserInfoFragment {
+		return false
+	}
+	return strings.Contains(lowered, fragment)
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	entity "go-manage-hex/internal/core/user"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	user := entity.User{Username: "johndoe", Email: "jdoe.work@example.com"}
+
+	test := []struct {
+		Name          string
+		Policy        PasswordPolicy
+		Password      string
+		ExpectedRules []string
+	}{
+		{
+			Name:     "Valid",
+			Policy:   testPolicy,
+			Password: "Password1234",
+		},
+		{
+			Name:          "TooShort",
+			Policy:        testPolicy,
+			Password:      "Pa1",
+			ExpectedRules: []string{RuleMinLength},
+		},
+		{
+			Name:          "TooLongForBcrypt",
+			Policy:        testPolicy,
+			Password:      "Pa1" + strings.Repeat("x", 70),
+			ExpectedRules: []string{RuleMaxLength},
+		},
+		{
+			Name:          "MissingClasses",
+			Policy:        testPolicy,
+			Password:      "password",
+			ExpectedRules: []string{RuleUppercase, RuleDigit},
+		},
+		{
+			Name:          "SymbolRequired",
+			Policy:        PasswordPolicy{RequireSymbol: true},
+			Password:      "Password1234",
+			ExpectedRules: []string{RuleSymbol},
+		},
+		{
+			Name:     "SymbolPresent",
+			Policy:   PasswordPolicy{RequireSymbol: true},
+			Password: "Password1234!",
+		},
+		{
+			Name:          "ContainsUsername",
+			Policy:        testPolicy,
+			Password:      "MyJohnDoe123",
+			ExpectedRules: []string{RuleContainsUsername},
+		},
+		{
+			Name:          "ContainsEmailLocalPart",
+			Policy:        testPolicy,
+			Password:      "Jdoe.Work2024",
+			ExpectedRules: []string{RuleContainsEmail},
+		},
+		{
+			Name:     "UserInfoAllowed",
+			Policy:   PasswordPolicy{},
+			Password: "johndoe",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			violations := tt.Policy.Validate(tt.Password, user)
+
+			rules := []string{}
+			for _, v := range violations {
+				rules = append(rules, v.Rule)
+				assert.NotEmpty(t, v.Message)
+			}
+
+			if tt.ExpectedRules == nil {
+				assert.Empty(t, rules)
+			} else {
+				assert.Equal(t, tt.ExpectedRules, rules)
+			}
+		})
+	}
+}
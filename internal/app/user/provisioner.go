@@ -0,0 +1,53 @@
+package user
+
+import (
+	"context"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/google/uuid"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+// UserProvisioner records user.created like CreateUser but skips the
+// password checks. It needs only the repository and the transactor, so
+// login sources can hold one before the user service that consults them
+// exists.
+type UserProvisioner struct {
+	Repo mysqlUser.MysqlRepository
+	Tx   mysqlUser.Transactor
+}
+
+func NewUserProvisioner(repo mysqlUser.MysqlRepository, tx mysqlUser.Transactor) mysqlUser.Provisioner {
+	return &UserProvisioner{Repo: repo, Tx: tx}
+}
+
+func (up *UserProvisioner) ProvisionUser(ctx context.Context, user mysqlUser.User) (created mysqlUser.User, err error) {
+	if up.Repo.CheckExists(ctx, user.Username) {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrUserAlreadyExists)
+	}
+	if up.Repo.IsDeleted(ctx, user.Username) {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrUserDeleted)
+	}
+
+	user.ID = uuid.NewString()
+	user.Password = ""
+
+	createErr := withinTx(ctx, up.Tx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.NewUser(ctx, user); err != nil {
+			return err
+		}
+		return record(events, config.EventUserCreated, user.Username, snapshot(user))
+	})
+	if createErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, createErr)
+	}
+
+	return user, nil
+}
+
+func (up *UserProvisioner) IsDeleted(ctx context.Context, username string) bool {
+	return up.Repo.IsDeleted(ctx, username)
+}
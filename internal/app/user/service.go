@@ -3,30 +3,61 @@ package user
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 
 	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
 	mysqlUser "go-manage-hex/internal/core/user"
 
 	"github.com/google/uuid"
 	"github.com/gustyaguero21/go-core/pkg/apperror"
-	"github.com/gustyaguero21/go-core/pkg/encrypter"
 	"github.com/gustyaguero21/go-core/pkg/validator"
 )
 
 type UserServices struct {
-	Repo mysqlUser.MysqlRepository
+	Repo     mysqlUser.MysqlRepository
+	Hasher   mysqlUser.PasswordHasher
+	Policy   PasswordPolicy
+	Breached mysqlUser.BreachedPasswordChecker
+	Tx       mysqlUser.Transactor
+	Sources  []mysqlUser.AuthSource
 }
 
-func NewUserService(repo mysqlUser.MysqlRepository) Usecases {
-	return &UserServices{Repo: repo}
+func NewUserService(repo mysqlUser.MysqlRepository, hasher mysqlUser.PasswordHasher, policy PasswordPolicy, breached mysqlUser.BreachedPasswordChecker, tx mysqlUser.Transactor, sources ...mysqlUser.AuthSource) Usecases {
+	if len(sources) == 0 {
+		sources = []mysqlUser.AuthSource{NewLocalPasswordSource(repo, hasher)}
+	}
+
+	return &UserServices{Repo: repo, Hasher: hasher, Policy: policy, Breached: breached, Tx: tx, Sources: sources}
 }
 
 func (us *UserServices) SearchUser(ctx context.Context, username string) (search mysqlUser.User, err error) {
-	if !us.Repo.CheckExists(username) {
+	if !us.Repo.CheckExists(ctx, username) {
 		return mysqlUser.User{}, apperror.AppError(config.ErrSearchingUser, config.ErrUserNotFound)
 	}
 
-	search, searchErr := us.Repo.GetByUsername(username)
+	search, searchErr := us.Repo.GetByUsername(ctx, username)
+	if searchErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrSearchingUser, searchErr)
+	}
+
+	return search, nil
+}
+
+func (us *UserServices) SearchUserByID(ctx context.Context, id string) (search mysqlUser.User, err error) {
+	search, searchErr := us.Repo.GetByID(ctx, id)
+	if searchErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrSearchingUser, searchErr)
+	}
+
+	return search, nil
+}
+
+// SearchUserByIDWithDeleted is SearchUserByID for callers that manage soft
+// deletes themselves; a deleted user comes back with DeletedAt set.
+func (us *UserServices) SearchUserByIDWithDeleted(ctx context.Context, id string) (search mysqlUser.User, err error) {
+	search, searchErr := us.Repo.GetByIDWithDeleted(ctx, id)
 	if searchErr != nil {
 		return mysqlUser.User{}, apperror.AppError(config.ErrSearchingUser, searchErr)
 	}
@@ -34,47 +65,141 @@ func (us *UserServices) SearchUser(ctx context.Context, username string) (search
 	return search, nil
 }
 
+func (us *UserServices) ListUsers(ctx context.Context, query mysqlUser.UserQuery) (users []mysqlUser.User, total int, err error) {
+	if query.Offset < 0 || query.Limit < 0 {
+		return nil, 0, apperror.AppError(config.ErrListingUsers, config.ErrInvalidUserQuery)
+	}
+
+	users, total, listErr := us.Repo.ListUsers(ctx, query)
+	if listErr != nil {
+		return nil, 0, apperror.AppError(config.ErrListingUsers, listErr)
+	}
+
+	return users, total, nil
+}
+
 func (us *UserServices) CreateUser(ctx context.Context, user mysqlUser.User) (created mysqlUser.User, err error) {
-	if us.Repo.CheckExists(user.Username) {
-		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrUserAlreadyExists)
+	user, prepareErr := us.PrepareUser(ctx, user, false)
+	if prepareErr != nil {
+		return mysqlUser.User{}, prepareErr
+	}
+
+	createErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.NewUser(ctx, user); err != nil {
+			return err
+		}
+		return record(events, config.EventUserCreated, user.Username, snapshot(user))
+	})
+	if createErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, createErr)
 	}
 
-	uID := uuid.NewString()
+	us.addPwdHistory(ctx, user.Username, user.Password)
+
+	return user, nil
+}
+
+// PrepareUser runs the CreateUser checks and returns the user with an ID and
+// a hashed password, ready for CreateUsers. A pre-hashed password skips the
+// policy and breach checks, which need the plain text, but must be a hash
+// the configured hasher can verify.
+func (us *UserServices) PrepareUser(ctx context.Context, user mysqlUser.User, preHashed bool) (prepared mysqlUser.User, err error) {
+	if us.Repo.CheckExists(ctx, user.Username) {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrUserAlreadyExists)
+	}
 
 	if !validator.ValidateEmail(user.Email) {
 		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrInvalidEmail)
 	}
 
-	if !validator.ValidatePassword(user.Password) {
-		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrInvalidPassword)
+	if preHashed {
+		if !us.Hasher.Supports(user.Password) {
+			return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, config.ErrInvalidHash)
+		}
+		user.ID = uuid.NewString()
+		return user, nil
 	}
 
-	hash, _ := encrypter.PasswordEncrypter(user.Password)
-
-	user.ID = uID
-	user.Password = string(hash)
+	violations := us.Policy.Validate(user.Password, user)
+	if us.isBreached(ctx, user.Password) {
+		violations = append(violations, breachedViolation())
+	}
+	if len(violations) > 0 {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, &PolicyError{Violations: violations})
+	}
 
-	if createErr := us.Repo.NewUser(user); createErr != nil {
-		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, createErr)
+	hash, hashErr := us.Hasher.Hash(ctx, user.Password)
+	if hashErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrCreatingUser, hashErr)
 	}
 
+	user.ID = uuid.NewString()
+	user.Password = hash
+
 	return user, nil
 }
 
+// CreateUsers inserts users returned by PrepareUser in one transaction, so a
+// batch is either fully created with its events or not at all.
+func (us *UserServices) CreateUsers(ctx context.Context, users []mysqlUser.User) error {
+	createErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.NewUsers(ctx, users); err != nil {
+			return err
+		}
+		for _, user := range users {
+			if err := record(events, config.EventUserCreated, user.Username, snapshot(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if createErr != nil {
+		return apperror.AppError(config.ErrCreatingUser, createErr)
+	}
+
+	for _, user := range users {
+		us.addPwdHistory(ctx, user.Username, user.Password)
+	}
+
+	return nil
+}
+
 func (us *UserServices) DeleteUser(ctx context.Context, username string) error {
-	if !us.Repo.CheckExists(username) {
+	if !us.Repo.CheckExists(ctx, username) {
 		return apperror.AppError(config.ErrDeletingUser, config.ErrUserNotFound)
 	}
 
-	if deleteErr := us.Repo.DeleteUser(username); deleteErr != nil {
+	deleteErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+		return record(events, config.EventUserDeleted, username, userRef{Username: username})
+	})
+	if deleteErr != nil {
 		return apperror.AppError(config.ErrDeletingUser, deleteErr)
 	}
 
 	return nil
 }
 
+// RestoreUser undoes a soft delete. The user keeps its password and
+// history, and is back in lookups, listings and logins.
+func (us *UserServices) RestoreUser(ctx context.Context, username string) error {
+	restoreErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.RestoreUser(ctx, username); err != nil {
+			return err
+		}
+		return record(events, config.EventUserRestored, username, userRef{Username: username})
+	})
+	if restoreErr != nil {
+		return apperror.AppError(config.ErrRestoringUser, restoreErr)
+	}
+
+	return nil
+}
+
 func (us *UserServices) UpdateUser(ctx context.Context, username string, user mysqlUser.User) (updated mysqlUser.User, err error) {
-	if !us.Repo.CheckExists(username) {
+	if !us.Repo.CheckExists(ctx, username) {
 		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, config.ErrUserNotFound)
 	}
 
@@ -82,7 +207,15 @@ func (us *UserServices) UpdateUser(ctx context.Context, username string, user my
 		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, config.ErrInvalidEmail)
 	}
 
-	if updateErr := us.Repo.UpdateUser(username, user); updateErr != nil {
+	updateErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.UpdateUser(ctx, username, user); err != nil {
+			return err
+		}
+		changed := user
+		changed.Username = username
+		return record(events, config.EventUserUpdated, username, snapshot(changed))
+	})
+	if updateErr != nil {
 		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, updateErr)
 	}
 
@@ -90,34 +223,196 @@ func (us *UserServices) UpdateUser(ctx context.Context, username string, user my
 }
 
 func (us *UserServices) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
-	if !us.Repo.CheckExists(username) {
+	if !us.Repo.CheckExists(ctx, username) {
 		return apperror.AppError(config.ErrChangingPwd, config.ErrUserNotFound)
 	}
 
-	if !validator.ValidatePassword(newPwd) {
-		return apperror.AppError(config.ErrChangingPwd, config.ErrInvalidPassword)
+	user, searchErr := us.Repo.GetByUsername(ctx, username)
+	if searchErr != nil {
+		return apperror.AppError(config.ErrChangingPwd, searchErr)
 	}
 
-	hash, _ := encrypter.PasswordEncrypter(newPwd)
+	hash, hashErr := us.hashNewPwd(ctx, user, newPwd)
+	if hashErr != nil {
+		return apperror.AppError(config.ErrChangingPwd, hashErr)
+	}
 
-	if changePwdErr := us.Repo.ChangePwd(string(hash), username); changePwdErr != nil {
+	changePwdErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.ChangePwd(ctx, hash, username); err != nil {
+			return err
+		}
+		return record(events, config.EventUserPasswordChanged, username, userRef{Username: username})
+	})
+	if changePwdErr != nil {
 		return apperror.AppError(config.ErrChangingPwd, changePwdErr)
 	}
 
+	us.addPwdHistory(ctx, username, hash)
+
 	return nil
 }
 
+// UpdateUserAndPwd applies a profile change and, when newPwd is set, a
+// password change in one transaction, so provisioning clients never see one
+// half applied.
+func (us *UserServices) UpdateUserAndPwd(ctx context.Context, username string, user mysqlUser.User, newPwd string) (updated mysqlUser.User, err error) {
+	if !us.Repo.CheckExists(ctx, username) {
+		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, config.ErrUserNotFound)
+	}
+
+	if !validator.ValidateEmail(user.Email) {
+		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, config.ErrInvalidEmail)
+	}
+
+	changed := user
+	changed.Username = username
+
+	hash := ""
+	if newPwd != "" {
+		stored, searchErr := us.Repo.GetByUsername(ctx, username)
+		if searchErr != nil {
+			return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, searchErr)
+		}
+		changed.Password = stored.Password
+
+		var hashErr error
+		if hash, hashErr = us.hashNewPwd(ctx, changed, newPwd); hashErr != nil {
+			return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, hashErr)
+		}
+	}
+
+	updateErr := us.withinTx(ctx, func(repo mysqlUser.MysqlRepository, events event.Recorder) error {
+		if err := repo.UpdateUser(ctx, username, user); err != nil {
+			return err
+		}
+		if err := record(events, config.EventUserUpdated, username, snapshot(changed)); err != nil {
+			return err
+		}
+		if hash == "" {
+			return nil
+		}
+		if err := repo.ChangePwd(ctx, hash, username); err != nil {
+			return err
+		}
+		return record(events, config.EventUserPasswordChanged, username, userRef{Username: username})
+	})
+	if updateErr != nil {
+		return mysqlUser.User{}, apperror.AppError(config.ErrUpdatingUser, updateErr)
+	}
+
+	if hash != "" {
+		us.addPwdHistory(ctx, username, hash)
+	}
+
+	return user, nil
+}
+
 func (us *UserServices) Login(ctx context.Context, username, password string) error {
-	user, err := us.Repo.GetByUsername(username)
+	if password == "" {
+		return config.ErrInvalidCredentials
+	}
+
+	err := config.ErrUserNotFound
+
+	for _, source := range us.Sources {
+		_, authErr := source.Authenticate(ctx, username, password)
+		switch {
+		case authErr == nil:
+			return nil
+		case errors.Is(authErr, config.ErrUserNotFound):
+			continue
+		case errors.Is(authErr, config.ErrAuthSourceUnavailable):
+			slog.WarnContext(ctx, "authentication source unavailable", "source", source.Name(), "error", authErr)
+			err = authErr
+			continue
+		default:
+			return authErr
+		}
+	}
+
+	return err
+}
+
+// hashNewPwd checks newPwd against the policy, the breach list and the
+// user's password history, and returns its hash.
+func (us *UserServices) hashNewPwd(ctx context.Context, user mysqlUser.User, newPwd string) (string, error) {
+	violations := us.Policy.Validate(newPwd, user)
+	if us.isBreached(ctx, newPwd) {
+		violations = append(violations, breachedViolation())
+	}
+
+	reused, historyErr := us.isReused(ctx, user, newPwd)
+	if historyErr != nil {
+		return "", historyErr
+	}
+	if reused {
+		violations = append(violations, PolicyViolation{
+			Rule:    RuleReused,
+			Message: fmt.Sprintf("password must not match any of the last %d passwords", us.Policy.HistorySize),
+		})
+	}
+
+	if len(violations) > 0 {
+		return "", &PolicyError{Violations: violations}
+	}
+
+	return us.Hasher.Hash(ctx, newPwd)
+}
+
+func (us *UserServices) isReused(ctx context.Context, user mysqlUser.User, password string) (bool, error) {
+	if us.Policy.HistorySize <= 0 {
+		return false, nil
+	}
+
+	history, err := us.Repo.GetPwdHistory(ctx, user.Username, us.Policy.HistorySize)
 	if err != nil {
-		return errors.New("user not found")
+		return false, err
 	}
 
-	decrypt := encrypter.PasswordDecrypter([]byte(user.Password), password)
+	if len(history) == 0 && user.Password != "" {
+		history = []string{user.Password}
+	}
 
-	if !decrypt {
-		return errors.New("wrong password")
+	for _, previous := range history {
+		match, verifyErr := us.Hasher.Verify(ctx, previous, password)
+		if errors.Is(verifyErr, config.ErrHasherBusy) || (verifyErr != nil && ctx.Err() != nil) {
+			return false, verifyErr
+		}
+		if match {
+			return true, nil
+		}
 	}
 
-	return nil
+	return false, nil
+}
+
+func (us *UserServices) addPwdHistory(ctx context.Context, username, hash string) {
+	if us.Policy.HistorySize <= 0 {
+		return
+	}
+
+	if historyErr := us.Repo.AddPwdHistory(ctx, username, hash); historyErr != nil {
+		slog.ErrorContext(ctx, "error storing password history", "username", username, "error", historyErr)
+	}
+}
+
+func (us *UserServices) isBreached(ctx context.Context, password string) bool {
+	if us.Breached == nil {
+		return false
+	}
+
+	breached, err := us.Breached.IsBreached(ctx, password)
+	if err != nil {
+		slog.WarnContext(ctx, "error checking breached password", "error", err)
+		return false
+	}
+
+	return breached
+}
+
+func breachedViolation() PolicyViolation {
+	return PolicyViolation{
+		Rule:    RuleBreached,
+		Message: "password appears in a known data breach",
+	}
 }
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
 	entity "go-manage-hex/internal/core/user"
+	"strings"
 	"testing"
 
 	"github.com/gustyaguero21/go-core/pkg/encrypter"
@@ -13,14 +15,33 @@ import (
 )
 
 type mockMysqlRepository struct {
-	CreateTableFn   func(tableName string) error
-	GetByUsernameFn func(username string) (entity.User, error)
-	CheckExistsFn   func(username string) bool
-	NewUserFn       func(user entity.User) error
-	DeleteUserFn    func(username string) error
-	UpdateUserFn    func(username string, user entity.User) error
-	ChangePwdFn     func(newPwd, username string) error
-	LoginFn         func(username, password string) error
+	CreateTableFn    func(tableName string) error
+	GetByUsernameFn  func(username string) (entity.User, error)
+	GetByEmailFn     func(email string) (entity.User, error)
+	GetByIDFn        func(id string) (entity.User, error)
+	GetByIDDeletedFn func(id string) (entity.User, error)
+	ListUsersFn      func(query entity.UserQuery) ([]entity.User, int, error)
+	CheckExistsFn    func(username string) bool
+	IsDeletedFn      func(username string) bool
+	NewUserFn        func(user entity.User) error
+	NewUsersFn       func(users []entity.User) error
+	DeleteUserFn     func(username string) error
+	RestoreUserFn    func(username string) error
+	UpdateUserFn     func(username string, user entity.User) error
+	ChangePwdFn      func(newPwd, username string) error
+	LoginFn          func(username, password string) error
+	AddPwdHistoryFn  func(username, hash string) error
+	GetPwdHistoryFn  func(username string, limit int) ([]string, error)
+}
+
+var testPolicy = PasswordPolicy{
+	MinLength:        8,
+	MaxLength:        72,
+	RequireUpper:     true,
+	RequireLower:     true,
+	RequireDigit:     true,
+	HistorySize:      3,
+	DisallowUserInfo: true,
 }
 
 func (m *mockMysqlRepository) CreateTable(tableName string) error {
@@ -30,48 +51,115 @@ func (m *mockMysqlRepository) CreateTable(tableName string) error {
 	return nil
 }
 
-func (m *mockMysqlRepository) GetByUsername(username string) (entity.User, error) {
+func (m *mockMysqlRepository) GetByUsername(ctx context.Context, username string) (entity.User, error) {
 	if m.GetByUsernameFn != nil {
 		return m.GetByUsernameFn(username)
 	}
 	return entity.User{}, nil
 }
 
-func (m *mockMysqlRepository) CheckExists(username string) bool {
+func (m *mockMysqlRepository) GetByEmail(ctx context.Context, email string) (entity.User, error) {
+	if m.GetByEmailFn != nil {
+		return m.GetByEmailFn(email)
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (m *mockMysqlRepository) GetByID(ctx context.Context, id string) (entity.User, error) {
+	if m.GetByIDFn != nil {
+		return m.GetByIDFn(id)
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (m *mockMysqlRepository) GetByIDWithDeleted(ctx context.Context, id string) (entity.User, error) {
+	if m.GetByIDDeletedFn != nil {
+		return m.GetByIDDeletedFn(id)
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (m *mockMysqlRepository) ListUsers(ctx context.Context, query entity.UserQuery) ([]entity.User, int, error) {
+	if m.ListUsersFn != nil {
+		return m.ListUsersFn(query)
+	}
+	return nil, 0, nil
+}
+
+func (m *mockMysqlRepository) CheckExists(ctx context.Context, username string) bool {
 	if m.CheckExistsFn != nil {
 		return m.CheckExistsFn(username)
 	}
 	return false
 }
 
-func (m *mockMysqlRepository) NewUser(user entity.User) error {
+func (m *mockMysqlRepository) IsDeleted(ctx context.Context, username string) bool {
+	if m.IsDeletedFn != nil {
+		return m.IsDeletedFn(username)
+	}
+	return false
+}
+
+func (m *mockMysqlRepository) NewUser(ctx context.Context, user entity.User) error {
 	if m.NewUserFn != nil {
 		return m.NewUserFn(user)
 	}
 	return nil
 }
 
-func (m *mockMysqlRepository) DeleteUser(username string) error {
+func (m *mockMysqlRepository) NewUsers(ctx context.Context, users []entity.User) error {
+	if m.NewUsersFn != nil {
+		return m.NewUsersFn(users)
+	}
+	return nil
+}
+
+func (m *mockMysqlRepository) DeleteUser(ctx context.Context, username string) error {
 	if m.DeleteUserFn != nil {
 		return m.DeleteUserFn(username)
 	}
 	return nil
 }
 
-func (m *mockMysqlRepository) UpdateUser(username string, user entity.User) error {
+func (m *mockMysqlRepository) RestoreUser(ctx context.Context, username string) error {
+	if m.RestoreUserFn != nil {
+		return m.RestoreUserFn(username)
+	}
+	return nil
+}
+
+func (m *mockMysqlRepository) UpdateUser(ctx context.Context, username string, user entity.User) error {
 	if m.UpdateUserFn != nil {
 		return m.UpdateUserFn(username, user)
 	}
 	return nil
 }
 
-func (m *mockMysqlRepository) ChangePwd(newPwd, username string) error {
+func (m *mockMysqlRepository) ChangePwd(ctx context.Context, newPwd, username string) error {
 	if m.ChangePwdFn != nil {
 		return m.ChangePwdFn(newPwd, username)
 	}
 	return nil
 }
 
+func (m *mockMysqlRepository) CreateHistoryTable(tableName string) error {
+	return nil
+}
+
+func (m *mockMysqlRepository) AddPwdHistory(ctx context.Context, username, hash string) error {
+	if m.AddPwdHistoryFn != nil {
+		return m.AddPwdHistoryFn(username, hash)
+	}
+	return nil
+}
+
+func (m *mockMysqlRepository) GetPwdHistory(ctx context.Context, username string, limit int) ([]string, error) {
+	if m.GetPwdHistoryFn != nil {
+		return m.GetPwdHistoryFn(username, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockMysqlRepository) Login(username, password string) error {
 	if m.LoginFn != nil {
 		return m.LoginFn(username, password)
@@ -79,6 +167,38 @@ func (m *mockMysqlRepository) Login(username, password string) error {
 	return nil
 }
 
+type mockPasswordHasher struct {
+	HashFn        func(password string) (string, error)
+	VerifyFn      func(encoded, password string) (bool, error)
+	NeedsRehashFn func(encoded string) bool
+}
+
+func (m *mockPasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	if m.HashFn != nil {
+		return m.HashFn(password)
+	}
+	hash, err := encrypter.PasswordEncrypter(password)
+	return string(hash), err
+}
+
+func (m *mockPasswordHasher) Verify(ctx context.Context, encoded, password string) (bool, error) {
+	if m.VerifyFn != nil {
+		return m.VerifyFn(encoded, password)
+	}
+	return encrypter.PasswordDecrypter([]byte(encoded), password), nil
+}
+
+func (m *mockPasswordHasher) NeedsRehash(encoded string) bool {
+	if m.NeedsRehashFn != nil {
+		return m.NeedsRehashFn(encoded)
+	}
+	return false
+}
+
+func (m *mockPasswordHasher) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$")
+}
+
 func TestSearchUser(t *testing.T) {
 	test := []struct {
 		Name         string
@@ -127,7 +247,7 @@ func TestSearchUser(t *testing.T) {
 					return tt.MockUser, tt.MockGetErr
 				},
 			}
-			service := NewUserService(&mockRepo)
+			service := NewUserService(&mockRepo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &mockRepo})
 
 			found, err := service.SearchUser(context.Background(), tt.Username)
 			if err != nil {
@@ -183,7 +303,7 @@ func TestCreateUser(t *testing.T) {
 				LastName: "Doe",
 				Username: "johndoe",
 				Email:    "johndoe@example.com",
-				Password: "Password1234567.",
+				Password: "password",
 			},
 			ExpectedErr: config.ErrInvalidPassword,
 		},
@@ -211,7 +331,7 @@ func TestCreateUser(t *testing.T) {
 					return tt.ExpectedErr
 				},
 			}
-			service := NewUserService(&mockRepo)
+			service := NewUserService(&mockRepo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &mockRepo})
 
 			_, err := service.CreateUser(context.Background(), tt.User)
 
@@ -263,7 +383,7 @@ func TestDeleteUser(t *testing.T) {
 				},
 			}
 
-			service := NewUserService(&repo)
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
 
 			deleteErr := service.DeleteUser(context.Background(), tt.Username)
 
@@ -331,7 +451,7 @@ func TestUpdateUser(t *testing.T) {
 					return tt.ExpectedErr
 				},
 			}
-			service := NewUserService(&repo)
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
 
 			_, err := service.UpdateUser(context.Background(), tt.Username, tt.User)
 
@@ -368,7 +488,7 @@ func TestChangeUserPwd(t *testing.T) {
 		},
 		{
 			Name:        "ChangeUserPwd_ErrInvalidPassword",
-			NewPwd:      "NewPassword1234.",
+			NewPwd:      "newpassword",
 			Username:    "johndoe",
 			MockExists:  true,
 			ExpectedErr: config.ErrInvalidPassword,
@@ -392,7 +512,7 @@ func TestChangeUserPwd(t *testing.T) {
 					return tt.ExpectedErr
 				},
 			}
-			service := NewUserService(&repo)
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
 
 			err := service.ChangeUserPwd(context.Background(), tt.NewPwd, tt.Username)
 			if err != nil {
@@ -469,7 +589,7 @@ func TestLogin(t *testing.T) {
 					return tt.ExpectedErr
 				},
 			}
-			service := NewUserService(&repo)
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
 
 			err := service.Login(context.Background(), tt.Username, tt.Password)
 			if err != nil {
@@ -480,3 +600,798 @@ func TestLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateUser_HashErr(t *testing.T) {
+	repo := mockMysqlRepository{
+		NewUserFn: func(user entity.User) error {
+			t.Fatal("user must not be stored when hashing fails")
+			return nil
+		},
+	}
+	hasher := mockPasswordHasher{
+		HashFn: func(password string) (string, error) {
+			return "", errors.New("hash error")
+		},
+	}
+	service := NewUserService(&repo, &hasher, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+	_, err := service.CreateUser(context.Background(), entity.User{
+		Username: "johndoe",
+		Email:    "johndoe@example.com",
+		Password: "Password1234567",
+	})
+
+	assert.Error(t, err)
+}
+
+func TestLogin_HasherBusy(t *testing.T) {
+	encrypted, _ := encrypter.PasswordEncrypter("Password12345")
+	repo := mockMysqlRepository{
+		GetByUsernameFn: func(username string) (entity.User, error) {
+			return entity.User{Username: "johndoe", Password: string(encrypted)}, nil
+		},
+	}
+	hasher := mockPasswordHasher{
+		VerifyFn: func(encoded, password string) (bool, error) {
+			return false, config.ErrHasherBusy
+		},
+	}
+	service := NewUserService(&repo, &hasher, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+	err := service.Login(context.Background(), "johndoe", "Password12345")
+
+	assert.ErrorIs(t, err, config.ErrHasherBusy)
+}
+
+func TestLogin_Rehash(t *testing.T) {
+	test := []struct {
+		Name          string
+		Password      string
+		NeedsRehash   bool
+		ExpectedErr   bool
+		ExpectRehash  bool
+		ChangePwdErr  error
+		StoredHash    string
+		RehashedValue string
+	}{
+		{
+			Name:          "Login_RehashOutdated",
+			Password:      "Password12345",
+			NeedsRehash:   true,
+			ExpectRehash:  true,
+			StoredHash:    "old-hash",
+			RehashedValue: "new-hash",
+		},
+		{
+			Name:         "Login_NoRehashWhenCurrent",
+			Password:     "Password12345",
+			NeedsRehash:  false,
+			ExpectRehash: false,
+			StoredHash:   "current-hash",
+		},
+		{
+			Name:          "Login_RehashStoreErrStillLogsIn",
+			Password:      "Password12345",
+			NeedsRehash:   true,
+			ExpectRehash:  true,
+			ChangePwdErr:  errors.New("db error"),
+			StoredHash:    "old-hash",
+			RehashedValue: "new-hash",
+		},
+		{
+			Name:         "Login_WrongPasswordNoRehash",
+			Password:     "wrong",
+			NeedsRehash:  true,
+			ExpectedErr:  true,
+			ExpectRehash: false,
+			StoredHash:   "old-hash",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			rehashed := ""
+			repo := mockMysqlRepository{
+				GetByUsernameFn: func(username string) (entity.User, error) {
+					return entity.User{Username: username, Password: tt.StoredHash}, nil
+				},
+				ChangePwdFn: func(newPwd, username string) error {
+					rehashed = newPwd
+					return tt.ChangePwdErr
+				},
+			}
+			hasher := mockPasswordHasher{
+				HashFn: func(password string) (string, error) {
+					return tt.RehashedValue, nil
+				},
+				VerifyFn: func(encoded, password string) (bool, error) {
+					return password == "Password12345", nil
+				},
+				NeedsRehashFn: func(encoded string) bool {
+					return tt.NeedsRehash
+				},
+			}
+			service := NewUserService(&repo, &hasher, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+			err := service.Login(context.Background(), "johndoe", tt.Password)
+			if tt.ExpectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.ExpectRehash {
+				assert.Equal(t, tt.RehashedValue, rehashed)
+			} else {
+				assert.Empty(t, rehashed)
+			}
+		})
+	}
+}
+
+type mockAuthSource struct {
+	name  string
+	calls int
+	err   error
+}
+
+func (m *mockAuthSource) Name() string {
+	return m.name
+}
+
+func (m *mockAuthSource) Authenticate(ctx context.Context, username, password string) (entity.User, error) {
+	m.calls++
+	return entity.User{Username: username}, m.err
+}
+
+func TestLogin_AuthSources(t *testing.T) {
+	test := []struct {
+		Name          string
+		FirstErr      error
+		SecondErr     error
+		ExpectedErr   error
+		ExpectedCalls int
+	}{
+		{
+			Name:          "Login_FirstSourceSucceeds",
+			ExpectedCalls: 0,
+		},
+		{
+			Name:          "Login_FallsThroughUnknownUser",
+			FirstErr:      config.ErrUserNotFound,
+			ExpectedCalls: 1,
+		},
+		{
+			Name:          "Login_FallsThroughUnavailableSource",
+			FirstErr:      config.ErrAuthSourceUnavailable,
+			ExpectedCalls: 1,
+		},
+		{
+			Name:          "Login_StopsOnInvalidCredentials",
+			FirstErr:      config.ErrInvalidCredentials,
+			ExpectedErr:   config.ErrInvalidCredentials,
+			ExpectedCalls: 0,
+		},
+		{
+			Name:          "Login_UnknownEverywhere",
+			FirstErr:      config.ErrUserNotFound,
+			SecondErr:     config.ErrUserNotFound,
+			ExpectedErr:   config.ErrUserNotFound,
+			ExpectedCalls: 1,
+		},
+		{
+			Name:          "Login_ReportsUnavailableWhenUnresolved",
+			FirstErr:      config.ErrAuthSourceUnavailable,
+			SecondErr:     config.ErrUserNotFound,
+			ExpectedErr:   config.ErrAuthSourceUnavailable,
+			ExpectedCalls: 1,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			first := &mockAuthSource{name: "ldap", err: tt.FirstErr}
+			second := &mockAuthSource{name: "local", err: tt.SecondErr}
+			service := NewUserService(&mockMysqlRepository{}, &mockPasswordHasher{}, testPolicy, nil, nil, first, second)
+
+			err := service.Login(context.Background(), "johndoe", "Password12345")
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, 1, first.calls)
+			assert.Equal(t, tt.ExpectedCalls, second.calls)
+		})
+	}
+}
+
+func TestLogin_LocalSkipsExternalAccounts(t *testing.T) {
+	repo := mockMysqlRepository{
+		GetByUsernameFn: func(username string) (entity.User, error) {
+			return entity.User{Username: username}, nil
+		},
+	}
+
+	_, err := NewLocalPasswordSource(&repo, &mockPasswordHasher{}).Authenticate(context.Background(), "johndoe", "Password12345")
+
+	assert.ErrorIs(t, err, config.ErrUserNotFound)
+}
+
+func TestChangeUserPwd_Policy(t *testing.T) {
+	previous, _ := encrypter.PasswordEncrypter("OldPassword1234")
+
+	test := []struct {
+		Name          string
+		NewPwd        string
+		History       []string
+		HistoryErr    error
+		ExpectedRules []string
+		ExpectedErr   error
+	}{
+		{
+			Name:    "ChangeUserPwd_Success",
+			NewPwd:  "BrandNew1234",
+			History: []string{string(previous)},
+		},
+		{
+			Name:          "ChangeUserPwd_Reused",
+			NewPwd:        "OldPassword1234",
+			History:       []string{string(previous)},
+			ExpectedRules: []string{RuleReused},
+		},
+		{
+			Name:          "ChangeUserPwd_MultipleViolations",
+			NewPwd:        "johndoe",
+			ExpectedRules: []string{RuleMinLength, RuleUppercase, RuleDigit, RuleContainsUsername, RuleContainsEmail},
+		},
+		{
+			Name:        "ChangeUserPwd_HistoryErr",
+			NewPwd:      "BrandNew1234",
+			HistoryErr:  errors.New("db error"),
+			ExpectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			stored := ""
+			repo := mockMysqlRepository{
+				CheckExistsFn: func(username string) bool {
+					return true
+				},
+				GetByUsernameFn: func(username string) (entity.User, error) {
+					return entity.User{Username: "johndoe", Email: "johndoe@example.com", Password: string(previous)}, nil
+				},
+				GetPwdHistoryFn: func(username string, limit int) ([]string, error) {
+					assert.Equal(t, testPolicy.HistorySize, limit)
+					return tt.History, tt.HistoryErr
+				},
+				AddPwdHistoryFn: func(username, hash string) error {
+					stored = hash
+					return nil
+				},
+			}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+			err := service.ChangeUserPwd(context.Background(), tt.NewPwd, "johndoe")
+
+			var policyErr *PolicyError
+			switch {
+			case tt.ExpectedErr != nil:
+				assert.Error(t, err)
+				assert.False(t, errors.As(err, &policyErr))
+			case tt.ExpectedRules != nil:
+				assert.ErrorIs(t, err, config.ErrInvalidPassword)
+				assert.True(t, errors.As(err, &policyErr))
+				rules := []string{}
+				for _, v := range policyErr.Violations {
+					rules = append(rules, v.Rule)
+				}
+				assert.Equal(t, tt.ExpectedRules, rules)
+				assert.Empty(t, stored)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, stored)
+			}
+		})
+	}
+}
+
+type mockBreachedChecker struct {
+	IsBreachedFn func(ctx context.Context, password string) (bool, error)
+}
+
+func (m *mockBreachedChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	return m.IsBreachedFn(ctx, password)
+}
+
+func TestCreateUser_Breached(t *testing.T) {
+	test := []struct {
+		Name        string
+		Breached    bool
+		CheckErr    error
+		ExpectedErr bool
+	}{
+		{
+			Name:        "CreateUser_BreachedPassword",
+			Breached:    true,
+			ExpectedErr: true,
+		},
+		{
+			Name:     "CreateUser_CleanPassword",
+			Breached: false,
+		},
+		{
+			Name:     "CreateUser_CheckerErrFailsOpen",
+			CheckErr: errors.New("index unavailable"),
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			repo := mockMysqlRepository{}
+			checker := mockBreachedChecker{
+				IsBreachedFn: func(ctx context.Context, password string) (bool, error) {
+					return tt.Breached, tt.CheckErr
+				},
+			}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, &checker, &mockTransactor{Repo: &repo})
+
+			_, err := service.CreateUser(context.Background(), entity.User{
+				Username: "johndoe",
+				Email:    "johndoe@example.com",
+				Password: "Password1234",
+			})
+
+			if tt.ExpectedErr {
+				var policyErr *PolicyError
+				assert.True(t, errors.As(err, &policyErr))
+				assert.Equal(t, RuleBreached, policyErr.Violations[0].Rule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSearchUserByID(t *testing.T) {
+	test := []struct {
+		Name        string
+		ID          string
+		ExpectedErr error
+	}{
+		{
+			Name: "SearchUserByID_Success",
+			ID:   "1",
+		},
+		{
+			Name:        "SearchUserByID_NotFound",
+			ID:          "2",
+			ExpectedErr: config.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			repo := mockMysqlRepository{
+				GetByIDFn: func(id string) (entity.User, error) {
+					if id != "1" {
+						return entity.User{}, config.ErrUserNotFound
+					}
+					return entity.User{ID: id, Username: "johndoe"}, nil
+				},
+			}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+			user, err := service.SearchUserByID(context.Background(), tt.ID)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "johndoe", user.Username)
+			}
+		})
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	test := []struct {
+		Name          string
+		Query         entity.UserQuery
+		RepoErr       error
+		ExpectedTotal int
+		ExpectedErr   error
+	}{
+		{
+			Name:          "ListUsers_Success",
+			Query:         entity.UserQuery{Limit: 10},
+			ExpectedTotal: 2,
+		},
+		{
+			Name:        "ListUsers_NegativeOffset",
+			Query:       entity.UserQuery{Offset: -1, Limit: 10},
+			ExpectedErr: config.ErrInvalidUserQuery,
+		},
+		{
+			Name:        "ListUsers_RepoErr",
+			Query:       entity.UserQuery{Limit: 10},
+			RepoErr:     config.ErrInvalidUserQuery,
+			ExpectedErr: config.ErrInvalidUserQuery,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			repo := mockMysqlRepository{
+				ListUsersFn: func(query entity.UserQuery) ([]entity.User, int, error) {
+					if tt.RepoErr != nil {
+						return nil, 0, tt.RepoErr
+					}
+					return []entity.User{{Username: "janedoe"}, {Username: "johndoe"}}, 2, nil
+				},
+			}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+			users, total, err := service.ListUsers(context.Background(), tt.Query)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedTotal, total)
+				assert.Len(t, users, tt.ExpectedTotal)
+			}
+		})
+	}
+}
+
+type mockTransactor struct {
+	Repo   entity.MysqlRepository
+	Events []event.Event
+}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(users entity.MysqlRepository, events event.Recorder) error) error {
+	var staged []event.Event
+	if err := fn(m.Repo, recorderFunc(func(events ...event.Event) error {
+		staged = append(staged, events...)
+		return nil
+	})); err != nil {
+		return err
+	}
+	m.Events = append(m.Events, staged...)
+	return nil
+}
+
+type recorderFunc func(events ...event.Event) error
+
+func (f recorderFunc) Record(events ...event.Event) error {
+	return f(events...)
+}
+
+func TestUserEvents(t *testing.T) {
+	existing := entity.User{ID: "1", Name: "John", LastName: "Doe", Username: "johndoe", Email: "johndoe@example.com"}
+
+	test := []struct {
+		Name         string
+		Action       func(service Usecases) error
+		RepoErr      error
+		ExpectedType string
+	}{
+		{
+			Name: "CreateUser_RecordsCreated",
+			Action: func(service Usecases) error {
+				_, err := service.CreateUser(context.Background(), entity.User{Name: "Jane", LastName: "Doe", Username: "janedoe", Email: "janedoe@example.com", Password: "Str0ngPassw0rd"})
+				return err
+			},
+			ExpectedType: config.EventUserCreated,
+		},
+		{
+			Name: "UpdateUser_RecordsUpdated",
+			Action: func(service Usecases) error {
+				_, err := service.UpdateUser(context.Background(), "johndoe", entity.User{Name: "Johnny", Email: "johnny@example.com"})
+				return err
+			},
+			ExpectedType: config.EventUserUpdated,
+		},
+		{
+			Name: "DeleteUser_RecordsDeleted",
+			Action: func(service Usecases) error {
+				return service.DeleteUser(context.Background(), "johndoe")
+			},
+			ExpectedType: config.EventUserDeleted,
+		},
+		{
+			Name: "ChangeUserPwd_RecordsPasswordChanged",
+			Action: func(service Usecases) error {
+				return service.ChangeUserPwd(context.Background(), "N3wStr0ngPassword", "johndoe")
+			},
+			ExpectedType: config.EventUserPasswordChanged,
+		},
+		{
+			Name: "RestoreUser_RecordsRestored",
+			Action: func(service Usecases) error {
+				return service.RestoreUser(context.Background(), "johndoe")
+			},
+			ExpectedType: config.EventUserRestored,
+		},
+		{
+			Name: "RestoreUser_NoEventWhenNotDeleted",
+			Action: func(service Usecases) error {
+				return service.RestoreUser(context.Background(), "johndoe")
+			},
+			RepoErr: config.ErrUserNotFound,
+		},
+		{
+			Name: "DeleteUser_NoEventOnFailure",
+			Action: func(service Usecases) error {
+				return service.DeleteUser(context.Background(), "johndoe")
+			},
+			RepoErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			repo := mockMysqlRepository{
+				CheckExistsFn: func(username string) bool {
+					return username == existing.Username
+				},
+				GetByUsernameFn: func(username string) (entity.User, error) {
+					return existing, nil
+				},
+				DeleteUserFn: func(username string) error {
+					return tt.RepoErr
+				},
+				RestoreUserFn: func(username string) error {
+					return tt.RepoErr
+				},
+			}
+			tx := &mockTransactor{Repo: &repo}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, tx)
+
+			err := tt.Action(service)
+			if tt.RepoErr != nil {
+				assert.Error(t, err)
+				assert.Empty(t, tx.Events)
+				return
+			}
+
+			assert.NoError(t, err)
+			if assert.Len(t, tx.Events, 1) {
+				assert.Equal(t, tt.ExpectedType, tx.Events[0].Type)
+				assert.NotEmpty(t, tx.Events[0].ID)
+				assert.NotContains(t, string(tx.Events[0].Payload), "password")
+			}
+		})
+	}
+}
+
+func TestUpdateUserAndPwd(t *testing.T) {
+	existing := entity.User{ID: "1", Name: "John", LastName: "Doe", Username: "johndoe", Email: "johndoe@example.com"}
+	changed := entity.User{Name: "Johnny", LastName: "Doe", Email: "johnny@example.com"}
+
+	test := []struct {
+		Name           string
+		NewPwd         string
+		ChangePwdErr   error
+		ExpectedErr    error
+		ExpectedEvents []string
+		ExpectedUpdate bool
+	}{
+		{
+			Name:           "ProfileOnly",
+			ExpectedEvents: []string{config.EventUserUpdated},
+			ExpectedUpdate: true,
+		},
+		{
+			Name:           "ProfileAndPassword",
+			NewPwd:         "N3wStr0ngPassword",
+			ExpectedEvents: []string{config.EventUserUpdated, config.EventUserPasswordChanged},
+			ExpectedUpdate: true,
+		},
+		{
+			Name:        "WeakPassword_NothingApplied",
+			NewPwd:      "weak",
+			ExpectedErr: &PolicyError{},
+		},
+		{
+			Name:           "ChangePwdErr_RolledBack",
+			NewPwd:         "N3wStr0ngPassword",
+			ChangePwdErr:   errors.New("db error"),
+			ExpectedErr:    errors.New("db error"),
+			ExpectedUpdate: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			updated := false
+			repo := mockMysqlRepository{
+				CheckExistsFn: func(username string) bool {
+					return username == existing.Username
+				},
+				GetByUsernameFn: func(username string) (entity.User, error) {
+					return existing, nil
+				},
+				UpdateUserFn: func(username string, user entity.User) error {
+					updated = true
+					return nil
+				},
+				ChangePwdFn: func(newPwd, username string) error {
+					return tt.ChangePwdErr
+				},
+			}
+			tx := &mockTransactor{Repo: &repo}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, tx)
+
+			_, err := service.UpdateUserAndPwd(context.Background(), "johndoe", changed, tt.NewPwd)
+			assert.Equal(t, tt.ExpectedUpdate, updated)
+			if tt.ExpectedErr != nil {
+				assert.Error(t, err)
+				assert.Empty(t, tx.Events)
+				return
+			}
+
+			assert.NoError(t, err)
+			var types []string
+			for _, e := range tx.Events {
+				types = append(types, e.Type)
+			}
+			assert.Equal(t, tt.ExpectedEvents, types)
+		})
+	}
+}
+
+func TestPrepareUser(t *testing.T) {
+	valid := entity.User{Name: "Jane", LastName: "Doe", Username: "janedoe", Email: "janedoe@example.com"}
+
+	test := []struct {
+		Name        string
+		Password    string
+		PreHashed   bool
+		Exists      bool
+		ExpectedErr error
+	}{
+		{
+			Name:     "PrepareUser_HashesPlainPassword",
+			Password: "Str0ngPassw0rd",
+		},
+		{
+			Name:        "PrepareUser_PolicyApplies",
+			Password:    "weak",
+			ExpectedErr: config.ErrInvalidPassword,
+		},
+		{
+			Name:      "PrepareUser_KeepsSupportedHash",
+			Password:  "$2a$10$abcdefghijklmnopqrstuv",
+			PreHashed: true,
+		},
+		{
+			Name:        "PrepareUser_RejectsUnknownHash",
+			Password:    "md5:5f4dcc3b5aa765d61d8327deb882cf99",
+			PreHashed:   true,
+			ExpectedErr: config.ErrInvalidHash,
+		},
+		{
+			Name:        "PrepareUser_ErrUserAlreadyExists",
+			Password:    "Str0ngPassw0rd",
+			Exists:      true,
+			ExpectedErr: config.ErrUserAlreadyExists,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			repo := mockMysqlRepository{
+				CheckExistsFn: func(username string) bool {
+					return tt.Exists
+				},
+				NewUserFn: func(user entity.User) error {
+					t.Fatal("PrepareUser must not store the user")
+					return nil
+				},
+			}
+			service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, &mockTransactor{Repo: &repo})
+
+			input := valid
+			input.Password = tt.Password
+			prepared, err := service.PrepareUser(context.Background(), input, tt.PreHashed)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, prepared.ID)
+			if tt.PreHashed {
+				assert.Equal(t, tt.Password, prepared.Password)
+			} else {
+				assert.NotEqual(t, tt.Password, prepared.Password)
+			}
+		})
+	}
+}
+
+func TestCreateUsers(t *testing.T) {
+	users := []entity.User{
+		{ID: "1", Username: "janedoe", Email: "janedoe@example.com", Password: "$2a$hash"},
+		{ID: "2", Username: "johndoe", Email: "johndoe@example.com", Password: "$2a$hash"},
+	}
+
+	t.Run("CreateUsers_RecordsOneEventPerUser", func(t *testing.T) {
+		var stored []entity.User
+		repo := mockMysqlRepository{
+			NewUsersFn: func(batch []entity.User) error {
+				stored = batch
+				return nil
+			},
+		}
+		tx := &mockTransactor{Repo: &repo}
+		service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, tx)
+
+		assert.NoError(t, service.CreateUsers(context.Background(), users))
+		assert.Equal(t, users, stored)
+		if assert.Len(t, tx.Events, 2) {
+			assert.Equal(t, config.EventUserCreated, tx.Events[0].Type)
+			assert.NotContains(t, string(tx.Events[1].Payload), "$2a$hash")
+		}
+	})
+
+	t.Run("CreateUsers_NoEventsOnFailure", func(t *testing.T) {
+		repo := mockMysqlRepository{
+			NewUsersFn: func(batch []entity.User) error {
+				return errors.New("duplicate entry")
+			},
+		}
+		tx := &mockTransactor{Repo: &repo}
+		service := NewUserService(&repo, &mockPasswordHasher{}, testPolicy, nil, tx)
+
+		assert.Error(t, service.CreateUsers(context.Background(), users))
+		assert.Empty(t, tx.Events)
+	})
+}
+
+func TestUserProvisioner(t *testing.T) {
+	repo := mockMysqlRepository{
+		CheckExistsFn: func(username string) bool {
+			return username == "johndoe"
+		},
+		IsDeletedFn: func(username string) bool {
+			return username == "gone"
+		},
+	}
+	tx := &mockTransactor{Repo: &repo}
+	provisioner := NewUserProvisioner(&repo, tx)
+
+	created, err := provisioner.ProvisionUser(context.Background(), entity.User{Username: "janedoe", Email: "janedoe@example.com", Password: "ignored"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Empty(t, created.Password)
+	if assert.Len(t, tx.Events, 1) {
+		assert.Equal(t, config.EventUserCreated, tx.Events[0].Type)
+		assert.Equal(t, "janedoe", tx.Events[0].Aggregate)
+	}
+
+	_, err = provisioner.ProvisionUser(context.Background(), entity.User{Username: "johndoe"})
+	assert.ErrorIs(t, err, config.ErrUserAlreadyExists)
+	assert.Len(t, tx.Events, 1)
+
+	_, err = provisioner.ProvisionUser(context.Background(), entity.User{Username: "gone"})
+	assert.ErrorIs(t, err, config.ErrUserDeleted)
+	assert.Len(t, tx.Events, 1)
+	assert.True(t, provisioner.IsDeleted(context.Background(), "gone"))
+}
+
+func TestUserEvents_NoTransactor(t *testing.T) {
+	created := false
+	repo := mockMysqlRepository{
+		NewUserFn: func(user entity.User) error {
+			created = true
+			return nil
+		},
+	}
+
+	_, err := NewUserProvisioner(&repo, nil).ProvisionUser(context.Background(), entity.User{Username: "janedoe"})
+	assert.ErrorIs(t, err, config.ErrNoTransactor)
+	assert.False(t, created)
+}
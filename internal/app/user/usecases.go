@@ -7,9 +7,16 @@ import (
 
 type Usecases interface {
 	SearchUser(ctx context.Context, username string) (search mysqlUser.User, err error)
+	SearchUserByID(ctx context.Context, id string) (search mysqlUser.User, err error)
+	SearchUserByIDWithDeleted(ctx context.Context, id string) (search mysqlUser.User, err error)
+	ListUsers(ctx context.Context, query mysqlUser.UserQuery) (users []mysqlUser.User, total int, err error)
 	CreateUser(ctx context.Context, user mysqlUser.User) (created mysqlUser.User, err error)
+	PrepareUser(ctx context.Context, user mysqlUser.User, preHashed bool) (prepared mysqlUser.User, err error)
+	CreateUsers(ctx context.Context, users []mysqlUser.User) error
 	DeleteUser(ctx context.Context, username string) error
+	RestoreUser(ctx context.Context, username string) error
 	UpdateUser(ctx context.Context, username string, user mysqlUser.User) (updated mysqlUser.User, err error)
+	UpdateUserAndPwd(ctx context.Context, username string, user mysqlUser.User, newPwd string) (updated mysqlUser.User, err error)
 	ChangeUserPwd(ctx context.Context, newPwd, username string) error
 	Login(ctx context.Context, username, password string) error
 }
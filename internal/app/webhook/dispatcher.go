@@ -0,0 +1,172 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	entity "go-manage-hex/internal/core/webhook"
+
+	"github.com/google/uuid"
+)
+
+type Dispatcher struct {
+	Subscriptions entity.SubscriptionRepository
+	Deliveries    entity.DeliveryRepository
+	Sender        entity.Sender
+	MaxAttempts   int
+	BackoffBase   time.Duration
+	BackoffMax    time.Duration
+	Lease         time.Duration
+	Now           func() time.Time
+}
+
+func NewDispatcher(subscriptions entity.SubscriptionRepository, deliveries entity.DeliveryRepository, sender entity.Sender, maxAttempts int, backoffBase, backoffMax, lease time.Duration) *Dispatcher {
+	return &Dispatcher{
+		Subscriptions: subscriptions,
+		Deliveries:    deliveries,
+		Sender:        sender,
+		MaxAttempts:   maxAttempts,
+		BackoffBase:   backoffBase,
+		BackoffMax:    backoffMax,
+		Lease:         lease,
+		Now:           time.Now,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, e event.Event) error {
+	subs, err := d.Subscriptions.ListSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	now := d.Now().UTC()
+	for _, sub := range subs {
+		if !subscribed(sub, e.Type) {
+			continue
+		}
+
+		if err := d.Deliveries.NewDelivery(entity.Delivery{
+			ID:             uuid.NewString(),
+			SubscriptionID: sub.ID,
+			EventID:        e.ID,
+			EventType:      e.Type,
+			Payload:        body,
+			Status:         config.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.Flush(ctx, config.DefaultWebhookBatchSize); err != nil {
+			slog.ErrorContext(ctx, "error dispatching webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) Flush(ctx context.Context, limit int) (int, error) {
+	due, err := d.Deliveries.Due(d.Now().UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+
+		now := d.Now().UTC()
+		claimed, claimErr := d.Deliveries.Claim(delivery.ID, now, now.Add(d.Lease))
+		if claimErr != nil {
+			slog.ErrorContext(ctx, "error claiming webhook delivery", "delivery_id", delivery.ID, "error", claimErr)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if d.attempt(ctx, &delivery) {
+			delivered++
+		}
+
+		if updateErr := d.Deliveries.UpdateDelivery(delivery); updateErr != nil {
+			slog.ErrorContext(ctx, "error updating webhook delivery", "delivery_id", delivery.ID, "error", updateErr)
+		}
+	}
+
+	return delivered, nil
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, delivery *entity.Delivery) bool {
+	sub, err := d.Subscriptions.GetSubscription(delivery.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, config.ErrWebhookNotFound) {
+			delivery.Status = config.DeliveryDead
+			delivery.LastError = err.Error()
+		}
+		return false
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.Sender.Send(ctx, sub, *delivery)
+	delivery.LastStatusCode = statusCode
+	now := d.Now().UTC()
+
+	if sendErr == nil {
+		delivery.Status = config.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return true
+	}
+
+	delivery.LastError = sendErr.Error()
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = config.DeliveryDead
+		return false
+	}
+
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	return false
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.BackoffBase
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= d.BackoffMax {
+			return d.BackoffMax
+		}
+	}
+	return min(wait, d.BackoffMax)
+}
+
+func subscribed(sub entity.Subscription, eventType string) bool {
+	return slices.Contains(sub.EventTypes, config.WebhookAllEvents) || slices.Contains(sub.EventTypes, eventType)
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+
+	"github.com/google/uuid"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+)
+
+type WebhookServices struct {
+	Subscriptions entity.SubscriptionRepository
+	Deliveries    entity.DeliveryRepository
+	Now           func() time.Time
+}
+
+func NewWebhookService(subscriptions entity.SubscriptionRepository, deliveries entity.DeliveryRepository) Usecases {
+	return &WebhookServices{
+		Subscriptions: subscriptions,
+		Deliveries:    deliveries,
+		Now:           time.Now,
+	}
+}
+
+func (ws *WebhookServices) CreateSubscription(ctx context.Context, rawURL string, eventTypes []string, secret string) (entity.Subscription, error) {
+	if !validURL(rawURL) {
+		return entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, config.ErrInvalidWebhookURL)
+	}
+
+	if !validEventTypes(eventTypes) {
+		return entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, config.ErrInvalidEventTypes)
+	}
+
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, err)
+		}
+		secret = generated
+	}
+	if len(secret) < config.WebhookMinSecretLength {
+		return entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, config.ErrInvalidWebhookSecret)
+	}
+
+	sub := entity.Subscription{
+		ID:         uuid.NewString(),
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  ws.Now().UTC(),
+	}
+
+	if createErr := ws.Subscriptions.NewSubscription(sub); createErr != nil {
+		return entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, createErr)
+	}
+
+	return sub, nil
+}
+
+func (ws *WebhookServices) ListSubscriptions(ctx context.Context) ([]entity.Subscription, error) {
+	subs, err := ws.Subscriptions.ListSubscriptions()
+	if err != nil {
+		return nil, apperror.AppError(config.ErrListingWebhooks, err)
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (ws *WebhookServices) DeleteSubscription(ctx context.Context, id string) error {
+	if deleteErr := ws.Subscriptions.DeleteSubscription(id); deleteErr != nil {
+		return apperror.AppError(config.ErrDeletingWebhook, deleteErr)
+	}
+	return nil
+}
+
+func (ws *WebhookServices) ListDeliveries(ctx context.Context, subscriptionID string) ([]entity.Delivery, error) {
+	if _, err := ws.Subscriptions.GetSubscription(subscriptionID); err != nil {
+		return nil, apperror.AppError(config.ErrListingDeliveries, err)
+	}
+
+	deliveries, err := ws.Deliveries.ListDeliveries(subscriptionID, config.WebhookDeliveryLimit)
+	if err != nil {
+		return nil, apperror.AppError(config.ErrListingDeliveries, err)
+	}
+	return deliveries, nil
+}
+
+func (ws *WebhookServices) Redeliver(ctx context.Context, deliveryID string) (entity.Delivery, error) {
+	delivery, err := ws.Deliveries.GetDelivery(deliveryID)
+	if err != nil {
+		return entity.Delivery{}, apperror.AppError(config.ErrRedelivering, err)
+	}
+
+	delivery.Status = config.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = ws.Now().UTC()
+	delivery.DeliveredAt = nil
+
+	if updateErr := ws.Deliveries.UpdateDelivery(delivery); updateErr != nil {
+		return entity.Delivery{}, apperror.AppError(config.ErrRedelivering, updateErr)
+	}
+
+	return delivery, nil
+}
+
+func validURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Scheme == "https" || parsed.Scheme == "http"
+}
+
+func validEventTypes(eventTypes []string) bool {
+	if len(eventTypes) == 0 {
+		return false
+	}
+	for _, t := range eventTypes {
+		if t != config.WebhookAllEvents && !slices.Contains(config.UserEventTypes, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, config.WebhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
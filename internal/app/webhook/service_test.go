@@ -0,0 +1,383 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	entity "go-manage-hex/internal/core/webhook"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySubscriptions struct {
+	subs map[string]entity.Subscription
+}
+
+func (m *memorySubscriptions) CreateTable(tableName string) error {
+	return nil
+}
+
+func (m *memorySubscriptions) NewSubscription(sub entity.Subscription) error {
+	if m.subs == nil {
+		m.subs = map[string]entity.Subscription{}
+	}
+	m.subs[sub.ID] = sub
+	return nil
+}
+
+func (m *memorySubscriptions) GetSubscription(id string) (entity.Subscription, error) {
+	sub, ok := m.subs[id]
+	if !ok {
+		return entity.Subscription{}, config.ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+func (m *memorySubscriptions) ListSubscriptions() ([]entity.Subscription, error) {
+	subs := []entity.Subscription{}
+	for _, sub := range m.subs {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (m *memorySubscriptions) DeleteSubscription(id string) error {
+	if _, ok := m.subs[id]; !ok {
+		return config.ErrWebhookNotFound
+	}
+	delete(m.subs, id)
+	return nil
+}
+
+type memoryDeliveries struct {
+	deliveries []entity.Delivery
+}
+
+func (m *memoryDeliveries) CreateTable(tableName string) error {
+	return nil
+}
+
+func (m *memoryDeliveries) NewDelivery(delivery entity.Delivery) error {
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func (m *memoryDeliveries) GetDelivery(id string) (entity.Delivery, error) {
+	for _, d := range m.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return entity.Delivery{}, config.ErrDeliveryNotFound
+}
+
+func (m *memoryDeliveries) ListDeliveries(subscriptionID string, limit int) ([]entity.Delivery, error) {
+	var deliveries []entity.Delivery
+	for _, d := range m.deliveries {
+		if d.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *memoryDeliveries) Due(now time.Time, limit int) ([]entity.Delivery, error) {
+	var due []entity.Delivery
+	for _, d := range m.deliveries {
+		if d.Status == config.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryDeliveries) Claim(id string, now, leaseUntil time.Time) (bool, error) {
+	for i, d := range m.deliveries {
+		if d.ID == id && d.Status == config.DeliveryPending && !d.NextAttemptAt.After(now) {
+			m.deliveries[i].NextAttemptAt = leaseUntil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// staleDeliveries hands every dispatcher the same due list, as when several
+// replicas read the batch before any of them has sent it.
+type staleDeliveries struct {
+	*memoryDeliveries
+	due []entity.Delivery
+}
+
+func (s *staleDeliveries) Due(now time.Time, limit int) ([]entity.Delivery, error) {
+	return s.due, nil
+}
+
+func (m *memoryDeliveries) UpdateDelivery(delivery entity.Delivery) error {
+	for i, d := range m.deliveries {
+		if d.ID == delivery.ID {
+			m.deliveries[i] = delivery
+			return nil
+		}
+	}
+	return config.ErrDeliveryNotFound
+}
+
+type fakeSender struct {
+	statusCode int
+	err        error
+	sent       []entity.Delivery
+}
+
+func (f *fakeSender) Send(ctx context.Context, sub entity.Subscription, delivery entity.Delivery) (int, error) {
+	f.sent = append(f.sent, delivery)
+	return f.statusCode, f.err
+}
+
+func TestCreateSubscription(t *testing.T) {
+	test := []struct {
+		Name        string
+		URL         string
+		EventTypes  []string
+		Secret      string
+		ExpectedErr error
+	}{
+		{
+			Name:       "CreateSubscription_Success",
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{config.EventUserCreated},
+			Secret:     "a-long-enough-secret",
+		},
+		{
+			Name:       "CreateSubscription_GeneratedSecret",
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{config.WebhookAllEvents},
+		},
+		{
+			Name:        "CreateSubscription_InvalidURL",
+			URL:         "ftp://partner.example.com",
+			EventTypes:  []string{config.EventUserCreated},
+			ExpectedErr: config.ErrInvalidWebhookURL,
+		},
+		{
+			Name:        "CreateSubscription_UnknownEventType",
+			URL:         "https://partner.example.com/hooks",
+			EventTypes:  []string{"user.exploded"},
+			ExpectedErr: config.ErrInvalidEventTypes,
+		},
+		{
+			Name:        "CreateSubscription_NoEventTypes",
+			URL:         "https://partner.example.com/hooks",
+			ExpectedErr: config.ErrInvalidEventTypes,
+		},
+		{
+			Name:        "CreateSubscription_ShortSecret",
+			URL:         "https://partner.example.com/hooks",
+			EventTypes:  []string{config.EventUserCreated},
+			Secret:      "short",
+			ExpectedErr: config.ErrInvalidWebhookSecret,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			subs := &memorySubscriptions{}
+			service := NewWebhookService(subs, &memoryDeliveries{})
+
+			created, err := service.CreateSubscription(context.Background(), tt.URL, tt.EventTypes, tt.Secret)
+
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				assert.Empty(t, subs.subs)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, created.Secret)
+			if tt.Secret != "" {
+				assert.Equal(t, tt.Secret, created.Secret)
+			}
+
+			listed, err := service.ListSubscriptions(context.Background())
+			assert.NoError(t, err)
+			if assert.Len(t, listed, 1) {
+				assert.Empty(t, listed[0].Secret)
+			}
+		})
+	}
+}
+
+func TestDispatcherPublish(t *testing.T) {
+	subs := &memorySubscriptions{}
+	subs.NewSubscription(entity.Subscription{ID: "created-only", EventTypes: []string{config.EventUserCreated}})
+	subs.NewSubscription(entity.Subscription{ID: "everything", EventTypes: []string{config.WebhookAllEvents}})
+	deliveries := &memoryDeliveries{}
+
+	dispatcher := NewDispatcher(subs, deliveries, &fakeSender{}, 3, time.Second, time.Minute, time.Minute)
+
+	deleted := event.Event{ID: "evt-1", Type: config.EventUserDeleted, Aggregate: "johndoe", Payload: []byte(`{}`)}
+	require.NoError(t, dispatcher.Publish(context.Background(), deleted))
+	require.NoError(t, dispatcher.Publish(context.Background(), deleted))
+
+	if assert.Len(t, deliveries.deliveries, 1) {
+		assert.Equal(t, "everything", deliveries.deliveries[0].SubscriptionID)
+		assert.Contains(t, string(deliveries.deliveries[0].Payload), `"id":"evt-1"`)
+	}
+
+	created := event.Event{ID: "evt-2", Type: config.EventUserCreated, Aggregate: "johndoe", Payload: []byte(`{}`)}
+	require.NoError(t, dispatcher.Publish(context.Background(), created))
+
+	assert.Len(t, deliveries.deliveries, 3)
+}
+
+func TestDispatcherFlush(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	test := []struct {
+		Name              string
+		Attempts          int
+		SubscriptionID    string
+		SendErr           error
+		StatusCode        int
+		ExpectedStatus    string
+		ExpectedAttempts  int
+		ExpectedNext      time.Time
+		ExpectedDelivered int
+	}{
+		{
+			Name:              "Flush_Delivered",
+			SubscriptionID:    "sub-1",
+			StatusCode:        200,
+			ExpectedStatus:    config.DeliveryDelivered,
+			ExpectedAttempts:  1,
+			ExpectedNext:      now,
+			ExpectedDelivered: 1,
+		},
+		{
+			Name:             "Flush_FirstFailureBacksOff",
+			SubscriptionID:   "sub-1",
+			SendErr:          config.ErrWebhookDelivery,
+			StatusCode:       500,
+			ExpectedStatus:   config.DeliveryPending,
+			ExpectedAttempts: 1,
+			ExpectedNext:     now.Add(10 * time.Second),
+		},
+		{
+			Name:             "Flush_BackoffDoubles",
+			Attempts:         2,
+			SubscriptionID:   "sub-1",
+			SendErr:          config.ErrWebhookDelivery,
+			StatusCode:       500,
+			ExpectedStatus:   config.DeliveryPending,
+			ExpectedAttempts: 3,
+			ExpectedNext:     now.Add(40 * time.Second),
+		},
+		{
+			Name:             "Flush_BackoffCapped",
+			Attempts:         6,
+			SubscriptionID:   "sub-1",
+			SendErr:          config.ErrWebhookDelivery,
+			StatusCode:       503,
+			ExpectedStatus:   config.DeliveryPending,
+			ExpectedAttempts: 7,
+			ExpectedNext:     now.Add(time.Minute),
+		},
+		{
+			Name:             "Flush_DeadLetter",
+			Attempts:         7,
+			SubscriptionID:   "sub-1",
+			SendErr:          errors.New("connection refused"),
+			ExpectedStatus:   config.DeliveryDead,
+			ExpectedAttempts: 8,
+			ExpectedNext:     now,
+		},
+		{
+			Name:             "Flush_SubscriptionDeleted",
+			SubscriptionID:   "gone",
+			ExpectedStatus:   config.DeliveryDead,
+			ExpectedAttempts: 0,
+			ExpectedNext:     now,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			subs := &memorySubscriptions{}
+			subs.NewSubscription(entity.Subscription{ID: "sub-1", URL: "https://partner.example.com", EventTypes: []string{config.WebhookAllEvents}})
+			deliveries := &memoryDeliveries{deliveries: []entity.Delivery{{
+				ID:             "delivery-1",
+				SubscriptionID: tt.SubscriptionID,
+				EventID:        "evt-1",
+				Status:         config.DeliveryPending,
+				Attempts:       tt.Attempts,
+				NextAttemptAt:  now,
+			}}}
+			sender := &fakeSender{statusCode: tt.StatusCode, err: tt.SendErr}
+
+			dispatcher := NewDispatcher(subs, deliveries, sender, 8, 10*time.Second, time.Minute, time.Minute)
+			dispatcher.Now = func() time.Time { return now }
+
+			delivered, err := dispatcher.Flush(context.Background(), 10)
+			require.NoError(t, err)
+
+			got := deliveries.deliveries[0]
+			assert.Equal(t, tt.ExpectedDelivered, delivered)
+			assert.Equal(t, tt.ExpectedStatus, got.Status)
+			assert.Equal(t, tt.ExpectedAttempts, got.Attempts)
+			assert.Equal(t, tt.ExpectedNext, got.NextAttemptAt)
+			assert.Equal(t, tt.StatusCode, got.LastStatusCode)
+		})
+	}
+}
+
+func TestDispatcherFlush_ClaimsOnce(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	subs := &memorySubscriptions{}
+	subs.NewSubscription(entity.Subscription{ID: "sub-1", URL: "https://partner.example.com", EventTypes: []string{config.WebhookAllEvents}})
+	pending := entity.Delivery{ID: "delivery-1", SubscriptionID: "sub-1", EventID: "evt-1", Status: config.DeliveryPending, NextAttemptAt: now}
+	deliveries := &staleDeliveries{memoryDeliveries: &memoryDeliveries{deliveries: []entity.Delivery{pending}}, due: []entity.Delivery{pending}}
+	sender := &fakeSender{err: config.ErrWebhookDelivery, statusCode: 500}
+
+	for range 2 {
+		dispatcher := NewDispatcher(subs, deliveries, sender, 8, 10*time.Second, time.Minute, time.Minute)
+		dispatcher.Now = func() time.Time { return now }
+
+		_, err := dispatcher.Flush(context.Background(), 10)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, sender.sent, 1)
+	assert.Equal(t, 1, deliveries.deliveries[0].Attempts)
+}
+
+func TestRedeliver(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	deliveredAt := now.Add(-time.Hour)
+
+	deliveries := &memoryDeliveries{deliveries: []entity.Delivery{{
+		ID:          "delivery-1",
+		Status:      config.DeliveryDead,
+		Attempts:    8,
+		DeliveredAt: &deliveredAt,
+	}}}
+	service := &WebhookServices{Subscriptions: &memorySubscriptions{}, Deliveries: deliveries, Now: func() time.Time { return now }}
+
+	queued, err := service.Redeliver(context.Background(), "delivery-1")
+	require.NoError(t, err)
+	assert.Equal(t, config.DeliveryPending, queued.Status)
+	assert.Equal(t, 0, queued.Attempts)
+	assert.Equal(t, now, deliveries.deliveries[0].NextAttemptAt)
+	assert.Nil(t, deliveries.deliveries[0].DeliveredAt)
+
+	_, err = service.Redeliver(context.Background(), "missing")
+	assert.ErrorIs(t, err, config.ErrDeliveryNotFound)
+}
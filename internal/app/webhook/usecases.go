@@ -0,0 +1,14 @@
+package webhook
+
+import (
+	"context"
+	entity "go-manage-hex/internal/core/webhook"
+)
+
+type Usecases interface {
+	CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (entity.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]entity.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string) ([]entity.Delivery, error)
+	Redeliver(ctx context.Context, deliveryID string) (entity.Delivery, error)
+}
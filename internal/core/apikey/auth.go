@@ -0,0 +1,7 @@
+package apikey
+
+import "context"
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (APIKey, error)
+}
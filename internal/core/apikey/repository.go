@@ -0,0 +1,12 @@
+package apikey
+
+import "time"
+
+type Repository interface {
+	CreateTable(tableName string) error
+	NewAPIKey(key APIKey) error
+	GetByPrefix(prefix string) (APIKey, error)
+	ListByUsername(username string) ([]APIKey, error)
+	Revoke(username, id string, revokedAt time.Time) error
+	TouchLastUsed(id string, usedAt time.Time) error
+}
@@ -0,0 +1,15 @@
+package bulk
+
+import "go-manage-hex/internal/core/user"
+
+// RecordReader returns io.EOF after the last record.
+type RecordReader interface {
+	Next() (Record, error)
+}
+
+// RecordWriter never receives password hashes; implementations only encode
+// the public user fields.
+type RecordWriter interface {
+	Write(u user.User) error
+	Flush() error
+}
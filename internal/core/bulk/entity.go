@@ -0,0 +1,58 @@
+package bulk
+
+import (
+	"fmt"
+
+	"go-manage-hex/internal/core/user"
+)
+
+// Record is one decoded import row. Line is the position in the source, used
+// for error reports and to resume an interrupted import.
+type Record struct {
+	Line      int
+	User      user.User
+	PreHashed bool
+}
+
+type RowError struct {
+	Line     int    `json:"line"`
+	Username string `json:"username,omitempty"`
+	Error    string `json:"error"`
+}
+
+type ImportOptions struct {
+	DryRun      bool `json:"dry_run"`
+	BatchSize   int  `json:"batch_size"`
+	ResumeAfter int  `json:"resume_after,omitempty"`
+}
+
+// ImportReport describes an import so far. Every line up to LastLine has been
+// handled, so a new run with ResumeAfter set to it continues where this one
+// stopped. In a dry run Created counts the rows that would be created.
+type ImportReport struct {
+	DryRun    bool       `json:"dry_run"`
+	Processed int        `json:"processed"`
+	Created   int        `json:"created"`
+	Failed    int        `json:"failed"`
+	LastLine  int        `json:"last_line"`
+	Errors    []RowError `json:"errors,omitempty"`
+}
+
+type ExportOptions struct {
+	Filter *user.UserFilter `json:"-"`
+}
+
+// ParseError marks a row that could not be decoded; the import reports it and
+// moves on to the next row.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", pe.Line, pe.Err)
+}
+
+func (pe *ParseError) Unwrap() error {
+	return pe.Err
+}
@@ -0,0 +1,18 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Event struct {
+	Sequence   int64           `json:"sequence,omitempty"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Aggregate  string          `json:"aggregate"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+
+	// Attempts counts failed publishes so far; it never leaves the outbox.
+	Attempts int `json:"-"`
+}
@@ -0,0 +1,7 @@
+package event
+
+import "context"
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
@@ -0,0 +1,17 @@
+package event
+
+import "time"
+
+type Recorder interface {
+	Record(events ...Event) error
+}
+
+type OutboxRepository interface {
+	Recorder
+	CreateTable(tableName string) error
+	Pending(now time.Time, limit int) ([]Event, error)
+	Claim(sequence int64, now, leaseUntil time.Time) (bool, error)
+	MarkPublished(sequence int64, publishedAt time.Time) error
+	MarkFailed(sequence int64, reason string, nextAttemptAt time.Time) error
+	MarkDead(sequence int64, reason string, deadAt time.Time) error
+}
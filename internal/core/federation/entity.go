@@ -0,0 +1,29 @@
+package federation
+
+import "time"
+
+type Identity struct {
+	Provider string    `json:"provider"`
+	Subject  string    `json:"subject"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	LinkedAt time.Time `json:"linked_at"`
+}
+
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+}
+
+type PendingLogin struct {
+	State        string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	LinkUsername string
+	ExpiresAt    time.Time
+}
@@ -0,0 +1,9 @@
+package federation
+
+import "context"
+
+type IdentityProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (Claims, error)
+}
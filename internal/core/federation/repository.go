@@ -0,0 +1,12 @@
+package federation
+
+type IdentityRepository interface {
+	CreateTable(tableName string) error
+	GetIdentity(provider, subject string) (Identity, error)
+	LinkIdentity(identity Identity) error
+}
+
+type StateStore interface {
+	Save(pending PendingLogin) error
+	Consume(state string) (PendingLogin, error)
+}
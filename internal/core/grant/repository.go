@@ -0,0 +1,14 @@
+package grant
+
+import (
+	"context"
+	"time"
+)
+
+// Repository stores the scopes granted to a user on top of the login
+// defaults. Granting a scope twice keeps the first grant.
+type Repository interface {
+	Grant(ctx context.Context, username string, scopes []string, grantedAt time.Time) error
+	Revoke(ctx context.Context, username string, scopes []string) error
+	List(ctx context.Context, username string) ([]string, error)
+}
@@ -0,0 +1,8 @@
+package health
+
+import "context"
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
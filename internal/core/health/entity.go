@@ -0,0 +1,16 @@
+package health
+
+import "time"
+
+type Report struct {
+	Status    string        `json:"status"`
+	Checks    []CheckResult `json:"checks,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,25 @@
+package idempotency
+
+import (
+	"net/http"
+	"time"
+)
+
+// Record is what is kept for a key: the fingerprint of the first request and,
+// once its handler returned, the response to replay. A record without a
+// Status is still being processed.
+type Record struct {
+	Caller      string
+	Key         string
+	Fingerprint string
+	Status      int
+	ContentType string
+	Headers     http.Header
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (r Record) Completed() bool {
+	return r.Status != 0
+}
@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps idempotency records shared by every replica, so a retry is
+// recognised whichever replica it reaches. Reserve is atomic: of several
+// concurrent requests with the same key only one gets reserved == true, the
+// others get the record it created.
+type Store interface {
+	Reserve(ctx context.Context, record Record) (existing Record, reserved bool, err error)
+	Complete(ctx context.Context, record Record) error
+	Release(ctx context.Context, caller, key string) error
+	Purge(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,33 @@
+package job
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work. Payload is private to the job type and
+// never returned by the API; Progress and Result are whatever the handler
+// reported, encoded as JSON.
+type Job struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	Payload        json.RawMessage `json:"-"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	Progress       json.RawMessage `json:"progress,omitempty"`
+	Result         json.RawMessage `json:"result,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	RunAt          time.Time       `json:"run_at"`
+	LeaseOwner     string          `json:"lease_owner,omitempty"`
+	LeaseExpiresAt *time.Time      `json:"lease_expires_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+}
+
+type JobQuery struct {
+	Type   string
+	Status string
+	Limit  int
+}
@@ -0,0 +1,20 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Repository stores jobs so they survive restarts and can be shared by
+// several replicas. Claim, Heartbeat and Finish are conditional on the lease,
+// so only one worker owns a job at a time.
+type Repository interface {
+	NewJob(ctx context.Context, job Job) error
+	GetJob(ctx context.Context, id string) (Job, error)
+	ListJobs(ctx context.Context, query JobQuery) ([]Job, error)
+	Due(ctx context.Context, types []string, now time.Time, limit int) ([]Job, error)
+	Claim(ctx context.Context, id, owner string, now, leaseUntil time.Time) (bool, error)
+	Heartbeat(ctx context.Context, id, owner string, progress json.RawMessage, now, leaseUntil time.Time) error
+	Finish(ctx context.Context, job Job, owner string) error
+}
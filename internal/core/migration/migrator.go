@@ -0,0 +1,18 @@
+package migration
+
+import (
+	"context"
+	"time"
+)
+
+type VersionStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Migrator interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context, steps int) error
+	Status(ctx context.Context) ([]VersionStatus, error)
+}
@@ -0,0 +1,25 @@
+package oidc
+
+import "time"
+
+type Client struct {
+	ID           string    `json:"client_id"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"client_name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	AuthMethod   string    `json:"token_endpoint_auth_method"`
+	Scopes       []string  `json:"-"`
+	CreatedAt    time.Time `json:"client_id_issued_at"`
+}
+
+type AuthorizationCode struct {
+	Code          string
+	ClientID      string
+	Username      string
+	RedirectURI   string
+	Scopes        []string
+	Nonce         string
+	CodeChallenge string
+	AuthTime      time.Time
+	ExpiresAt     time.Time
+}
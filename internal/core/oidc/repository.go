@@ -0,0 +1,14 @@
+package oidc
+
+type ClientRepository interface {
+	CreateTable(tableName string) error
+	NewClient(client Client) error
+	GetClient(id string) (Client, error)
+}
+
+type CodeStore interface {
+	Save(code AuthorizationCode) error
+	// Consume returns and deletes code, but only when it was issued to
+	// clientID: another client presenting it must not be able to burn it.
+	Consume(code, clientID string) (AuthorizationCode, error)
+}
@@ -0,0 +1,6 @@
+package oidc
+
+type IDTokenSigner interface {
+	Sign(claims map[string]any) (string, error)
+	JWKS() map[string]any
+}
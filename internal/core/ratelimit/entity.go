@@ -0,0 +1,53 @@
+package ratelimit
+
+import "time"
+
+// Policy allows Limit requests per Period. Buckets of different policies are
+// kept apart, so Name must be unique per policy.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+}
+
+// Decision is the outcome of taking a token. Reset is the time until the
+// bucket is full again and RetryAfter, set only when the request is denied,
+// the time until the next token.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Bucket is the token bucket a store keeps per key. A zero Bucket is full.
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills the bucket for the time elapsed since it was last updated and
+// spends one token if there is one.
+func (b Bucket) Take(policy Policy, now time.Time) (Bucket, Decision) {
+	capacity := float64(policy.Limit)
+	interval := float64(policy.Period) / capacity
+
+	tokens := capacity
+	if !b.Updated.IsZero() {
+		elapsed := max(now.Sub(b.Updated), 0)
+		tokens = min(capacity, b.Tokens+float64(elapsed)/interval)
+	}
+
+	decision := Decision{Limit: policy.Limit}
+	if tokens >= 1 {
+		tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - tokens) * interval)
+	}
+	decision.Remaining = int(tokens)
+	decision.Reset = time.Duration((capacity - tokens) * interval)
+
+	return Bucket{Tokens: tokens, Updated: now}, decision
+}
@@ -0,0 +1,10 @@
+package ratelimit
+
+import "context"
+
+// Store takes tokens from the bucket of key under policy. The in-memory store
+// limits each replica on its own; a shared store makes every replica spend
+// from the same buckets.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Decision, error)
+}
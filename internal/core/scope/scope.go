@@ -0,0 +1,56 @@
+package scope
+
+import (
+	"slices"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+)
+
+func Parse(raw string) []string {
+	scopes := []string{}
+	for _, s := range strings.Fields(raw) {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+func String(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+func Has(granted []string, required ...string) bool {
+	for _, r := range required {
+		if !slices.Contains(granted, r) {
+			return false
+		}
+	}
+	return true
+}
+
+func Validate(scopes []string) error {
+	if len(scopes) == 0 {
+		return config.ErrInvalidScope
+	}
+	for _, s := range scopes {
+		if !slices.Contains(config.AllScopes, s) {
+			return config.ErrInvalidScope
+		}
+	}
+	return nil
+}
+
+func Narrow(requested, allowed []string) ([]string, error) {
+	if len(requested) == 0 {
+		return slices.Clone(allowed), nil
+	}
+	if err := Validate(requested); err != nil {
+		return nil, err
+	}
+	if !Has(allowed, requested...) {
+		return nil, config.ErrInvalidScope
+	}
+	return requested, nil
+}
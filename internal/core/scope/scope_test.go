@@ -0,0 +1,61 @@
+package scope
+
+import (
+	"go-manage-hex/cmd/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []string{"users:read", "users:write"}, Parse(" users:read  users:write users:read "))
+	assert.Equal(t, []string{}, Parse(""))
+	assert.Equal(t, "users:read users:write", String([]string{"users:read", "users:write"}))
+}
+
+func TestNarrow(t *testing.T) {
+	test := []struct {
+		Name        string
+		Requested   []string
+		Allowed     []string
+		Expected    []string
+		ExpectedErr error
+	}{
+		{
+			Name:     "DefaultsToAllowed",
+			Allowed:  config.AllScopes,
+			Expected: config.AllScopes,
+		},
+		{
+			Name:      "Narrower",
+			Requested: []string{config.ScopeUsersRead},
+			Allowed:   config.AllScopes,
+			Expected:  []string{config.ScopeUsersRead},
+		},
+		{
+			Name:        "Unknown",
+			Requested:   []string{"admin:all"},
+			Allowed:     config.AllScopes,
+			ExpectedErr: config.ErrInvalidScope,
+		},
+		{
+			Name:        "Wider",
+			Requested:   []string{config.ScopeUsersDelete},
+			Allowed:     []string{config.ScopeUsersRead},
+			ExpectedErr: config.ErrInvalidScope,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			granted, err := Narrow(tt.Requested, tt.Allowed)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, granted)
+			assert.True(t, Has(granted, tt.Expected...))
+		})
+	}
+}
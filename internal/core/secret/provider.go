@@ -0,0 +1,5 @@
+package secret
+
+type Provider interface {
+	Lookup(key string) (value string, found bool, err error)
+}
@@ -1,6 +1,11 @@
 package user
 
+type TokenClaims struct {
+	Username string
+	Scopes   []string
+}
+
 type Authorization interface {
-	GenerateJWT(username string) (string, error)
-	ValidateJWT(tokenStr string) (string, error)
+	GenerateJWT(username string, scopes []string) (string, error)
+	ValidateJWT(tokenStr string) (TokenClaims, error)
 }
@@ -0,0 +1,8 @@
+package user
+
+import "context"
+
+type AuthSource interface {
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (User, error)
+}
@@ -0,0 +1,7 @@
+package user
+
+import "context"
+
+type BreachedPasswordChecker interface {
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type User struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
@@ -7,4 +9,19 @@ type User struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+
+	// DeletedAt is only set by lookups that include soft-deleted rows.
+	DeletedAt *time.Time `json:"-"`
+}
+
+type UserFilter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+type UserQuery struct {
+	Filter *UserFilter
+	Offset int
+	Limit  int
 }
@@ -0,0 +1,23 @@
+package user
+
+import "context"
+
+type PasswordHasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	Verify(ctx context.Context, encoded, password string) (bool, error)
+	NeedsRehash(encoded string) bool
+	Supports(encoded string) bool
+}
+
+type backgroundKey struct{}
+
+// WithBackground marks ctx as background work, such as an import, that can
+// wait for hashing capacity instead of competing with logins for it.
+func WithBackground(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundKey{}, true)
+}
+
+func IsBackground(ctx context.Context) bool {
+	background, _ := ctx.Value(backgroundKey{}).(bool)
+	return background
+}
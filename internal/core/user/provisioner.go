@@ -0,0 +1,12 @@
+package user
+
+import "context"
+
+// Provisioner creates local accounts for users who authenticate elsewhere,
+// such as LDAP or a federated identity provider. They have no local password.
+// IsDeleted reports a soft-deleted local account, which those sources must
+// refuse even though the other directory still accepts the user.
+type Provisioner interface {
+	ProvisionUser(ctx context.Context, user User) (User, error)
+	IsDeleted(ctx context.Context, username string) bool
+}
@@ -1,11 +1,23 @@
 package user
 
+import "context"
+
 type MysqlRepository interface {
 	CreateTable(tableName string) error
-	GetByUsername(username string) (User, error)
-	CheckExists(username string) bool
-	NewUser(user User) error
-	DeleteUser(username string) error
-	UpdateUser(username string, user User) error
-	ChangePwd(newPwd, username string) error
+	GetByUsername(ctx context.Context, username string) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
+	GetByID(ctx context.Context, id string) (User, error)
+	GetByIDWithDeleted(ctx context.Context, id string) (User, error)
+	ListUsers(ctx context.Context, query UserQuery) ([]User, int, error)
+	CheckExists(ctx context.Context, username string) bool
+	IsDeleted(ctx context.Context, username string) bool
+	NewUser(ctx context.Context, user User) error
+	NewUsers(ctx context.Context, users []User) error
+	DeleteUser(ctx context.Context, username string) error
+	RestoreUser(ctx context.Context, username string) error
+	UpdateUser(ctx context.Context, username string, user User) error
+	ChangePwd(ctx context.Context, newPwd, username string) error
+	CreateHistoryTable(tableName string) error
+	AddPwdHistory(ctx context.Context, username, hash string) error
+	GetPwdHistory(ctx context.Context, username string, limit int) ([]string, error)
 }
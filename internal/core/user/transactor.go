@@ -0,0 +1,11 @@
+package user
+
+import (
+	"context"
+
+	"go-manage-hex/internal/core/event"
+)
+
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(users MysqlRepository, events event.Recorder) error) error
+}
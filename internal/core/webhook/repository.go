@@ -0,0 +1,21 @@
+package webhook
+
+import "time"
+
+type SubscriptionRepository interface {
+	CreateTable(tableName string) error
+	NewSubscription(sub Subscription) error
+	GetSubscription(id string) (Subscription, error)
+	ListSubscriptions() ([]Subscription, error)
+	DeleteSubscription(id string) error
+}
+
+type DeliveryRepository interface {
+	CreateTable(tableName string) error
+	NewDelivery(delivery Delivery) error
+	GetDelivery(id string) (Delivery, error)
+	ListDeliveries(subscriptionID string, limit int) ([]Delivery, error)
+	Due(now time.Time, limit int) ([]Delivery, error)
+	Claim(id string, now, leaseUntil time.Time) (bool, error)
+	UpdateDelivery(delivery Delivery) error
+}
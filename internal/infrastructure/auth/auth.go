@@ -1,9 +1,14 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/scope"
+	entity "go-manage-hex/internal/core/user"
 	claim "go-manage-hex/internal/infrastructure/http/middleware"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,6 +17,10 @@ import (
 type JWTService struct {
 	SecretKey string
 	Duration  time.Duration
+
+	mu        sync.RWMutex
+	previous  string
+	rotatedAt time.Time
 }
 
 func NewJWTService(secret string, duration time.Duration) *JWTService {
@@ -21,11 +30,41 @@ func NewJWTService(secret string, duration time.Duration) *JWTService {
 	}
 }
 
-func (j *JWTService) GenerateJWT(username string) (string, error) {
+// Rotate switches the signing secret. Tokens signed with the previous secret
+// keep validating until they would have expired anyway.
+func (j *JWTService) Rotate(secret string) error {
+	if err := config.CheckJWTSecret(secret); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if secret == j.SecretKey {
+		return nil
+	}
+
+	j.previous, j.SecretKey = j.SecretKey, secret
+	j.rotatedAt = time.Now()
+	return nil
+}
+
+func (j *JWTService) secrets() (string, string) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if j.previous != "" && time.Since(j.rotatedAt) < j.Duration {
+		return j.SecretKey, j.previous
+	}
+	return j.SecretKey, ""
+}
+
+func (j *JWTService) GenerateJWT(username string, scopes []string) (string, error) {
 	now := time.Now()
 
 	claims := claim.Claims{
 		Username: username,
+		Scope:    scope.String(scopes),
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(j.Duration)),
@@ -34,24 +73,38 @@ func (j *JWTService) GenerateJWT(username string) (string, error) {
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString([]byte(j.SecretKey))
+	current, _ := j.secrets()
+
+	return token.SignedString([]byte(current))
 }
 
-func (j *JWTService) ValidateJWT(tokenStr string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &claim.Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("invalid token sign")
-		}
-		return []byte(j.SecretKey), nil
-	})
+func (j *JWTService) ValidateJWT(tokenStr string) (entity.TokenClaims, error) {
+	current, previous := j.secrets()
+
+	token, err := parse(tokenStr, current)
+	if errors.Is(err, jwt.ErrTokenSignatureInvalid) && previous != "" {
+		token, err = parse(tokenStr, previous)
+	}
 	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
+		return entity.TokenClaims{}, fmt.Errorf("invalid token: %w", err)
 	}
 
 	claims, ok := token.Claims.(*claim.Claims)
 	if !ok || !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return entity.TokenClaims{}, fmt.Errorf("invalid token")
 	}
 
-	return claims.Username, nil
+	return entity.TokenClaims{
+		Username: claims.Username,
+		Scopes:   scope.Parse(claims.Scope),
+	}, nil
+}
+
+func parse(tokenStr, secret string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenStr, &claim.Claims{}, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("invalid token sign")
+		}
+		return []byte(secret), nil
+	})
 }
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"go-manage-hex/cmd/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWTScopes(t *testing.T) {
+	service := NewJWTService("secret", time.Minute)
+
+	token, err := service.GenerateJWT("johndoe", []string{config.ScopeUsersRead, config.ScopeUsersWrite})
+	assert.NoError(t, err)
+
+	claims, err := service.ValidateJWT(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "johndoe", claims.Username)
+	assert.Equal(t, []string{config.ScopeUsersRead, config.ScopeUsersWrite}, claims.Scopes)
+
+	_, err = NewJWTService("other", time.Minute).ValidateJWT(token)
+	assert.Error(t, err)
+}
+
+func TestJWTRotate(t *testing.T) {
+	oldSecret := "old-jwt-secret-0123456789abcdefgh"
+	newSecret := "new-jwt-secret-0123456789abcdefgh"
+
+	service := NewJWTService(oldSecret, time.Minute)
+	oldToken, err := service.GenerateJWT("johndoe", nil)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, service.Rotate("short"), config.ErrWeakJWTSecret)
+	assert.Equal(t, oldSecret, service.SecretKey)
+
+	assert.NoError(t, service.Rotate(newSecret))
+
+	newToken, err := service.GenerateJWT("johndoe", nil)
+	assert.NoError(t, err)
+
+	_, err = NewJWTService(newSecret, time.Minute).ValidateJWT(newToken)
+	assert.NoError(t, err)
+
+	_, err = service.ValidateJWT(oldToken)
+	assert.NoError(t, err, "tokens signed before the rotation stay valid")
+
+	service.rotatedAt = time.Now().Add(-time.Hour)
+	_, err = service.ValidateJWT(oldToken)
+	assert.Error(t, err)
+}
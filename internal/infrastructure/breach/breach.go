@@ -0,0 +1,56 @@
+package breach
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/user"
+)
+
+func NewChecker(mode, corpusPath, indexPath, apiURL string, timeout time.Duration) (entity.BreachedPasswordChecker, error) {
+	switch mode {
+	case config.BreachCheckOff:
+		return nil, nil
+	case config.BreachCheckIndex:
+		if err := ensureIndex(corpusPath, indexPath); err != nil {
+			return nil, err
+		}
+		index, err := OpenIndex(indexPath)
+		if err != nil {
+			return nil, err
+		}
+		return index, nil
+	case config.BreachCheckHTTP:
+		return NewHTTPChecker(apiURL, timeout), nil
+	default:
+		return nil, config.ErrUnknownBreachMode
+	}
+}
+
+func ensureIndex(corpusPath, indexPath string) error {
+	if corpusPath == "" {
+		return nil
+	}
+
+	corpus, err := os.Stat(corpusPath)
+	if err != nil {
+		return err
+	}
+
+	if index, err := os.Stat(indexPath); err == nil && !index.ModTime().Before(corpus.ModTime()) {
+		return nil
+	}
+
+	slog.Info("building breached password index", "corpus", corpusPath)
+
+	count, err := BuildIndex(corpusPath, indexPath)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("breached password index built", "hashes", count)
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package breach
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeRangeFiles(t *testing.T, passwords ...string) string {
+	dir := t.TempDir()
+
+	ranges := map[string][]string{}
+	for _, password := range passwords {
+		full := sha1Hex(password)
+		ranges[full[:5]] = append(ranges[full[:5]], full[5:])
+	}
+
+	for prefix, suffixes := range ranges {
+		sort.Strings(suffixes)
+		lines := make([]string, 0, len(suffixes))
+		for _, suffix := range suffixes {
+			lines = append(lines, suffix+":42")
+		}
+		if err := os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestIndexChecker(t *testing.T) {
+	breached := []string{"password", "123456", "qwerty", "Password1234", "letmein"}
+
+	test := []struct {
+		Name     string
+		Corpus   func(t *testing.T) string
+		Password string
+		Expected bool
+	}{
+		{
+			Name:     "RangeFiles_Breached",
+			Corpus:   func(t *testing.T) string { return writeRangeFiles(t, breached...) },
+			Password: "qwerty",
+			Expected: true,
+		},
+		{
+			Name:     "RangeFiles_NotBreached",
+			Corpus:   func(t *testing.T) string { return writeRangeFiles(t, breached...) },
+			Password: "Tr0ub4dor&3-horse",
+			Expected: false,
+		},
+		{
+			Name: "OrderedFile_Breached",
+			Corpus: func(t *testing.T) string {
+				hashes := []string{}
+				for _, p := range breached {
+					hashes = append(hashes, sha1Hex(p)+":7")
+				}
+				sort.Strings(hashes)
+				path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+				if err := os.WriteFile(path, []byte(strings.Join(hashes, "\n")), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return path
+			},
+			Password: "letmein",
+			Expected: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			indexPath := filepath.Join(t.TempDir(), "breached.idx")
+
+			count, err := BuildIndex(tt.Corpus(t), indexPath)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(len(breached)), count)
+
+			index, err := OpenIndex(indexPath)
+			assert.NoError(t, err)
+			defer index.Close()
+
+			found, err := index.IsBreached(context.Background(), tt.Password)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, found)
+		})
+	}
+}
+
+func TestBuildIndex_Err(t *testing.T) {
+	test := []struct {
+		Name        string
+		Content     string
+		ExpectedErr error
+	}{
+		{
+			Name:        "NotSorted",
+			Content:     sha1Hex("qwerty") + ":1\n" + sha1Hex("password") + ":1",
+			ExpectedErr: config.ErrCorpusNotSorted,
+		},
+		{
+			Name:        "InvalidLine",
+			Content:     "not-a-hash:1",
+			ExpectedErr: config.ErrInvalidCorpusLine,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			dir := t.TempDir()
+			corpus := filepath.Join(dir, "corpus.txt")
+			if err := os.WriteFile(corpus, []byte(tt.Content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := BuildIndex(corpus, filepath.Join(dir, "breached.idx"))
+			assert.ErrorIs(t, err, tt.ExpectedErr)
+
+			_, statErr := os.Stat(filepath.Join(dir, "breached.idx"))
+			assert.True(t, os.IsNotExist(statErr))
+		})
+	}
+}
+
+func TestOpenIndex_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.idx")
+	if err := os.WriteFile(path, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := OpenIndex(path)
+	assert.ErrorIs(t, err, config.ErrInvalidBreachIndex)
+}
+
+func TestHTTPChecker(t *testing.T) {
+	breachedHash := sha1Hex("password")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		if len(prefix) != 5 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if prefix == "FFFFF" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+
+		fmt.Fprintln(w, "0000000000000000000000000000000000A:0")
+		if prefix == breachedHash[:5] {
+			fmt.Fprintf(w, "%s:3861493\r\n", breachedHash[5:])
+		}
+	}))
+	defer server.Close()
+
+	checker := NewHTTPChecker(server.URL, time.Second)
+
+	found, err := checker.IsBreached(context.Background(), "password")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = checker.IsBreached(context.Background(), "Tr0ub4dor&3-horse")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestNewChecker(t *testing.T) {
+	checker, err := NewChecker(config.BreachCheckOff, "", "", "", time.Second)
+	assert.NoError(t, err)
+	assert.Nil(t, checker)
+
+	_, err = NewChecker("bogus", "", "", "", time.Second)
+	assert.ErrorIs(t, err, config.ErrUnknownBreachMode)
+
+	corpus := writeRangeFiles(t, "password")
+	indexPath := filepath.Join(t.TempDir(), "breached.idx")
+
+	checker, err = NewChecker(config.BreachCheckIndex, corpus, indexPath, "", time.Second)
+	assert.NoError(t, err)
+
+	found, err := checker.IsBreached(context.Background(), "password")
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
@@ -0,0 +1,132 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+)
+
+const (
+	digestSize = 20
+	prefixSize = 5
+)
+
+type digest [digestSize]byte
+
+// readCorpus streams every digest of an HIBP corpus in ascending order. The
+// corpus is either a single file of full "HASH:COUNT" lines or a directory of
+// range files named after their 5 character prefix holding "SUFFIX:COUNT" lines.
+func readCorpus(path string, fn func(d digest) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return readCorpusFile(path, rangePrefix(path), fn)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && rangePrefix(entry.Name()) != "" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.ToUpper(names[i]) < strings.ToUpper(names[j])
+	})
+
+	var last *digest
+	for _, name := range names {
+		err := readCorpusFile(filepath.Join(path, name), rangePrefix(name), func(d digest) error {
+			if last != nil && bytes.Compare(d[:], last[:]) <= 0 {
+				return fmt.Errorf("%w: %s", config.ErrCorpusNotSorted, name)
+			}
+			last = &d
+			return fn(d)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readCorpusFile(path, prefix string, fn func(d digest) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	var last *digest
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		hash, count, found := strings.Cut(text, ":")
+		if found {
+			if n, err := strconv.Atoi(strings.TrimSpace(count)); err == nil && n == 0 {
+				continue
+			}
+		}
+
+		if len(hash) == 2*digestSize-prefixSize {
+			if prefix == "" {
+				return fmt.Errorf("%w: %s:%d", config.ErrInvalidCorpusLine, path, line)
+			}
+			hash = prefix + hash
+		}
+
+		var d digest
+		if len(hash) != 2*digestSize {
+			return fmt.Errorf("%w: %s:%d", config.ErrInvalidCorpusLine, path, line)
+		}
+		if _, err := hex.Decode(d[:], []byte(hash)); err != nil {
+			return fmt.Errorf("%w: %s:%d", config.ErrInvalidCorpusLine, path, line)
+		}
+
+		if last != nil && bytes.Compare(d[:], last[:]) <= 0 {
+			return fmt.Errorf("%w: %s:%d", config.ErrCorpusNotSorted, path, line)
+		}
+		last = &d
+
+		if err := fn(d); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func rangePrefix(name string) string {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if len(base) != prefixSize {
+		return ""
+	}
+	if _, err := hex.DecodeString(base + "0"); err != nil {
+		return ""
+	}
+	return strings.ToUpper(base)
+}
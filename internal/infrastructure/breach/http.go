@@ -0,0 +1,59 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+)
+
+type HTTPChecker struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewHTTPChecker(baseURL string, timeout time.Duration) *HTTPChecker {
+	return &HTTPChecker{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (hc *HTTPChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	full := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := full[:prefixSize], full[prefixSize:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/range/%s", hc.BaseURL, prefix), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := hc.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%w: status %d", config.ErrBreachLookup, resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		return strings.TrimSpace(count) != "0", nil
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,173 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+
+	"go-manage-hex/cmd/config"
+)
+
+// Index layout: magic, record count, a 65536 entry fan-out table holding the
+// cumulative record count per leading two bytes, then the sorted digests.
+const (
+	indexMagic   = "GMHBRIX1"
+	fanoutSize   = 1 << 16
+	headerSize   = len(indexMagic) + 8
+	recordOffset = int64(headerSize + fanoutSize*8)
+)
+
+type IndexChecker struct {
+	file   *os.File
+	count  uint64
+	fanout []uint64
+}
+
+func BuildIndex(corpusPath, indexPath string) (uint64, error) {
+	tmpPath := indexPath + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpPath)
+	defer file.Close()
+
+	if _, err := file.Seek(recordOffset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	writer := bufio.NewWriter(file)
+	fanout := make([]uint64, fanoutSize)
+
+	var count uint64
+	err = readCorpus(corpusPath, func(d digest) error {
+		fanout[bucket(d[:])]++
+		count++
+		_, err := writer.Write(d[:])
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writer.Flush(); err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, recordOffset)
+	copy(header, indexMagic)
+	binary.BigEndian.PutUint64(header[len(indexMagic):], count)
+
+	var cumulative uint64
+	for i, n := range fanout {
+		cumulative += n
+		binary.BigEndian.PutUint64(header[headerSize+i*8:], cumulative)
+	}
+
+	if _, err := file.WriteAt(header, 0); err != nil {
+		return 0, err
+	}
+
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+
+	if err := file.Close(); err != nil {
+		return 0, err
+	}
+
+	return count, os.Rename(tmpPath, indexPath)
+}
+
+func OpenIndex(indexPath string) (*IndexChecker, error) {
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, recordOffset)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		file.Close()
+		return nil, config.ErrInvalidBreachIndex
+	}
+
+	if string(header[:len(indexMagic)]) != indexMagic {
+		file.Close()
+		return nil, config.ErrInvalidBreachIndex
+	}
+
+	count := binary.BigEndian.Uint64(header[len(indexMagic):])
+
+	fanout := make([]uint64, fanoutSize)
+	for i := range fanout {
+		fanout[i] = binary.BigEndian.Uint64(header[headerSize+i*8:])
+	}
+
+	info, err := file.Stat()
+	if err != nil || fanout[fanoutSize-1] != count || info.Size() != recordOffset+int64(count)*digestSize {
+		file.Close()
+		return nil, config.ErrInvalidBreachIndex
+	}
+
+	return &IndexChecker{file: file, count: count, fanout: fanout}, nil
+}
+
+func (ic *IndexChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	return ic.contains(sum[:])
+}
+
+func (ic *IndexChecker) Count() uint64 {
+	return ic.count
+}
+
+func (ic *IndexChecker) Close() error {
+	return ic.file.Close()
+}
+
+func (ic *IndexChecker) contains(target []byte) (bool, error) {
+	b := bucket(target)
+
+	var lo uint64
+	if b > 0 {
+		lo = ic.fanout[b-1]
+	}
+	hi := ic.fanout[b]
+
+	record := make([]byte, digestSize)
+
+	var readErr error
+	i := sort.Search(int(hi-lo), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := ic.file.ReadAt(record, recordOffset+int64(lo+uint64(i))*digestSize); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(record, target) >= 0
+	})
+	if readErr != nil {
+		return false, readErr
+	}
+
+	if uint64(i) == hi-lo {
+		return false, nil
+	}
+
+	if _, err := ic.file.ReadAt(record, recordOffset+int64(lo+uint64(i))*digestSize); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(record, target), nil
+}
+
+func bucket(d []byte) int {
+	return int(d[0])<<8 | int(d[1])
+}
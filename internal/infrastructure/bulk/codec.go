@@ -0,0 +1,257 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/bulk"
+	"go-manage-hex/internal/core/user"
+)
+
+const (
+	columnUsername     = "username"
+	columnName         = "name"
+	columnLastName     = "last_name"
+	columnEmail        = "email"
+	columnPassword     = "password"
+	columnPasswordHash = "password_hash"
+)
+
+var exportColumns = []string{"id", columnUsername, columnName, columnLastName, columnEmail}
+
+// importRow is the NDJSON shape of an import line. Exactly one of Password
+// and PasswordHash is expected.
+type importRow struct {
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email"`
+	Password     string `json:"password,omitempty"`
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+// exportRow has no password field at all so a hash can never leak into an
+// export, whatever the caller passes in.
+type exportRow struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	LastName string `json:"last_name"`
+	Email    string `json:"email"`
+}
+
+func (r importRow) record(line int) entity.Record {
+	record := entity.Record{
+		Line: line,
+		User: user.User{
+			Username: r.Username,
+			Name:     r.Name,
+			LastName: r.LastName,
+			Email:    r.Email,
+			Password: r.Password,
+		},
+	}
+	if r.PasswordHash != "" {
+		record.User.Password = r.PasswordHash
+		record.PreHashed = true
+	}
+	return record
+}
+
+// FormatFromPath guesses the format from a file extension, defaulting to CSV.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return config.BulkFormatNDJSON
+	default:
+		return config.BulkFormatCSV
+	}
+}
+
+// ParseFilter reads the "<field> <operator> <value>" syntax shared by the
+// admin CLI and the export endpoint.
+func ParseFilter(filter string) (*user.UserFilter, error) {
+	if filter == "" {
+		return nil, nil
+	}
+	parts := strings.SplitN(filter, " ", 3)
+	if len(parts) != 3 {
+		return nil, config.ErrInvalidFilter
+	}
+	return &user.UserFilter{Field: parts[0], Operator: parts[1], Value: parts[2]}, nil
+}
+
+func NewReader(format string, r io.Reader) (entity.RecordReader, error) {
+	switch format {
+	case config.BulkFormatCSV:
+		return newCSVReader(r)
+	case config.BulkFormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), config.BulkMaxLineBytes)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", config.ErrUnknownBulkFormat, format)
+	}
+}
+
+func NewWriter(format string, w io.Writer) (entity.RecordWriter, error) {
+	switch format {
+	case config.BulkFormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case config.BulkFormatNDJSON:
+		buffered := bufio.NewWriter(w)
+		return &ndjsonWriter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", config.ErrUnknownBulkFormat, format)
+	}
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty file", config.ErrMissingColumn)
+		}
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{columnUsername, columnName, columnLastName, columnEmail} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: %s", config.ErrMissingColumn, required)
+		}
+	}
+	_, hasPassword := columns[columnPassword]
+	_, hasHash := columns[columnPasswordHash]
+	if !hasPassword && !hasHash {
+		return nil, fmt.Errorf("%w: %s or %s", config.ErrMissingColumn, columnPassword, columnPasswordHash)
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (cr *csvReader) Next() (entity.Record, error) {
+	fields, err := cr.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return entity.Record{}, &entity.ParseError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return entity.Record{}, err
+	}
+
+	field := func(name string) string {
+		i, ok := cr.columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+	line, _ := cr.reader.FieldPos(0)
+
+	row := importRow{
+		Username:     field(columnUsername),
+		Name:         field(columnName),
+		LastName:     field(columnLastName),
+		Email:        field(columnEmail),
+		Password:     field(columnPassword),
+		PasswordHash: field(columnPasswordHash),
+	}
+	return row.record(line), nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (nr *ndjsonReader) Next() (entity.Record, error) {
+	for nr.scanner.Scan() {
+		nr.line++
+		content := bytes.TrimSpace(nr.scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		var row importRow
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			return entity.Record{}, &entity.ParseError{Line: nr.line, Err: err}
+		}
+		return row.record(nr.line), nil
+	}
+
+	if err := nr.scanner.Err(); err != nil {
+		return entity.Record{}, err
+	}
+	return entity.Record{}, io.EOF
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func (cw *csvWriter) Write(u user.User) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	return cw.writer.Write([]string{u.ID, u.Username, u.Name, u.LastName, u.Email})
+}
+
+// Flush also writes the header so an empty export is still a valid file.
+func (cw *csvWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if cw.header {
+		return nil
+	}
+	cw.header = true
+	return cw.writer.Write(exportColumns)
+}
+
+type ndjsonWriter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (nw *ndjsonWriter) Write(u user.User) error {
+	return nw.encoder.Encode(exportRow{
+		ID:       u.ID,
+		Username: u.Username,
+		Name:     u.Name,
+		LastName: u.LastName,
+		Email:    u.Email,
+	})
+}
+
+func (nw *ndjsonWriter) Flush() error {
+	return nw.buffered.Flush()
+}
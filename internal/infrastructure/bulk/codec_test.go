@@ -0,0 +1,162 @@
+package bulk
+
+import (
+	"bytes"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/bulk"
+	"go-manage-hex/internal/core/user"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, records entity.RecordReader) ([]entity.Record, []int) {
+	t.Helper()
+
+	var rows []entity.Record
+	var parseErrors []int
+	for {
+		record, err := records.Next()
+		if errors.Is(err, io.EOF) {
+			return rows, parseErrors
+		}
+		var parseErr *entity.ParseError
+		if errors.As(err, &parseErr) {
+			parseErrors = append(parseErrors, parseErr.Line)
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, record)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	test := []struct {
+		Name                string
+		Format              string
+		Input               string
+		ExpectedErr         error
+		ExpectedLines       []int
+		ExpectedPreHashed   []bool
+		ExpectedParseErrors []int
+	}{
+		{
+			Name:   "CSV",
+			Format: config.BulkFormatCSV,
+			Input: "Username,Name,Last_Name,Email,Password,Password_Hash\n" +
+				"janedoe,Jane,Doe,jane@doe.com,Str0ngPassw0rd,\n" +
+				"johndoe,John,Doe,john@doe.com,,$argon2id$hash\n" +
+				"bad,\"unterminated,Doe,bad@doe.com,x,\n",
+			ExpectedLines:       []int{2, 3},
+			ExpectedPreHashed:   []bool{false, true},
+			ExpectedParseErrors: []int{4},
+		},
+		{
+			Name:        "CSVMissingColumn",
+			Format:      config.BulkFormatCSV,
+			Input:       "username,name,email,password\n",
+			ExpectedErr: config.ErrMissingColumn,
+		},
+		{
+			Name:        "CSVMissingPassword",
+			Format:      config.BulkFormatCSV,
+			Input:       "username,name,last_name,email\n",
+			ExpectedErr: config.ErrMissingColumn,
+		},
+		{
+			Name:   "NDJSON",
+			Format: config.BulkFormatNDJSON,
+			Input: `{"username":"janedoe","name":"Jane","last_name":"Doe","email":"jane@doe.com","password":"Str0ngPassw0rd"}` + "\n" +
+				"\n" +
+				`{"username":"johndoe","email":"john@doe.com","password_hash":"$2a$10$hash"}` + "\n" +
+				`{"username":"eve","role":"admin"}` + "\n" +
+				`{not json` + "\n",
+			ExpectedLines:       []int{1, 3},
+			ExpectedPreHashed:   []bool{false, true},
+			ExpectedParseErrors: []int{4, 5},
+		},
+		{
+			Name:        "UnknownFormat",
+			Format:      "xml",
+			ExpectedErr: config.ErrUnknownBulkFormat,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			records, err := NewReader(tt.Format, strings.NewReader(tt.Input))
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			rows, parseErrors := readAll(t, records)
+
+			var lines []int
+			var preHashed []bool
+			for _, row := range rows {
+				lines = append(lines, row.Line)
+				preHashed = append(preHashed, row.PreHashed)
+				assert.NotEmpty(t, row.User.Password)
+			}
+			assert.Equal(t, tt.ExpectedLines, lines)
+			assert.Equal(t, tt.ExpectedPreHashed, preHashed)
+			assert.Equal(t, tt.ExpectedParseErrors, parseErrors)
+			assert.Equal(t, "janedoe", rows[0].User.Username)
+			assert.Equal(t, "Jane", rows[0].User.Name)
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	u := user.User{ID: "1", Username: "janedoe", Name: "Jane", LastName: "Doe", Email: "jane@doe.com", Password: "$argon2id$hash"}
+
+	test := []struct {
+		Name     string
+		Format   string
+		Expected string
+	}{
+		{
+			Name:     "CSV",
+			Format:   config.BulkFormatCSV,
+			Expected: "id,username,name,last_name,email\n1,janedoe,Jane,Doe,jane@doe.com\n",
+		},
+		{
+			Name:     "NDJSON",
+			Format:   config.BulkFormatNDJSON,
+			Expected: `{"id":"1","username":"janedoe","name":"Jane","last_name":"Doe","email":"jane@doe.com"}` + "\n",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			records, err := NewWriter(tt.Format, &out)
+			require.NoError(t, err)
+
+			require.NoError(t, records.Write(u))
+			require.NoError(t, records.Flush())
+
+			assert.Equal(t, tt.Expected, out.String())
+			assert.NotContains(t, out.String(), "argon2id")
+		})
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	filter, err := ParseFilter("email co doe.com")
+	require.NoError(t, err)
+	assert.Equal(t, &user.UserFilter{Field: "email", Operator: "co", Value: "doe.com"}, filter)
+
+	filter, err = ParseFilter("")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = ParseFilter("email")
+	assert.ErrorIs(t, err, config.ErrInvalidFilter)
+}
@@ -0,0 +1,133 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/bulk"
+	"go-manage-hex/internal/app/job"
+	entity "go-manage-hex/internal/core/bulk"
+	jobEntity "go-manage-hex/internal/core/job"
+)
+
+// ImportPayload is stored with a users.import job. Path must be readable by
+// every replica that runs the queue.
+type ImportPayload struct {
+	Path    string               `json:"path"`
+	Format  string               `json:"format"`
+	Options entity.ImportOptions `json:"options"`
+}
+
+type ExportPayload struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Filter string `json:"filter,omitempty"`
+}
+
+type ExportResult struct {
+	Exported int `json:"exported"`
+}
+
+// ImportJob runs an import from a spooled file. A retried job resumes after
+// the last saved progress; rows a crashed attempt created after its last
+// heartbeat are reported as existing users rather than created twice.
+func ImportJob(service bulk.Usecases) job.Handler {
+	return func(ctx context.Context, j jobEntity.Job, progress func(v any)) (any, error) {
+		var payload ImportPayload
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, err
+		}
+
+		var previous entity.ImportReport
+		if len(j.Progress) > 0 {
+			if err := json.Unmarshal(j.Progress, &previous); err != nil {
+				return nil, err
+			}
+		}
+		options := payload.Options
+		options.ResumeAfter = max(options.ResumeAfter, previous.LastLine)
+
+		report, err := importFile(ctx, service, payload, options, func(current entity.ImportReport) {
+			progress(merge(previous, current))
+		})
+		merged := merge(previous, report)
+		progress(merged)
+
+		if ctx.Err() == nil && (err == nil || j.Attempts >= j.MaxAttempts) {
+			os.Remove(payload.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+}
+
+func importFile(ctx context.Context, service bulk.Usecases, payload ImportPayload, options entity.ImportOptions, progress func(entity.ImportReport)) (entity.ImportReport, error) {
+	report := entity.ImportReport{DryRun: options.DryRun, LastLine: options.ResumeAfter}
+
+	file, err := os.Open(payload.Path)
+	if err != nil {
+		return report, err
+	}
+	defer file.Close()
+
+	records, err := NewReader(payload.Format, file)
+	if err != nil {
+		return report, err
+	}
+
+	return service.Import(ctx, records, options, progress)
+}
+
+// ExportJob writes the export to the path chosen when the job was queued,
+// starting over on a retry.
+func ExportJob(service bulk.Usecases) job.Handler {
+	return func(ctx context.Context, j jobEntity.Job, progress func(v any)) (any, error) {
+		var payload ExportPayload
+		if err := json.Unmarshal(j.Payload, &payload); err != nil {
+			return nil, err
+		}
+
+		filter, err := ParseFilter(payload.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := os.Create(payload.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		records, err := NewWriter(payload.Format, file)
+		if err != nil {
+			return nil, err
+		}
+
+		exported, err := service.Export(ctx, records, entity.ExportOptions{Filter: filter})
+		if err != nil {
+			return nil, err
+		}
+		if err := file.Close(); err != nil {
+			return nil, err
+		}
+
+		return ExportResult{Exported: exported}, nil
+	}
+}
+
+// merge adds the report of a resumed attempt to what earlier attempts did.
+func merge(previous, current entity.ImportReport) entity.ImportReport {
+	merged := current
+	merged.Processed += previous.Processed
+	merged.Created += previous.Created
+	merged.Failed += previous.Failed
+	merged.Errors = append(append([]entity.RowError(nil), previous.Errors...), current.Errors...)
+	if len(merged.Errors) > config.BulkMaxReportedErrors {
+		merged.Errors = merged.Errors[:config.BulkMaxReportedErrors]
+	}
+	return merged
+}
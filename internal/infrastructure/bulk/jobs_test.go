@@ -0,0 +1,112 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/bulk"
+	jobEntity "go-manage-hex/internal/core/job"
+	"go-manage-hex/internal/core/user"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBulk struct {
+	options entity.ImportOptions
+	report  entity.ImportReport
+	err     error
+}
+
+func (f *fakeBulk) Import(ctx context.Context, records entity.RecordReader, options entity.ImportOptions, progress func(entity.ImportReport)) (entity.ImportReport, error) {
+	f.options = options
+	progress(f.report)
+	return f.report, f.err
+}
+
+func (f *fakeBulk) Export(ctx context.Context, records entity.RecordWriter, options entity.ExportOptions) (int, error) {
+	if err := records.Write(user.User{ID: "1", Username: "janedoe"}); err != nil {
+		return 0, err
+	}
+	return 1, records.Flush()
+}
+
+func TestImportJob(t *testing.T) {
+	test := []struct {
+		Name            string
+		Attempts        int
+		Err             error
+		ExpectedRemoved bool
+	}{
+		{Name: "Success", Attempts: 2, ExpectedRemoved: true},
+		{Name: "RetryKeepsFile", Attempts: 2, Err: io.ErrUnexpectedEOF},
+		{Name: "LastAttemptRemovesFile", Attempts: 3, Err: io.ErrUnexpectedEOF, ExpectedRemoved: true},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "import.csv")
+			require.NoError(t, os.WriteFile(path, []byte("username,name,last_name,email,password\n"), 0o600))
+
+			service := &fakeBulk{
+				report: entity.ImportReport{Processed: 10, Created: 9, Failed: 1, LastLine: 51, Errors: []entity.RowError{{Line: 50}}},
+				err:    tt.Err,
+			}
+			payload, err := json.Marshal(ImportPayload{Path: path, Format: config.BulkFormatCSV, Options: entity.ImportOptions{ResumeAfter: 1}})
+			require.NoError(t, err)
+			previous, err := json.Marshal(entity.ImportReport{Processed: 40, Created: 40, LastLine: 41})
+			require.NoError(t, err)
+
+			var reported entity.ImportReport
+			result, err := ImportJob(service)(context.Background(), jobEntity.Job{
+				Payload:     payload,
+				Progress:    previous,
+				Attempts:    tt.Attempts,
+				MaxAttempts: 3,
+			}, func(v any) { reported = v.(entity.ImportReport) })
+
+			assert.ErrorIs(t, err, tt.Err)
+			assert.Equal(t, 41, service.options.ResumeAfter)
+			assert.Equal(t, 50, reported.Processed)
+			assert.Equal(t, 49, reported.Created)
+			assert.Equal(t, 51, reported.LastLine)
+			if tt.Err == nil {
+				assert.Equal(t, reported, result)
+			}
+
+			_, statErr := os.Stat(path)
+			assert.Equal(t, tt.ExpectedRemoved, os.IsNotExist(statErr))
+		})
+	}
+}
+
+func TestExportJob(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.ndjson")
+	payload, err := json.Marshal(ExportPayload{Path: path, Format: config.BulkFormatNDJSON, Filter: "email co doe.com"})
+	require.NoError(t, err)
+
+	result, err := ExportJob(&fakeBulk{})(context.Background(), jobEntity.Job{Payload: payload}, func(any) {})
+	require.NoError(t, err)
+	assert.Equal(t, ExportResult{Exported: 1}, result)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"username":"janedoe"`)
+}
+
+func TestMerge(t *testing.T) {
+	var previous entity.ImportReport
+	for i := 0; i < config.BulkMaxReportedErrors; i++ {
+		previous.Errors = append(previous.Errors, entity.RowError{Line: i})
+	}
+	previous.Failed = len(previous.Errors)
+
+	merged := merge(previous, entity.ImportReport{Failed: 1, Errors: []entity.RowError{{Line: 999}}})
+
+	assert.Equal(t, config.BulkMaxReportedErrors+1, merged.Failed)
+	assert.Len(t, merged.Errors, config.BulkMaxReportedErrors)
+}
@@ -0,0 +1,71 @@
+package bulk
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-manage-hex/cmd/config"
+)
+
+// Sweeper deletes spooled files older than TTL. Imports whose job ran out of
+// attempts and exports nobody downloaded are otherwise never removed.
+type Sweeper struct {
+	Dir string
+	TTL time.Duration
+	Now func() time.Time
+}
+
+func NewSweeper(dir string, ttl time.Duration) *Sweeper {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return &Sweeper{Dir: dir, TTL: ttl, Now: time.Now}
+}
+
+// Run sweeps until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(config.BulkSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if removed := s.Sweep(); removed > 0 {
+			slog.InfoContext(ctx, "removed expired bulk files", "count", removed)
+		}
+	}
+}
+
+// Sweep removes the expired files and returns how many it removed. Only
+// names the bulk handler creates are matched, since Dir may be the shared
+// system temp directory.
+func (s *Sweeper) Sweep() int {
+	cutoff := s.Now().Add(-s.TTL)
+	removed := 0
+
+	for _, pattern := range []string{config.BulkImportFilePattern, config.BulkExportFilePattern} {
+		matches, err := filepath.Glob(filepath.Join(s.Dir, pattern+"*"))
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.ModTime().After(cutoff) {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				slog.Warn("error removing expired bulk file", "path", path, "error", err)
+				continue
+			}
+			removed++
+		}
+	}
+	return removed
+}
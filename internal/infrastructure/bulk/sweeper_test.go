@@ -0,0 +1,43 @@
+package bulk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweeper_RemovesExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	files := map[string]time.Time{
+		"import-1.csv":    old,
+		"export-1.ndjson": old,
+		"import-2.csv":    now,
+		"export-2.csv":    now,
+		"unrelated.csv":   old,
+	}
+	for name, modTime := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0o600))
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	sweeper := NewSweeper(dir, time.Hour)
+	sweeper.Now = func() time.Time { return now }
+
+	assert.Equal(t, 2, sweeper.Sweep())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"import-2.csv", "export-2.csv", "unrelated.csv"}, left)
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	entity "go-manage-hex/internal/core/bulk"
+	codec "go-manage-hex/internal/infrastructure/bulk"
+)
+
+// stdio is the --file value that reads from stdin or writes to stdout.
+const stdio = "-"
+
+func (c *CLI) userImport(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user import")
+	file := flags.String("file", "", "CSV or NDJSON file to import, - for stdin")
+	format := flags.String("format", "", "csv or ndjson, guessed from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate every row without creating users")
+	batchSize := flags.Int("batch-size", 0, "users inserted per transaction")
+	resumeAfter := flags.Int("resume-after", 0, "skip every line up to this one")
+	if err := parse(flags, args, "file"); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = codec.FormatFromPath(*file)
+	}
+
+	var source io.Reader = os.Stdin
+	if *file != stdio {
+		opened, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer opened.Close()
+		source = opened
+	}
+
+	records, err := codec.NewReader(*format, source)
+	if err != nil {
+		return err
+	}
+
+	report, err := c.Bulk.Import(ctx, records, entity.ImportOptions{
+		DryRun:      *dryRun,
+		BatchSize:   *batchSize,
+		ResumeAfter: *resumeAfter,
+	}, func(progress entity.ImportReport) {
+		fmt.Fprintf(c.Err, "processed %d, created %d, failed %d (line %d)\n", progress.Processed, progress.Created, progress.Failed, progress.LastLine)
+	})
+	if err != nil {
+		fmt.Fprintf(c.Err, "import stopped, resume with --resume-after %d\n", report.LastLine)
+		return err
+	}
+
+	return c.print(*asJSON, report, func(w io.Writer) {
+		for _, rowErr := range report.Errors {
+			fmt.Fprintf(w, "line %d\t%s\t%s\n", rowErr.Line, rowErr.Username, rowErr.Error)
+		}
+		verb := "created"
+		if report.DryRun {
+			verb = "would be created"
+		}
+		fmt.Fprintf(w, "%d rows processed, %d %s, %d failed\n", report.Processed, report.Created, verb, report.Failed)
+	})
+}
+
+func (c *CLI) userExport(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user export")
+	file := flags.String("file", "", "destination file, - for stdout")
+	format := flags.String("format", "", "csv or ndjson, guessed from the file extension when empty")
+	filter := flags.String("filter", "", "filter as \"<field> <eq|sw|co> <value>\"")
+	if err := parse(flags, args, "file"); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = codec.FormatFromPath(*file)
+	}
+
+	parsed, err := codec.ParseFilter(*filter)
+	if err != nil {
+		return err
+	}
+
+	// The summary goes to stderr when the export itself is on stdout.
+	destination, summary := c.Out, c.Err
+	if *file != stdio {
+		created, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer created.Close()
+		destination, summary = created, c.Out
+	}
+
+	records, err := codec.NewWriter(*format, destination)
+	if err != nil {
+		return err
+	}
+
+	exported, err := c.Bulk.Export(ctx, records, entity.ExportOptions{Filter: parsed})
+	if err != nil {
+		return err
+	}
+
+	view := exportView{Exported: exported, File: *file}
+	return printTo(summary, *asJSON, view, func(w io.Writer) {
+		fmt.Fprintf(w, "%d users exported\n", view.Exported)
+	})
+}
+
+type exportView struct {
+	Exported int    `json:"exported"`
+	File     string `json:"file"`
+}
@@ -0,0 +1,525 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/bulk"
+	"go-manage-hex/internal/app/grant"
+	"go-manage-hex/internal/app/user"
+	"go-manage-hex/internal/core/migration"
+	"go-manage-hex/internal/core/scope"
+	entity "go-manage-hex/internal/core/user"
+	codec "go-manage-hex/internal/infrastructure/bulk"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+// CLI runs administrative commands against the same use cases as the HTTP
+// API, so policy, hashing and domain events behave identically.
+type CLI struct {
+	Users          user.Usecases
+	Grants         grant.Usecases
+	Bulk           bulk.Usecases
+	Migrations     migration.Migrator
+	Out            io.Writer
+	Err            io.Writer
+	PasswordLength int
+}
+
+func NewCLI(users user.Usecases, grants grant.Usecases, bulkUsers bulk.Usecases, migrations migration.Migrator, out, errOut io.Writer, passwordLength int) *CLI {
+	return &CLI{
+		Users:          users,
+		Grants:         grants,
+		Bulk:           bulkUsers,
+		Migrations:     migrations,
+		Out:            out,
+		Err:            errOut,
+		PasswordLength: passwordLength,
+	}
+}
+
+type userView struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	LastName          string `json:"last_name"`
+	Username          string `json:"username"`
+	Email             string `json:"email"`
+	GeneratedPassword string `json:"generated_password,omitempty"`
+}
+
+type listView struct {
+	Users []userView `json:"users"`
+	Total int        `json:"total"`
+}
+
+type scopesView struct {
+	Username    string   `json:"username"`
+	Granted     []string `json:"granted"`
+	LoginScopes []string `json:"login_scopes"`
+}
+
+type messageView struct {
+	Message           string `json:"message"`
+	Username          string `json:"username,omitempty"`
+	GeneratedPassword string `json:"generated_password,omitempty"`
+}
+
+func (c *CLI) commands() map[string]map[string]command {
+	return map[string]map[string]command{
+		"user": {
+			"create":         {"--username --name --last-name --email [--password]", c.userCreate},
+			"get":            {"--username | --id", c.userGet},
+			"list":           {"[--filter \"<field> <op> <value>\"] [--offset] [--limit]", c.userList},
+			"update":         {"--username [--name] [--last-name] [--email]", c.userUpdate},
+			"delete":         {"--username --yes", c.userDelete},
+			"restore":        {"--username", c.userRestore},
+			"reset-password": {"--username [--password]", c.userResetPassword},
+			"import":         {"--file [--format csv|ndjson] [--dry-run] [--batch-size] [--resume-after]", c.userImport},
+			"export":         {"--file [--format csv|ndjson] [--filter \"<field> <op> <value>\"]", c.userExport},
+		},
+		"role": {
+			"grant":  {"--username --scopes \"<scope> ...\"", c.roleGrant},
+			"revoke": {"--username --scopes \"<scope> ...\"", c.roleRevoke},
+			"list":   {"--username", c.roleList},
+		},
+		"migrate": {
+			"up":     {"", c.migrateUp},
+			"down":   {"--force [--steps]", c.migrateDown},
+			"status": {"", c.migrateStatus},
+		},
+	}
+}
+
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	commands := c.commands()
+
+	if len(args) < 2 {
+		c.usage(commands)
+		return config.ErrUnknownCommand
+	}
+
+	cmd, ok := commands[args[0]][args[1]]
+	if !ok {
+		c.usage(commands)
+		return fmt.Errorf("%w: %s %s", config.ErrUnknownCommand, args[0], args[1])
+	}
+
+	return cmd.run(ctx, args[2:])
+}
+
+func (c *CLI) usage(commands map[string]map[string]command) {
+	fmt.Fprintln(c.Err, "usage: admin [config flags] <group> <command> [flags] [--json]")
+	for _, group := range sortedKeys(commands) {
+		for _, name := range sortedKeys(commands[group]) {
+			fmt.Fprintf(c.Err, "  %s %s %s\n", group, name, commands[group][name].usage)
+		}
+	}
+}
+
+func (c *CLI) userCreate(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user create")
+	username := flags.String("username", "", "username")
+	name := flags.String("name", "", "first name")
+	lastName := flags.String("last-name", "", "last name")
+	email := flags.String("email", "", "email")
+	password := flags.String("password", "", "password, generated when empty")
+	if err := parse(flags, args, "username", "name", "last-name", "email"); err != nil {
+		return err
+	}
+
+	generated, err := c.passwordOrGenerate(password)
+	if err != nil {
+		return err
+	}
+
+	created, err := c.Users.CreateUser(ctx, entity.User{
+		Name:     *name,
+		LastName: *lastName,
+		Username: *username,
+		Email:    *email,
+		Password: *password,
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+
+	view := toView(created)
+	view.GeneratedPassword = generated
+
+	return c.print(*asJSON, view, func(w io.Writer) {
+		printUsers(w, []userView{view})
+		if generated != "" {
+			fmt.Fprintf(w, "\ngenerated password: %s\n", generated)
+		}
+	})
+}
+
+func (c *CLI) userGet(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user get")
+	username := flags.String("username", "", "username")
+	id := flags.String("id", "", "user id")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	var found entity.User
+	var err error
+	switch {
+	case *id != "":
+		found, err = c.Users.SearchUserByID(ctx, *id)
+	case *username != "":
+		found, err = c.Users.SearchUser(ctx, *username)
+	default:
+		return fmt.Errorf("%w: --username or --id", config.ErrMissingFlag)
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+
+	view := toView(found)
+	return c.print(*asJSON, view, func(w io.Writer) {
+		printUsers(w, []userView{view})
+	})
+}
+
+func (c *CLI) userList(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user list")
+	filter := flags.String("filter", "", "filter as \"<field> <eq|sw|co> <value>\"")
+	offset := flags.Int("offset", 0, "users to skip")
+	limit := flags.Int("limit", config.DefaultAdminListLimit, "maximum users to return")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	parsed, err := codec.ParseFilter(*filter)
+	if err != nil {
+		return err
+	}
+	query := entity.UserQuery{Filter: parsed, Offset: *offset, Limit: *limit}
+
+	users, total, err := c.Users.ListUsers(ctx, query)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	view := listView{Users: make([]userView, 0, len(users)), Total: total}
+	for _, u := range users {
+		view.Users = append(view.Users, toView(u))
+	}
+
+	return c.print(*asJSON, view, func(w io.Writer) {
+		printUsers(w, view.Users)
+		fmt.Fprintf(w, "\n%d of %d users\n", len(view.Users), view.Total)
+	})
+}
+
+func (c *CLI) userUpdate(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user update")
+	username := flags.String("username", "", "username")
+	name := flags.String("name", "", "new first name")
+	lastName := flags.String("last-name", "", "new last name")
+	email := flags.String("email", "", "new email")
+	if err := parse(flags, args, "username"); err != nil {
+		return err
+	}
+
+	existing, err := c.Users.SearchUser(ctx, *username)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	changes := existing
+	if *name != "" {
+		changes.Name = *name
+	}
+	if *lastName != "" {
+		changes.LastName = *lastName
+	}
+	if *email != "" {
+		changes.Email = *email
+	}
+
+	updated, err := c.Users.UpdateUser(ctx, *username, changes)
+	if err != nil {
+		return c.fail(err)
+	}
+	updated.ID, updated.Username = existing.ID, existing.Username
+
+	view := toView(updated)
+	return c.print(*asJSON, view, func(w io.Writer) {
+		printUsers(w, []userView{view})
+	})
+}
+
+func (c *CLI) userDelete(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user delete")
+	username := flags.String("username", "", "username")
+	yes := flags.Bool("yes", false, "confirm the deletion")
+	if err := parse(flags, args, "username"); err != nil {
+		return err
+	}
+	if !*yes {
+		return config.ErrNotConfirmed
+	}
+
+	if err := c.Users.DeleteUser(ctx, *username); err != nil {
+		return c.fail(err)
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.UserDeletedMsg, Username: *username})
+}
+
+func (c *CLI) userRestore(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user restore")
+	username := flags.String("username", "", "username")
+	if err := parse(flags, args, "username"); err != nil {
+		return err
+	}
+
+	if err := c.Users.RestoreUser(ctx, *username); err != nil {
+		return c.fail(err)
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.UserRestoredMsg, Username: *username})
+}
+
+func (c *CLI) userResetPassword(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("user reset-password")
+	username := flags.String("username", "", "username")
+	password := flags.String("password", "", "new password, generated when empty")
+	if err := parse(flags, args, "username"); err != nil {
+		return err
+	}
+
+	generated, err := c.passwordOrGenerate(password)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Users.ChangeUserPwd(ctx, *password, *username); err != nil {
+		return c.fail(err)
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.UserPwdChangeMsg, Username: *username, GeneratedPassword: generated})
+}
+
+func (c *CLI) roleGrant(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("role grant")
+	username := flags.String("username", "", "username")
+	scopes := flags.String("scopes", "", "scopes to grant, separated by spaces or commas")
+	if err := parse(flags, args, "username", "scopes"); err != nil {
+		return err
+	}
+
+	if err := c.Grants.Grant(ctx, *username, parseScopes(*scopes)); err != nil {
+		return err
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.ScopesGrantedMsg, Username: *username})
+}
+
+func (c *CLI) roleRevoke(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("role revoke")
+	username := flags.String("username", "", "username")
+	scopes := flags.String("scopes", "", "scopes to revoke, separated by spaces or commas")
+	if err := parse(flags, args, "username", "scopes"); err != nil {
+		return err
+	}
+
+	if err := c.Grants.Revoke(ctx, *username, parseScopes(*scopes)); err != nil {
+		return err
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.ScopesRevokedMsg, Username: *username})
+}
+
+func (c *CLI) roleList(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("role list")
+	username := flags.String("username", "", "username")
+	if err := parse(flags, args, "username"); err != nil {
+		return err
+	}
+
+	granted, err := c.Grants.Granted(ctx, *username)
+	if err != nil {
+		return err
+	}
+	loginScopes, err := c.Grants.LoginScopes(ctx, *username)
+	if err != nil {
+		return err
+	}
+
+	view := scopesView{Username: *username, Granted: granted, LoginScopes: loginScopes}
+	return c.print(*asJSON, view, func(w io.Writer) {
+		fmt.Fprintf(w, "granted: %s\n", scope.String(view.Granted))
+		fmt.Fprintf(w, "login scopes: %s\n", scope.String(view.LoginScopes))
+	})
+}
+
+func (c *CLI) migrateUp(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("migrate up")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	if err := c.Migrations.Up(ctx); err != nil {
+		return err
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.MigrationUpMsg})
+}
+
+func (c *CLI) migrateDown(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("migrate down")
+	force := flags.Bool("force", false, "confirm rolling back, which can drop tables and columns")
+	steps := flags.Int("steps", config.DefaultMigrationDownSteps, "migrations to roll back, newest first")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if !*force {
+		return config.ErrNotForced
+	}
+
+	if err := c.Migrations.Down(ctx, *steps); err != nil {
+		return err
+	}
+
+	return c.printMessage(*asJSON, messageView{Message: config.MigrationDownMsg})
+}
+
+func (c *CLI) migrateStatus(ctx context.Context, args []string) error {
+	flags, asJSON := c.flagSet("migrate status")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+
+	statuses, err := c.Migrations.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.print(*asJSON, statuses, func(w io.Writer) {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+		}
+		tw.Flush()
+	})
+}
+
+func (c *CLI) flagSet(name string) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.Err)
+	asJSON := flags.Bool("json", false, "print the result as JSON")
+	return flags, asJSON
+}
+
+func parse(flags *flag.FlagSet, args []string, required ...string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	for _, name := range required {
+		if flags.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("%w: --%s", config.ErrMissingFlag, name)
+		}
+	}
+	return nil
+}
+
+func (c *CLI) passwordOrGenerate(password *string) (string, error) {
+	if *password != "" {
+		return "", nil
+	}
+
+	generated, err := generatePassword(c.PasswordLength)
+	if err != nil {
+		return "", err
+	}
+	*password = generated
+	return generated, nil
+}
+
+// fail prints the policy violations, which the error message only names.
+func (c *CLI) fail(err error) error {
+	var policyErr *user.PolicyError
+	if errors.As(err, &policyErr) {
+		for _, violation := range policyErr.Violations {
+			fmt.Fprintf(c.Err, "  - %s\n", violation.Message)
+		}
+	}
+	return err
+}
+
+func (c *CLI) print(asJSON bool, value any, text func(w io.Writer)) error {
+	return printTo(c.Out, asJSON, value, text)
+}
+
+func printTo(w io.Writer, asJSON bool, value any, text func(w io.Writer)) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	text(w)
+	return nil
+}
+
+func (c *CLI) printMessage(asJSON bool, view messageView) error {
+	return c.print(asJSON, view, func(w io.Writer) {
+		fmt.Fprintln(w, view.Message)
+		if view.GeneratedPassword != "" {
+			fmt.Fprintf(w, "generated password: %s\n", view.GeneratedPassword)
+		}
+	})
+}
+
+func printUsers(w io.Writer, users []userView) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tUSERNAME\tNAME\tLAST NAME\tEMAIL")
+	for _, u := range users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", u.ID, u.Username, u.Name, u.LastName, u.Email)
+	}
+	tw.Flush()
+}
+
+func toView(u entity.User) userView {
+	return userView{
+		ID:       u.ID,
+		Name:     u.Name,
+		LastName: u.LastName,
+		Username: u.Username,
+		Email:    u.Email,
+	}
+}
+
+func parseScopes(raw string) []string {
+	return scope.Parse(strings.ReplaceAll(raw, ",", " "))
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,402 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/bulk"
+	"go-manage-hex/internal/app/user"
+	"go-manage-hex/internal/core/migration"
+	"go-manage-hex/internal/core/scope"
+	entity "go-manage-hex/internal/core/user"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsecases struct {
+	users     map[string]entity.User
+	deleted   map[string]entity.User
+	policyErr error
+}
+
+func (f *fakeUsecases) SearchUser(ctx context.Context, username string) (entity.User, error) {
+	u, ok := f.users[username]
+	if !ok {
+		return entity.User{}, config.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (f *fakeUsecases) SearchUserByID(ctx context.Context, id string) (entity.User, error) {
+	for _, u := range f.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (f *fakeUsecases) SearchUserByIDWithDeleted(ctx context.Context, id string) (entity.User, error) {
+	return f.SearchUserByID(ctx, id)
+}
+
+func (f *fakeUsecases) ListUsers(ctx context.Context, query entity.UserQuery) ([]entity.User, int, error) {
+	var users []entity.User
+	for _, name := range sortedKeys(f.users) {
+		users = append(users, f.users[name])
+	}
+	return users, len(users), nil
+}
+
+func (f *fakeUsecases) CreateUser(ctx context.Context, u entity.User) (entity.User, error) {
+	if f.policyErr != nil {
+		return entity.User{}, f.policyErr
+	}
+	u.ID = "id-" + u.Username
+	f.users[u.Username] = u
+	return u, nil
+}
+
+func (f *fakeUsecases) PrepareUser(ctx context.Context, u entity.User, preHashed bool) (entity.User, error) {
+	if _, ok := f.users[u.Username]; ok {
+		return entity.User{}, config.ErrUserAlreadyExists
+	}
+	u.ID = "id-" + u.Username
+	return u, nil
+}
+
+func (f *fakeUsecases) CreateUsers(ctx context.Context, users []entity.User) error {
+	for _, u := range users {
+		f.users[u.Username] = u
+	}
+	return nil
+}
+
+func (f *fakeUsecases) DeleteUser(ctx context.Context, username string) error {
+	f.deleted[username] = f.users[username]
+	delete(f.users, username)
+	return nil
+}
+
+func (f *fakeUsecases) RestoreUser(ctx context.Context, username string) error {
+	u, ok := f.deleted[username]
+	if !ok {
+		return config.ErrUserNotFound
+	}
+	delete(f.deleted, username)
+	f.users[username] = u
+	return nil
+}
+
+func (f *fakeUsecases) UpdateUser(ctx context.Context, username string, u entity.User) (entity.User, error) {
+	f.users[username] = u
+	return u, nil
+}
+
+func (f *fakeUsecases) UpdateUserAndPwd(ctx context.Context, username string, u entity.User, newPwd string) (entity.User, error) {
+	if newPwd != "" {
+		u.Password = newPwd
+	}
+	f.users[username] = u
+	return u, nil
+}
+
+func (f *fakeUsecases) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
+	u := f.users[username]
+	u.Password = newPwd
+	f.users[username] = u
+	return nil
+}
+
+func (f *fakeUsecases) Login(ctx context.Context, username, password string) error {
+	return nil
+}
+
+type fakeMigrator struct {
+	calls []string
+}
+
+func (f *fakeMigrator) Up(ctx context.Context) error {
+	f.calls = append(f.calls, "up")
+	return nil
+}
+
+func (f *fakeMigrator) Down(ctx context.Context, steps int) error {
+	f.calls = append(f.calls, fmt.Sprintf("down %d", steps))
+	return nil
+}
+
+// fakeGrants stands in for the grant service with users:read as the only
+// login default.
+type fakeGrants struct {
+	granted map[string][]string
+}
+
+func (f *fakeGrants) Grant(ctx context.Context, username string, scopes []string) error {
+	if err := scope.Validate(scopes); err != nil {
+		return err
+	}
+	for _, s := range scopes {
+		if !slices.Contains(f.granted[username], s) {
+			f.granted[username] = append(f.granted[username], s)
+		}
+	}
+	return nil
+}
+
+func (f *fakeGrants) Revoke(ctx context.Context, username string, scopes []string) error {
+	f.granted[username] = slices.DeleteFunc(f.granted[username], func(s string) bool {
+		return slices.Contains(scopes, s)
+	})
+	return nil
+}
+
+func (f *fakeGrants) Granted(ctx context.Context, username string) ([]string, error) {
+	return f.granted[username], nil
+}
+
+func (f *fakeGrants) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	return append([]string{config.ScopeUsersRead}, f.granted[username]...), nil
+}
+
+var appliedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (f *fakeMigrator) Status(ctx context.Context) ([]migration.VersionStatus, error) {
+	return []migration.VersionStatus{{Version: 1, Name: "baseline", AppliedAt: &appliedAt}, {Version: 2, Name: "widen_user_password"}}, nil
+}
+
+func newTestCLI() (*CLI, *fakeUsecases, *fakeMigrator, *bytes.Buffer, *bytes.Buffer) {
+	users := &fakeUsecases{users: map[string]entity.User{
+		"johndoe": {ID: "id-johndoe", Name: "John", LastName: "Doe", Username: "johndoe", Email: "john@doe.com", Password: "$argon2id$hash"},
+	}, deleted: map[string]entity.User{}}
+	migrator := &fakeMigrator{}
+	var out, errOut bytes.Buffer
+	grants := &fakeGrants{granted: map[string][]string{}}
+	return NewCLI(users, grants, bulk.NewBulkService(users, 0), migrator, &out, &errOut, config.GeneratedPasswordLength), users, migrator, &out, &errOut
+}
+
+func TestUserCommands(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Args          []string
+		ExpectedOut   []string
+		ExpectedError error
+	}{
+		{
+			Name:        "Get",
+			Args:        []string{"user", "get", "--username", "johndoe"},
+			ExpectedOut: []string{"USERNAME", "johndoe", "john@doe.com"},
+		},
+		{
+			Name:        "GetByID",
+			Args:        []string{"user", "get", "--id", "id-johndoe"},
+			ExpectedOut: []string{"johndoe"},
+		},
+		{
+			Name:          "GetMissingFlag",
+			Args:          []string{"user", "get"},
+			ExpectedError: config.ErrMissingFlag,
+		},
+		{
+			Name:        "List",
+			Args:        []string{"user", "list", "--filter", "email co doe.com"},
+			ExpectedOut: []string{"johndoe", "1 of 1 users"},
+		},
+		{
+			Name:          "ListInvalidFilter",
+			Args:          []string{"user", "list", "--filter", "email"},
+			ExpectedError: config.ErrInvalidFilter,
+		},
+		{
+			Name:          "CreateMissingFlag",
+			Args:          []string{"user", "create", "--username", "janedoe"},
+			ExpectedError: config.ErrMissingFlag,
+		},
+		{
+			Name:          "DeleteNotConfirmed",
+			Args:          []string{"user", "delete", "--username", "johndoe"},
+			ExpectedError: config.ErrNotConfirmed,
+		},
+		{
+			Name:        "Delete",
+			Args:        []string{"user", "delete", "--username", "johndoe", "--yes"},
+			ExpectedOut: []string{config.UserDeletedMsg},
+		},
+		{
+			Name:        "ResetPassword",
+			Args:        []string{"user", "reset-password", "--username", "johndoe", "--password", "N3w-Passw0rd!"},
+			ExpectedOut: []string{config.UserPwdChangeMsg},
+		},
+		{
+			Name:          "RestoreNotDeleted",
+			Args:          []string{"user", "restore", "--username", "johndoe"},
+			ExpectedError: config.ErrUserNotFound,
+		},
+		{
+			Name:          "UnknownCommand",
+			Args:          []string{"user", "suspend"},
+			ExpectedError: config.ErrUnknownCommand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			cli, _, _, out, _ := newTestCLI()
+
+			err := cli.Run(context.Background(), tt.Args)
+
+			if tt.ExpectedError != nil {
+				assert.ErrorIs(t, err, tt.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			for _, expected := range tt.ExpectedOut {
+				assert.Contains(t, out.String(), expected)
+			}
+			assert.NotContains(t, out.String(), "$argon2id$hash")
+		})
+	}
+}
+
+func TestUserCreateJSON(t *testing.T) {
+	cli, users, _, out, _ := newTestCLI()
+
+	err := cli.Run(context.Background(), []string{"user", "create", "--username", "janedoe", "--name", "Jane", "--last-name", "Doe", "--email", "jane@doe.com", "--json"})
+	require.NoError(t, err)
+
+	var created userView
+	require.NoError(t, json.Unmarshal(out.Bytes(), &created))
+	assert.Equal(t, "id-janedoe", created.ID)
+	assert.Len(t, created.GeneratedPassword, config.GeneratedPasswordLength)
+	assert.Equal(t, created.GeneratedPassword, users.users["janedoe"].Password)
+}
+
+func TestUserCreatePolicyViolations(t *testing.T) {
+	cli, users, _, _, errOut := newTestCLI()
+	users.policyErr = &user.PolicyError{Violations: []user.PolicyViolation{{Rule: user.RuleMinLength, Message: "password must be at least 8 characters long"}}}
+
+	err := cli.Run(context.Background(), []string{"user", "create", "--username", "janedoe", "--name", "Jane", "--last-name", "Doe", "--email", "jane@doe.com", "--password", "short"})
+
+	assert.ErrorIs(t, err, config.ErrInvalidPassword)
+	assert.Contains(t, errOut.String(), "password must be at least 8 characters long")
+}
+
+func TestUserUpdateKeepsUnsetFields(t *testing.T) {
+	cli, users, _, _, _ := newTestCLI()
+
+	require.NoError(t, cli.Run(context.Background(), []string{"user", "update", "--username", "johndoe", "--email", "new@doe.com"}))
+
+	assert.Equal(t, "John", users.users["johndoe"].Name)
+	assert.Equal(t, "new@doe.com", users.users["johndoe"].Email)
+}
+
+func TestUserDeleteRestore(t *testing.T) {
+	cli, users, _, out, _ := newTestCLI()
+	ctx := context.Background()
+
+	require.NoError(t, cli.Run(ctx, []string{"user", "delete", "--username", "johndoe", "--yes"}))
+	assert.NotContains(t, users.users, "johndoe")
+
+	out.Reset()
+	require.NoError(t, cli.Run(ctx, []string{"user", "restore", "--username", "johndoe"}))
+	assert.Contains(t, out.String(), config.UserRestoredMsg)
+	assert.Equal(t, "john@doe.com", users.users["johndoe"].Email)
+}
+
+func TestRoleCommands(t *testing.T) {
+	cli, _, _, out, _ := newTestCLI()
+	ctx := context.Background()
+
+	require.NoError(t, cli.Run(ctx, []string{"role", "grant", "--username", "johndoe", "--scopes", "users:write,users:delete"}))
+	assert.Contains(t, out.String(), config.ScopesGrantedMsg)
+
+	assert.ErrorIs(t, cli.Run(ctx, []string{"role", "grant", "--username", "johndoe", "--scopes", "admin"}), config.ErrInvalidScope)
+	assert.ErrorIs(t, cli.Run(ctx, []string{"role", "grant", "--username", "johndoe"}), config.ErrMissingFlag)
+
+	require.NoError(t, cli.Run(ctx, []string{"role", "revoke", "--username", "johndoe", "--scopes", "users:delete"}))
+
+	out.Reset()
+	require.NoError(t, cli.Run(ctx, []string{"role", "list", "--username", "johndoe", "--json"}))
+
+	var view scopesView
+	require.NoError(t, json.Unmarshal(out.Bytes(), &view))
+	assert.Equal(t, []string{config.ScopeUsersWrite}, view.Granted)
+	assert.Equal(t, []string{config.ScopeUsersRead, config.ScopeUsersWrite}, view.LoginScopes)
+}
+
+func TestUserImportExport(t *testing.T) {
+	cli, users, _, out, _ := newTestCLI()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	source := filepath.Join(dir, "users.csv")
+	require.NoError(t, os.WriteFile(source, []byte("username,name,last_name,email,password\n"+
+		"janedoe,Jane,Doe,jane@doe.com,Passw0rd!\n"+
+		"johndoe,John,Doe,john@doe.com,Passw0rd!\n"), 0o600))
+
+	require.NoError(t, cli.Run(ctx, []string{"user", "import", "--file", source, "--dry-run"}))
+	assert.Contains(t, out.String(), "1 would be created, 1 failed")
+	assert.NotContains(t, users.users, "janedoe")
+
+	out.Reset()
+	require.NoError(t, cli.Run(ctx, []string{"user", "import", "--file", source}))
+	assert.Contains(t, out.String(), "line 3\tjohndoe")
+	assert.Contains(t, users.users, "janedoe")
+
+	out.Reset()
+	target := filepath.Join(dir, "users.ndjson")
+	require.NoError(t, cli.Run(ctx, []string{"user", "export", "--file", target}))
+	assert.Contains(t, out.String(), "2 users exported")
+
+	exported, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(exported), `"username":"janedoe"`)
+	assert.NotContains(t, string(exported), "password")
+	assert.NotContains(t, string(exported), "$argon2id$hash")
+}
+
+func TestMigrateCommands(t *testing.T) {
+	cli, _, migrator, out, _ := newTestCLI()
+	ctx := context.Background()
+
+	require.NoError(t, cli.Run(ctx, []string{"migrate", "up"}))
+	assert.ErrorIs(t, cli.Run(ctx, []string{"migrate", "down"}), config.ErrNotForced)
+	require.NoError(t, cli.Run(ctx, []string{"migrate", "down", "--force"}))
+	require.NoError(t, cli.Run(ctx, []string{"migrate", "down", "--force", "--steps", "3"}))
+	assert.Equal(t, []string{"up", "down 1", "down 3"}, migrator.calls)
+
+	out.Reset()
+	require.NoError(t, cli.Run(ctx, []string{"migrate", "status"}))
+	assert.Contains(t, out.String(), "2025-01-01T00:00:00Z")
+	assert.Contains(t, out.String(), "pending")
+
+	out.Reset()
+	require.NoError(t, cli.Run(ctx, []string{"migrate", "status", "--json"}))
+
+	var statuses []migration.VersionStatus
+	require.NoError(t, json.Unmarshal(out.Bytes(), &statuses))
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, appliedAt, *statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+}
+
+func TestGeneratePassword(t *testing.T) {
+	password, err := generatePassword(config.GeneratedPasswordLength)
+	require.NoError(t, err)
+	assert.Len(t, password, config.GeneratedPasswordLength)
+
+	assert.True(t, strings.ContainsFunc(password, unicode.IsUpper))
+	assert.True(t, strings.ContainsFunc(password, unicode.IsLower))
+	assert.True(t, strings.ContainsFunc(password, unicode.IsDigit))
+	assert.True(t, strings.ContainsAny(password, passwordClasses[3]))
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+var passwordClasses = []string{
+	"abcdefghijkmnopqrstuvwxyz",
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"23456789",
+	"!#%+-=?@^_",
+}
+
+// generatePassword returns a random password with at least one character of
+// every class, so it satisfies the strictest policy configuration.
+func generatePassword(length int) (string, error) {
+	length = max(length, len(passwordClasses))
+
+	var all string
+	for _, class := range passwordClasses {
+		all += class
+	}
+
+	password := make([]byte, length)
+	for i := range password {
+		alphabet := all
+		if i < len(passwordClasses) {
+			alphabet = passwordClasses[i]
+		}
+
+		char, err := randomChar(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password[i] = char
+	}
+
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomChar(alphabet string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, err
+	}
+	return alphabet[n.Int64()], nil
+}
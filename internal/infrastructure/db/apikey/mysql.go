@@ -0,0 +1,151 @@
+package apikey
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/apikey"
+	"strings"
+	"time"
+)
+
+type APIKeyMysql struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyMysql(db *sql.DB) entity.Repository {
+	return &APIKeyMysql{DB: db}
+}
+
+func (am *APIKeyMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateAPIKeyTableQuery, tableName)
+
+	_, err := am.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (am *APIKeyMysql) NewAPIKey(key entity.APIKey) error {
+	query := fmt.Sprintf(config.NewAPIKeyQuery, config.GetAPIKeyTable())
+
+	_, err := am.DB.Exec(query, key.ID, key.Username, key.Name, key.Prefix, key.Hash, strings.Join(key.Scopes, ","), nullTime(key.ExpiresAt), key.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (am *APIKeyMysql) GetByPrefix(prefix string) (entity.APIKey, error) {
+	query := fmt.Sprintf(config.GetAPIKeyByPrefixQuery, config.GetAPIKeyTable())
+
+	key, err := scanAPIKey(am.DB.QueryRow(query, prefix))
+	if err != nil {
+		return entity.APIKey{}, config.ErrAPIKeyNotFound
+	}
+
+	return key, nil
+}
+
+func (am *APIKeyMysql) ListByUsername(username string) ([]entity.APIKey, error) {
+	query := fmt.Sprintf(config.ListAPIKeysQuery, config.GetAPIKeyTable())
+
+	rows, err := am.DB.Query(query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (am *APIKeyMysql) Revoke(username, id string, revokedAt time.Time) error {
+	query := fmt.Sprintf(config.RevokeAPIKeyQuery, config.GetAPIKeyTable())
+
+	result, err := am.DB.Exec(query, revokedAt, id, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (am *APIKeyMysql) TouchLastUsed(id string, usedAt time.Time) error {
+	query := fmt.Sprintf(config.TouchAPIKeyQuery, config.GetAPIKeyTable())
+
+	_, err := am.DB.Exec(query, usedAt, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row scanner) (entity.APIKey, error) {
+	var (
+		key                              entity.APIKey
+		scopes                           string
+		expiresAt, lastUsedAt, revokedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&key.ID,
+		&key.Username,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&key.CreatedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	key.Scopes = []string{}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	key.ExpiresAt = timePtr(expiresAt)
+	key.LastUsedAt = timePtr(lastUsedAt)
+	key.RevokedAt = timePtr(revokedAt)
+
+	return key, nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,164 @@
+package apikey
+
+import (
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/apikey"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyColumns = []string{"id", "username", "name", "prefix", "hash", "scopes", "expires_at", "last_used_at", "created_at", "revoked_at"}
+
+func TestNewAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyMysql(db)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.NewAPIKeyQuery, config.GetAPIKeyTable()))).
+		WithArgs("1", "johndoe", "ci", "abcdef012345", "hash", "users:read,users:write", nil, created).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.NewAPIKey(entity.APIKey{
+		ID:        "1",
+		Username:  "johndoe",
+		Name:      "ci",
+		Prefix:    "abcdef012345",
+		Hash:      "hash",
+		Scopes:    []string{"users:read", "users:write"},
+		CreatedAt: created,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetByPrefix(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetAPIKeyByPrefixQuery, config.GetAPIKeyTable()))
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := created.Add(24 * time.Hour)
+
+	test := []struct {
+		Name        string
+		MockFunc    func()
+		ExpectedErr error
+	}{
+		{
+			Name: "GetByPrefix_Success",
+			MockFunc: func() {
+				rows := sqlmock.NewRows(apiKeyColumns).
+					AddRow("1", "johndoe", "ci", "abcdef012345", "hash", "users:read", expires, nil, created, nil)
+				mock.ExpectQuery(query).WithArgs("abcdef012345").WillReturnRows(rows)
+			},
+		},
+		{
+			Name: "GetByPrefix_NotFound",
+			MockFunc: func() {
+				mock.ExpectQuery(query).WithArgs("abcdef012345").WillReturnError(fmt.Errorf("sql: no rows in result set"))
+			},
+			ExpectedErr: config.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			key, err := repo.GetByPrefix("abcdef012345")
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"users:read"}, key.Scopes)
+			assert.Equal(t, expires, *key.ExpiresAt)
+			assert.Nil(t, key.LastUsedAt)
+			assert.Nil(t, key.RevokedAt)
+		})
+	}
+}
+
+func TestListByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyMysql(db)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows(apiKeyColumns).
+		AddRow("1", "johndoe", "ci", "abcdef012345", "hash", "", nil, created, created, nil).
+		AddRow("2", "johndoe", "old", "012345abcdef", "hash", "users:read", nil, nil, created, created)
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.ListAPIKeysQuery, config.GetAPIKeyTable()))).
+		WithArgs("johndoe").WillReturnRows(rows)
+
+	keys, err := repo.ListByUsername("johndoe")
+
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.Empty(t, keys[0].Scopes)
+	assert.NotNil(t, keys[0].LastUsedAt)
+	assert.NotNil(t, keys[1].RevokedAt)
+}
+
+func TestRevoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.RevokeAPIKeyQuery, config.GetAPIKeyTable()))
+	revoked := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	test := []struct {
+		Name        string
+		Affected    int64
+		ExpectedErr error
+	}{
+		{
+			Name:     "Revoke_Success",
+			Affected: 1,
+		},
+		{
+			Name:        "Revoke_NotFound",
+			Affected:    0,
+			ExpectedErr: config.ErrAPIKeyNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			mock.ExpectExec(query).
+				WithArgs(revoked, "1", "johndoe").
+				WillReturnResult(sqlmock.NewResult(0, tt.Affected))
+
+			err := repo.Revoke("johndoe", "1", revoked)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,40 +1,59 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"go-manage-hex/cmd/config"
-	"log"
+	"log/slog"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
-func DatabaseConn() (*sql.DB, error) {
-	tempDB, err := sql.Open("mysql", config.GetDSNRoot())
+// DatabaseConn opens the pool with a connector that asks password for the
+// current credential on every new connection, so a rotated password is used
+// without reopening the pool.
+func DatabaseConn(cfg config.MySQLConfig, password func() string) (*sql.DB, error) {
+	tempDB, err := sql.Open("mysql", cfg.DSNRoot())
 	if err != nil {
 		return nil, err
 	}
+	defer tempDB.Close()
 
 	if pingErr := tempDB.Ping(); pingErr != nil {
-		log.Fatal("error pinging to database")
+		return nil, fmt.Errorf("error pinging to database: %w", pingErr)
 	}
 
-	if err := checkExists(tempDB, config.GetMysqlDBName()); err != nil {
-		log.Print("DATABASE NOT FOUND. CREATING....")
-		if createErr := createDB(tempDB, config.GetMysqlDBName()); createErr != nil {
+	if err := checkExists(tempDB, cfg.Database); err != nil {
+		slog.Info("database not found, creating it", "database", cfg.Database)
+		if createErr := createDB(tempDB, cfg.Database); createErr != nil {
 			return nil, fmt.Errorf("failed to create database: %w", createErr)
 		}
-		log.Print("DATABASE CREATED SUCCESSFULLY")
+		slog.Info("database created", "database", cfg.Database)
 	} else {
-		log.Print("DATABASE FOUND. USING")
+		slog.Info("using existing database", "database", cfg.Database)
 	}
 
-	db, err := sql.Open("mysql", config.GetDSN_DB())
+	mysqlCfg, err := mysql.ParseDSN(cfg.DSN())
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	if err := mysqlCfg.Apply(mysql.BeforeConnect(func(ctx context.Context, c *mysql.Config) error {
+		c.Passwd = password()
+		return nil
+	})); err != nil {
+		return nil, err
+	}
+
+	connector, err := mysql.NewConnector(mysqlCfg)
+	if err != nil {
+		return nil, err
 	}
-	if useErr := useDB(db, config.GetMysqlDBName()); useErr != nil {
-		log.Fatal(err)
+
+	db := sql.OpenDB(connector)
+	if useErr := useDB(db, cfg.Database); useErr != nil {
+		db.Close()
+		return nil, useErr
 	}
 
 	return db, nil
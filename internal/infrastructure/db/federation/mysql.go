@@ -0,0 +1,55 @@
+package federation
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+)
+
+type IdentityMysql struct {
+	DB *sql.DB
+}
+
+func NewIdentityMysql(db *sql.DB) entity.IdentityRepository {
+	return &IdentityMysql{DB: db}
+}
+
+func (im *IdentityMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateIdentityTableQuery, tableName)
+
+	_, err := im.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (im *IdentityMysql) GetIdentity(provider, subject string) (entity.Identity, error) {
+	query := fmt.Sprintf(config.GetIdentityQuery, config.GetIdentityTable())
+
+	var identity entity.Identity
+
+	err := im.DB.QueryRow(query, provider, subject).Scan(
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Username,
+		&identity.Email,
+		&identity.LinkedAt,
+	)
+	if err != nil {
+		return entity.Identity{}, config.ErrIdentityNotFound
+	}
+
+	return identity, nil
+}
+
+func (im *IdentityMysql) LinkIdentity(identity entity.Identity) error {
+	query := fmt.Sprintf(config.LinkIdentityQuery, config.GetIdentityTable())
+
+	_, err := im.DB.Exec(query, identity.Provider, identity.Subject, identity.Username, identity.Email, identity.LinkedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package federation
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewIdentityMysql(db)
+	linked := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.LinkIdentityQuery, config.GetIdentityTable()))).
+		WithArgs("google", "sub-1", "johndoe", "john@example.com", linked).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.LinkIdentity(entity.Identity{
+		Provider: "google",
+		Subject:  "sub-1",
+		Username: "johndoe",
+		Email:    "john@example.com",
+		LinkedAt: linked,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewIdentityMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetIdentityQuery, config.GetIdentityTable()))
+
+	test := []struct {
+		Name        string
+		MockFunc    func()
+		ExpectedErr error
+	}{
+		{
+			Name: "GetIdentity_Success",
+			MockFunc: func() {
+				rows := sqlmock.NewRows([]string{"provider", "subject", "username", "email", "linked_at"}).
+					AddRow("google", "sub-1", "johndoe", "john@example.com", time.Now())
+				mock.ExpectQuery(query).WithArgs("google", "sub-1").WillReturnRows(rows)
+			},
+		},
+		{
+			Name: "GetIdentity_NotFound",
+			MockFunc: func() {
+				mock.ExpectQuery(query).WithArgs("google", "sub-1").WillReturnError(sql.ErrNoRows)
+			},
+			ExpectedErr: config.ErrIdentityNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			identity, err := repo.GetIdentity("google", "sub-1")
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "johndoe", identity.Username)
+			}
+		})
+	}
+}
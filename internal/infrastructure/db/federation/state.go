@@ -0,0 +1,78 @@
+package federation
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+	"time"
+)
+
+// StateMysql keeps pending federated logins in MySQL, so the callback can
+// land on any replica. Only a hash of the state is stored.
+type StateMysql struct {
+	DB  *sql.DB
+	Now func() time.Time
+}
+
+func NewStateMysql(db *sql.DB) entity.StateStore {
+	return &StateMysql{DB: db, Now: time.Now}
+}
+
+func (sm *StateMysql) Save(pending entity.PendingLogin) error {
+	table := config.GetFederatedStateTable()
+
+	if _, err := sm.DB.Exec(fmt.Sprintf(config.PurgeFederatedStatesQuery, table), sm.Now().UTC()); err != nil {
+		return err
+	}
+
+	_, err := sm.DB.Exec(fmt.Sprintf(config.SaveFederatedStateQuery, table),
+		hashState(pending.State),
+		pending.Provider,
+		pending.Nonce,
+		pending.CodeVerifier,
+		pending.LinkUsername,
+		pending.ExpiresAt.UTC(),
+	)
+	return err
+}
+
+// Consume reads and deletes the pending login; when two callbacks race
+// with the same state only the one whose delete hits the row gets it.
+func (sm *StateMysql) Consume(state string) (entity.PendingLogin, error) {
+	table := config.GetFederatedStateTable()
+	key := hashState(state)
+
+	pending := entity.PendingLogin{State: state}
+	err := sm.DB.QueryRow(fmt.Sprintf(config.GetFederatedStateQuery, table), key).Scan(
+		&pending.Provider,
+		&pending.Nonce,
+		&pending.CodeVerifier,
+		&pending.LinkUsername,
+		&pending.ExpiresAt,
+	)
+	if err != nil {
+		return entity.PendingLogin{}, config.ErrInvalidState
+	}
+
+	result, err := sm.DB.Exec(fmt.Sprintf(config.DeleteFederatedStateQuery, table), key)
+	if err != nil {
+		return entity.PendingLogin{}, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return entity.PendingLogin{}, err
+	}
+	if affected == 0 {
+		return entity.PendingLogin{}, config.ErrInvalidState
+	}
+
+	return pending, nil
+}
+
+func hashState(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
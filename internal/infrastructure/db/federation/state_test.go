@@ -0,0 +1,90 @@
+package federation
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateMysql(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &StateMysql{DB: db, Now: func() time.Time { return now }}
+	table := config.GetFederatedStateTable()
+	key := hashState("state-1")
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.PurgeFederatedStatesQuery, table))).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.SaveFederatedStateQuery, table))).
+		WithArgs(key, "acme", "nonce-1", "verifier-1", "johndoe", now.Add(config.FederatedStateTTL)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rows := sqlmock.NewRows([]string{"provider", "nonce", "code_verifier", "link_username", "expires_at"}).
+		AddRow("acme", "nonce-1", "verifier-1", "johndoe", now.Add(config.FederatedStateTTL))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetFederatedStateQuery, table))).
+		WithArgs(key).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.DeleteFederatedStateQuery, table))).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetFederatedStateQuery, table))).
+		WithArgs(key).
+		WillReturnError(sql.ErrNoRows)
+
+	err = store.Save(entity.PendingLogin{
+		State:        "state-1",
+		Provider:     "acme",
+		Nonce:        "nonce-1",
+		CodeVerifier: "verifier-1",
+		LinkUsername: "johndoe",
+		ExpiresAt:    now.Add(config.FederatedStateTTL),
+	})
+	require.NoError(t, err)
+
+	pending, err := store.Consume("state-1")
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", pending.State)
+	assert.Equal(t, "verifier-1", pending.CodeVerifier)
+	assert.Equal(t, "johndoe", pending.LinkUsername)
+
+	_, err = store.Consume("state-1")
+	assert.ErrorIs(t, err, config.ErrInvalidState)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStateMysql_ConsumeRace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	store := NewStateMysql(db)
+	table := config.GetFederatedStateTable()
+	key := hashState("state-1")
+
+	rows := sqlmock.NewRows([]string{"provider", "nonce", "code_verifier", "link_username", "expires_at"}).
+		AddRow("acme", "nonce-1", "verifier-1", "", time.Now().Add(time.Minute))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetFederatedStateQuery, table))).
+		WithArgs(key).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.DeleteFederatedStateQuery, table))).
+		WithArgs(key).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = store.Consume("state-1")
+
+	assert.ErrorIs(t, err, config.ErrInvalidState)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package grant
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/grant"
+	"strings"
+	"time"
+)
+
+type GrantMysql struct {
+	DB *sql.DB
+}
+
+func NewGrantMysql(db *sql.DB) entity.Repository {
+	return &GrantMysql{DB: db}
+}
+
+// Grant inserts every scope with a single multi-row statement.
+func (gm *GrantMysql) Grant(ctx context.Context, username string, scopes []string, grantedAt time.Time) error {
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(config.GrantScopeQuery, config.GetScopeGrantTable()) + strings.Repeat(config.GrantScopeValues, len(scopes)-1)
+
+	args := make([]any, 0, len(scopes)*3)
+	for _, s := range scopes {
+		args = append(args, username, s, grantedAt)
+	}
+
+	_, err := gm.DB.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (gm *GrantMysql) Revoke(ctx context.Context, username string, scopes []string) error {
+	query := fmt.Sprintf(config.RevokeScopeQuery, config.GetScopeGrantTable())
+
+	for _, s := range scopes {
+		if _, err := gm.DB.ExecContext(ctx, query, username, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (gm *GrantMysql) List(ctx context.Context, username string) ([]string, error) {
+	query := fmt.Sprintf(config.ListScopeGrantsQuery, config.GetScopeGrantTable())
+
+	rows, err := gm.DB.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scopes := []string{}
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, s)
+	}
+
+	return scopes, rows.Err()
+}
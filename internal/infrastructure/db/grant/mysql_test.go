@@ -0,0 +1,68 @@
+package grant
+
+import (
+	"context"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewGrantMysql(db)
+	granted := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.GrantScopeQuery, config.GetScopeGrantTable())+config.GrantScopeValues)).
+		WithArgs("johndoe", "users:write", granted, "johndoe", "users:delete", granted).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.Grant(context.Background(), "johndoe", []string{"users:write", "users:delete"}, granted))
+	assert.NoError(t, repo.Grant(context.Background(), "johndoe", nil, granted))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewGrantMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.RevokeScopeQuery, config.GetScopeGrantTable()))
+
+	mock.ExpectExec(query).WithArgs("johndoe", "users:write").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("johndoe", "users:delete").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.Revoke(context.Background(), "johndoe", []string{"users:write", "users:delete"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewGrantMysql(db)
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.ListScopeGrantsQuery, config.GetScopeGrantTable()))).
+		WithArgs("johndoe").
+		WillReturnRows(sqlmock.NewRows([]string{"scope"}).AddRow("users:delete").AddRow("users:write"))
+
+	scopes, err := repo.List(context.Background(), "johndoe")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users:delete", "users:write"}, scopes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
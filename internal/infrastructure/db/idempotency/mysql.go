@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/idempotency"
+	"go-manage-hex/internal/infrastructure/db"
+)
+
+type IdempotencyMysql struct {
+	DB db.Executor
+}
+
+func NewIdempotencyMysql(conn db.Executor) entity.Store {
+	return &IdempotencyMysql{DB: conn}
+}
+
+// Reserve drops an expired record for the key first, so a key can be reused
+// once its TTL is over and a request whose replica died holds it only until
+// the lock times out. The insert is ignored when the key is taken.
+func (im *IdempotencyMysql) Reserve(ctx context.Context, record entity.Record) (entity.Record, bool, error) {
+	table := config.GetIdempotencyTable()
+
+	if _, err := im.DB.ExecContext(ctx, fmt.Sprintf(config.ExpireIdempotencyKeyQuery, table), record.Caller, record.Key, record.CreatedAt); err != nil {
+		return entity.Record{}, false, err
+	}
+
+	result, err := im.DB.ExecContext(ctx, fmt.Sprintf(config.ReserveIdempotencyKeyQuery, table),
+		record.Caller,
+		record.Key,
+		record.Fingerprint,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return entity.Record{}, false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return entity.Record{}, false, err
+	}
+	if affected == 1 {
+		return record, true, nil
+	}
+
+	existing, err := scanRecord(im.DB.QueryRowContext(ctx, fmt.Sprintf(config.GetIdempotencyKeyQuery, table), record.Caller, record.Key))
+	if err != nil {
+		return entity.Record{}, false, err
+	}
+	return existing, false, nil
+}
+
+// Complete stores the response. It only applies to a record that is still
+// in progress, so a request that outlived its lock cannot overwrite the
+// response of the request that took the key over.
+func (im *IdempotencyMysql) Complete(ctx context.Context, record entity.Record) error {
+	query := fmt.Sprintf(config.CompleteIdempotencyKeyQuery, config.GetIdempotencyTable())
+
+	_, err := im.DB.ExecContext(ctx, query,
+		record.Status,
+		record.ContentType,
+		encodeHeaders(record.Headers),
+		record.Body,
+		record.ExpiresAt,
+		record.Caller,
+		record.Key,
+	)
+	return err
+}
+
+func (im *IdempotencyMysql) Release(ctx context.Context, caller, key string) error {
+	query := fmt.Sprintf(config.ReleaseIdempotencyKeyQuery, config.GetIdempotencyTable())
+
+	_, err := im.DB.ExecContext(ctx, query, caller, key)
+	return err
+}
+
+func (im *IdempotencyMysql) Purge(ctx context.Context, now time.Time) (int64, error) {
+	query := fmt.Sprintf(config.PurgeIdempotencyKeysQuery, config.GetIdempotencyTable())
+
+	result, err := im.DB.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanRecord(row *sql.Row) (entity.Record, error) {
+	var (
+		record      entity.Record
+		status      sql.NullInt64
+		contentType sql.NullString
+		headers     sql.NullString
+	)
+
+	err := row.Scan(
+		&record.Caller,
+		&record.Key,
+		&record.Fingerprint,
+		&status,
+		&contentType,
+		&headers,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return entity.Record{}, err
+	}
+
+	record.Status = int(status.Int64)
+	record.ContentType = contentType.String
+	record.Headers, err = decodeHeaders(headers.String)
+	if err != nil {
+		return entity.Record{}, err
+	}
+	return record, nil
+}
+
+// encodeHeaders keeps the headers in their wire format, one per line.
+func encodeHeaders(headers http.Header) sql.NullString {
+	if len(headers) == 0 {
+		return sql.NullString{}
+	}
+	var buf bytes.Buffer
+	headers.Write(&buf)
+	return sql.NullString{String: buf.String(), Valid: true}
+}
+
+func decodeHeaders(encoded string) (http.Header, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(encoded + "\r\n"))).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	return http.Header(headers), nil
+}
@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/idempotency"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var recordColumns = []string{"caller", "idempotency_key", "fingerprint", "status_code", "content_type", "headers", "body", "created_at", "expires_at"}
+
+func query(q string) string {
+	return regexp.QuoteMeta(fmt.Sprintf(q, config.GetIdempotencyTable()))
+}
+
+func TestReserve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store := NewIdempotencyMysql(db)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	record := entity.Record{Caller: "user:johndoe", Key: "key-1", Fingerprint: "fp", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	mock.ExpectExec(query(config.ExpireIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(query(config.ReserveIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", "fp", now, now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(query(config.ExpireIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(query(config.ReserveIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", "fp", now, now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(query(config.GetIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1").
+		WillReturnRows(sqlmock.NewRows(recordColumns).
+			AddRow("user:johndoe", "key-1", "fp", http.StatusOK, "application/json", "Location: /jobs/1\r\nRetry-After: 5\r\n", []byte(`{"status":201}`), now, now.Add(time.Hour)))
+
+	mock.ExpectExec(query(config.ExpireIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(query(config.ReserveIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1", "fp", now, now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(query(config.GetIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-1").
+		WillReturnRows(sqlmock.NewRows(recordColumns).
+			AddRow("user:johndoe", "key-1", "other", nil, nil, nil, nil, now, now.Add(time.Minute)))
+
+	reserved, ok, err := store.Reserve(ctx, record)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, record, reserved)
+
+	existing, ok, err := store.Reserve(ctx, record)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, existing.Completed())
+	assert.Equal(t, "application/json", existing.ContentType)
+	assert.Equal(t, http.Header{"Location": {"/jobs/1"}, "Retry-After": {"5"}}, existing.Headers)
+	assert.JSONEq(t, `{"status":201}`, string(existing.Body))
+
+	existing, ok, err = store.Reserve(ctx, record)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, existing.Completed())
+	assert.Equal(t, "other", existing.Fingerprint)
+	assert.Nil(t, existing.Headers)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteReleasePurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	store := NewIdempotencyMysql(db)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(query(config.CompleteIdempotencyKeyQuery)).
+		WithArgs(http.StatusOK, "application/json", "Location: /jobs/1\r\n", []byte(`{}`), now.Add(time.Hour), "user:johndoe", "key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query(config.ReleaseIdempotencyKeyQuery)).
+		WithArgs("user:johndoe", "key-2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query(config.PurgeIdempotencyKeysQuery)).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err = store.Complete(ctx, entity.Record{
+		Caller:      "user:johndoe",
+		Key:         "key-1",
+		Status:      http.StatusOK,
+		ContentType: "application/json",
+		Headers:     http.Header{"Location": {"/jobs/1"}},
+		Body:        []byte(`{}`),
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Release(ctx, "user:johndoe", "key-2"))
+
+	purged, err := store.Purge(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,246 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/job"
+	"go-manage-hex/internal/infrastructure/db"
+)
+
+type JobMysql struct {
+	DB db.Executor
+}
+
+func NewJobMysql(conn db.Executor) entity.Repository {
+	return &JobMysql{DB: conn}
+}
+
+func (jm *JobMysql) NewJob(ctx context.Context, job entity.Job) error {
+	query := fmt.Sprintf(config.NewJobQuery, config.GetJobTable())
+
+	_, err := jm.DB.ExecContext(ctx, query,
+		job.ID,
+		job.Type,
+		string(job.Payload),
+		job.Status,
+		job.Attempts,
+		job.MaxAttempts,
+		job.RunAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	return err
+}
+
+func (jm *JobMysql) GetJob(ctx context.Context, id string) (entity.Job, error) {
+	query := fmt.Sprintf(config.GetJobQuery, config.GetJobTable())
+
+	job, err := scanJob(jm.DB.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Job{}, config.ErrJobNotFound
+	}
+	if err != nil {
+		return entity.Job{}, err
+	}
+
+	return job, nil
+}
+
+func (jm *JobMysql) ListJobs(ctx context.Context, q entity.JobQuery) ([]entity.Job, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if q.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, q.Type)
+	}
+	if q.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, q.Status)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(config.ListJobsQuery, config.GetJobTable(), where)
+	return jm.queryJobs(ctx, query, append(args, q.Limit)...)
+}
+
+// Due returns pending jobs whose time has come and running jobs whose lease
+// expired because their worker died.
+func (jm *JobMysql) Due(ctx context.Context, types []string, now time.Time, limit int) ([]entity.Job, error) {
+	if len(types) == 0 {
+		return []entity.Job{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(types)), ",")
+	query := fmt.Sprintf(config.DueJobsQuery, config.GetJobTable(), placeholders)
+
+	args := make([]any, 0, len(types)+5)
+	for _, t := range types {
+		args = append(args, t)
+	}
+	args = append(args, config.JobPending, now, config.JobRunning, now, limit)
+
+	return jm.queryJobs(ctx, query, args...)
+}
+
+// Claim takes the lease with a conditional update; when several replicas race
+// for the same job only one of them sees a row affected.
+func (jm *JobMysql) Claim(ctx context.Context, id, owner string, now, leaseUntil time.Time) (bool, error) {
+	query := fmt.Sprintf(config.ClaimJobQuery, config.GetJobTable())
+
+	result, err := jm.DB.ExecContext(ctx, query,
+		config.JobRunning, owner, leaseUntil, now,
+		id, config.JobPending, now, config.JobRunning, now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (jm *JobMysql) Heartbeat(ctx context.Context, id, owner string, progress json.RawMessage, now, leaseUntil time.Time) error {
+	query := fmt.Sprintf(config.HeartbeatJobQuery, config.GetJobTable())
+
+	result, err := jm.DB.ExecContext(ctx, query, nullJSON(progress), leaseUntil, now, id, owner, config.JobRunning)
+	return leaseHeld(result, err)
+}
+
+func (jm *JobMysql) Finish(ctx context.Context, job entity.Job, owner string) error {
+	query := fmt.Sprintf(config.FinishJobQuery, config.GetJobTable())
+
+	result, err := jm.DB.ExecContext(ctx, query,
+		job.Status,
+		job.Attempts,
+		nullJSON(job.Progress),
+		nullJSON(job.Result),
+		nullString(job.LastError),
+		job.RunAt,
+		job.UpdatedAt,
+		nullTime(job.FinishedAt),
+		job.ID,
+		owner,
+	)
+	return leaseHeld(result, err)
+}
+
+func (jm *JobMysql) queryJobs(ctx context.Context, query string, args ...any) ([]entity.Job, error) {
+	rows, err := jm.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []entity.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func leaseHeld(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrLeaseLost
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row scanner) (entity.Job, error) {
+	var (
+		job            entity.Job
+		payload        string
+		progress       sql.NullString
+		result         sql.NullString
+		lastError      sql.NullString
+		leaseOwner     sql.NullString
+		leaseExpiresAt sql.NullTime
+		finishedAt     sql.NullTime
+	)
+
+	err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&payload,
+		&job.Status,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&progress,
+		&result,
+		&lastError,
+		&job.RunAt,
+		&leaseOwner,
+		&leaseExpiresAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&finishedAt,
+	)
+	if err != nil {
+		return entity.Job{}, err
+	}
+
+	job.Payload = json.RawMessage(payload)
+	if progress.Valid {
+		job.Progress = json.RawMessage(progress.String)
+	}
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	job.LastError = lastError.String
+	job.LeaseOwner = leaseOwner.String
+	if leaseExpiresAt.Valid {
+		job.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return job, nil
+}
+
+func nullJSON(raw json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: len(raw) > 0}
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,142 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/job"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var jobColumns = []string{"id", "type", "payload", "status", "attempts", "max_attempts", "progress", "result", "last_error", "run_at", "lease_owner", "lease_expires_at", "created_at", "updated_at", "finished_at"}
+
+func TestJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewJobMysql(db)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.NewJobQuery, config.GetJobTable()))).
+		WithArgs("job-1", config.JobTypeUserExport, `{"format":"csv"}`, config.JobPending, 0, 3, now, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetJobQuery, config.GetJobTable()))).
+		WithArgs("job-1").
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow("job-1", config.JobTypeUserExport, `{"format":"csv"}`, config.JobSucceeded, 1, 3, nil, `{"exported":2}`, nil, now, nil, nil, now, now, now))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetJobQuery, config.GetJobTable()))).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.ListJobsQuery, config.GetJobTable(), " WHERE type = ? AND status = ?"))).
+		WithArgs(config.JobTypeUserImport, config.JobFailed, 10).
+		WillReturnRows(sqlmock.NewRows(jobColumns))
+
+	err = repo.NewJob(ctx, entity.Job{
+		ID:          "job-1",
+		Type:        config.JobTypeUserExport,
+		Payload:     json.RawMessage(`{"format":"csv"}`),
+		Status:      config.JobPending,
+		MaxAttempts: 3,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	assert.NoError(t, err)
+
+	job, err := repo.GetJob(ctx, "job-1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"exported":2}`, string(job.Result))
+	assert.Nil(t, job.Progress)
+	assert.NotNil(t, job.FinishedAt)
+
+	_, err = repo.GetJob(ctx, "missing")
+	assert.ErrorIs(t, err, config.ErrJobNotFound)
+
+	jobs, err := repo.ListJobs(ctx, entity.JobQuery{Type: config.JobTypeUserImport, Status: config.JobFailed, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLeases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewJobMysql(db)
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := now.Add(30 * time.Second)
+	types := []string{config.JobTypeUserImport, config.JobTypeUserExport}
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.DueJobsQuery, config.GetJobTable(), "?,?"))).
+		WithArgs(config.JobTypeUserImport, config.JobTypeUserExport, config.JobPending, now, config.JobRunning, now, 2).
+		WillReturnRows(sqlmock.NewRows(jobColumns).
+			AddRow("job-1", config.JobTypeUserImport, `{}`, config.JobRunning, 1, 3, `{"last_line":40}`, nil, nil, now, "dead-worker", now, now, now, nil))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimJobQuery, config.GetJobTable()))).
+		WithArgs(config.JobRunning, "worker-a", until, now, "job-1", config.JobPending, now, config.JobRunning, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimJobQuery, config.GetJobTable()))).
+		WithArgs(config.JobRunning, "worker-b", until, now, "job-1", config.JobPending, now, config.JobRunning, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.HeartbeatJobQuery, config.GetJobTable()))).
+		WithArgs(`{"last_line":80}`, until, now, "job-1", "worker-b", config.JobRunning).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.FinishJobQuery, config.GetJobTable()))).
+		WithArgs(config.JobSucceeded, 2, `{"last_line":80}`, nil, nil, now, now, now, "job-1", "worker-a").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	due, err := repo.Due(ctx, types, now, 2)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, "dead-worker", due[0].LeaseOwner)
+
+	claimed, err := repo.Claim(ctx, "job-1", "worker-a", now, until)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.Claim(ctx, "job-1", "worker-b", now, until)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	err = repo.Heartbeat(ctx, "job-1", "worker-b", json.RawMessage(`{"last_line":80}`), now, until)
+	assert.ErrorIs(t, err, config.ErrLeaseLost)
+
+	err = repo.Finish(ctx, entity.Job{
+		ID:         "job-1",
+		Status:     config.JobSucceeded,
+		Attempts:   2,
+		Progress:   json.RawMessage(`{"last_line":80}`),
+		RunAt:      now,
+		UpdatedAt:  now,
+		FinishedAt: &now,
+	}, "worker-a")
+	assert.NoError(t, err)
+
+	due, err = repo.Due(ctx, nil, now, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,341 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/migration"
+)
+
+type Table struct {
+	Name        string
+	CreateQuery string
+}
+
+// Migration is one schema version. Up and Down run on the connection that
+// holds the migration lock and must tolerate a schema that is already
+// partly there, since DDL in MySQL cannot be rolled back.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, conn *sql.Conn) error
+	Down    func(ctx context.Context, conn *sql.Conn) error
+}
+
+// MigratorMysql applies versioned migrations and records each one in the
+// schema version table. A MySQL named lock keeps replicas that start at the
+// same time from migrating concurrently.
+type MigratorMysql struct {
+	DB         *sql.DB
+	Tables     []Table
+	Migrations []Migration
+	Now        func() time.Time
+}
+
+func NewMigratorMysql(db *sql.DB) *MigratorMysql {
+	users := config.GetMysqlTable()
+	oidcClients := config.GetOIDCClientTable()
+	outbox := config.GetOutboxTable()
+	idempotencyKeys := config.GetIdempotencyTable()
+
+	baseline := []Table{
+		{Name: users, CreateQuery: config.CreateTableQuery},
+		{Name: config.GetPwdHistoryTable(), CreateQuery: config.CreateHistoryTableQuery},
+		{Name: config.GetAPIKeyTable(), CreateQuery: config.CreateAPIKeyTableQuery},
+		{Name: oidcClients, CreateQuery: config.CreateOIDCClientTableQuery},
+		{Name: config.GetIdentityTable(), CreateQuery: config.CreateIdentityTableQuery},
+		{Name: outbox, CreateQuery: config.CreateOutboxTableQuery},
+		{Name: config.GetWebhookTable(), CreateQuery: config.CreateWebhookTableQuery},
+		{Name: config.GetDeliveryTable(), CreateQuery: config.CreateDeliveryTableQuery},
+		{Name: config.GetJobTable(), CreateQuery: config.CreateJobTableQuery},
+		{Name: idempotencyKeys, CreateQuery: config.CreateIdempotencyTableQuery},
+	}
+	scopeGrants := Table{Name: config.GetScopeGrantTable(), CreateQuery: config.CreateScopeGrantTableQuery}
+	federatedStates := Table{Name: config.GetFederatedStateTable(), CreateQuery: config.CreateFederatedStateTableQuery}
+
+	return &MigratorMysql{
+		DB:     db,
+		Tables: append(slices.Clone(baseline), scopeGrants, federatedStates),
+		Migrations: []Migration{
+			{
+				// The baseline creates every table with its current columns,
+				// so databases from before versioning adopt it unchanged and
+				// the migrations below only add what those lack.
+				Version: 1,
+				Name:    "baseline",
+				Up:      createTables(baseline...),
+				Down:    dropTables(baseline...),
+			},
+			{
+				Version: 2,
+				Name:    "widen_user_password",
+				Up:      exec(fmt.Sprintf(config.WidenUserPasswordQuery, users)),
+				// Narrowing the column again would truncate argon2 hashes.
+				Down: noop,
+			},
+			{
+				Version: 3,
+				Name:    "oidc_client_scopes",
+				Up:      addColumn(oidcClients, "scopes", config.OIDCClientScopesColumn),
+				Down:    dropColumn(oidcClients, "scopes"),
+			},
+			{
+				Version: 4,
+				Name:    "user_soft_delete",
+				Up:      addColumn(users, "deleted_at", config.UserDeletedAtColumn),
+				// Without the column, soft-deleted users would come back, so
+				// rolling back purges them for good.
+				Down: sequence(
+					exec(fmt.Sprintf(config.PurgeDeletedUsersQuery, users)),
+					dropColumn(users, "deleted_at"),
+				),
+			},
+			{
+				Version: 5,
+				Name:    "user_scope_grants",
+				Up:      createTables(scopeGrants),
+				Down:    dropTables(scopeGrants),
+			},
+			{
+				Version: 6,
+				Name:    "outbox_retries",
+				Up: sequence(
+					addColumn(outbox, "next_attempt_at", config.OutboxNextAttemptAtColumn),
+					addColumn(outbox, "dead_at", config.OutboxDeadAtColumn),
+				),
+				Down: sequence(
+					dropColumn(outbox, "dead_at"),
+					dropColumn(outbox, "next_attempt_at"),
+				),
+			},
+			{
+				Version: 7,
+				Name:    "federated_states",
+				Up:      createTables(federatedStates),
+				Down:    dropTables(federatedStates),
+			},
+			{
+				Version: 8,
+				Name:    "idempotency_headers",
+				Up:      addColumn(idempotencyKeys, "headers", config.IdempotencyHeadersColumn),
+				Down:    dropColumn(idempotencyKeys, "headers"),
+			},
+		},
+		Now: time.Now,
+	}
+}
+
+func (mm *MigratorMysql) TableNames() []string {
+	names := make([]string, len(mm.Tables))
+	for i, table := range mm.Tables {
+		names[i] = table.Name
+	}
+	return names
+}
+
+func (mm *MigratorMysql) Up(ctx context.Context) error {
+	return mm.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := mm.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range mm.Migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := migration.Up(ctx, conn); err != nil {
+				return fmt.Errorf("%w: %d %s: %w", config.ErrMigration, migration.Version, migration.Name, err)
+			}
+
+			query := fmt.Sprintf(config.RecordVersionQuery, config.GetSchemaVersionTable())
+			if _, err := conn.ExecContext(ctx, query, migration.Version, migration.Name, mm.Now().UTC()); err != nil {
+				return fmt.Errorf("%w: %d %s: %w", config.ErrMigration, migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down rolls back the latest applied migrations, newest first.
+func (mm *MigratorMysql) Down(ctx context.Context, steps int) error {
+	return mm.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := mm.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(mm.Migrations) - 1; i >= 0 && steps > 0; i-- {
+			migration := mm.Migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := migration.Down(ctx, conn); err != nil {
+				return fmt.Errorf("%w: %d %s: %w", config.ErrMigration, migration.Version, migration.Name, err)
+			}
+
+			query := fmt.Sprintf(config.RemoveVersionQuery, config.GetSchemaVersionTable())
+			if _, err := conn.ExecContext(ctx, query, migration.Version); err != nil {
+				return fmt.Errorf("%w: %d %s: %w", config.ErrMigration, migration.Version, migration.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+func (mm *MigratorMysql) Status(ctx context.Context) ([]entity.VersionStatus, error) {
+	conn, err := mm.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+	defer conn.Close()
+
+	applied := map[int]time.Time{}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, config.TableExistsQuery, config.GetSchemaVersionTable()).Scan(&count); err != nil {
+		return nil, fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+	if count > 0 {
+		if applied, err = mm.applied(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]entity.VersionStatus, 0, len(mm.Migrations))
+	for _, migration := range mm.Migrations {
+		status := entity.VersionStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// locked runs fn on a single connection holding the migration lock, because
+// MySQL named locks belong to the session that took them.
+func (mm *MigratorMysql) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := mm.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+	defer conn.Close()
+
+	lock := config.GetSchemaVersionTable()
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, config.AcquireMigrationLockQuery, lock, config.MigrationLockTimeoutSeconds).Scan(&acquired); err != nil {
+		return fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+	if acquired.Int64 != 1 {
+		return config.ErrMigrationLock
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), config.ReleaseMigrationLockQuery, lock)
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(config.CreateSchemaVersionTableQuery, lock)); err != nil {
+		return fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+
+	return fn(conn)
+}
+
+func (mm *MigratorMysql) applied(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(config.AppliedVersionsQuery, config.GetSchemaVersionTable()))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", config.ErrMigration, err)
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("%w: %w", config.ErrMigration, err)
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func createTables(tables ...Table) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		for _, table := range tables {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf(table.CreateQuery, table.Name)); err != nil {
+				return fmt.Errorf("%s: %w", table.Name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func dropTables(tables ...Table) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		for i := len(tables) - 1; i >= 0; i-- {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf(config.DropTableQuery, tables[i].Name)); err != nil {
+				return fmt.Errorf("%s: %w", tables[i].Name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func exec(query string) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, query)
+		return err
+	}
+}
+
+func sequence(fns ...func(ctx context.Context, conn *sql.Conn) error) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		for _, fn := range fns {
+			if err := fn(ctx, conn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func noop(ctx context.Context, conn *sql.Conn) error {
+	return nil
+}
+
+// addColumn skips the ALTER when the baseline already created the column,
+// as it does on fresh databases; MySQL has no ADD COLUMN IF NOT EXISTS.
+func addColumn(table, column, definition string) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		exists, err := columnExists(ctx, conn, table, column)
+		if err != nil || exists {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, fmt.Sprintf(config.AddColumnQuery, table, column, definition))
+		return err
+	}
+}
+
+func dropColumn(table, column string) func(ctx context.Context, conn *sql.Conn) error {
+	return func(ctx context.Context, conn *sql.Conn) error {
+		exists, err := columnExists(ctx, conn, table, column)
+		if err != nil || !exists {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, fmt.Sprintf(config.DropColumnQuery, table, column))
+		return err
+	}
+}
+
+func columnExists(ctx context.Context, conn *sql.Conn, table, column string) (bool, error) {
+	var count int
+	if err := conn.QueryRowContext(ctx, config.ColumnExistsQuery, table, column).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
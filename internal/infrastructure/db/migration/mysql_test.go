@@ -0,0 +1,201 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMigrator(t *testing.T) (*MigratorMysql, sqlmock.Sqlmock, time.Time) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	migrator := NewMigratorMysql(db)
+	migrator.Now = func() time.Time { return now }
+	migrator.Migrations = []Migration{
+		{
+			Version: 1,
+			Name:    "baseline",
+			Up:      createTables(Table{Name: "users", CreateQuery: config.CreateTableQuery}),
+			Down:    dropTables(Table{Name: "users", CreateQuery: config.CreateTableQuery}),
+		},
+		{
+			Version: 2,
+			Name:    "user_nickname",
+			Up:      addColumn("users", "nickname", "VARCHAR(36) NULL"),
+			Down:    dropColumn("users", "nickname"),
+		},
+	}
+	return migrator, mock, now
+}
+
+func expectLock(mock sqlmock.Sqlmock, acquired int) {
+	mock.ExpectQuery(regexp.QuoteMeta(config.AcquireMigrationLockQuery)).
+		WithArgs(config.GetSchemaVersionTable(), config.MigrationLockTimeoutSeconds).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(acquired))
+	if acquired != 1 {
+		return
+	}
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.CreateSchemaVersionTableQuery, config.GetSchemaVersionTable()))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta(config.ReleaseMigrationLockQuery)).
+		WithArgs(config.GetSchemaVersionTable()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions map[int]time.Time) {
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range []int{1, 2} {
+		if appliedAt, ok := versions[version]; ok {
+			rows.AddRow(version, appliedAt)
+		}
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.AppliedVersionsQuery, config.GetSchemaVersionTable()))).
+		WillReturnRows(rows)
+}
+
+func expectColumn(mock sqlmock.Sqlmock, count int) {
+	mock.ExpectQuery(regexp.QuoteMeta(config.ColumnExistsQuery)).
+		WithArgs("users", "nickname").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+func TestMigratorUp(t *testing.T) {
+	t.Run("Pending", func(t *testing.T) {
+		migrator, mock, now := newTestMigrator(t)
+
+		expectLock(mock, 1)
+		expectApplied(mock, nil)
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.CreateTableQuery, "users"))).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RecordVersionQuery, config.GetSchemaVersionTable()))).
+			WithArgs(1, "baseline", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectColumn(mock, 0)
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.AddColumnQuery, "users", "nickname", "VARCHAR(36) NULL"))).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RecordVersionQuery, config.GetSchemaVersionTable()))).
+			WithArgs(2, "user_nickname", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUnlock(mock)
+
+		assert.NoError(t, migrator.Up(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ColumnFromBaseline", func(t *testing.T) {
+		migrator, mock, now := newTestMigrator(t)
+
+		expectLock(mock, 1)
+		expectApplied(mock, map[int]time.Time{1: now})
+		expectColumn(mock, 1)
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RecordVersionQuery, config.GetSchemaVersionTable()))).
+			WithArgs(2, "user_nickname", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectUnlock(mock)
+
+		assert.NoError(t, migrator.Up(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("FailureNotRecorded", func(t *testing.T) {
+		migrator, mock, _ := newTestMigrator(t)
+
+		expectLock(mock, 1)
+		expectApplied(mock, nil)
+		mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.CreateTableQuery, "users"))).
+			WillReturnError(errors.New("denied"))
+		expectUnlock(mock)
+
+		err := migrator.Up(context.Background())
+		assert.ErrorIs(t, err, config.ErrMigration)
+		assert.ErrorContains(t, err, "1 baseline: users: denied")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		migrator, mock, _ := newTestMigrator(t)
+
+		expectLock(mock, 0)
+
+		assert.ErrorIs(t, migrator.Up(context.Background()), config.ErrMigrationLock)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMigratorDown(t *testing.T) {
+	migrator, mock, now := newTestMigrator(t)
+
+	expectLock(mock, 1)
+	expectApplied(mock, map[int]time.Time{1: now, 2: now})
+	expectColumn(mock, 1)
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.DropColumnQuery, "users", "nickname"))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RemoveVersionQuery, config.GetSchemaVersionTable()))).
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectUnlock(mock)
+
+	assert.NoError(t, migrator.Down(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorStatus(t *testing.T) {
+	t.Run("Versioned", func(t *testing.T) {
+		migrator, mock, now := newTestMigrator(t)
+
+		mock.ExpectQuery(regexp.QuoteMeta(config.TableExistsQuery)).
+			WithArgs(config.GetSchemaVersionTable()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		expectApplied(mock, map[int]time.Time{1: now})
+
+		statuses, err := migrator.Status(context.Background())
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		assert.Equal(t, now, *statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NeverMigrated", func(t *testing.T) {
+		migrator, mock, _ := newTestMigrator(t)
+
+		mock.ExpectQuery(regexp.QuoteMeta(config.TableExistsQuery)).
+			WithArgs(config.GetSchemaVersionTable()).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+		statuses, err := migrator.Status(context.Background())
+		require.NoError(t, err)
+		assert.Nil(t, statuses[0].AppliedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestNewMigratorMysql(t *testing.T) {
+	migrator := NewMigratorMysql(&sql.DB{})
+
+	for i, migration := range migrator.Migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Name)
+	}
+	assert.Contains(t, migrator.TableNames(), config.GetMysqlTable())
+	assert.Contains(t, migrator.TableNames(), config.GetIdempotencyTable())
+	assert.Contains(t, migrator.TableNames(), config.GetScopeGrantTable())
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/oidc"
+	"strings"
+)
+
+type ClientMysql struct {
+	DB *sql.DB
+}
+
+func NewClientMysql(db *sql.DB) entity.ClientRepository {
+	return &ClientMysql{DB: db}
+}
+
+func (cm *ClientMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateOIDCClientTableQuery, tableName)
+
+	_, err := cm.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cm *ClientMysql) NewClient(client entity.Client) error {
+	query := fmt.Sprintf(config.NewOIDCClientQuery, config.GetOIDCClientTable())
+
+	_, err := cm.DB.Exec(query, client.ID, client.SecretHash, client.Name, strings.Join(client.RedirectURIs, " "), client.AuthMethod, strings.Join(client.Scopes, " "), client.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cm *ClientMysql) GetClient(id string) (entity.Client, error) {
+	query := fmt.Sprintf(config.GetOIDCClientQuery, config.GetOIDCClientTable())
+
+	var (
+		client       entity.Client
+		redirectURIs string
+		scopes       string
+	)
+
+	err := cm.DB.QueryRow(query, id).Scan(
+		&client.ID,
+		&client.SecretHash,
+		&client.Name,
+		&redirectURIs,
+		&client.AuthMethod,
+		&scopes,
+		&client.CreatedAt,
+	)
+	if err != nil {
+		return entity.Client{}, config.ErrClientNotFound
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.Scopes = strings.Fields(scopes)
+
+	return client, nil
+}
@@ -0,0 +1,90 @@
+package oidc
+
+import (
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/oidc"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewClientMysql(db)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.NewOIDCClientQuery, config.GetOIDCClientTable()))).
+		WithArgs("client-1", "hash", "wiki", "https://a.example/cb https://b.example/cb", config.ClientAuthBasic, "openid users:read", created).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.NewClient(entity.Client{
+		ID:           "client-1",
+		SecretHash:   "hash",
+		Name:         "wiki",
+		RedirectURIs: []string{"https://a.example/cb", "https://b.example/cb"},
+		AuthMethod:   config.ClientAuthBasic,
+		Scopes:       []string{"openid", "users:read"},
+		CreatedAt:    created,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetClient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewClientMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetOIDCClientQuery, config.GetOIDCClientTable()))
+
+	test := []struct {
+		Name        string
+		MockFunc    func()
+		ExpectedErr error
+	}{
+		{
+			Name: "GetClient_Success",
+			MockFunc: func() {
+				rows := sqlmock.NewRows([]string{"id", "secret_hash", "name", "redirect_uris", "auth_method", "scopes", "created_at"}).
+					AddRow("client-1", "hash", "wiki", "https://a.example/cb https://b.example/cb", config.ClientAuthBasic, "openid profile", time.Now())
+				mock.ExpectQuery(query).WithArgs("client-1").WillReturnRows(rows)
+			},
+		},
+		{
+			Name: "GetClient_NotFound",
+			MockFunc: func() {
+				mock.ExpectQuery(query).WithArgs("client-1").WillReturnError(fmt.Errorf("sql: no rows in result set"))
+			},
+			ExpectedErr: config.ErrClientNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			client, err := repo.GetClient("client-1")
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"https://a.example/cb", "https://b.example/cb"}, client.RedirectURIs)
+			assert.Equal(t, []string{"openid", "profile"}, client.Scopes)
+		})
+	}
+}
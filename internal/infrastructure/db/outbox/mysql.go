@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/event"
+	"go-manage-hex/internal/infrastructure/db"
+	"time"
+)
+
+type OutboxMysql struct {
+	DB db.Executor
+}
+
+func NewOutboxMysql(conn db.Executor) entity.OutboxRepository {
+	return &OutboxMysql{DB: conn}
+}
+
+func (om *OutboxMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateOutboxTableQuery, tableName)
+
+	_, err := om.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (om *OutboxMysql) Record(events ...entity.Event) error {
+	query := fmt.Sprintf(config.RecordEventQuery, config.GetOutboxTable())
+
+	for _, e := range events {
+		if _, err := om.DB.Exec(query, e.ID, e.Type, e.Aggregate, string(e.Payload), e.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pending returns the head event of each aggregate that is due; events
+// behind it wait until it is published or dead-lettered.
+func (om *OutboxMysql) Pending(now time.Time, limit int) ([]entity.Event, error) {
+	query := fmt.Sprintf(config.PendingEventsQuery, config.GetOutboxTable())
+
+	rows, err := om.DB.Query(query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []entity.Event
+	for rows.Next() {
+		var (
+			e       entity.Event
+			payload string
+		)
+		if err := rows.Scan(&e.Sequence, &e.ID, &e.Type, &e.Aggregate, &payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = []byte(payload)
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Claim hides the event from other relays until leaseUntil by pushing its
+// next attempt forward; when replicas race only one sees a row affected.
+func (om *OutboxMysql) Claim(sequence int64, now, leaseUntil time.Time) (bool, error) {
+	query := fmt.Sprintf(config.ClaimEventQuery, config.GetOutboxTable())
+
+	result, err := om.DB.Exec(query, leaseUntil, sequence, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (om *OutboxMysql) MarkPublished(sequence int64, publishedAt time.Time) error {
+	query := fmt.Sprintf(config.MarkPublishedQuery, config.GetOutboxTable())
+
+	_, err := om.DB.Exec(query, publishedAt, sequence)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (om *OutboxMysql) MarkFailed(sequence int64, reason string, nextAttemptAt time.Time) error {
+	query := fmt.Sprintf(config.MarkFailedQuery, config.GetOutboxTable())
+
+	_, err := om.DB.Exec(query, reason, nextAttemptAt, sequence)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (om *OutboxMysql) MarkDead(sequence int64, reason string, deadAt time.Time) error {
+	query := fmt.Sprintf(config.MarkDeadQuery, config.GetOutboxTable())
+
+	_, err := om.DB.Exec(query, reason, deadAt, sequence)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewOutboxMysql(db)
+	occurred := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RecordEventQuery, config.GetOutboxTable()))).
+		WithArgs("evt-1", config.EventUserCreated, "johndoe", `{"username":"johndoe"}`, occurred).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	rows := sqlmock.NewRows([]string{"sequence", "event_id", "event_type", "aggregate", "payload", "occurred_at", "attempts"}).
+		AddRow(1, "evt-1", config.EventUserCreated, "johndoe", `{"username":"johndoe"}`, occurred, 2)
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.PendingEventsQuery, config.GetOutboxTable()))).
+		WithArgs(occurred, 10).
+		WillReturnRows(rows)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimEventQuery, config.GetOutboxTable()))).
+		WithArgs(occurred.Add(time.Minute), int64(1), occurred).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimEventQuery, config.GetOutboxTable()))).
+		WithArgs(occurred.Add(time.Minute), int64(1), occurred).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.MarkPublishedQuery, config.GetOutboxTable()))).
+		WithArgs(occurred, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.MarkFailedQuery, config.GetOutboxTable()))).
+		WithArgs("receiver down", occurred.Add(time.Second), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.MarkDeadQuery, config.GetOutboxTable()))).
+		WithArgs("receiver down", occurred, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.Record(event.Event{
+		ID:         "evt-1",
+		Type:       config.EventUserCreated,
+		Aggregate:  "johndoe",
+		Payload:    []byte(`{"username":"johndoe"}`),
+		OccurredAt: occurred,
+	})
+	assert.NoError(t, err)
+
+	pending, err := repo.Pending(occurred, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, int64(1), pending[0].Sequence)
+		assert.Equal(t, 2, pending[0].Attempts)
+		assert.JSONEq(t, `{"username":"johndoe"}`, string(pending[0].Payload))
+	}
+
+	claimed, err := repo.Claim(1, occurred, occurred.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.Claim(1, occurred, occurred.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	assert.NoError(t, repo.MarkPublished(1, occurred))
+	assert.NoError(t, repo.MarkFailed(1, "receiver down", occurred.Add(time.Second)))
+	assert.NoError(t, repo.MarkDead(1, "receiver down", occurred))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTx(t *testing.T) {
+	test := []struct {
+		Name        string
+		FnErr       error
+		ExpectedErr bool
+	}{
+		{
+			Name: "WithinTx_Commit",
+		},
+		{
+			Name:        "WithinTx_Rollback",
+			FnErr:       errors.New("insert failed"),
+			ExpectedErr: true,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.DeleteQuery, config.GetMysqlTable()))).
+				WithArgs(sqlmock.AnyArg(), "johndoe").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			if tt.FnErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.RecordEventQuery, config.GetOutboxTable()))).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+
+			err = NewMysqlTransactor(db).WithinTx(context.Background(), func(users mysqlUser.MysqlRepository, events event.Recorder) error {
+				if err := users.DeleteUser(context.Background(), "johndoe"); err != nil {
+					return err
+				}
+				if tt.FnErr != nil {
+					return tt.FnErr
+				}
+				return events.Record(event.Event{ID: "evt-1", Type: config.EventUserDeleted, Aggregate: "johndoe", Payload: []byte(`{}`)})
+			})
+
+			if tt.ExpectedErr {
+				assert.ErrorIs(t, err, tt.FnErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+	userRepository "go-manage-hex/internal/infrastructure/db/user"
+)
+
+type MysqlTransactor struct {
+	DB *sql.DB
+}
+
+func NewMysqlTransactor(db *sql.DB) mysqlUser.Transactor {
+	return &MysqlTransactor{DB: db}
+}
+
+func (mt *MysqlTransactor) WithinTx(ctx context.Context, fn func(users mysqlUser.MysqlRepository, events event.Recorder) error) error {
+	tx, err := mt.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if fnErr := fn(userRepository.NewUserMysql(tx), NewOutboxMysql(tx)); fnErr != nil {
+		tx.Rollback()
+		return fnErr
+	}
+
+	return tx.Commit()
+}
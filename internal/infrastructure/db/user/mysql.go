@@ -1,18 +1,30 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"go-manage-hex/cmd/config"
 	mysqlrepo "go-manage-hex/internal/core/user"
+	"go-manage-hex/internal/infrastructure/db"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+const duplicateEntry = 1062
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type UserMysql struct {
-	DB *sql.DB
+	DB db.Executor
 }
 
-func NewUserMysql(db *sql.DB) mysqlrepo.MysqlRepository {
-	return &UserMysql{DB: db}
+func NewUserMysql(conn db.Executor) mysqlrepo.MysqlRepository {
+	return &UserMysql{DB: conn}
 }
 
 func (um *UserMysql) CreateTable(tableName string) error {
@@ -25,12 +37,54 @@ func (um *UserMysql) CreateTable(tableName string) error {
 	return nil
 }
 
-func (um *UserMysql) GetByUsername(username string) (mysqlrepo.User, error) {
+func (um *UserMysql) GetByUsername(ctx context.Context, username string) (mysqlrepo.User, error) {
 	query := fmt.Sprintf(config.GetByUsernameQuery, config.GetMysqlTable())
 
 	var user mysqlrepo.User
 
-	err := um.DB.QueryRow(query, username).Scan(
+	err := um.DB.QueryRowContext(ctx, query, username).Scan(
+		&user.ID,
+		&user.Name,
+		&user.LastName,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+	)
+
+	if err != nil {
+		return mysqlrepo.User{}, config.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (um *UserMysql) GetByEmail(ctx context.Context, email string) (mysqlrepo.User, error) {
+	query := fmt.Sprintf(config.GetByEmailQuery, config.GetMysqlTable())
+
+	var user mysqlrepo.User
+
+	err := um.DB.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Name,
+		&user.LastName,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+	)
+
+	if err != nil {
+		return mysqlrepo.User{}, config.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (um *UserMysql) GetByID(ctx context.Context, id string) (mysqlrepo.User, error) {
+	query := fmt.Sprintf(config.GetByIDQuery, config.GetMysqlTable())
+
+	var user mysqlrepo.User
+
+	err := um.DB.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.LastName,
@@ -46,51 +100,240 @@ func (um *UserMysql) GetByUsername(username string) (mysqlrepo.User, error) {
 	return user, nil
 }
 
-func (um *UserMysql) NewUser(user mysqlrepo.User) error {
+// GetByIDWithDeleted also finds soft-deleted users, with DeletedAt set, so
+// callers can tell a deactivated account from one that never existed.
+func (um *UserMysql) GetByIDWithDeleted(ctx context.Context, id string) (mysqlrepo.User, error) {
+	query := fmt.Sprintf(config.GetByIDWithDeletedQuery, config.GetMysqlTable())
+
+	var (
+		user      mysqlrepo.User
+		deletedAt sql.NullTime
+	)
+
+	err := um.DB.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Name,
+		&user.LastName,
+		&user.Username,
+		&user.Email,
+		&user.Password,
+		&deletedAt,
+	)
+
+	if err != nil {
+		return mysqlrepo.User{}, config.ErrUserNotFound
+	}
+
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+
+	return user, nil
+}
+
+func (um *UserMysql) ListUsers(ctx context.Context, userQuery mysqlrepo.UserQuery) ([]mysqlrepo.User, int, error) {
+	where, args, err := whereClause(userQuery.Filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(config.CountUsersQuery, config.GetMysqlTable(), where)
+	if err := um.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	if userQuery.Limit <= 0 {
+		return nil, total, nil
+	}
+
+	query := fmt.Sprintf(config.ListUsersQuery, config.GetMysqlTable(), where)
+
+	rows, err := um.DB.QueryContext(ctx, query, append(args, userQuery.Limit, userQuery.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []mysqlrepo.User
+	for rows.Next() {
+		var user mysqlrepo.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.LastName, &user.Username, &user.Email, &user.Password); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+func (um *UserMysql) NewUser(ctx context.Context, user mysqlrepo.User) error {
 	query := fmt.Sprintf(config.NewUserQuery, config.GetMysqlTable())
 
-	_, err := um.DB.Exec(query, user.ID, user.Name, user.LastName, user.Username, user.Email, user.Password)
+	_, err := um.DB.ExecContext(ctx, query, user.ID, user.Name, user.LastName, user.Username, user.Email, user.Password)
 	if err != nil {
-		return err
+		return duplicate(err)
 	}
 	return nil
 }
 
-func (um *UserMysql) DeleteUser(username string) error {
+// NewUsers inserts every user with a single multi-row statement.
+func (um *UserMysql) NewUsers(ctx context.Context, users []mysqlrepo.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(config.NewUserQuery, config.GetMysqlTable()) + strings.Repeat(config.NewUserValues, len(users)-1)
+
+	args := make([]any, 0, len(users)*6)
+	for _, user := range users {
+		args = append(args, user.ID, user.Name, user.LastName, user.Username, user.Email, user.Password)
+	}
+
+	_, err := um.DB.ExecContext(ctx, query, args...)
+	return duplicate(err)
+}
+
+// DeleteUser soft-deletes the user. The row keeps its username and email, so
+// both stay reserved until the user is restored or purged.
+func (um *UserMysql) DeleteUser(ctx context.Context, username string) error {
 	query := fmt.Sprintf(config.DeleteQuery, config.GetMysqlTable())
 
-	_, err := um.DB.Exec(query, username)
+	_, err := um.DB.ExecContext(ctx, query, time.Now().UTC(), username)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (um *UserMysql) UpdateUser(username string, user mysqlrepo.User) error {
+func (um *UserMysql) RestoreUser(ctx context.Context, username string) error {
+	query := fmt.Sprintf(config.RestoreQuery, config.GetMysqlTable())
+
+	result, err := um.DB.ExecContext(ctx, query, username)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrUserNotFound
+	}
+	return nil
+}
+
+func (um *UserMysql) UpdateUser(ctx context.Context, username string, user mysqlrepo.User) error {
 	query := fmt.Sprintf(config.UpdateQuery, config.GetMysqlTable())
 
-	_, err := um.DB.Exec(query, user.Name, user.LastName, user.Email, username)
+	_, err := um.DB.ExecContext(ctx, query, user.Name, user.LastName, user.Email, username)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (um *UserMysql) ChangePwd(newPwd, username string) error {
+func (um *UserMysql) ChangePwd(ctx context.Context, newPwd, username string) error {
 	query := fmt.Sprintf(config.ChangePwdQuery, config.GetMysqlTable())
 
-	_, err := um.DB.Exec(query, newPwd, username)
+	_, err := um.DB.ExecContext(ctx, query, newPwd, username)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (um *UserMysql) CheckExists(username string) bool {
+func (um *UserMysql) CheckExists(ctx context.Context, username string) bool {
 	query := fmt.Sprintf(config.CheckExistsQuery, config.GetMysqlTable())
 
 	var exists string
 
-	return um.DB.QueryRow(query, username).Scan(&exists) == nil
+	return um.DB.QueryRowContext(ctx, query, username).Scan(&exists) == nil
+
+}
+
+// IsDeleted reports whether username belongs to a soft-deleted user, whose
+// row still holds the username and email.
+func (um *UserMysql) IsDeleted(ctx context.Context, username string) bool {
+	query := fmt.Sprintf(config.IsDeletedQuery, config.GetMysqlTable())
+
+	var deleted string
+
+	return um.DB.QueryRowContext(ctx, query, username).Scan(&deleted) == nil
+}
+
+func (um *UserMysql) CreateHistoryTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateHistoryTableQuery, tableName)
+
+	_, err := um.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (um *UserMysql) AddPwdHistory(ctx context.Context, username, hash string) error {
+	query := fmt.Sprintf(config.AddPwdHistoryQuery, config.GetPwdHistoryTable())
+
+	_, err := um.DB.ExecContext(ctx, query, username, hash)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (um *UserMysql) GetPwdHistory(ctx context.Context, username string, limit int) ([]string, error) {
+	query := fmt.Sprintf(config.GetPwdHistoryQuery, config.GetPwdHistoryTable())
 
+	rows, err := um.DB.QueryContext(ctx, query, username, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		history = append(history, hash)
+	}
+
+	return history, rows.Err()
+}
+
+func whereClause(filter *mysqlrepo.UserFilter) (string, []any, error) {
+	if filter == nil {
+		return "", nil, nil
+	}
+
+	if !slices.Contains(config.UserColumns, filter.Field) {
+		return "", nil, config.ErrInvalidUserQuery
+	}
+
+	escaped := likeEscaper.Replace(filter.Value)
+
+	switch filter.Operator {
+	case config.FilterEqual:
+		return " AND " + filter.Field + " = ?", []any{filter.Value}, nil
+	case config.FilterStartsWith:
+		return " AND " + filter.Field + " LIKE ?", []any{escaped + "%"}, nil
+	case config.FilterContains:
+		return " AND " + filter.Field + " LIKE ?", []any{"%" + escaped + "%"}, nil
+	default:
+		return "", nil, config.ErrInvalidUserQuery
+	}
+}
+
+// duplicate maps unique key violations to ErrUserAlreadyExists. The existence
+// check before an insert ignores soft-deleted users, whose username and email
+// still hold the unique indexes.
+func duplicate(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntry {
+		return fmt.Errorf("%w: %w", config.ErrUserAlreadyExists, err)
+	}
+	return err
 }
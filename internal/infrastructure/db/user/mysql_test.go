@@ -1,15 +1,20 @@
 package user
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"go-manage-hex/cmd/config"
 	mysqlrepo "go-manage-hex/internal/core/user"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTable(t *testing.T) {
@@ -83,7 +88,7 @@ func TestGetByUsername(t *testing.T) {
 			MockFunc: func(u mysqlrepo.User) {
 				rows := sqlmock.NewRows([]string{"id", "name", "last_name", "username", "email", "password"}).
 					AddRow(u.ID, u.Name, u.LastName, u.Username, u.Email, u.Password)
-				mock.ExpectQuery(config.GetByUsernameTest).
+				mock.ExpectQuery(regexp.QuoteMeta(config.GetByUsernameTest)).
 					WithArgs("John").WillReturnRows(rows)
 			},
 		},
@@ -93,7 +98,7 @@ func TestGetByUsername(t *testing.T) {
 			ExpectedUser: mysqlrepo.User{},
 			ExpectedErr:  sql.ErrNoRows,
 			MockFunc: func(u mysqlrepo.User) {
-				mock.ExpectQuery(config.GetByUsernameTest).
+				mock.ExpectQuery(regexp.QuoteMeta(config.GetByUsernameTest)).
 					WithArgs("John").WillReturnError(err)
 			},
 		},
@@ -103,7 +108,7 @@ func TestGetByUsername(t *testing.T) {
 			ExpectedUser: mysqlrepo.User{},
 			ExpectedErr:  sql.ErrNoRows,
 			MockFunc: func(u mysqlrepo.User) {
-				mock.ExpectQuery(config.GetByUsernameTest).
+				mock.ExpectQuery(regexp.QuoteMeta(config.GetByUsernameTest)).
 					WithArgs("John").WillReturnError(sql.ErrNoRows)
 			},
 		},
@@ -113,7 +118,7 @@ func TestGetByUsername(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc(tt.ExpectedUser)
 
-			result, err := repo.GetByUsername(tt.SearchName)
+			result, err := repo.GetByUsername(context.Background(), tt.SearchName)
 			if tt.ExpectedErr != nil {
 				assert.Error(t, err)
 			} else {
@@ -124,6 +129,63 @@ func TestGetByUsername(t *testing.T) {
 	}
 }
 
+func TestGetByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetByEmailQuery, config.GetMysqlTable()))
+
+	tests := []struct {
+		Name         string
+		Email        string
+		ExpectedUser mysqlrepo.User
+		ExpectedErr  error
+		MockFunc     func(u mysqlrepo.User)
+	}{
+		{
+			Name:         "GetByEmail_Success",
+			Email:        "john@example.com",
+			ExpectedUser: mysqlrepo.User{ID: "1", Username: "johndoe", Email: "john@example.com"},
+			ExpectedErr:  nil,
+			MockFunc: func(u mysqlrepo.User) {
+				rows := sqlmock.NewRows([]string{"id", "name", "last_name", "username", "email", "password"}).
+					AddRow(u.ID, u.Name, u.LastName, u.Username, u.Email, u.Password)
+				mock.ExpectQuery(query).
+					WithArgs("john@example.com").WillReturnRows(rows)
+			},
+		},
+		{
+			Name:         "GetByEmail_NoRows",
+			Email:        "john@example.com",
+			ExpectedUser: mysqlrepo.User{},
+			ExpectedErr:  config.ErrUserNotFound,
+			MockFunc: func(u mysqlrepo.User) {
+				mock.ExpectQuery(query).
+					WithArgs("john@example.com").WillReturnError(sql.ErrNoRows)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc(tt.ExpectedUser)
+
+			result, err := repo.GetByEmail(context.Background(), tt.Email)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedUser.Username, result.Username)
+			}
+		})
+	}
+}
+
 func TestNewUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -177,7 +239,7 @@ func TestNewUser(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc()
 
-			err := repo.NewUser(tt.NewUser)
+			err := repo.NewUser(context.Background(), tt.NewUser)
 			if err != nil {
 				assert.Error(t, err)
 			} else {
@@ -187,6 +249,30 @@ func TestNewUser(t *testing.T) {
 	}
 }
 
+func TestNewUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	users := []mysqlrepo.User{
+		{ID: "1", Name: "John", LastName: "Doe", Username: "johndoe", Email: "johndoe@example.com", Password: "hash1"},
+		{ID: "2", Name: "Jane", LastName: "Doe", Username: "janedoe", Email: "janedoe@example.com", Password: "hash2"},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(config.NewUserTest+config.NewUserValues)).
+		WithArgs("1", "John", "Doe", "johndoe", "johndoe@example.com", "hash1", "2", "Jane", "Doe", "janedoe", "janedoe@example.com", "hash2").
+		WillReturnResult(sqlmock.NewResult(2, 2))
+
+	assert.NoError(t, repo.NewUsers(context.Background(), users))
+	assert.NoError(t, repo.NewUsers(context.Background(), nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -209,7 +295,7 @@ func TestDeleteUser(t *testing.T) {
 			ExpectedErr: nil,
 			MockFunc: func() {
 				mock.ExpectExec(regexp.QuoteMeta(config.DeleteUserTest)).
-					WithArgs("johndoe").
+					WithArgs(sqlmock.AnyArg(), "johndoe").
 					WillReturnResult(sqlmock.NewResult(1, 1))
 			},
 		},
@@ -219,7 +305,7 @@ func TestDeleteUser(t *testing.T) {
 			ExpectedErr: err,
 			MockFunc: func() {
 				mock.ExpectExec(regexp.QuoteMeta(config.DeleteUserTest)).
-					WithArgs("johndoe").
+					WithArgs(sqlmock.AnyArg(), "johndoe").
 					WillReturnError(err)
 			},
 		},
@@ -229,7 +315,7 @@ func TestDeleteUser(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc()
 
-			err := repo.DeleteUser(tt.DeleteUser)
+			err := repo.DeleteUser(context.Background(), tt.DeleteUser)
 			if err != nil {
 				assert.Error(t, err)
 			} else {
@@ -239,6 +325,67 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
+func TestNewUsers_DeletedUserKeepsUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	mock.ExpectExec(regexp.QuoteMeta(config.NewUserTest)).
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'johndoe' for key 'username'"})
+
+	err = repo.NewUsers(context.Background(), []mysqlrepo.User{{ID: "1", Username: "johndoe"}})
+	assert.ErrorIs(t, err, config.ErrUserAlreadyExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	test := []struct {
+		Name        string
+		Affected    int64
+		ExpectedErr error
+	}{
+		{
+			Name:     "RestoreUser_Success",
+			Affected: 1,
+		},
+		{
+			Name:        "RestoreUser_NotDeleted",
+			Affected:    0,
+			ExpectedErr: config.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			mock.ExpectExec(regexp.QuoteMeta(config.RestoreUserTest)).
+				WithArgs("johndoe").
+				WillReturnResult(sqlmock.NewResult(0, tt.Affected))
+
+			err := repo.RestoreUser(context.Background(), "johndoe")
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestUpdateUser(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -290,7 +437,7 @@ func TestUpdateUser(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc()
 
-			err := repo.UpdateUser(tt.Update, tt.UpdateUser)
+			err := repo.UpdateUser(context.Background(), tt.Update, tt.UpdateUser)
 			if err != nil {
 				assert.Error(t, err)
 			} else {
@@ -344,7 +491,7 @@ func TestChangePwd(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc()
 
-			err := repo.ChangePwd(tt.NewPwd, tt.ChangePwdUsername)
+			err := repo.ChangePwd(context.Background(), tt.NewPwd, tt.ChangePwdUsername)
 			if err != nil {
 				assert.Error(t, err)
 			} else {
@@ -396,9 +543,258 @@ func TestCheckExists(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.MockFunc()
 
-			result := repo.CheckExists(tt.Username)
+			result := repo.CheckExists(context.Background(), tt.Username)
 
 			assert.Equal(t, result, tt.ExpectedResult)
 		})
 	}
 }
+
+func TestIsDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.IsDeletedTest, config.GetMysqlTable()))
+
+	mock.ExpectQuery(query).WithArgs("johndoe").WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
+	assert.True(t, repo.IsDeleted(context.Background(), "johndoe"))
+
+	mock.ExpectQuery(query).WithArgs("janedoe").WillReturnRows(sqlmock.NewRows([]string{"1"}))
+	assert.False(t, repo.IsDeleted(context.Background(), "janedoe"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddPwdHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	query := regexp.QuoteMeta(fmt.Sprintf(config.AddPwdHistoryQuery, config.GetPwdHistoryTable()))
+
+	test := []struct {
+		Name        string
+		ExpectedErr error
+		MockFunc    func()
+	}{
+		{
+			Name: "AddPwdHistory_Success",
+			MockFunc: func() {
+				mock.ExpectExec(query).
+					WithArgs("johndoe", "hash").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			Name:        "AddPwdHistory_Err",
+			ExpectedErr: fmt.Errorf("db error"),
+			MockFunc: func() {
+				mock.ExpectExec(query).
+					WillReturnError(fmt.Errorf("db error"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			err := repo.AddPwdHistory(context.Background(), "johndoe", "hash")
+			if tt.ExpectedErr != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetPwdHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetPwdHistoryQuery, config.GetPwdHistoryTable()))
+
+	test := []struct {
+		Name            string
+		ExpectedHistory []string
+		ExpectedErr     error
+		MockFunc        func()
+	}{
+		{
+			Name:            "GetPwdHistory_Success",
+			ExpectedHistory: []string{"hash2", "hash1"},
+			MockFunc: func() {
+				rows := sqlmock.NewRows([]string{"password"}).AddRow("hash2").AddRow("hash1")
+				mock.ExpectQuery(query).
+					WithArgs("johndoe", 5).
+					WillReturnRows(rows)
+			},
+		},
+		{
+			Name:        "GetPwdHistory_Err",
+			ExpectedErr: fmt.Errorf("db error"),
+			MockFunc: func() {
+				mock.ExpectQuery(query).
+					WillReturnError(fmt.Errorf("db error"))
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			history, err := repo.GetPwdHistory(context.Background(), "johndoe", 5)
+			if tt.ExpectedErr != nil {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ExpectedHistory, history)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestGetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetByIDQuery, config.GetMysqlTable()))
+
+	rows := sqlmock.NewRows([]string{"id", "name", "last_name", "username", "email", "password"}).
+		AddRow("1", "John", "Doe", "johndoe", "john@example.com", "hash")
+	mock.ExpectQuery(query).WithArgs("1").WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs("2").WillReturnError(sql.ErrNoRows)
+
+	user, err := repo.GetByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "johndoe", user.Username)
+
+	_, err = repo.GetByID(context.Background(), "2")
+	assert.ErrorIs(t, err, config.ErrUserNotFound)
+}
+
+func TestGetByIDWithDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+	query := regexp.QuoteMeta(fmt.Sprintf(config.GetByIDWithDeletedQuery, config.GetMysqlTable()))
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	columns := []string{"id", "name", "last_name", "username", "email", "password", "deleted_at"}
+	mock.ExpectQuery(query).WithArgs("1").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("1", "John", "Doe", "johndoe", "john@example.com", "hash", nil))
+	mock.ExpectQuery(query).WithArgs("2").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow("2", "Jane", "Doe", "janedoe", "jane@example.com", "hash", deletedAt))
+	mock.ExpectQuery(query).WithArgs("3").WillReturnError(sql.ErrNoRows)
+
+	user, err := repo.GetByIDWithDeleted(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Nil(t, user.DeletedAt)
+
+	user, err = repo.GetByIDWithDeleted(context.Background(), "2")
+	assert.NoError(t, err)
+	require.NotNil(t, user.DeletedAt)
+	assert.Equal(t, deletedAt, *user.DeletedAt)
+
+	_, err = repo.GetByIDWithDeleted(context.Background(), "3")
+	assert.ErrorIs(t, err, config.ErrUserNotFound)
+}
+
+func TestListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewUserMysql(db)
+
+	test := []struct {
+		Name          string
+		Query         mysqlrepo.UserQuery
+		Where         string
+		Args          []driver.Value
+		ExpectedCount int
+		ExpectedErr   error
+	}{
+		{
+			Name:          "ListUsers_NoFilter",
+			Query:         mysqlrepo.UserQuery{Limit: 10},
+			ExpectedCount: 1,
+		},
+		{
+			Name:          "ListUsers_Equal",
+			Query:         mysqlrepo.UserQuery{Filter: &mysqlrepo.UserFilter{Field: config.UserColumnUsername, Operator: config.FilterEqual, Value: "johndoe"}, Limit: 10},
+			Where:         " AND username = ?",
+			Args:          []driver.Value{"johndoe"},
+			ExpectedCount: 1,
+		},
+		{
+			Name:          "ListUsers_StartsWithEscapesWildcards",
+			Query:         mysqlrepo.UserQuery{Filter: &mysqlrepo.UserFilter{Field: config.UserColumnEmail, Operator: config.FilterStartsWith, Value: "john_%"}, Limit: 10, Offset: 5},
+			Where:         " AND email LIKE ?",
+			Args:          []driver.Value{`john\_\%%`},
+			ExpectedCount: 1,
+		},
+		{
+			Name:        "ListUsers_UnknownColumn",
+			Query:       mysqlrepo.UserQuery{Filter: &mysqlrepo.UserFilter{Field: "password", Operator: config.FilterEqual, Value: "x"}, Limit: 10},
+			ExpectedErr: config.ErrInvalidUserQuery,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			if tt.ExpectedErr == nil {
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.CountUsersQuery, config.GetMysqlTable(), tt.Where))).
+					WithArgs(tt.Args...).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.ExpectedCount))
+
+				rows := sqlmock.NewRows([]string{"id", "name", "last_name", "username", "email", "password"}).
+					AddRow("1", "John", "Doe", "johndoe", "john@example.com", "hash")
+				mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.ListUsersQuery, config.GetMysqlTable(), tt.Where))).
+					WithArgs(append(tt.Args, tt.Query.Limit, tt.Query.Offset)...).
+					WillReturnRows(rows)
+			}
+
+			users, total, err := repo.ListUsers(context.Background(), tt.Query)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectedCount, total)
+			assert.Len(t, users, tt.ExpectedCount)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
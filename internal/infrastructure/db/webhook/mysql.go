@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+	"go-manage-hex/internal/infrastructure/db"
+	"strings"
+	"time"
+)
+
+type SubscriptionMysql struct {
+	DB db.Executor
+}
+
+func NewSubscriptionMysql(conn db.Executor) entity.SubscriptionRepository {
+	return &SubscriptionMysql{DB: conn}
+}
+
+func (sm *SubscriptionMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateWebhookTableQuery, tableName)
+
+	_, err := sm.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sm *SubscriptionMysql) NewSubscription(sub entity.Subscription) error {
+	query := fmt.Sprintf(config.NewWebhookQuery, config.GetWebhookTable())
+
+	_, err := sm.DB.Exec(query, sub.ID, sub.URL, strings.Join(sub.EventTypes, ","), sub.Secret, sub.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (sm *SubscriptionMysql) GetSubscription(id string) (entity.Subscription, error) {
+	query := fmt.Sprintf(config.GetWebhookQuery, config.GetWebhookTable())
+
+	sub, err := scanSubscription(sm.DB.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Subscription{}, config.ErrWebhookNotFound
+	}
+	if err != nil {
+		return entity.Subscription{}, err
+	}
+
+	return sub, nil
+}
+
+func (sm *SubscriptionMysql) ListSubscriptions() ([]entity.Subscription, error) {
+	query := fmt.Sprintf(config.ListWebhooksQuery, config.GetWebhookTable())
+
+	rows, err := sm.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []entity.Subscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (sm *SubscriptionMysql) DeleteSubscription(id string) error {
+	query := fmt.Sprintf(config.DeleteWebhookQuery, config.GetWebhookTable())
+
+	result, err := sm.DB.Exec(query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return config.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+type DeliveryMysql struct {
+	DB db.Executor
+}
+
+func NewDeliveryMysql(conn db.Executor) entity.DeliveryRepository {
+	return &DeliveryMysql{DB: conn}
+}
+
+func (dm *DeliveryMysql) CreateTable(tableName string) error {
+	query := fmt.Sprintf(config.CreateDeliveryTableQuery, tableName)
+
+	_, err := dm.DB.Exec(query)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dm *DeliveryMysql) NewDelivery(delivery entity.Delivery) error {
+	query := fmt.Sprintf(config.NewDeliveryQuery, config.GetDeliveryTable())
+
+	_, err := dm.DB.Exec(query,
+		delivery.ID,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		string(delivery.Payload),
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dm *DeliveryMysql) GetDelivery(id string) (entity.Delivery, error) {
+	query := fmt.Sprintf(config.GetDeliveryQuery, config.GetDeliveryTable())
+
+	delivery, err := scanDelivery(dm.DB.QueryRow(query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Delivery{}, config.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func (dm *DeliveryMysql) ListDeliveries(subscriptionID string, limit int) ([]entity.Delivery, error) {
+	query := fmt.Sprintf(config.ListDeliveriesQuery, config.GetDeliveryTable())
+
+	return dm.queryDeliveries(query, subscriptionID, limit)
+}
+
+func (dm *DeliveryMysql) Due(now time.Time, limit int) ([]entity.Delivery, error) {
+	query := fmt.Sprintf(config.DueDeliveriesQuery, config.GetDeliveryTable())
+
+	return dm.queryDeliveries(query, config.DeliveryPending, now, limit)
+}
+
+// Claim pushes next_attempt_at to leaseUntil if the delivery is still due,
+// so when several replicas flush the same batch only one of them sees a row
+// affected and sends it. A dispatcher that dies mid-send leaves the delivery
+// to be retried once the lease runs out.
+func (dm *DeliveryMysql) Claim(id string, now, leaseUntil time.Time) (bool, error) {
+	query := fmt.Sprintf(config.ClaimDeliveryQuery, config.GetDeliveryTable())
+
+	result, err := dm.DB.Exec(query, leaseUntil, id, config.DeliveryPending, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (dm *DeliveryMysql) UpdateDelivery(delivery entity.Delivery) error {
+	query := fmt.Sprintf(config.UpdateDeliveryQuery, config.GetDeliveryTable())
+
+	_, err := dm.DB.Exec(query,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.LastStatusCode,
+		nullString(delivery.LastError),
+		delivery.NextAttemptAt,
+		nullTime(delivery.DeliveredAt),
+		delivery.ID,
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dm *DeliveryMysql) queryDeliveries(query string, args ...any) ([]entity.Delivery, error) {
+	rows, err := dm.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []entity.Delivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSubscription(row scanner) (entity.Subscription, error) {
+	var (
+		sub        entity.Subscription
+		eventTypes string
+	)
+
+	if err := row.Scan(&sub.ID, &sub.URL, &eventTypes, &sub.Secret, &sub.CreatedAt); err != nil {
+		return entity.Subscription{}, err
+	}
+
+	sub.EventTypes = []string{}
+	if eventTypes != "" {
+		sub.EventTypes = strings.Split(eventTypes, ",")
+	}
+
+	return sub, nil
+}
+
+func scanDelivery(row scanner) (entity.Delivery, error) {
+	var (
+		delivery    entity.Delivery
+		payload     string
+		lastError   sql.NullString
+		deliveredAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.LastStatusCode,
+		&lastError,
+		&delivery.NextAttemptAt,
+		&delivery.CreatedAt,
+		&deliveredAt,
+	)
+	if err != nil {
+		return entity.Delivery{}, err
+	}
+
+	delivery.Payload = []byte(payload)
+	delivery.LastError = lastError.String
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return delivery, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"database/sql"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var deliveryColumns = []string{"id", "subscription_id", "event_id", "event_type", "payload", "status", "attempts", "last_status_code", "last_error", "next_attempt_at", "created_at", "delivered_at"}
+
+func TestSubscriptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewSubscriptionMysql(db)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.NewWebhookQuery, config.GetWebhookTable()))).
+		WithArgs("sub-1", "https://partner.example.com", "user.created,user.deleted", "secret", created).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetWebhookQuery, config.GetWebhookTable()))).
+		WithArgs("sub-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "event_types", "secret", "created_at"}).
+			AddRow("sub-1", "https://partner.example.com", "user.created,user.deleted", "secret", created))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetWebhookQuery, config.GetWebhookTable()))).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.DeleteWebhookQuery, config.GetWebhookTable()))).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = repo.NewSubscription(entity.Subscription{
+		ID:         "sub-1",
+		URL:        "https://partner.example.com",
+		EventTypes: []string{config.EventUserCreated, config.EventUserDeleted},
+		Secret:     "secret",
+		CreatedAt:  created,
+	})
+	assert.NoError(t, err)
+
+	sub, err := repo.GetSubscription("sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{config.EventUserCreated, config.EventUserDeleted}, sub.EventTypes)
+
+	_, err = repo.GetSubscription("missing")
+	assert.ErrorIs(t, err, config.ErrWebhookNotFound)
+
+	assert.ErrorIs(t, repo.DeleteSubscription("missing"), config.ErrWebhookNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	repo := NewDeliveryMysql(db)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.NewDeliveryQuery, config.GetDeliveryTable()))).
+		WithArgs("delivery-1", "sub-1", "evt-1", config.EventUserCreated, `{"id":"evt-1"}`, config.DeliveryPending, 0, now, now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.DueDeliveriesQuery, config.GetDeliveryTable()))).
+		WithArgs(config.DeliveryPending, now, 10).
+		WillReturnRows(sqlmock.NewRows(deliveryColumns).
+			AddRow("delivery-1", "sub-1", "evt-1", config.EventUserCreated, `{"id":"evt-1"}`, config.DeliveryPending, 2, 500, "status 500", now, now, nil))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimDeliveryQuery, config.GetDeliveryTable()))).
+		WithArgs(now.Add(time.Minute), "delivery-1", config.DeliveryPending, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.ClaimDeliveryQuery, config.GetDeliveryTable()))).
+		WithArgs(now.Add(time.Minute), "delivery-1", config.DeliveryPending, now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(config.UpdateDeliveryQuery, config.GetDeliveryTable()))).
+		WithArgs(config.DeliveryDelivered, 3, 200, sql.NullString{}, now, sql.NullTime{Time: now, Valid: true}, "delivery-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(config.GetDeliveryQuery, config.GetDeliveryTable()))).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	err = repo.NewDelivery(entity.Delivery{
+		ID:             "delivery-1",
+		SubscriptionID: "sub-1",
+		EventID:        "evt-1",
+		EventType:      config.EventUserCreated,
+		Payload:        []byte(`{"id":"evt-1"}`),
+		Status:         config.DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	})
+	assert.NoError(t, err)
+
+	due, err := repo.Due(now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "status 500", due[0].LastError)
+		assert.Nil(t, due[0].DeliveredAt)
+	}
+
+	claimed, err := repo.Claim("delivery-1", now, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = repo.Claim("delivery-1", now, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	delivered := due[0]
+	delivered.Status = config.DeliveryDelivered
+	delivered.Attempts = 3
+	delivered.LastStatusCode = 200
+	delivered.LastError = ""
+	delivered.DeliveredAt = &now
+	assert.NoError(t, repo.UpdateDelivery(delivered))
+
+	_, err = repo.GetDelivery("missing")
+	assert.ErrorIs(t, err, config.ErrDeliveryNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
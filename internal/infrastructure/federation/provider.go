@@ -0,0 +1,260 @@
+package federation
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type OIDCProvider struct {
+	ProviderName string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Client       *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]*rsa.PublicKey
+}
+
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string) *OIDCProvider {
+	return &OIDCProvider{
+		ProviderName: name,
+		Issuer:       strings.TrimSuffix(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (op *OIDCProvider) Name() string {
+	return op.ProviderName
+}
+
+func (op *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := op.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", op.ClientID)
+	params.Set("redirect_uri", op.RedirectURL)
+	params.Set("scope", strings.Join(config.OIDCScopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", config.PKCEMethodS256)
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+func (op *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (entity.Claims, error) {
+	doc, err := op.discover(ctx)
+	if err != nil {
+		return entity.Claims{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", op.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", op.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return entity.Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if op.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(op.ClientID), url.QueryEscape(op.ClientSecret))
+	}
+
+	resp, err := op.Client.Do(req)
+	if err != nil {
+		return entity.Claims{}, fmt.Errorf("%w: %v", config.ErrProviderExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return entity.Claims{}, fmt.Errorf("%w: status %d", config.ErrProviderExchange, resp.StatusCode)
+	}
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.IDToken == "" {
+		return entity.Claims{}, config.ErrProviderExchange
+	}
+
+	return op.verify(ctx, doc, token.IDToken, nonce)
+}
+
+func (op *OIDCProvider) verify(ctx context.Context, doc *discoveryDocument, idToken, nonce string) (entity.Claims, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return op.publicKey(ctx, doc, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(op.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return entity.Claims{}, fmt.Errorf("%w: %v", config.ErrInvalidIDToken, err)
+	}
+
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return entity.Claims{}, fmt.Errorf("%w: nonce mismatch", config.ErrInvalidIDToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return entity.Claims{}, fmt.Errorf("%w: missing subject", config.ErrInvalidIDToken)
+	}
+
+	return entity.Claims{
+		Subject:           subject,
+		Email:             stringClaim(claims, "email"),
+		EmailVerified:     boolClaim(claims, "email_verified"),
+		GivenName:         stringClaim(claims, "given_name"),
+		FamilyName:        stringClaim(claims, "family_name"),
+		PreferredUsername: stringClaim(claims, "preferred_username"),
+	}, nil
+}
+
+func (op *OIDCProvider) discover(ctx context.Context) (*discoveryDocument, error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if op.discovery != nil {
+		return op.discovery, nil
+	}
+
+	var doc discoveryDocument
+	if err := op.getJSON(ctx, op.Issuer+config.OIDCDiscoveryPath, &doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Issuer != op.Issuer || doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, config.ErrProviderDiscovery
+	}
+
+	op.discovery = &doc
+	return op.discovery, nil
+}
+
+func (op *OIDCProvider) publicKey(ctx context.Context, doc *discoveryDocument, kid string) (*rsa.PublicKey, error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if key, ok := op.keys[kid]; ok {
+		return key, nil
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := op.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	op.keys = map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, nErr := base64.RawURLEncoding.DecodeString(k.N)
+		e, eErr := base64.RawURLEncoding.DecodeString(k.E)
+		if nErr != nil || eErr != nil {
+			continue
+		}
+		op.keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	key, ok := op.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown signing key", config.ErrInvalidIDToken)
+	}
+	return key, nil
+}
+
+func (op *OIDCProvider) getJSON(ctx context.Context, endpoint string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := op.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", config.ErrProviderDiscovery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status %d", config.ErrProviderDiscovery, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func stringClaim(claims jwt.MapClaims, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+func boolClaim(claims jwt.MapClaims, key string) bool {
+	switch value := claims[key].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
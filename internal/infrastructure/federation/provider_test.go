@@ -0,0 +1,190 @@
+package federation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	audience string
+	nonce    string
+	verifier string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	idp := &fakeIdP{key: key, audience: "client-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.OIDCDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		idp.nonce = query.Get("nonce")
+
+		callback, _ := url.Parse(query.Get("redirect_uri"))
+		callback.RawQuery = url.Values{"code": {"upstream-code"}, "state": {query.Get("state")}}.Encode()
+		http.Redirect(w, r, callback.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client-1" || secret != "s3cret" || r.FormValue("code") != "upstream-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		idp.verifier = r.FormValue("code_verifier")
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":                idp.server.URL,
+			"aud":                idp.audience,
+			"sub":                "upstream-42",
+			"email":              "john@example.com",
+			"email_verified":     true,
+			"given_name":         "John",
+			"family_name":        "Doe",
+			"preferred_username": "johndoe",
+			"nonce":              idp.nonce,
+			"iat":                time.Now().Unix(),
+			"exp":                time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = "test-key"
+		signed, _ := token.SignedString(idp.key)
+
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+		}}})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *fakeIdP) authorize(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	test := []struct {
+		Name        string
+		Audience    string
+		Nonce       string
+		ExpectedErr error
+	}{
+		{
+			Name:     "Exchange_Success",
+			Audience: "client-1",
+			Nonce:    "nonce-1",
+		},
+		{
+			Name:        "Exchange_WrongAudience",
+			Audience:    "someone-else",
+			Nonce:       "nonce-1",
+			ExpectedErr: config.ErrInvalidIDToken,
+		},
+		{
+			Name:        "Exchange_NonceMismatch",
+			Audience:    "client-1",
+			Nonce:       "other-nonce",
+			ExpectedErr: config.ErrInvalidIDToken,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			idp := newFakeIdP(t)
+			idp.audience = tt.Audience
+			provider := NewOIDCProvider("acme", idp.server.URL, "client-1", "s3cret", "http://localhost/callback")
+
+			authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge")
+			require.NoError(t, err)
+
+			code, state := idp.authorize(t, authURL)
+			assert.Equal(t, "state-1", state)
+
+			claims, err := provider.Exchange(context.Background(), code, "verifier-1", tt.Nonce)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "verifier-1", idp.verifier)
+			assert.Equal(t, "upstream-42", claims.Subject)
+			assert.Equal(t, "john@example.com", claims.Email)
+			assert.True(t, claims.EmailVerified)
+			assert.Equal(t, "johndoe", claims.PreferredUsername)
+		})
+	}
+}
+
+func TestOIDCProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	idp := newFakeIdP(t)
+	provider := NewOIDCProvider("acme", idp.server.URL+"/other", "client-1", "s3cret", "http://localhost/callback")
+
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+
+	assert.ErrorIs(t, err, config.ErrProviderDiscovery)
+}
+
+func TestMemoryStateStore(t *testing.T) {
+	store := NewMemoryStateStore()
+
+	require.NoError(t, store.Save(pendingLogin("state-1")))
+
+	pending, err := store.Consume("state-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme", pending.Provider)
+
+	_, err = store.Consume("state-1")
+	assert.ErrorIs(t, err, config.ErrInvalidState)
+}
+
+func pendingLogin(state string) entity.PendingLogin {
+	return entity.PendingLogin{State: state, Provider: "acme", ExpiresAt: time.Now().Add(time.Minute)}
+}
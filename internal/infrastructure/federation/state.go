@@ -0,0 +1,53 @@
+package federation
+
+import (
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/federation"
+)
+
+// MemoryStateStore keeps pending logins in process. With several replicas
+// the callback may land on one that never saw the login, so the server
+// uses StateMysql; this one suits single-process tools and tests.
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	pending map[string]entity.PendingLogin
+	now     func() time.Time
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{
+		pending: map[string]entity.PendingLogin{},
+		now:     time.Now,
+	}
+}
+
+func (ms *MemoryStateStore) Save(pending entity.PendingLogin) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	for k, p := range ms.pending {
+		if !now.Before(p.ExpiresAt) {
+			delete(ms.pending, k)
+		}
+	}
+
+	ms.pending[pending.State] = pending
+	return nil
+}
+
+func (ms *MemoryStateStore) Consume(state string) (entity.PendingLogin, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	found, ok := ms.pending[state]
+	if !ok {
+		return entity.PendingLogin{}, config.ErrInvalidState
+	}
+	delete(ms.pending, state)
+
+	return found, nil
+}
@@ -0,0 +1,69 @@
+package hasher
+
+import (
+	"context"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/user"
+)
+
+type scheme interface {
+	entity.PasswordHasher
+	Matches(encoded string) bool
+}
+
+type MultiHasher struct {
+	Preferred scheme
+	Schemes   []scheme
+}
+
+func NewPasswordHasher(algorithm string, bcryptCost int, argon2Params Argon2Params) (entity.PasswordHasher, error) {
+	bcryptHasher := NewBcryptHasher(bcryptCost)
+	argon2Hasher := NewArgon2Hasher(argon2Params)
+
+	schemes := []scheme{argon2Hasher, bcryptHasher}
+
+	switch algorithm {
+	case config.HashArgon2id:
+		return &MultiHasher{Preferred: argon2Hasher, Schemes: schemes}, nil
+	case config.HashBcrypt:
+		return &MultiHasher{Preferred: bcryptHasher, Schemes: schemes}, nil
+	default:
+		return nil, config.ErrUnknownHashAlgorithm
+	}
+}
+
+func (m *MultiHasher) Hash(ctx context.Context, password string) (string, error) {
+	return m.Preferred.Hash(ctx, password)
+}
+
+func (m *MultiHasher) Verify(ctx context.Context, encoded, password string) (bool, error) {
+	s := m.schemeFor(encoded)
+	if s == nil {
+		return false, config.ErrInvalidHash
+	}
+	return s.Verify(ctx, encoded, password)
+}
+
+func (m *MultiHasher) NeedsRehash(encoded string) bool {
+	if !m.Preferred.Matches(encoded) {
+		return true
+	}
+	return m.Preferred.NeedsRehash(encoded)
+}
+
+// Supports reports whether encoded is a well-formed hash of a known scheme,
+// which is what pre-hashed imports must provide.
+func (m *MultiHasher) Supports(encoded string) bool {
+	s := m.schemeFor(encoded)
+	return s != nil && s.Supports(encoded)
+}
+
+func (m *MultiHasher) schemeFor(encoded string) scheme {
+	for _, s := range m.Schemes {
+		if s.Matches(encoded) {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package hasher
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/user"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2Params = Argon2Params{
+	Memory:      1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+func TestArgon2Hasher(t *testing.T) {
+	h := NewArgon2Hasher(testArgon2Params)
+
+	encoded, err := h.Hash(context.Background(), "Password1234")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := h.Verify(context.Background(), encoded, "Password1234")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(context.Background(), encoded, "Password123")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(encoded))
+
+	stronger := NewArgon2Hasher(Argon2Params{Memory: 2048, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	assert.True(t, stronger.NeedsRehash(encoded))
+
+	_, err = h.Verify(context.Background(), "$argon2id$v=19$broken", "Password1234")
+	assert.ErrorIs(t, err, config.ErrInvalidHash)
+}
+
+func TestArgon2Hasher_UntrustedParams(t *testing.T) {
+	h := NewArgon2Hasher(testArgon2Params)
+	salt := "c2FsdHNhbHRzYWx0c2FsdA"
+	key := "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+
+	tests := []struct {
+		Name    string
+		Encoded string
+	}{
+		{Name: "ZeroParallelism", Encoded: "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key},
+		{Name: "ZeroIterations", Encoded: "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key},
+		{Name: "ZeroMemory", Encoded: "$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key},
+		{Name: "HugeMemory", Encoded: "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key},
+		{Name: "HugeIterations", Encoded: "$argon2id$v=19$m=1024,t=100000,p=1$" + salt + "$" + key},
+		{Name: "EmptySalt", Encoded: "$argon2id$v=19$m=1024,t=1,p=1$$" + key},
+		{Name: "EmptyKey", Encoded: "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			ok, err := h.Verify(context.Background(), tt.Encoded, "anything")
+			assert.ErrorIs(t, err, config.ErrInvalidHash)
+			assert.False(t, ok)
+			assert.False(t, h.Supports(tt.Encoded))
+		})
+	}
+}
+
+func TestBcryptHasher(t *testing.T) {
+	h := NewBcryptHasher(bcrypt.MinCost)
+
+	encoded, err := h.Hash(context.Background(), "Password1234")
+	assert.NoError(t, err)
+	assert.True(t, h.Matches(encoded))
+
+	ok, err := h.Verify(context.Background(), encoded, "Password1234")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify(context.Background(), encoded, "Password123")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(encoded))
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(encoded))
+}
+
+func TestMultiHasher(t *testing.T) {
+	test := []struct {
+		Name        string
+		Algorithm   string
+		LegacyHash  func() string
+		ExpectedErr error
+		NeedsRehash bool
+	}{
+		{
+			Name:      "Argon2idPreferred_BcryptLegacy",
+			Algorithm: config.HashArgon2id,
+			LegacyHash: func() string {
+				hash, _ := NewBcryptHasher(bcrypt.MinCost).Hash(context.Background(), "Password1234")
+				return hash
+			},
+			NeedsRehash: true,
+		},
+		{
+			Name:      "BcryptPreferred_Argon2idLegacy",
+			Algorithm: config.HashBcrypt,
+			LegacyHash: func() string {
+				hash, _ := NewArgon2Hasher(testArgon2Params).Hash(context.Background(), "Password1234")
+				return hash
+			},
+			NeedsRehash: true,
+		},
+		{
+			Name:        "UnknownAlgorithm",
+			Algorithm:   "md5",
+			ExpectedErr: config.ErrUnknownHashAlgorithm,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			h, err := NewPasswordHasher(tt.Algorithm, bcrypt.MinCost, testArgon2Params)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			legacy := tt.LegacyHash()
+
+			ok, err := h.Verify(context.Background(), legacy, "Password1234")
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.NeedsRehash, h.NeedsRehash(legacy))
+
+			current, err := h.Hash(context.Background(), "Password1234")
+			assert.NoError(t, err)
+			assert.False(t, h.NeedsRehash(current))
+
+			_, err = h.Verify(context.Background(), "plaintext", "Password1234")
+			assert.ErrorIs(t, err, config.ErrInvalidHash)
+		})
+	}
+}
+
+func TestSupports(t *testing.T) {
+	h, err := NewPasswordHasher(config.HashArgon2id, bcrypt.MinCost, testArgon2Params)
+	assert.NoError(t, err)
+
+	argon2Hash, _ := NewArgon2Hasher(testArgon2Params).Hash(context.Background(), "Password1234")
+	bcryptHash, _ := NewBcryptHasher(bcrypt.MinCost).Hash(context.Background(), "Password1234")
+
+	assert.True(t, h.Supports(argon2Hash))
+	assert.True(t, h.Supports(bcryptHash))
+	assert.False(t, h.Supports(bcryptHash[:30]))
+	assert.False(t, h.Supports("$argon2id$v=19$broken"))
+	assert.False(t, h.Supports("5f4dcc3b5aa765d61d8327deb882cf99"))
+}
+
+type blockingHasher struct {
+	entity.PasswordHasher
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingHasher) Hash(ctx context.Context, password string) (string, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return "hash", nil
+}
+
+func TestPooledHasher(t *testing.T) {
+	next := &blockingHasher{started: make(chan struct{}), release: make(chan struct{})}
+	pool := NewPooledHasher(next, 1, 20*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		_, err := pool.Hash(context.Background(), "first")
+		done <- err
+	}()
+	<-next.started
+	assert.Equal(t, 1, pool.InUse())
+
+	_, err := pool.Hash(context.Background(), "second")
+	assert.ErrorIs(t, err, config.ErrHasherBusy)
+	assert.Equal(t, uint64(1), pool.Rejected())
+	assert.Equal(t, 0, pool.Waiting())
+
+	close(next.release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, pool.InUse())
+
+	go func() { <-next.started }()
+	hash, err := pool.Hash(context.Background(), "third")
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", hash)
+	assert.Equal(t, 1, pool.Size())
+}
+
+func TestPooledHasher_Cancelled(t *testing.T) {
+	next := &blockingHasher{started: make(chan struct{}), release: make(chan struct{})}
+	pool := NewPooledHasher(next, 1, time.Minute)
+
+	go pool.Hash(context.Background(), "first")
+	<-next.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := pool.Hash(ctx, "second")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, pool.Rejected())
+
+	close(next.release)
+}
+
+// gateHasher holds every "import" hash until release is closed.
+type gateHasher struct {
+	entity.PasswordHasher
+	started chan struct{}
+	release chan struct{}
+}
+
+func (g *gateHasher) Hash(ctx context.Context, password string) (string, error) {
+	if password == "import" {
+		g.started <- struct{}{}
+		<-g.release
+	}
+	return "hash", nil
+}
+
+func TestPooledHasher_Background(t *testing.T) {
+	next := &gateHasher{started: make(chan struct{}, 2), release: make(chan struct{})}
+	pool := NewPooledHasher(next, 2, 20*time.Millisecond)
+	background := entity.WithBackground(context.Background())
+
+	done := make(chan error)
+	for range 2 {
+		go func() {
+			_, err := pool.Hash(background, "import")
+			done <- err
+		}()
+	}
+	<-next.started
+
+	// Background work holds half the pool; the other slot serves logins.
+	_, err := pool.Hash(context.Background(), "login")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pool.InUse())
+
+	// The second import waits past QueueTimeout without being rejected.
+	time.Sleep(40 * time.Millisecond)
+	assert.Zero(t, pool.Rejected())
+	assert.Zero(t, pool.Waiting())
+
+	close(next.release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, <-done)
+	assert.Zero(t, pool.InUse())
+}
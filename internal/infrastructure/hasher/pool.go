@@ -0,0 +1,127 @@
+package hasher
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/user"
+)
+
+// PooledHasher runs Hash and Verify on at most Size goroutines at once, so a
+// burst of logins queues up instead of taking every CPU (and, with argon2id,
+// its memory) away from the other requests. A call that waits longer than
+// QueueTimeout, or whose context ends first, fails without hashing.
+//
+// Calls on a context marked with user.WithBackground wait for a slot as long
+// as their context lives and never time out. At most half the slots serve
+// them at once, so logins keep the rest. Waiting and Rejected only count the
+// other calls, since those are the ones a user is waiting on.
+type PooledHasher struct {
+	entity.PasswordHasher
+	QueueTimeout time.Duration
+
+	slots      chan struct{}
+	background chan struct{}
+	waiting    atomic.Int64
+	rejected   atomic.Uint64
+}
+
+func NewPooledHasher(next entity.PasswordHasher, size int, queueTimeout time.Duration) *PooledHasher {
+	size = max(size, 1)
+	return &PooledHasher{
+		PasswordHasher: next,
+		QueueTimeout:   queueTimeout,
+		slots:          make(chan struct{}, size),
+		background:     make(chan struct{}, max(size/2, 1)),
+	}
+}
+
+func (ph *PooledHasher) Hash(ctx context.Context, password string) (string, error) {
+	if err := ph.acquire(ctx); err != nil {
+		return "", err
+	}
+	defer ph.release(ctx)
+
+	return ph.PasswordHasher.Hash(ctx, password)
+}
+
+func (ph *PooledHasher) Verify(ctx context.Context, encoded, password string) (bool, error) {
+	if err := ph.acquire(ctx); err != nil {
+		return false, err
+	}
+	defer ph.release(ctx)
+
+	return ph.PasswordHasher.Verify(ctx, encoded, password)
+}
+
+func (ph *PooledHasher) Size() int {
+	return cap(ph.slots)
+}
+
+func (ph *PooledHasher) InUse() int {
+	return len(ph.slots)
+}
+
+func (ph *PooledHasher) Waiting() int {
+	return int(ph.waiting.Load())
+}
+
+func (ph *PooledHasher) Rejected() uint64 {
+	return ph.rejected.Load()
+}
+
+func (ph *PooledHasher) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if entity.IsBackground(ctx) {
+		return ph.acquireBackground(ctx)
+	}
+
+	select {
+	case ph.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	ph.waiting.Add(1)
+	defer ph.waiting.Add(-1)
+
+	timer := time.NewTimer(ph.QueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case ph.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		ph.rejected.Add(1)
+		return config.ErrHasherBusy
+	}
+}
+
+func (ph *PooledHasher) acquireBackground(ctx context.Context) error {
+	select {
+	case ph.background <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case ph.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		<-ph.background
+		return ctx.Err()
+	}
+}
+
+func (ph *PooledHasher) release(ctx context.Context) {
+	<-ph.slots
+	if entity.IsBackground(ctx) {
+		<-ph.background
+	}
+}
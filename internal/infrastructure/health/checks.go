@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/health"
+	"go-manage-hex/internal/core/migration"
+	"go-manage-hex/internal/infrastructure/oidc"
+)
+
+type CheckFunc struct {
+	CheckName string
+	Fn        func(ctx context.Context) error
+}
+
+func NewCheck(name string, fn func(ctx context.Context) error) entity.Checker {
+	return &CheckFunc{CheckName: name, Fn: fn}
+}
+
+func (cf *CheckFunc) Name() string {
+	return cf.CheckName
+}
+
+func (cf *CheckFunc) Check(ctx context.Context) error {
+	return cf.Fn(ctx)
+}
+
+func NewDBCheck(db *sql.DB) entity.Checker {
+	return NewCheck("mysql", db.PingContext)
+}
+
+// NewMigrationsCheck is down until every known schema version is applied,
+// so a replica running newer code than the database never takes traffic.
+func NewMigrationsCheck(migrator migration.Migrator) entity.Checker {
+	return NewCheck("migrations", func(ctx context.Context) error {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		var pending []string
+		for _, status := range statuses {
+			if status.AppliedAt == nil {
+				pending = append(pending, fmt.Sprintf("%d %s", status.Version, status.Name))
+			}
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("%w: %s", config.ErrPendingMigrations, strings.Join(pending, ", "))
+		}
+		return nil
+	})
+}
+
+func NewSigningKeyCheck(signer *oidc.RSASigner) entity.Checker {
+	return NewCheck("signing_key", func(ctx context.Context) error {
+		if signer == nil {
+			return config.ErrSigningKeyMissing
+		}
+		key, _ := signer.SigningKey()
+		if key == nil {
+			return config.ErrSigningKeyMissing
+		}
+		return key.Validate()
+	})
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/migration"
+	"go-manage-hex/internal/infrastructure/oidc"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMigrator struct {
+	statuses []migration.VersionStatus
+	err      error
+}
+
+func (fm *fakeMigrator) Up(ctx context.Context) error {
+	return nil
+}
+
+func (fm *fakeMigrator) Down(ctx context.Context, steps int) error {
+	return nil
+}
+
+func (fm *fakeMigrator) Status(ctx context.Context) ([]migration.VersionStatus, error) {
+	return fm.statuses, fm.err
+}
+
+func TestMigrationsCheck(t *testing.T) {
+	applied := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		Name          string
+		Migrator      *fakeMigrator
+		ExpectedError error
+	}{
+		{
+			Name: "AllApplied",
+			Migrator: &fakeMigrator{statuses: []migration.VersionStatus{
+				{Version: 1, Name: "baseline", AppliedAt: &applied},
+				{Version: 2, Name: "widen_user_password", AppliedAt: &applied},
+			}},
+		},
+		{
+			Name: "Pending",
+			Migrator: &fakeMigrator{statuses: []migration.VersionStatus{
+				{Version: 1, Name: "baseline", AppliedAt: &applied},
+				{Version: 2, Name: "widen_user_password"},
+			}},
+			ExpectedError: config.ErrPendingMigrations,
+		},
+		{
+			Name:          "StatusError",
+			Migrator:      &fakeMigrator{err: errors.New("connection lost")},
+			ExpectedError: errors.New("connection lost"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			checkErr := NewMigrationsCheck(tt.Migrator).Check(context.Background())
+
+			switch {
+			case tt.ExpectedError == nil:
+				assert.NoError(t, checkErr)
+			case errors.Is(tt.ExpectedError, config.ErrPendingMigrations):
+				assert.ErrorIs(t, checkErr, config.ErrPendingMigrations)
+				assert.Contains(t, checkErr.Error(), "2 widen_user_password")
+			default:
+				assert.EqualError(t, checkErr, tt.ExpectedError.Error())
+			}
+		})
+	}
+}
+
+func TestDBCheck(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectPing()
+	assert.NoError(t, NewDBCheck(db).Check(context.Background()))
+
+	mock.ExpectPing().WillReturnError(errors.New("bad connection"))
+	assert.Error(t, NewDBCheck(db).Check(context.Background()))
+}
+
+func TestSigningKeyCheck(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	assert.NoError(t, NewSigningKeyCheck(oidc.NewRSASigner(key)).Check(context.Background()))
+	assert.ErrorIs(t, NewSigningKeyCheck(nil).Check(context.Background()), config.ErrSigningKeyMissing)
+}
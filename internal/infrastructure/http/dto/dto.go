@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type UserDTO struct {
 	ID       string `json:"id" binding:"required"`
 	Name     string `json:"name" binding:"required"`
@@ -23,6 +25,7 @@ type ChangePwdDTO struct {
 type LoginRequestDTO struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	Scope    string `json:"scope"`
 }
 
 type UserResponseDTO struct {
@@ -30,3 +33,47 @@ type UserResponseDTO struct {
 	Message string `json:"message"`
 	Data    any    `json:"data,omitempty"`
 }
+
+type PolicyErrorDTO struct {
+	Status     int    `json:"status"`
+	Error      string `json:"error"`
+	Violations any    `json:"violations"`
+}
+
+type CreateAPIKeyDTO struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyCreatedDTO struct {
+	Key    string `json:"key"`
+	APIKey any    `json:"api_key"`
+}
+
+type RegisterClientDTO struct {
+	ClientName              string   `json:"client_name" binding:"required"`
+	RedirectURIs            []string `json:"redirect_uris" binding:"required"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	Scope                   string   `json:"scope"`
+}
+
+type ClientRegisteredDTO struct {
+	ClientID                string   `json:"client_id"`
+	ClientSecret            string   `json:"client_secret,omitempty"`
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+	Scope                   string   `json:"scope"`
+	ClientIDIssuedAt        int64    `json:"client_id_issued_at"`
+}
+
+type FederatedLinkDTO struct {
+	RedirectURL string `json:"redirect_url"`
+}
+
+type CreateWebhookDTO struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	Secret     string   `json:"secret"`
+}
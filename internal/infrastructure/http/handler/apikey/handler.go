@@ -0,0 +1,113 @@
+package apikey
+
+import (
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/apikey"
+	"net/http"
+
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type APIKeyHandler struct {
+	Service apikey.Usecases
+}
+
+func NewAPIKeyHandler(service apikey.Usecases) *APIKeyHandler {
+	return &APIKeyHandler{Service: service}
+}
+
+func (ah *APIKeyHandler) CreateAPIKeyHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	if c.GetString(config.CtxAuthMethod) != config.AuthMethodJWT {
+		web.NewError(c, http.StatusForbidden, config.APIKeyRequiresLoginMsg)
+		return
+	}
+
+	var body dto.CreateAPIKeyDTO
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		web.NewError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	granted, _ := c.Get(config.CtxScopes)
+	scopes, _ := granted.([]string)
+
+	created, rawKey, createErr := ah.Service.CreateAPIKey(c, c.GetString(config.CtxUsername), body.Name, body.Scopes, scopes, body.ExpiresAt)
+	if errors.Is(createErr, config.ErrInsufficientScope) {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", error_description="an api key cannot have scopes the token does not have"`)
+		web.NewError(c, http.StatusForbidden, config.InsufficientScopeMsg)
+		return
+	}
+	if createErr != nil {
+		web.NewError(c, statusFor(createErr), createErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyResponse(http.StatusCreated, config.APIKeyCreatedMsg, &dto.APIKeyCreatedDTO{
+		Key:    rawKey,
+		APIKey: created,
+	}))
+}
+
+func (ah *APIKeyHandler) ListAPIKeysHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	keys, listErr := ah.Service.ListAPIKeys(c, c.GetString(config.CtxUsername))
+	if listErr != nil {
+		web.NewError(c, http.StatusInternalServerError, listErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeyResponse(http.StatusOK, config.APIKeysFoundMsg, keys))
+}
+
+func (ah *APIKeyHandler) RevokeAPIKeyHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	if c.GetString(config.CtxAuthMethod) != config.AuthMethodJWT {
+		web.NewError(c, http.StatusForbidden, config.APIKeyRequiresLoginMsg)
+		return
+	}
+
+	id := c.Query("id")
+	if id == "" {
+		web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+		return
+	}
+
+	if revokeErr := ah.Service.RevokeAPIKey(c, c.GetString(config.CtxUsername), id); revokeErr != nil {
+		web.NewError(c, statusFor(revokeErr), revokeErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeyResponse(http.StatusOK, config.APIKeyRevokedMsg, nil))
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrAPIKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrUserNotFound):
+		return http.StatusUnauthorized
+	case errors.Is(err, config.ErrInvalidAPIKeyName),
+		errors.Is(err, config.ErrInvalidAPIKeyExpiry),
+		errors.Is(err, config.ErrInvalidScope):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func apiKeyResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	}
+}
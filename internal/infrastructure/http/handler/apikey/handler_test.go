@@ -0,0 +1,217 @@
+package apikey
+
+import (
+	"context"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/apikey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUsecases struct {
+	mock.Mock
+}
+
+func (m *MockUsecases) CreateAPIKey(ctx context.Context, username, name string, scopes, granted []string, expiresAt *time.Time) (entity.APIKey, string, error) {
+	args := m.Called(ctx, username, name, scopes, granted, expiresAt)
+	return args.Get(0).(entity.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockUsecases) ListAPIKeys(ctx context.Context, username string) ([]entity.APIKey, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]entity.APIKey), args.Error(1)
+}
+
+func (m *MockUsecases) RevokeAPIKey(ctx context.Context, username, id string) error {
+	args := m.Called(ctx, username, id)
+	return args.Error(0)
+}
+
+func (m *MockUsecases) Authenticate(ctx context.Context, rawKey string) (entity.APIKey, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(entity.APIKey), args.Error(1)
+}
+
+func newContext(w *httptest.ResponseRecorder, method, target, body, authMethod string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set(config.CtxUsername, "johndoe")
+	c.Set(config.CtxAuthMethod, authMethod)
+	c.Set(config.CtxScopes, []string{"users:read"})
+	return c
+}
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	handler := NewAPIKeyHandler(mockUsecase)
+
+	tests := []struct {
+		Name           string
+		Body           string
+		AuthMethod     string
+		MockFunc       func()
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:       "Success",
+			Body:       `{"name":"ci","scopes":["users:read"]}`,
+			AuthMethod: config.AuthMethodJWT,
+			MockFunc: func() {
+				mockUsecase.
+					On("CreateAPIKey", mock.Anything, "johndoe", "ci", []string{"users:read"}, []string{"users:read"}, (*time.Time)(nil)).
+					Return(entity.APIKey{ID: "1", Prefix: "abcdef012345"}, "gmh_abcdef012345_secret", nil).
+					Once()
+			},
+			ExpectedStatus: http.StatusCreated,
+			ExpectedBody:   "gmh_abcdef012345_secret",
+		},
+		{
+			Name:       "Scope Not Granted",
+			Body:       `{"name":"ci","scopes":["users:delete"]}`,
+			AuthMethod: config.AuthMethodJWT,
+			MockFunc: func() {
+				mockUsecase.
+					On("CreateAPIKey", mock.Anything, "johndoe", "ci", []string{"users:delete"}, []string{"users:read"}, (*time.Time)(nil)).
+					Return(entity.APIKey{}, "", fmt.Errorf("error creating api key. Error: %w", config.ErrInsufficientScope)).
+					Once()
+			},
+			ExpectedStatus: http.StatusForbidden,
+			ExpectedBody:   config.InsufficientScopeMsg,
+		},
+		{
+			Name:           "Rejected For API Key Callers",
+			Body:           `{"name":"ci"}`,
+			AuthMethod:     config.AuthMethodAPIKey,
+			MockFunc:       func() {},
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "Invalid Body",
+			Body:           `{"scopes":[]}`,
+			AuthMethod:     config.AuthMethodJWT,
+			MockFunc:       func() {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "Invalid Expiry",
+			Body:       `{"name":"ci"}`,
+			AuthMethod: config.AuthMethodJWT,
+			MockFunc: func() {
+				mockUsecase.
+					On("CreateAPIKey", mock.Anything, "johndoe", "ci", []string(nil), []string{"users:read"}, (*time.Time)(nil)).
+					Return(entity.APIKey{}, "", fmt.Errorf("error creating api key. Error: %w", config.ErrInvalidAPIKeyExpiry)).
+					Once()
+			},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:       "Owner Deleted",
+			Body:       `{"name":"ci","scopes":["users:read"]}`,
+			AuthMethod: config.AuthMethodJWT,
+			MockFunc: func() {
+				mockUsecase.
+					On("CreateAPIKey", mock.Anything, "johndoe", "ci", []string{"users:read"}, []string{"users:read"}, (*time.Time)(nil)).
+					Return(entity.APIKey{}, "", fmt.Errorf("error creating api key. Error: %w", config.ErrUserNotFound)).
+					Once()
+			},
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			w := httptest.NewRecorder()
+			c := newContext(w, http.MethodPost, "/api-keys", tt.Body, tt.AuthMethod)
+
+			handler.CreateAPIKeyHandler(c)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedBody)
+			assert.NotContains(t, w.Body.String(), `"hash"`)
+		})
+	}
+
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestListAPIKeysHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	handler := NewAPIKeyHandler(mockUsecase)
+
+	mockUsecase.
+		On("ListAPIKeys", mock.Anything, "johndoe").
+		Return([]entity.APIKey{{ID: "1", Prefix: "abcdef012345", Hash: "secret-hash"}}, nil).
+		Once()
+
+	w := httptest.NewRecorder()
+	c := newContext(w, http.MethodGet, "/api-keys", "", config.AuthMethodAPIKey)
+
+	handler.ListAPIKeysHandler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "abcdef012345")
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestRevokeAPIKeyHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	handler := NewAPIKeyHandler(mockUsecase)
+
+	tests := []struct {
+		Name           string
+		Target         string
+		MockFunc       func()
+		ExpectedStatus int
+	}{
+		{
+			Name:   "Success",
+			Target: "/api-keys?id=1",
+			MockFunc: func() {
+				mockUsecase.On("RevokeAPIKey", mock.Anything, "johndoe", "1").Return(nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:           "Missing Id",
+			Target:         "/api-keys",
+			MockFunc:       func() {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Not Found",
+			Target: "/api-keys?id=2",
+			MockFunc: func() {
+				mockUsecase.
+					On("RevokeAPIKey", mock.Anything, "johndoe", "2").
+					Return(fmt.Errorf("error revoking api key. Error: %w", config.ErrAPIKeyNotFound)).
+					Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			w := httptest.NewRecorder()
+			c := newContext(w, http.MethodDelete, tt.Target, "", config.AuthMethodJWT)
+
+			handler.RevokeAPIKeyHandler(c)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+		})
+	}
+}
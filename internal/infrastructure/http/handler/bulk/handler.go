@@ -0,0 +1,208 @@
+package bulk
+
+import (
+	"encoding/json"
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/job"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+
+	entity "go-manage-hex/internal/core/bulk"
+	jobEntity "go-manage-hex/internal/core/job"
+	codec "go-manage-hex/internal/infrastructure/bulk"
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+var contentTypes = map[string]string{
+	config.BulkFormatCSV:    "text/csv",
+	config.BulkFormatNDJSON: "application/x-ndjson",
+}
+
+// BulkHandler spools uploads and results to Dir and queues a job for them, so
+// no request is held open while rows are processed. Uploads larger than
+// MaxUploadBytes are rejected before they fill the disk.
+type BulkHandler struct {
+	Jobs           job.Usecases
+	Dir            string
+	MaxUploadBytes int64
+}
+
+func NewBulkHandler(jobs job.Usecases, dir string, maxUploadBytes int64) *BulkHandler {
+	return &BulkHandler{Jobs: jobs, Dir: dir, MaxUploadBytes: maxUploadBytes}
+}
+
+func (bh *BulkHandler) ImportHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	format := c.DefaultQuery("format", config.BulkFormatCSV)
+	options, ok := bh.importOptions(c)
+	if !ok || !slices.Contains(config.BulkFormats, format) {
+		web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+		return
+	}
+
+	file, spoolErr := os.CreateTemp(bh.Dir, config.BulkImportFilePattern+format)
+	if spoolErr != nil {
+		web.NewError(c, http.StatusInternalServerError, spoolErr.Error())
+		return
+	}
+	defer file.Close()
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, bh.MaxUploadBytes)
+	if _, copyErr := io.Copy(file, body); copyErr != nil {
+		os.Remove(file.Name())
+		var tooLarge *http.MaxBytesError
+		if errors.As(copyErr, &tooLarge) {
+			web.NewError(c, http.StatusRequestEntityTooLarge, config.UploadTooLargeMsg)
+			return
+		}
+		web.NewError(c, http.StatusBadRequest, config.InvalidBodyMsg)
+		return
+	}
+	if _, seekErr := file.Seek(0, io.SeekStart); seekErr != nil {
+		os.Remove(file.Name())
+		web.NewError(c, http.StatusInternalServerError, seekErr.Error())
+		return
+	}
+
+	// Checking the header here rejects a malformed upload right away instead
+	// of failing it later in a worker.
+	if _, readerErr := codec.NewReader(format, file); readerErr != nil {
+		os.Remove(file.Name())
+		web.NewError(c, statusFor(readerErr), readerErr.Error())
+		return
+	}
+
+	queued, enqueueErr := bh.Jobs.Enqueue(c, config.JobTypeUserImport, codec.ImportPayload{Path: file.Name(), Format: format, Options: options})
+	if enqueueErr != nil {
+		os.Remove(file.Name())
+		web.NewError(c, statusFor(enqueueErr), enqueueErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, bulkResponse(http.StatusAccepted, config.BulkJobQueuedMsg, queued))
+}
+
+func (bh *BulkHandler) ExportHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	format := c.DefaultQuery("format", config.BulkFormatCSV)
+	filter := c.Query("filter")
+	if _, filterErr := codec.ParseFilter(filter); filterErr != nil || !slices.Contains(config.BulkFormats, format) {
+		web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+		return
+	}
+
+	file, createErr := os.CreateTemp(bh.Dir, config.BulkExportFilePattern+format)
+	if createErr != nil {
+		web.NewError(c, http.StatusInternalServerError, createErr.Error())
+		return
+	}
+	file.Close()
+
+	queued, enqueueErr := bh.Jobs.Enqueue(c, config.JobTypeUserExport, codec.ExportPayload{Path: file.Name(), Format: format, Filter: filter})
+	if enqueueErr != nil {
+		os.Remove(file.Name())
+		web.NewError(c, statusFor(enqueueErr), enqueueErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, bulkResponse(http.StatusAccepted, config.BulkJobQueuedMsg, queued))
+}
+
+func (bh *BulkHandler) JobResultHandler(c *gin.Context) {
+	found, getErr := bh.Jobs.GetJob(c, c.Param("id"))
+	if getErr != nil {
+		c.Header("Content-Type", "application/json")
+		web.NewError(c, statusFor(getErr), getErr.Error())
+		return
+	}
+
+	payload, resultErr := exportResult(found)
+	if resultErr != nil {
+		c.Header("Content-Type", "application/json")
+		web.NewError(c, statusFor(resultErr), resultErr.Error())
+		return
+	}
+
+	c.Header("Content-Type", contentTypes[payload.Format])
+	c.FileAttachment(payload.Path, "users."+payload.Format)
+}
+
+func (bh *BulkHandler) importOptions(c *gin.Context) (entity.ImportOptions, bool) {
+	var options entity.ImportOptions
+
+	if raw := c.Query("dry_run"); raw != "" {
+		dryRun, err := strconv.ParseBool(raw)
+		if err != nil {
+			return options, false
+		}
+		options.DryRun = dryRun
+	}
+	if raw := c.Query("batch_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size <= 0 {
+			return options, false
+		}
+		options.BatchSize = size
+	}
+	if raw := c.Query("resume_after"); raw != "" {
+		line, err := strconv.Atoi(raw)
+		if err != nil || line < 0 {
+			return options, false
+		}
+		options.ResumeAfter = line
+	}
+
+	return options, true
+}
+
+func exportResult(found jobEntity.Job) (codec.ExportPayload, error) {
+	var payload codec.ExportPayload
+	switch {
+	case found.Type != config.JobTypeUserExport, found.Status == config.JobFailed:
+		return payload, config.ErrJobHasNoResult
+	case found.Status != config.JobSucceeded:
+		return payload, config.ErrJobNotFinished
+	}
+
+	if err := json.Unmarshal(found.Payload, &payload); err != nil {
+		return payload, err
+	}
+	if _, err := os.Stat(payload.Path); errors.Is(err, os.ErrNotExist) {
+		return payload, config.ErrJobResultExpired
+	}
+	return payload, nil
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrJobNotFound),
+		errors.Is(err, config.ErrJobHasNoResult):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrJobNotFinished):
+		return http.StatusConflict
+	case errors.Is(err, config.ErrJobResultExpired):
+		return http.StatusGone
+	case errors.Is(err, config.ErrUnknownBulkFormat),
+		errors.Is(err, config.ErrMissingColumn):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func bulkResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	}
+}
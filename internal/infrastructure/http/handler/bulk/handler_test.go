@@ -0,0 +1,213 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/bulk"
+	jobEntity "go-manage-hex/internal/core/job"
+	codec "go-manage-hex/internal/infrastructure/bulk"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockJobs struct {
+	mock.Mock
+}
+
+func (m *MockJobs) Enqueue(ctx context.Context, jobType string, payload any) (jobEntity.Job, error) {
+	args := m.Called(ctx, jobType, payload)
+	return args.Get(0).(jobEntity.Job), args.Error(1)
+}
+
+func (m *MockJobs) GetJob(ctx context.Context, id string) (jobEntity.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(jobEntity.Job), args.Error(1)
+}
+
+func (m *MockJobs) ListJobs(ctx context.Context, query jobEntity.JobQuery) ([]jobEntity.Job, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]jobEntity.Job), args.Error(1)
+}
+
+func newRouter(handler *BulkHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/users/import", handler.ImportHandler)
+	r.POST("/users/export", handler.ExportHandler)
+	r.GET("/jobs/:id/result", handler.JobResultHandler)
+	return r
+}
+
+func importPayload(options entity.ImportOptions) any {
+	return mock.MatchedBy(func(p codec.ImportPayload) bool {
+		return p.Format == config.BulkFormatCSV && p.Options == options
+	})
+}
+
+func TestBulkHandlers(t *testing.T) {
+	dir := t.TempDir()
+	result := filepath.Join(dir, "export-1.csv")
+	require.NoError(t, os.WriteFile(result, []byte("id,username,name,last_name,email\n"), 0o600))
+	payload, err := json.Marshal(codec.ExportPayload{Path: result, Format: config.BulkFormatCSV})
+	require.NoError(t, err)
+	expired, err := json.Marshal(codec.ExportPayload{Path: filepath.Join(dir, "export-swept.csv"), Format: config.BulkFormatCSV})
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name           string
+		Method         string
+		Target         string
+		Body           string
+		MockFunc       func(m *MockJobs)
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:   "Import_Queued",
+			Method: http.MethodPost,
+			Target: "/users/import?dry_run=true&batch_size=50",
+			Body:   "username,name,last_name,email,password\njanedoe,Jane,Doe,jane@doe.com,Str0ngPassw0rd\n",
+			MockFunc: func(m *MockJobs) {
+				m.On("Enqueue", mock.Anything, config.JobTypeUserImport, importPayload(entity.ImportOptions{DryRun: true, BatchSize: 50})).
+					Return(jobEntity.Job{ID: "job-1", Status: config.JobPending}, nil).Once()
+			},
+			ExpectedStatus: http.StatusAccepted,
+			ExpectedBody:   `"id":"job-1"`,
+		},
+		{
+			Name:   "Import_EnqueueFails",
+			Method: http.MethodPost,
+			Target: "/users/import",
+			Body:   "username,name,last_name,email,password\n",
+			MockFunc: func(m *MockJobs) {
+				m.On("Enqueue", mock.Anything, config.JobTypeUserImport, importPayload(entity.ImportOptions{})).
+					Return(jobEntity.Job{}, errors.New("db down")).Once()
+			},
+			ExpectedStatus: http.StatusInternalServerError,
+		},
+		{
+			Name:           "Import_MissingColumn",
+			Method:         http.MethodPost,
+			Target:         "/users/import",
+			Body:           "username,email\n",
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "Import_TooLarge",
+			Method:         http.MethodPost,
+			Target:         "/users/import",
+			Body:           "username,name,last_name,email,password\n" + strings.Repeat("janedoe,Jane,Doe,jane@doe.com,Str0ngPassw0rd\n", 100),
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusRequestEntityTooLarge,
+			ExpectedBody:   config.UploadTooLargeMsg,
+		},
+		{
+			Name:           "Import_InvalidQuery",
+			Method:         http.MethodPost,
+			Target:         "/users/import?batch_size=-1",
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Export_Queued",
+			Method: http.MethodPost,
+			Target: "/users/export?format=ndjson&filter=email+co+doe.com",
+			MockFunc: func(m *MockJobs) {
+				m.On("Enqueue", mock.Anything, config.JobTypeUserExport, mock.MatchedBy(func(p codec.ExportPayload) bool {
+					return p.Format == config.BulkFormatNDJSON && p.Filter == "email co doe.com" && strings.HasPrefix(filepath.Base(p.Path), "export-")
+				})).
+					Return(jobEntity.Job{ID: "job-2", Status: config.JobPending}, nil).Once()
+			},
+			ExpectedStatus: http.StatusAccepted,
+		},
+		{
+			Name:           "Export_UnknownFormat",
+			Method:         http.MethodPost,
+			Target:         "/users/export?format=xml",
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Result_NotFound",
+			Method: http.MethodGet,
+			Target: "/jobs/missing/result",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "missing").Return(jobEntity.Job{}, config.ErrJobNotFound).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:   "Result_NotFinished",
+			Method: http.MethodGet,
+			Target: "/jobs/job-2/result",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "job-2").
+					Return(jobEntity.Job{ID: "job-2", Type: config.JobTypeUserExport, Status: config.JobRunning}, nil).Once()
+			},
+			ExpectedStatus: http.StatusConflict,
+		},
+		{
+			Name:   "Result_Import",
+			Method: http.MethodGet,
+			Target: "/jobs/job-1/result",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "job-1").
+					Return(jobEntity.Job{ID: "job-1", Type: config.JobTypeUserImport, Status: config.JobSucceeded}, nil).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:   "Result_Expired",
+			Method: http.MethodGet,
+			Target: "/jobs/job-3/result",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "job-3").
+					Return(jobEntity.Job{ID: "job-3", Type: config.JobTypeUserExport, Status: config.JobSucceeded, Payload: expired}, nil).Once()
+			},
+			ExpectedStatus: http.StatusGone,
+		},
+		{
+			Name:   "Result_Success",
+			Method: http.MethodGet,
+			Target: "/jobs/job-2/result",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "job-2").
+					Return(jobEntity.Job{ID: "job-2", Type: config.JobTypeUserExport, Status: config.JobSucceeded, Payload: payload}, nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "id,username",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			mockJobs := new(MockJobs)
+			tt.MockFunc(mockJobs)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.Method, tt.Target, strings.NewReader(tt.Body))
+			newRouter(NewBulkHandler(mockJobs, dir, 1024)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedBody)
+			mockJobs.AssertExpectations(t)
+		})
+	}
+
+	// Only the queued import keeps its upload; the worker removes it later.
+	spooled, err := filepath.Glob(filepath.Join(dir, "import-*"))
+	require.NoError(t, err)
+	assert.Len(t, spooled, 1)
+}
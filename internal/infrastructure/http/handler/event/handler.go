@@ -0,0 +1,98 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	eventService "go-manage-hex/internal/app/event"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventStreamHandler struct {
+	Stream    eventService.Streamer
+	Heartbeat time.Duration
+}
+
+func NewEventStreamHandler(stream eventService.Streamer) *EventStreamHandler {
+	return &EventStreamHandler{Stream: stream, Heartbeat: config.StreamHeartbeatInterval}
+}
+
+func (eh *EventStreamHandler) StreamHandler(c *gin.Context) {
+	lastID, resume := lastEventID(c)
+
+	backlog, reset, client := eh.Stream.Subscribe(lastID, resume)
+	defer eh.Stream.Unsubscribe(client)
+
+	// The stream outlives the server write timeout.
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if reset {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", config.EventStreamReset)
+	}
+	for _, streamed := range backlog {
+		if err := writeEvent(c.Writer, streamed); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eh.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case streamed, ok := <-client.Events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(c.Writer, streamed); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, streamed eventService.StreamEvent) error {
+	data, err := json.Marshal(streamed.Event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", streamed.ID, streamed.Event.Type, data)
+	return err
+}
+
+func lastEventID(c *gin.Context) (int64, bool) {
+	raw := c.GetHeader(config.LastEventIDHeader)
+	if raw == "" {
+		raw = c.Query("lastEventId")
+	}
+	if raw == "" {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false
+	}
+	return id, true
+}
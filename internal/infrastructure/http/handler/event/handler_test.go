@@ -0,0 +1,99 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	eventService "go-manage-hex/internal/app/event"
+	entity "go-manage-hex/internal/core/event"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStreamServer(stream *eventService.Broadcaster) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(config.EventStreamPath, NewEventStreamHandler(stream).StreamHandler)
+	return httptest.NewServer(r)
+}
+
+func readFrame(t *testing.T, reader *bufio.Reader) string {
+	var frame []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(frame, "\n")
+		}
+		frame = append(frame, line)
+	}
+}
+
+func TestStreamHandler(t *testing.T) {
+	stream := eventService.NewBroadcaster(10, 8)
+	server := newStreamServer(stream)
+	defer server.Close()
+
+	stream.Publish(context.Background(), entity.Event{ID: "evt-1", Type: config.EventUserCreated, Aggregate: "johndoe"})
+	stream.Publish(context.Background(), entity.Event{ID: "evt-2", Type: config.EventUserUpdated, Aggregate: "johndoe"})
+
+	test := []struct {
+		Name           string
+		LastEventID    string
+		Live           *entity.Event
+		ExpectedFrames []string
+	}{
+		{
+			Name:        "Stream_ResumeFromLastEventID",
+			LastEventID: "1",
+			Live:        &entity.Event{ID: "evt-3", Type: config.EventUserDeleted, Aggregate: "johndoe"},
+			ExpectedFrames: []string{
+				"id: 2\nevent: user.updated",
+				"id: 3\nevent: user.deleted",
+			},
+		},
+		{
+			Name:        "Stream_ResetWhenUnknown",
+			LastEventID: "99",
+			ExpectedFrames: []string{
+				"event: reset\ndata: {}",
+				"id: 1\nevent: user.created",
+			},
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+config.EventStreamPath, nil)
+			req.Header.Set(config.LastEventIDHeader, tt.LastEventID)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+			if tt.Live != nil {
+				stream.Publish(context.Background(), *tt.Live)
+			}
+
+			reader := bufio.NewReader(resp.Body)
+			for _, expected := range tt.ExpectedFrames {
+				assert.True(t, strings.HasPrefix(readFrame(t, reader), expected), expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package federation
+
+import (
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/federation"
+	"go-manage-hex/internal/app/grant"
+	"net/http"
+
+	entity "go-manage-hex/internal/core/user"
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type FederationHandler struct {
+	Service     federation.Usecases
+	Grants      grant.Usecases
+	AuthService entity.Authorization
+}
+
+func NewFederationHandler(service federation.Usecases, grants grant.Usecases, authService entity.Authorization) *FederationHandler {
+	return &FederationHandler{Service: service, Grants: grants, AuthService: authService}
+}
+
+func (fh *FederationHandler) LoginHandler(c *gin.Context) {
+	redirectURL, binding, err := fh.Service.Begin(c, c.Param("provider"), "")
+	if err != nil {
+		web.NewError(c, statusFor(err), err.Error())
+		return
+	}
+
+	setStateCookie(c, binding, int(config.FederatedStateTTL.Seconds()))
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (fh *FederationHandler) LinkHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	redirectURL, binding, err := fh.Service.Begin(c, c.Param("provider"), c.GetString(config.CtxUsername))
+	if err != nil {
+		web.NewError(c, statusFor(err), err.Error())
+		return
+	}
+
+	setStateCookie(c, binding, int(config.FederatedStateTTL.Seconds()))
+
+	c.JSON(http.StatusOK, federationResponse(http.StatusOK, config.IdentityLinkStartedMsg, &dto.FederatedLinkDTO{
+		RedirectURL: redirectURL,
+	}))
+}
+
+func (fh *FederationHandler) CallbackHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	if upstreamErr := c.Query("error"); upstreamErr != "" {
+		web.NewError(c, http.StatusUnauthorized, upstreamErr)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+		return
+	}
+
+	binding, _ := c.Cookie(config.FederatedStateCookie)
+	setStateCookie(c, "", -1)
+
+	username, err := fh.Service.Complete(c, c.Param("provider"), code, state, binding)
+	if err != nil {
+		web.NewError(c, statusFor(err), err.Error())
+		return
+	}
+
+	scopes, err := fh.Grants.LoginScopes(c, username)
+	if err != nil {
+		web.NewError(c, http.StatusInternalServerError, config.ErrLoadingScopes)
+		return
+	}
+
+	token, err := fh.AuthService.GenerateJWT(username, scopes)
+	if err != nil {
+		web.NewError(c, http.StatusInternalServerError, "error generating token")
+		return
+	}
+
+	c.JSON(http.StatusOK, federationResponse(http.StatusOK, config.UserLoggedMsg, token))
+}
+
+// setStateCookie is Lax rather than Strict because the callback arrives as
+// a top-level redirect from the IdP's site.
+func setStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     config.FederatedStateCookie,
+		Value:    value,
+		Path:     config.FederatedStateCookiePath,
+		MaxAge:   maxAge,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrInvalidState):
+		return http.StatusBadRequest
+	case errors.Is(err, config.ErrIdentityLinked):
+		return http.StatusConflict
+	case errors.Is(err, config.ErrAccountNotProvisioned):
+		return http.StatusForbidden
+	case errors.Is(err, config.ErrInvalidIDToken),
+		errors.Is(err, config.ErrProviderExchange):
+		return http.StatusUnauthorized
+	case errors.Is(err, config.ErrProviderDiscovery):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func federationResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	}
+}
@@ -0,0 +1,220 @@
+package federation
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/grant"
+	entity "go-manage-hex/internal/core/user"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUsecases struct {
+	mock.Mock
+}
+
+func (m *MockUsecases) Begin(ctx context.Context, provider, linkUsername string) (string, string, error) {
+	args := m.Called(ctx, provider, linkUsername)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockUsecases) Complete(ctx context.Context, provider, code, state, binding string) (string, error) {
+	args := m.Called(ctx, provider, code, state, binding)
+	return args.String(0), args.Error(1)
+}
+
+type MockAuth struct {
+	mock.Mock
+}
+
+func (m *MockAuth) GenerateJWT(username string, scopes []string) (string, error) {
+	args := m.Called(username, scopes)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuth) ValidateJWT(tokenStr string) (entity.TokenClaims, error) {
+	args := m.Called(tokenStr)
+	return args.Get(0).(entity.TokenClaims), args.Error(1)
+}
+
+type staticGrants struct {
+	grant.Usecases
+	scopes []string
+}
+
+func (s *staticGrants) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	return s.scopes, nil
+}
+
+func newContext(w *httptest.ResponseRecorder, target, provider string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Params = gin.Params{{Key: "provider", Value: provider}}
+	c.Set(config.CtxUsername, "johndoe")
+	return c
+}
+
+func TestLoginHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	handler := NewFederationHandler(mockUsecase, &staticGrants{}, new(MockAuth))
+
+	tests := []struct {
+		Name             string
+		Provider         string
+		MockFunc         func()
+		ExpectedStatus   int
+		ExpectedLocation string
+		ExpectedCookie   string
+	}{
+		{
+			Name:     "Redirects To Provider",
+			Provider: "acme",
+			MockFunc: func() {
+				mockUsecase.On("Begin", mock.Anything, "acme", "").
+					Return("https://idp.example/authorize?state=abc", "binding-abc", nil).Once()
+			},
+			ExpectedStatus:   http.StatusFound,
+			ExpectedLocation: "https://idp.example/authorize?state=abc",
+			ExpectedCookie:   "binding-abc",
+		},
+		{
+			Name:     "Unknown Provider",
+			Provider: "other",
+			MockFunc: func() {
+				mockUsecase.On("Begin", mock.Anything, "other", "").
+					Return("", "", config.ErrUnknownProvider).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			w := httptest.NewRecorder()
+			handler.LoginHandler(newContext(w, "/federated/"+tt.Provider+"/login", tt.Provider))
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Equal(t, tt.ExpectedLocation, w.Header().Get("Location"))
+			if tt.ExpectedCookie == "" {
+				assert.Empty(t, w.Result().Cookies())
+				return
+			}
+			assertStateCookie(t, w, tt.ExpectedCookie)
+		})
+	}
+}
+
+func TestLinkHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	handler := NewFederationHandler(mockUsecase, &staticGrants{}, new(MockAuth))
+
+	mockUsecase.On("Begin", mock.Anything, "acme", "johndoe").
+		Return("https://idp.example/authorize?state=abc", "binding-abc", nil).Once()
+
+	w := httptest.NewRecorder()
+	handler.LinkHandler(newContext(w, "/federated/acme/link", "acme"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "https://idp.example/authorize?state=abc")
+	assertStateCookie(t, w, "binding-abc")
+}
+
+func assertStateCookie(t *testing.T, w *httptest.ResponseRecorder, value string) {
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, config.FederatedStateCookie, cookies[0].Name)
+	assert.Equal(t, value, cookies[0].Value)
+	assert.Equal(t, config.FederatedStateCookiePath, cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+}
+
+func TestCallbackHandler(t *testing.T) {
+	mockUsecase := new(MockUsecases)
+	mockAuth := new(MockAuth)
+	handler := NewFederationHandler(mockUsecase, &staticGrants{scopes: []string{config.ScopeUsersRead}}, mockAuth)
+
+	tests := []struct {
+		Name           string
+		Target         string
+		Cookie         string
+		MockFunc       func()
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:   "Success",
+			Target: "/federated/acme/callback?code=c1&state=s1",
+			Cookie: "binding-s1",
+			MockFunc: func() {
+				mockUsecase.On("Complete", mock.Anything, "acme", "c1", "s1", "binding-s1").Return("johndoe", nil).Once()
+				mockAuth.On("GenerateJWT", "johndoe", []string{config.ScopeUsersRead}).Return("local-token", nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   "local-token",
+		},
+		{
+			Name:           "Upstream Error",
+			Target:         "/federated/acme/callback?error=access_denied&state=s1",
+			MockFunc:       func() {},
+			ExpectedStatus: http.StatusUnauthorized,
+			ExpectedBody:   "access_denied",
+		},
+		{
+			Name:           "Missing Code",
+			Target:         "/federated/acme/callback?state=s1",
+			MockFunc:       func() {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Invalid State",
+			Target: "/federated/acme/callback?code=c1&state=stale",
+			Cookie: "binding-stale",
+			MockFunc: func() {
+				mockUsecase.On("Complete", mock.Anything, "acme", "c1", "stale", "binding-stale").Return("", config.ErrInvalidState).Once()
+			},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "No State Cookie",
+			Target: "/federated/acme/callback?code=c1&state=s3",
+			MockFunc: func() {
+				mockUsecase.On("Complete", mock.Anything, "acme", "c1", "s3", "").Return("", config.ErrInvalidState).Once()
+			},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Identity Linked Elsewhere",
+			Target: "/federated/acme/callback?code=c1&state=s2",
+			Cookie: "binding-s2",
+			MockFunc: func() {
+				mockUsecase.On("Complete", mock.Anything, "acme", "c1", "s2", "binding-s2").Return("", config.ErrIdentityLinked).Once()
+			},
+			ExpectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			w := httptest.NewRecorder()
+			c := newContext(w, tt.Target, "acme")
+			if tt.Cookie != "" {
+				c.Request.AddCookie(&http.Cookie{Name: config.FederatedStateCookie, Value: tt.Cookie})
+			}
+			handler.CallbackHandler(c)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedBody)
+		})
+	}
+}
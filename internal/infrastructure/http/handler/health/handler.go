@@ -0,0 +1,35 @@
+package health
+
+import (
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	Service health.Usecases
+}
+
+func NewHealthHandler(service health.Usecases) *HealthHandler {
+	return &HealthHandler{Service: service}
+}
+
+func (hh *HealthHandler) LivenessHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, hh.Service.Liveness(c))
+}
+
+func (hh *HealthHandler) ReadinessHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	report := hh.Service.Readiness(c)
+
+	status := http.StatusOK
+	if report.Status != config.HealthStatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
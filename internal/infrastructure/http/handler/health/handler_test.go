@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockUsecases struct {
+	mock.Mock
+}
+
+func (m *MockUsecases) Liveness(ctx context.Context) entity.Report {
+	args := m.Called(ctx)
+	return args.Get(0).(entity.Report)
+}
+
+func (m *MockUsecases) Readiness(ctx context.Context) entity.Report {
+	args := m.Called(ctx)
+	return args.Get(0).(entity.Report)
+}
+
+func TestHealthHandlers(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Target         string
+		MockFunc       func(m *MockUsecases)
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:   "Liveness",
+			Target: config.LivenessPath,
+			MockFunc: func(m *MockUsecases) {
+				m.On("Liveness", mock.Anything).Return(entity.Report{Status: config.HealthStatusUp}).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   config.HealthStatusUp,
+		},
+		{
+			Name:   "Readiness_Up",
+			Target: config.ReadinessPath,
+			MockFunc: func(m *MockUsecases) {
+				m.On("Readiness", mock.Anything).Return(entity.Report{
+					Status: config.HealthStatusUp,
+					Checks: []entity.CheckResult{{Name: "mysql", Status: config.HealthStatusUp, LatencyMs: 1.2}},
+				}).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   config.HealthStatusUp,
+		},
+		{
+			Name:   "Readiness_Down",
+			Target: config.ReadinessPath,
+			MockFunc: func(m *MockUsecases) {
+				m.On("Readiness", mock.Anything).Return(entity.Report{
+					Status: config.HealthStatusDown,
+					Checks: []entity.CheckResult{{Name: "mysql", Status: config.HealthStatusDown, Error: "connection refused"}},
+				}).Once()
+			},
+			ExpectedStatus: http.StatusServiceUnavailable,
+			ExpectedBody:   config.HealthStatusDown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			mockService := new(MockUsecases)
+			tt.MockFunc(mockService)
+
+			handler := NewHealthHandler(mockService)
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET(config.LivenessPath, handler.LivenessHandler)
+			r.GET(config.ReadinessPath, handler.ReadinessHandler)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.Target, nil))
+
+			assert.Equal(t, tt.ExpectedStatus, rec.Code)
+
+			var report entity.Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			assert.Equal(t, tt.ExpectedBody, report.Status)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
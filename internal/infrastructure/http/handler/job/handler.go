@@ -0,0 +1,81 @@
+package job
+
+import (
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/job"
+	"net/http"
+	"slices"
+	"strconv"
+
+	entity "go-manage-hex/internal/core/job"
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+var jobStatuses = []string{config.JobPending, config.JobRunning, config.JobSucceeded, config.JobFailed}
+
+type JobHandler struct {
+	Service job.Usecases
+}
+
+func NewJobHandler(service job.Usecases) *JobHandler {
+	return &JobHandler{Service: service}
+}
+
+func (jh *JobHandler) GetJobHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	found, getErr := jh.Service.GetJob(c, c.Param("id"))
+	if getErr != nil {
+		web.NewError(c, statusFor(getErr), getErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, jobResponse(http.StatusOK, config.JobFoundMsg, found))
+}
+
+func (jh *JobHandler) ListJobsHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	query := entity.JobQuery{Type: c.Query("type"), Status: c.Query("status")}
+	if query.Status != "" && !slices.Contains(jobStatuses, query.Status) {
+		web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+		return
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			web.NewError(c, http.StatusBadRequest, config.InvalidQueryParamsMsg)
+			return
+		}
+		query.Limit = limit
+	}
+
+	jobs, listErr := jh.Service.ListJobs(c, query)
+	if listErr != nil {
+		web.NewError(c, statusFor(listErr), listErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, jobResponse(http.StatusOK, config.JobsFoundMsg, jobs))
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrJobNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func jobResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	}
+}
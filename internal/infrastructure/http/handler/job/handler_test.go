@@ -0,0 +1,108 @@
+package job
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/job"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockJobs struct {
+	mock.Mock
+}
+
+func (m *MockJobs) Enqueue(ctx context.Context, jobType string, payload any) (entity.Job, error) {
+	args := m.Called(ctx, jobType, payload)
+	return args.Get(0).(entity.Job), args.Error(1)
+}
+
+func (m *MockJobs) GetJob(ctx context.Context, id string) (entity.Job, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.Job), args.Error(1)
+}
+
+func (m *MockJobs) ListJobs(ctx context.Context, query entity.JobQuery) ([]entity.Job, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]entity.Job), args.Error(1)
+}
+
+func newRouter(handler *JobHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/jobs", handler.ListJobsHandler)
+	r.GET("/jobs/:id", handler.GetJobHandler)
+	return r
+}
+
+func TestJobHandlers(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Target         string
+		MockFunc       func(m *MockJobs)
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{
+			Name:   "GetJob_Success",
+			Target: "/jobs/job-1",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "job-1").
+					Return(entity.Job{ID: "job-1", Status: config.JobRunning, Payload: []byte(`{"path":"/tmp/secret"}`), Progress: []byte(`{"processed":40}`)}, nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `"progress":{"processed":40}`,
+		},
+		{
+			Name:   "GetJob_NotFound",
+			Target: "/jobs/missing",
+			MockFunc: func(m *MockJobs) {
+				m.On("GetJob", mock.Anything, "missing").Return(entity.Job{}, config.ErrJobNotFound).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:   "ListJobs_Filtered",
+			Target: "/jobs?type=users.import&status=failed&limit=10",
+			MockFunc: func(m *MockJobs) {
+				m.On("ListJobs", mock.Anything, entity.JobQuery{Type: config.JobTypeUserImport, Status: config.JobFailed, Limit: 10}).
+					Return([]entity.Job{{ID: "job-1"}}, nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+			ExpectedBody:   `"id":"job-1"`,
+		},
+		{
+			Name:           "ListJobs_InvalidStatus",
+			Target:         "/jobs?status=done",
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:           "ListJobs_InvalidLimit",
+			Target:         "/jobs?limit=0",
+			MockFunc:       func(m *MockJobs) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			mockJobs := new(MockJobs)
+			tt.MockFunc(mockJobs)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.Target, nil)
+			newRouter(NewJobHandler(mockJobs)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedBody)
+			assert.NotContains(t, w.Body.String(), "/tmp/secret")
+			mockJobs.AssertExpectations(t)
+		})
+	}
+}
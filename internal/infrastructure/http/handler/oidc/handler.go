@@ -0,0 +1,217 @@
+package oidc
+
+import (
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/oidc"
+	"go-manage-hex/internal/core/scope"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="POST">
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="nonce" value="{{.Request.Nonce}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<label>Username <input name="username" autocomplete="username" required></label>
+<label>Password <input name="password" type="password" autocomplete="current-password" required></label>
+<button type="submit">Sign in</button>
+</form>
+</body>
+</html>
+`))
+
+type OIDCHandler struct {
+	Service oidc.Usecases
+}
+
+func NewOIDCHandler(service oidc.Usecases) *OIDCHandler {
+	return &OIDCHandler{Service: service}
+}
+
+func (oh *OIDCHandler) DiscoveryHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, oh.Service.Discovery())
+}
+
+func (oh *OIDCHandler) JWKSHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, oh.Service.JWKS())
+}
+
+func (oh *OIDCHandler) AuthorizeFormHandler(c *gin.Context) {
+	req := authorizeRequest(c.Query)
+
+	if err := oh.Service.ValidateAuthorize(c, req); err != nil {
+		authorizeError(c, req, err)
+		return
+	}
+
+	renderLogin(c, http.StatusOK, req, "")
+}
+
+func (oh *OIDCHandler) AuthorizeHandler(c *gin.Context) {
+	req := authorizeRequest(c.PostForm)
+
+	redirectURL, err := oh.Service.Authorize(c, req, c.PostForm("username"), c.PostForm("password"))
+	if errors.Is(err, config.ErrInvalidCredentials) {
+		renderLogin(c, http.StatusUnauthorized, req, "invalid credentials")
+		return
+	}
+	if errors.Is(err, config.ErrHasherBusy) {
+		c.Header(config.RetryAfterHeader, config.HashBusyRetryAfter)
+		renderLogin(c, http.StatusServiceUnavailable, req, "too many sign-ins right now, try again in a moment")
+		return
+	}
+	if err != nil {
+		authorizeError(c, req, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, redirectURL)
+}
+
+func (oh *OIDCHandler) TokenHandler(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	req := oidc.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+		CodeVerifier: c.PostForm("code_verifier"),
+	}
+
+	basicID, basicSecret, usedBasic := c.Request.BasicAuth()
+	if usedBasic {
+		req.ClientID, _ = url.QueryUnescape(basicID)
+		req.ClientSecret, _ = url.QueryUnescape(basicSecret)
+	}
+
+	resp, err := oh.Service.Exchange(c, req)
+	if err != nil {
+		oauthErr, ok := oidc.IsOAuthError(err)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, &oidc.OAuthError{Code: "server_error"})
+			return
+		}
+
+		status := http.StatusBadRequest
+		if oauthErr.Code == config.OAuthInvalidClient {
+			status = http.StatusUnauthorized
+			if usedBasic {
+				c.Header("WWW-Authenticate", `Basic realm="token"`)
+			}
+		}
+		c.JSON(status, oauthErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (oh *OIDCHandler) UserInfoHandler(c *gin.Context) {
+	granted, _ := c.Get(config.CtxScopes)
+	scopes, _ := granted.([]string)
+
+	claims, err := oh.Service.UserInfo(c, c.GetString(config.CtxUsername), scopes)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, &oidc.OAuthError{Code: "invalid_token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+func (oh *OIDCHandler) RegisterHandler(c *gin.Context) {
+	var body dto.RegisterClientDTO
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, &oidc.OAuthError{Code: config.OAuthInvalidClientMetadata, Description: err.Error()})
+		return
+	}
+
+	client, secret, err := oh.Service.RegisterClient(c, body.ClientName, body.RedirectURIs, body.TokenEndpointAuthMethod, scope.Parse(body.Scope))
+	switch {
+	case errors.Is(err, config.ErrInvalidRedirectURI):
+		c.JSON(http.StatusBadRequest, &oidc.OAuthError{Code: config.OAuthInvalidRedirectURI, Description: err.Error()})
+		return
+	case errors.Is(err, config.ErrInvalidClientMetadata):
+		c.JSON(http.StatusBadRequest, &oidc.OAuthError{Code: config.OAuthInvalidClientMetadata, Description: err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, &oidc.OAuthError{Code: "server_error"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, &dto.ClientRegisteredDTO{
+		ClientID:                client.ID,
+		ClientSecret:            secret,
+		ClientName:              client.Name,
+		RedirectURIs:            client.RedirectURIs,
+		TokenEndpointAuthMethod: client.AuthMethod,
+		Scope:                   scope.String(client.Scopes),
+		ClientIDIssuedAt:        client.CreatedAt.Unix(),
+	})
+}
+
+func authorizeRequest(get func(string) string) oidc.AuthorizeRequest {
+	return oidc.AuthorizeRequest{
+		ResponseType:        get("response_type"),
+		ClientID:            get("client_id"),
+		RedirectURI:         get("redirect_uri"),
+		Scope:               get("scope"),
+		State:               get("state"),
+		Nonce:               get("nonce"),
+		CodeChallenge:       get("code_challenge"),
+		CodeChallengeMethod: get("code_challenge_method"),
+	}
+}
+
+func authorizeError(c *gin.Context, req oidc.AuthorizeRequest, err error) {
+	oauthErr, ok := oidc.IsOAuthError(err)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, &oidc.OAuthError{Code: "server_error"})
+		return
+	}
+
+	if !oauthErr.Redirect {
+		c.JSON(http.StatusBadRequest, oauthErr)
+		return
+	}
+
+	c.Redirect(http.StatusFound, oidc.RedirectWith(req.RedirectURI, url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+		"state":             {req.State},
+	}))
+}
+
+func renderLogin(c *gin.Context, status int, req oidc.AuthorizeRequest, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Status(status)
+
+	_ = loginPage.Execute(c.Writer, struct {
+		Request oidc.AuthorizeRequest
+		Error   string
+	}{Request: req, Error: message})
+}
@@ -0,0 +1,448 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/grant"
+	oidcapp "go-manage-hex/internal/app/oidc"
+	entity "go-manage-hex/internal/core/oidc"
+	userEntity "go-manage-hex/internal/core/user"
+	"go-manage-hex/internal/infrastructure/auth"
+	"go-manage-hex/internal/infrastructure/http/middleware"
+	oidcinfra "go-manage-hex/internal/infrastructure/oidc"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registrationToken = "registration-secret"
+
+type fakeUsers struct{}
+
+var johnDoe = userEntity.User{
+	ID:       "8f0d3c1e-0000-4000-8000-000000000001",
+	Name:     "John",
+	LastName: "Doe",
+	Username: "johndoe",
+	Email:    "johndoe@example.com",
+}
+
+func (f *fakeUsers) SearchUser(ctx context.Context, username string) (userEntity.User, error) {
+	if username != johnDoe.Username {
+		return userEntity.User{}, config.ErrUserNotFound
+	}
+	return johnDoe, nil
+}
+
+func (f *fakeUsers) SearchUserByID(ctx context.Context, id string) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) SearchUserByIDWithDeleted(ctx context.Context, id string) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) ListUsers(ctx context.Context, query userEntity.UserQuery) ([]userEntity.User, int, error) {
+	return nil, 0, errors.New("not implemented")
+}
+
+func (f *fakeUsers) CreateUser(ctx context.Context, user userEntity.User) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) PrepareUser(ctx context.Context, user userEntity.User, preHashed bool) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) CreateUsers(ctx context.Context, users []userEntity.User) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeUsers) DeleteUser(ctx context.Context, username string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeUsers) RestoreUser(ctx context.Context, username string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeUsers) UpdateUser(ctx context.Context, username string, user userEntity.User) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) UpdateUserAndPwd(ctx context.Context, username string, user userEntity.User, newPwd string) (userEntity.User, error) {
+	return userEntity.User{}, errors.New("not implemented")
+}
+
+func (f *fakeUsers) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
+	return errors.New("not implemented")
+}
+
+func (f *fakeUsers) Login(ctx context.Context, username, password string) error {
+	if username == johnDoe.Username && password == "Password1234" {
+		return nil
+	}
+	return errors.New("wrong password")
+}
+
+type fakeGrants struct {
+	grant.Usecases
+}
+
+func (f *fakeGrants) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	return config.AllScopes, nil
+}
+
+type memoryClients struct {
+	mu      sync.Mutex
+	clients map[string]entity.Client
+}
+
+func (m *memoryClients) CreateTable(tableName string) error {
+	return nil
+}
+
+func (m *memoryClients) NewClient(client entity.Client) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[client.ID] = client
+	return nil
+}
+
+func (m *memoryClients) GetClient(id string) (entity.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client, ok := m.clients[id]
+	if !ok {
+		return entity.Client{}, config.ErrClientNotFound
+	}
+	return client, nil
+}
+
+func newProvider(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+
+	signer, err := oidcinfra.LoadRSASigner("")
+	require.NoError(t, err)
+
+	jwtService := auth.NewJWTService("secret", 15*time.Minute)
+	mw := middleware.NewMiddleware(jwtService, nil)
+
+	router := gin.New()
+	server := httptest.NewServer(router)
+
+	provider := oidcapp.NewOIDCService(
+		&memoryClients{clients: map[string]entity.Client{}},
+		oidcinfra.NewMemoryCodeStore(),
+		&fakeUsers{},
+		&fakeGrants{},
+		jwtService,
+		signer,
+		server.URL+config.BaseURL,
+		time.Hour,
+		15*time.Minute,
+	)
+	handler := NewOIDCHandler(provider)
+
+	api := router.Group(config.BaseURL)
+	api.GET(config.OIDCDiscoveryPath, handler.DiscoveryHandler)
+	api.GET(config.OIDCJWKSPath, handler.JWKSHandler)
+	api.GET(config.OIDCAuthorizePath, handler.AuthorizeFormHandler)
+	api.POST(config.OIDCAuthorizePath, handler.AuthorizeHandler)
+	api.POST(config.OIDCTokenPath, handler.TokenHandler)
+	api.POST(config.OIDCRegisterPath, mw.RequireStaticToken(registrationToken), handler.RegisterHandler)
+	api.GET(config.OIDCUserInfoPath, mw.RequireAuth, mw.RequireScope(config.ScopeOpenID), handler.UserInfoHandler)
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// relyingParty is a minimal stand-in for an app using the provider: it
+// discovers endpoints, runs the code flow with PKCE and verifies ID tokens.
+type relyingParty struct {
+	t            *testing.T
+	http         *http.Client
+	redirectURI  string
+	clientID     string
+	clientSecret string
+	metadata     map[string]any
+}
+
+func newRelyingParty(t *testing.T, issuer string) *relyingParty {
+	rp := &relyingParty{
+		t:           t,
+		redirectURI: "https://rp.internal.example/callback",
+		http: &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}},
+	}
+
+	resp, err := rp.http.Get(issuer + config.OIDCDiscoveryPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&rp.metadata))
+	require.Equal(t, issuer, rp.metadata["issuer"])
+
+	return rp
+}
+
+func (rp *relyingParty) endpoint(name string) string {
+	return rp.metadata[name].(string)
+}
+
+func (rp *relyingParty) register(authMethod string) {
+	body := fmt.Sprintf(`{"client_name":"wiki","redirect_uris":[%q],"token_endpoint_auth_method":%q}`, rp.redirectURI, authMethod)
+	req, _ := http.NewRequest(http.MethodPost, rp.endpoint("registration_endpoint"), strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+registrationToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := rp.http.Do(req)
+	require.NoError(rp.t, err)
+	defer resp.Body.Close()
+	require.Equal(rp.t, http.StatusCreated, resp.StatusCode)
+
+	var registered map[string]any
+	require.NoError(rp.t, json.NewDecoder(resp.Body).Decode(&registered))
+	rp.clientID = registered["client_id"].(string)
+	rp.clientSecret, _ = registered["client_secret"].(string)
+}
+
+func (rp *relyingParty) authorize(scope, verifier, password string) (*http.Response, url.Values) {
+	sum := sha256.Sum256([]byte(verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {rp.clientID},
+		"redirect_uri":          {rp.redirectURI},
+		"scope":                 {scope},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6_WzA2Mj"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	form, err := rp.http.Get(rp.endpoint("authorization_endpoint") + "?" + params.Encode())
+	require.NoError(rp.t, err)
+	form.Body.Close()
+	if form.StatusCode != http.StatusOK {
+		return form, rp.callback(form)
+	}
+
+	params.Set("username", "johndoe")
+	params.Set("password", password)
+
+	resp, err := rp.http.PostForm(rp.endpoint("authorization_endpoint"), params)
+	require.NoError(rp.t, err)
+	resp.Body.Close()
+
+	return resp, rp.callback(resp)
+}
+
+func (rp *relyingParty) callback(resp *http.Response) url.Values {
+	if resp.StatusCode != http.StatusFound {
+		return nil
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(rp.t, err)
+	require.True(rp.t, strings.HasPrefix(location.String(), rp.redirectURI))
+
+	return location.Query()
+}
+
+func (rp *relyingParty) exchange(code, verifier string) (int, map[string]any) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {rp.redirectURI},
+		"code_verifier": {verifier},
+	}
+
+	if rp.clientSecret == "" {
+		form.Set("client_id", rp.clientID)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, rp.endpoint("token_endpoint"), strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if rp.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(rp.clientID), url.QueryEscape(rp.clientSecret))
+	}
+
+	resp, err := rp.http.Do(req)
+	require.NoError(rp.t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(rp.t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func (rp *relyingParty) verifyIDToken(raw string) jwt.MapClaims {
+	resp, err := rp.http.Get(rp.endpoint("jwks_uri"))
+	require.NoError(rp.t, err)
+	defer resp.Body.Close()
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	require.NoError(rp.t, json.NewDecoder(resp.Body).Decode(&jwks))
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (any, error) {
+		for _, key := range jwks.Keys {
+			if key.Kid != token.Header["kid"] {
+				continue
+			}
+			n, _ := base64.RawURLEncoding.DecodeString(key.N)
+			e, _ := base64.RawURLEncoding.DecodeString(key.E)
+			return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+		}
+		return nil, errors.New("unknown kid")
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(rp.metadata["issuer"].(string)),
+		jwt.WithAudience(rp.clientID),
+	)
+	require.NoError(rp.t, err)
+
+	return claims
+}
+
+func (rp *relyingParty) userinfo(accessToken string) (int, map[string]any) {
+	req, _ := http.NewRequest(http.MethodGet, rp.endpoint("userinfo_endpoint"), nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := rp.http.Do(req)
+	require.NoError(rp.t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	return resp.StatusCode, body
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	tests := []struct {
+		Name       string
+		AuthMethod string
+	}{
+		{Name: "Confidential client", AuthMethod: config.ClientAuthBasic},
+		{Name: "Public client", AuthMethod: config.ClientAuthNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			provider := newProvider(t)
+			rp := newRelyingParty(t, provider.URL+config.BaseURL)
+			rp.register(tt.AuthMethod)
+
+			verifier := strings.Repeat("v", 50)
+
+			_, params := rp.authorize("openid profile email", verifier, "Password1234")
+			require.NotNil(t, params)
+			assert.Equal(t, "xyz", params.Get("state"))
+
+			status, tokens := rp.exchange(params.Get("code"), verifier)
+			require.Equal(t, http.StatusOK, status, tokens)
+			assert.Equal(t, "Bearer", tokens["token_type"])
+			assert.Equal(t, "openid profile email", tokens["scope"])
+			assert.Equal(t, float64(900), tokens["expires_in"])
+
+			claims := rp.verifyIDToken(tokens["id_token"].(string))
+			assert.Equal(t, johnDoe.ID, claims["sub"])
+			assert.Equal(t, "n-0S6_WzA2Mj", claims["nonce"])
+			assert.Equal(t, johnDoe.Email, claims["email"])
+
+			status, info := rp.userinfo(tokens["access_token"].(string))
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, johnDoe.ID, info["sub"])
+			assert.Equal(t, "johndoe", info["preferred_username"])
+			assert.Equal(t, "John Doe", info["name"])
+
+			status, reused := rp.exchange(params.Get("code"), verifier)
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Equal(t, config.OAuthInvalidGrant, reused["error"])
+		})
+	}
+}
+
+func TestAuthorizationCodeFlow_Errors(t *testing.T) {
+	provider := newProvider(t)
+	rp := newRelyingParty(t, provider.URL+config.BaseURL)
+	rp.register(config.ClientAuthBasic)
+
+	verifier := strings.Repeat("v", 50)
+
+	resp, params := rp.authorize("openid", verifier, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Nil(t, params)
+
+	_, params = rp.authorize("openid", verifier, "Password1234")
+	status, body := rp.exchange(params.Get("code"), strings.Repeat("x", 50))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, config.OAuthInvalidGrant, body["error"])
+
+	_, params = rp.authorize("profile", verifier, "Password1234")
+	assert.Equal(t, config.OAuthInvalidScope, params.Get("error"))
+	assert.Equal(t, "xyz", params.Get("state"))
+
+	_, params = rp.authorize("openid", verifier, "Password1234")
+	rp.clientSecret = "wrong-secret"
+	status, body = rp.exchange(params.Get("code"), verifier)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, config.OAuthInvalidClient, body["error"])
+
+	status, _ = rp.userinfo("not-a-token")
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
+
+func TestAuthorizeUnknownRedirect(t *testing.T) {
+	provider := newProvider(t)
+	rp := newRelyingParty(t, provider.URL+config.BaseURL)
+	rp.register(config.ClientAuthBasic)
+
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {rp.clientID},
+		"redirect_uri":  {"https://attacker.example/callback"},
+		"scope":         {"openid"},
+	}
+
+	resp, err := rp.http.Get(rp.endpoint("authorization_endpoint") + "?" + params.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Location"))
+}
+
+func TestRegisterRequiresToken(t *testing.T) {
+	provider := newProvider(t)
+
+	resp, err := http.Post(provider.URL+config.BaseURL+config.OIDCRegisterPath, "application/json",
+		strings.NewReader(`{"client_name":"wiki","redirect_uris":["https://rp.example/cb"]}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,75 @@
+package scim
+
+import "go-manage-hex/cmd/config"
+
+type attribute struct {
+	Name          string      `json:"name"`
+	Type          string      `json:"type"`
+	Description   string      `json:"description,omitempty"`
+	MultiValued   bool        `json:"multiValued"`
+	Required      bool        `json:"required"`
+	CaseExact     bool        `json:"caseExact"`
+	Mutability    string      `json:"mutability"`
+	Returned      string      `json:"returned"`
+	Uniqueness    string      `json:"uniqueness"`
+	SubAttributes []attribute `json:"subAttributes,omitempty"`
+}
+
+func serviceProviderConfig(baseURL string) map[string]any {
+	return map[string]any{
+		"schemas":          []string{config.SCIMSchemaSPConfig},
+		"documentationUri": baseURL,
+		"deactivation":     map[string]any{"supported": true, "behavior": "delete"},
+		"patch":            map[string]bool{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": config.SCIMMaxResults},
+		"changePassword":   map[string]bool{"supported": true},
+		"sort":             map[string]bool{"supported": false},
+		"etag":             map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "Dedicated SCIM bearer token",
+			"primary":     true,
+		}},
+		"meta": map[string]string{"resourceType": "ServiceProviderConfig", "location": baseURL + "/ServiceProviderConfig"},
+	}
+}
+
+func resourceTypes(baseURL string) []map[string]any {
+	return []map[string]any{{
+		"schemas":     []string{config.SCIMSchemaResourceType},
+		"id":          "User",
+		"name":        "User",
+		"endpoint":    "/Users",
+		"description": "User Account",
+		"schema":      config.SCIMSchemaUser,
+		"meta":        map[string]string{"resourceType": "ResourceType", "location": baseURL + "/ResourceTypes/User"},
+	}}
+}
+
+func userSchema(baseURL string) map[string]any {
+	return map[string]any{
+		"schemas":     []string{config.SCIMSchemaSchema},
+		"id":          config.SCIMSchemaUser,
+		"name":        "User",
+		"description": "User Account",
+		"attributes": []attribute{
+			{Name: "userName", Type: "string", Required: true, Mutability: "immutable", Returned: "default", Uniqueness: "server"},
+			{Name: "name", Type: "complex", Mutability: "readWrite", Returned: "default", Uniqueness: "none", SubAttributes: []attribute{
+				{Name: "givenName", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+				{Name: "familyName", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+				{Name: "formatted", Type: "string", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+			}},
+			{Name: "displayName", Type: "string", Mutability: "readOnly", Returned: "default", Uniqueness: "none"},
+			{Name: "emails", Type: "complex", MultiValued: true, Required: true, Mutability: "readWrite", Returned: "default", Uniqueness: "server", SubAttributes: []attribute{
+				{Name: "value", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "server"},
+				{Name: "type", Type: "string", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+				{Name: "primary", Type: "boolean", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			}},
+			{Name: "active", Type: "boolean", Description: "Setting active to false deletes the user", Mutability: "readWrite", Returned: "default", Uniqueness: "none"},
+			{Name: "password", Type: "string", Mutability: "writeOnly", Returned: "never", Uniqueness: "none"},
+		},
+		"meta": map[string]string{"resourceType": "Schema", "location": baseURL + "/Schemas/" + config.SCIMSchemaUser},
+	}
+}
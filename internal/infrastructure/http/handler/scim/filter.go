@@ -0,0 +1,51 @@
+package scim
+
+import (
+	"go-manage-hex/cmd/config"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	entity "go-manage-hex/internal/core/user"
+)
+
+var filterExpr = regexp.MustCompile(`^\s*([A-Za-z][\w.]*)\s+([A-Za-z]{2})\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+var filterAttributes = map[string]string{
+	"id":              config.UserColumnID,
+	"username":        config.UserColumnUsername,
+	"name.givenname":  config.UserColumnName,
+	"name.familyname": config.UserColumnLastName,
+	"emails":          config.UserColumnEmail,
+	"emails.value":    config.UserColumnEmail,
+}
+
+func parseFilter(filter string) (*entity.UserFilter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+
+	match := filterExpr.FindStringSubmatch(filter)
+	if match == nil {
+		return nil, newError(http.StatusBadRequest, config.SCIMInvalidFilter, "only \"<attribute> <eq|sw|co> \\\"<value>\\\"\" filters are supported")
+	}
+
+	column, ok := filterAttributes[strings.ToLower(match[1])]
+	if !ok {
+		return nil, newError(http.StatusBadRequest, config.SCIMInvalidFilter, "unsupported filter attribute "+match[1])
+	}
+
+	operator := strings.ToLower(match[2])
+	if !slices.Contains(config.FilterOperators, operator) {
+		return nil, newError(http.StatusBadRequest, config.SCIMInvalidFilter, "unsupported filter operator "+match[2])
+	}
+
+	value, err := strconv.Unquote(match[3])
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, config.SCIMInvalidFilter, "invalid filter value")
+	}
+
+	return &entity.UserFilter{Field: column, Operator: operator, Value: value}, nil
+}
@@ -0,0 +1,310 @@
+package scim
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/user"
+	"net/http"
+	"strconv"
+
+	entity "go-manage-hex/internal/core/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SCIMHandler struct {
+	Service user.Usecases
+	BaseURL string
+}
+
+func NewSCIMHandler(service user.Usecases, baseURL string) *SCIMHandler {
+	return &SCIMHandler{Service: service, BaseURL: baseURL}
+}
+
+func (sh *SCIMHandler) CreateUserHandler(c *gin.Context) {
+	var resource UserResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		sh.fail(c, newError(http.StatusBadRequest, config.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	change, err := fromResource(resource)
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	if !change.Active {
+		sh.fail(c, newError(http.StatusBadRequest, config.SCIMInvalidValue, "users cannot be created inactive"))
+		return
+	}
+
+	newUser := change.User
+
+	if newUser.Password == "" {
+		if newUser.Password, err = generatePassword(); err != nil {
+			sh.fail(c, err)
+			return
+		}
+	}
+
+	created, err := sh.Service.CreateUser(c, newUser)
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	location := sh.location(created.ID)
+	c.Header("Location", location)
+	sh.respond(c, http.StatusCreated, toResource(created, location))
+}
+
+func (sh *SCIMHandler) GetUserHandler(c *gin.Context) {
+	found, err := sh.Service.SearchUserByID(c, c.Param("id"))
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	sh.respond(c, http.StatusOK, toResource(found, sh.location(found.ID)))
+}
+
+func (sh *SCIMHandler) ListUsersHandler(c *gin.Context) {
+	filter, err := parseFilter(c.Query("filter"))
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	startIndex := queryInt(c, "startIndex", 1)
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := min(max(queryInt(c, "count", config.SCIMDefaultCount), 0), config.SCIMMaxResults)
+
+	users, total, err := sh.Service.ListUsers(c, entity.UserQuery{
+		Filter: filter,
+		Offset: startIndex - 1,
+		Limit:  count,
+	})
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	resources := make([]UserResource, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, toResource(u, sh.location(u.ID)))
+	}
+
+	sh.respond(c, http.StatusOK, ListResponse{
+		Schemas:      []string{config.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (sh *SCIMHandler) ReplaceUserHandler(c *gin.Context) {
+	existing, err := sh.Service.SearchUserByIDWithDeleted(c, c.Param("id"))
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	var resource UserResource
+	if err := c.ShouldBindJSON(&resource); err != nil {
+		sh.fail(c, newError(http.StatusBadRequest, config.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	replacement, err := fromResource(resource)
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	if replacement.User.Username != existing.Username {
+		sh.fail(c, newError(http.StatusBadRequest, config.SCIMMutability, "userName cannot be changed"))
+		return
+	}
+
+	sh.save(c, existing, replacement)
+}
+
+func (sh *SCIMHandler) PatchUserHandler(c *gin.Context) {
+	existing, err := sh.Service.SearchUserByIDWithDeleted(c, c.Param("id"))
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	var request PatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		sh.fail(c, newError(http.StatusBadRequest, config.SCIMInvalidSyntax, err.Error()))
+		return
+	}
+
+	patched, err := applyPatch(existing, request)
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	sh.save(c, existing, patched)
+}
+
+func (sh *SCIMHandler) DeleteUserHandler(c *gin.Context) {
+	existing, err := sh.Service.SearchUserByID(c, c.Param("id"))
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	if err := sh.Service.DeleteUser(c, existing.Username); err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (sh *SCIMHandler) ServiceProviderConfigHandler(c *gin.Context) {
+	sh.respond(c, http.StatusOK, serviceProviderConfig(sh.BaseURL))
+}
+
+func (sh *SCIMHandler) ResourceTypesHandler(c *gin.Context) {
+	types := resourceTypes(sh.BaseURL)
+	sh.respond(c, http.StatusOK, ListResponse{
+		Schemas:      []string{config.SCIMSchemaListResponse},
+		TotalResults: len(types),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+}
+
+func (sh *SCIMHandler) SchemasHandler(c *gin.Context) {
+	schema := userSchema(sh.BaseURL)
+
+	if id := c.Param("id"); id != "" {
+		if id != config.SCIMSchemaUser {
+			sh.fail(c, newError(http.StatusNotFound, "", "schema not found"))
+			return
+		}
+		sh.respond(c, http.StatusOK, schema)
+		return
+	}
+
+	sh.respond(c, http.StatusOK, ListResponse{
+		Schemas:      []string{config.SCIMSchemaListResponse},
+		TotalResults: 1,
+		StartIndex:   1,
+		ItemsPerPage: 1,
+		Resources:    []map[string]any{schema},
+	})
+}
+
+// save applies a PUT or PATCH. Deactivating a user soft-deletes it, since
+// local accounts have no disabled state; IdPs send active:false on
+// unassignment and active:true when the user is assigned again, which
+// restores it.
+func (sh *SCIMHandler) save(c *gin.Context, existing entity.User, change userChange) {
+	deleted := existing.DeletedAt != nil
+
+	if !change.Active {
+		if !deleted {
+			if err := sh.Service.DeleteUser(c, existing.Username); err != nil {
+				sh.fail(c, err)
+				return
+			}
+		}
+
+		resource := toResource(existing, sh.location(existing.ID))
+		resource.Active = json.RawMessage("false")
+		sh.respond(c, http.StatusOK, resource)
+		return
+	}
+
+	if deleted {
+		if err := sh.Service.RestoreUser(c, existing.Username); err != nil {
+			sh.fail(c, err)
+			return
+		}
+	}
+
+	updated, err := sh.Service.UpdateUserAndPwd(c, existing.Username, change.User, change.Password)
+	if err != nil {
+		sh.fail(c, err)
+		return
+	}
+
+	updated.ID = existing.ID
+	updated.Username = existing.Username
+	updated.DeletedAt = nil
+
+	sh.respond(c, http.StatusOK, toResource(updated, sh.location(existing.ID)))
+}
+
+func (sh *SCIMHandler) location(id string) string {
+	return sh.BaseURL + "/Users/" + id
+}
+
+func (sh *SCIMHandler) respond(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", config.SCIMContentType)
+	c.JSON(status, body)
+}
+
+func (sh *SCIMHandler) fail(c *gin.Context, err error) {
+	var scimErr *Error
+	if !errors.As(err, &scimErr) {
+		scimErr = mapError(err)
+	}
+
+	c.Header("Content-Type", config.SCIMContentType)
+	if scimErr.status == http.StatusServiceUnavailable {
+		c.Header(config.RetryAfterHeader, config.HashBusyRetryAfter)
+	}
+	c.AbortWithStatusJSON(scimErr.status, scimErr)
+}
+
+func mapError(err error) *Error {
+	var policyErr *user.PolicyError
+
+	switch {
+	case errors.Is(err, config.ErrUserNotFound):
+		return newError(http.StatusNotFound, "", config.ErrUserNotFound.Error())
+	case errors.Is(err, config.ErrUserAlreadyExists):
+		return newError(http.StatusConflict, config.SCIMUniqueness, config.ErrUserAlreadyExists.Error())
+	case errors.As(err, &policyErr):
+		return newError(http.StatusBadRequest, config.SCIMInvalidValue, policyErr.Error())
+	case errors.Is(err, config.ErrInvalidEmail):
+		return newError(http.StatusBadRequest, config.SCIMInvalidValue, config.ErrInvalidEmail.Error())
+	case errors.Is(err, config.ErrInvalidUserQuery):
+		return newError(http.StatusBadRequest, config.SCIMInvalidFilter, config.ErrInvalidUserQuery.Error())
+	case errors.Is(err, config.ErrHasherBusy):
+		return newError(http.StatusServiceUnavailable, "", config.ErrHasherBusy.Error())
+	default:
+		return newError(http.StatusInternalServerError, "", err.Error())
+	}
+}
+
+func queryInt(c *gin.Context, key string, fallback int) int {
+	value, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func generatePassword() (string, error) {
+	buf := make([]byte, config.SCIMGeneratedPwdLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "Aa1!" + base64.RawURLEncoding.EncodeToString(buf)[:config.SCIMGeneratedPwdLen], nil
+}
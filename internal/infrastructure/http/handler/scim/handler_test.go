@@ -0,0 +1,386 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/user"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	entity "go-manage-hex/internal/core/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryUsers struct {
+	users     map[string]entity.User
+	deleted   map[string]entity.User
+	passwords map[string]string
+}
+
+func newMemoryUsers() *memoryUsers {
+	return &memoryUsers{users: map[string]entity.User{}, deleted: map[string]entity.User{}, passwords: map[string]string{}}
+}
+
+func (m *memoryUsers) SearchUser(ctx context.Context, username string) (entity.User, error) {
+	u, ok := m.users[username]
+	if !ok {
+		return entity.User{}, config.ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (m *memoryUsers) SearchUserByID(ctx context.Context, id string) (entity.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (m *memoryUsers) SearchUserByIDWithDeleted(ctx context.Context, id string) (entity.User, error) {
+	if u, err := m.SearchUserByID(ctx, id); err == nil {
+		return u, nil
+	}
+	for _, u := range m.deleted {
+		if u.ID == id {
+			deletedAt := time.Now()
+			u.DeletedAt = &deletedAt
+			return u, nil
+		}
+	}
+	return entity.User{}, config.ErrUserNotFound
+}
+
+func (m *memoryUsers) ListUsers(ctx context.Context, query entity.UserQuery) ([]entity.User, int, error) {
+	var matched []entity.User
+	for _, u := range m.users {
+		if query.Filter == nil || (query.Filter.Field == config.UserColumnUsername && strings.HasPrefix(u.Username, query.Filter.Value)) {
+			matched = append(matched, u)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Username < matched[j].Username })
+
+	total := len(matched)
+	start := min(query.Offset, total)
+	end := min(start+query.Limit, total)
+	return matched[start:end], total, nil
+}
+
+func (m *memoryUsers) CreateUser(ctx context.Context, u entity.User) (entity.User, error) {
+	if _, ok := m.users[u.Username]; ok {
+		return entity.User{}, config.ErrUserAlreadyExists
+	}
+	if len(u.Password) < 8 {
+		return entity.User{}, &user.PolicyError{Violations: []user.PolicyViolation{{Rule: user.RuleMinLength}}}
+	}
+	m.passwords[u.Username] = u.Password
+	u.ID = uuid.NewString()
+	u.Password = ""
+	m.users[u.Username] = u
+	return u, nil
+}
+
+func (m *memoryUsers) PrepareUser(ctx context.Context, u entity.User, preHashed bool) (entity.User, error) {
+	return u, nil
+}
+
+func (m *memoryUsers) CreateUsers(ctx context.Context, users []entity.User) error {
+	for _, u := range users {
+		m.users[u.Username] = u
+	}
+	return nil
+}
+
+func (m *memoryUsers) DeleteUser(ctx context.Context, username string) error {
+	u, ok := m.users[username]
+	if !ok {
+		return config.ErrUserNotFound
+	}
+	m.deleted[username] = u
+	delete(m.users, username)
+	return nil
+}
+
+func (m *memoryUsers) RestoreUser(ctx context.Context, username string) error {
+	u, ok := m.deleted[username]
+	if !ok {
+		return config.ErrUserNotFound
+	}
+	m.users[username] = u
+	delete(m.deleted, username)
+	return nil
+}
+
+func (m *memoryUsers) UpdateUser(ctx context.Context, username string, u entity.User) (entity.User, error) {
+	existing := m.users[username]
+	existing.Name, existing.LastName, existing.Email = u.Name, u.LastName, u.Email
+	m.users[username] = existing
+	return u, nil
+}
+
+func (m *memoryUsers) UpdateUserAndPwd(ctx context.Context, username string, u entity.User, newPwd string) (entity.User, error) {
+	if newPwd != "" && len(newPwd) < 8 {
+		return entity.User{}, &user.PolicyError{Violations: []user.PolicyViolation{{Rule: user.RuleMinLength}}}
+	}
+	if newPwd != "" {
+		m.passwords[username] = newPwd
+	}
+	return m.UpdateUser(ctx, username, u)
+}
+
+func (m *memoryUsers) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
+	m.passwords[username] = newPwd
+	return nil
+}
+
+func (m *memoryUsers) Login(ctx context.Context, username, password string) error {
+	return nil
+}
+
+func newRouter(users *memoryUsers) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	handler := NewSCIMHandler(users, "https://id.example.com/scim/v2")
+
+	r := gin.New()
+	r.GET("/ServiceProviderConfig", handler.ServiceProviderConfigHandler)
+	r.GET("/Schemas", handler.SchemasHandler)
+	r.GET("/Users", handler.ListUsersHandler)
+	r.POST("/Users", handler.CreateUserHandler)
+	r.GET("/Users/:id", handler.GetUserHandler)
+	r.PUT("/Users/:id", handler.ReplaceUserHandler)
+	r.PATCH("/Users/:id", handler.PatchUserHandler)
+	r.DELETE("/Users/:id", handler.DeleteUserHandler)
+	return r
+}
+
+func do(r *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", config.SCIMContentType)
+	r.ServeHTTP(w, req)
+	return w
+}
+
+const janeResource = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"userName": "janedoe",
+	"name": {"givenName": "Jane", "familyName": "Doe"},
+	"emails": [{"value": "old@example.com"}, {"value": "jane@example.com", "primary": true}],
+	"active": true
+}`
+
+func TestSCIMUserLifecycle(t *testing.T) {
+	users := newMemoryUsers()
+	r := newRouter(users)
+
+	w := do(r, http.MethodPost, "/Users", janeResource)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, config.SCIMContentType, w.Header().Get("Content-Type"))
+
+	var created UserResource
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jane@example.com", created.Emails[0].Value)
+	assert.Empty(t, created.Password)
+	assert.Equal(t, "https://id.example.com/scim/v2/Users/"+created.ID, w.Header().Get("Location"))
+	assert.NotEmpty(t, users.passwords["janedoe"])
+
+	w = do(r, http.MethodPost, "/Users", janeResource)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), config.SCIMUniqueness)
+
+	w = do(r, http.MethodPatch, "/Users/"+created.ID, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "replace", "path": "name.familyName", "value": "Smith"},
+			{"op": "replace", "value": {"emails[type eq \"work\"].value": "jane.smith@example.com", "password": "N3w-Passw0rd!"}}
+		]
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "Smith", users.users["janedoe"].LastName)
+	assert.Equal(t, "jane.smith@example.com", users.users["janedoe"].Email)
+	assert.Equal(t, "N3w-Passw0rd!", users.passwords["janedoe"])
+
+	w = do(r, http.MethodPatch, "/Users/"+created.ID, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "replace", "path": "name.familyName", "value": "Jones"},
+			{"op": "replace", "path": "password", "value": "short"}
+		]
+	}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "Smith", users.users["janedoe"].LastName)
+	assert.Equal(t, "N3w-Passw0rd!", users.passwords["janedoe"])
+
+	w = do(r, http.MethodPut, "/Users/"+created.ID, strings.Replace(janeResource, `"janedoe"`, `"renamed"`, 1))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), config.SCIMMutability)
+
+	w = do(r, http.MethodGet, "/Users/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"familyName":"Smith"`)
+
+	w = do(r, http.MethodDelete, "/Users/"+created.ID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = do(r, http.MethodGet, "/Users/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), config.SCIMSchemaError)
+}
+
+func TestListUsersHandler(t *testing.T) {
+	users := newMemoryUsers()
+	for _, name := range []string{"alice", "bob", "bobby", "carol"} {
+		users.users[name] = entity.User{ID: name + "-id", Username: name, Email: name + "@example.com"}
+	}
+	r := newRouter(users)
+
+	tests := []struct {
+		Name           string
+		Query          string
+		ExpectedStatus int
+		ExpectedTotal  int
+		ExpectedItems  int
+	}{
+		{Name: "All", Query: "", ExpectedStatus: http.StatusOK, ExpectedTotal: 4, ExpectedItems: 4},
+		{Name: "Paginated", Query: "?startIndex=2&count=2", ExpectedStatus: http.StatusOK, ExpectedTotal: 4, ExpectedItems: 2},
+		{Name: "Count Zero", Query: "?count=0", ExpectedStatus: http.StatusOK, ExpectedTotal: 4, ExpectedItems: 0},
+		{Name: "Filtered", Query: `?filter=userName+sw+"bob"`, ExpectedStatus: http.StatusOK, ExpectedTotal: 2, ExpectedItems: 2},
+		{Name: "Invalid Filter", Query: `?filter=userName+gt+"bob"`, ExpectedStatus: http.StatusBadRequest},
+		{Name: "Unknown Attribute", Query: `?filter=password+eq+"x"`, ExpectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			w := do(r, http.MethodGet, "/Users"+tt.Query, "")
+			require.Equal(t, tt.ExpectedStatus, w.Code, w.Body.String())
+			if tt.ExpectedStatus != http.StatusOK {
+				assert.Contains(t, w.Body.String(), config.SCIMInvalidFilter)
+				return
+			}
+
+			var list struct {
+				TotalResults int               `json:"totalResults"`
+				ItemsPerPage int               `json:"itemsPerPage"`
+				Resources    []json.RawMessage `json:"Resources"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+			assert.Equal(t, tt.ExpectedTotal, list.TotalResults)
+			assert.Equal(t, tt.ExpectedItems, list.ItemsPerPage)
+			assert.Len(t, list.Resources, tt.ExpectedItems)
+		})
+	}
+}
+
+func TestCreateUserHandler_Validation(t *testing.T) {
+	r := newRouter(newMemoryUsers())
+
+	tests := []struct {
+		Name           string
+		Body           string
+		ExpectedStatus int
+		ExpectedType   string
+	}{
+		{Name: "Missing UserName", Body: `{"emails":[{"value":"a@example.com"}]}`, ExpectedStatus: http.StatusBadRequest, ExpectedType: config.SCIMInvalidValue},
+		{Name: "Inactive", Body: `{"userName":"a","active":false}`, ExpectedStatus: http.StatusBadRequest, ExpectedType: config.SCIMInvalidValue},
+		{Name: "Invalid Active", Body: `{"userName":"a","active":"no"}`, ExpectedStatus: http.StatusBadRequest, ExpectedType: config.SCIMInvalidValue},
+		{Name: "Weak Password", Body: `{"userName":"a","password":"short"}`, ExpectedStatus: http.StatusBadRequest, ExpectedType: config.SCIMInvalidValue},
+		{Name: "Malformed", Body: `{"userName":`, ExpectedStatus: http.StatusBadRequest, ExpectedType: config.SCIMInvalidSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			w := do(r, http.MethodPost, "/Users", tt.Body)
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.ExpectedType)
+		})
+	}
+}
+
+func TestDeactivateUser(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Method string
+		Body   string
+	}{
+		{Name: "Patch", Method: http.MethodPatch, Body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":false}]}`},
+		{Name: "Patch Without Path", Method: http.MethodPatch, Body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","value":{"active":"False"}}]}`},
+		{Name: "Put", Method: http.MethodPut, Body: strings.Replace(janeResource, `"active": true`, `"active": false`, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			users := newMemoryUsers()
+			users.users["janedoe"] = entity.User{ID: "jane-id", Username: "janedoe", Email: "jane@example.com"}
+			r := newRouter(users)
+
+			w := do(r, tt.Method, "/Users/jane-id", tt.Body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"active":false`)
+			assert.NotContains(t, users.users, "janedoe")
+
+			w = do(r, http.MethodGet, "/Users/jane-id", "")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		})
+	}
+}
+
+func TestReactivateUser(t *testing.T) {
+	deactivate := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":false}]}`
+
+	tests := []struct {
+		Name   string
+		Method string
+		Body   string
+	}{
+		{Name: "Patch", Method: http.MethodPatch, Body: `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":true}]}`},
+		{Name: "Put", Method: http.MethodPut, Body: janeResource},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			users := newMemoryUsers()
+			users.users["janedoe"] = entity.User{ID: "jane-id", Username: "janedoe", Email: "jane@example.com"}
+			r := newRouter(users)
+
+			w := do(r, http.MethodPatch, "/Users/jane-id", deactivate)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			w = do(r, http.MethodPatch, "/Users/jane-id", deactivate)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"active":false`)
+
+			w = do(r, tt.Method, "/Users/jane-id", tt.Body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), `"active":true`)
+			assert.Contains(t, users.users, "janedoe")
+			assert.NotContains(t, users.deleted, "janedoe")
+
+			w = do(r, http.MethodGet, "/Users/jane-id", "")
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"active":true`)
+		})
+	}
+}
+
+func TestDiscoveryHandlers(t *testing.T) {
+	r := newRouter(newMemoryUsers())
+
+	w := do(r, http.MethodGet, "/ServiceProviderConfig", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), config.SCIMSchemaSPConfig)
+	assert.Contains(t, w.Body.String(), `"deactivation":{"behavior":"delete","supported":true}`)
+
+	w = do(r, http.MethodGet, "/Schemas", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), config.SCIMSchemaUser)
+}
@@ -0,0 +1,154 @@
+package scim
+
+import (
+	"encoding/json"
+	"go-manage-hex/cmd/config"
+	"net/http"
+	"slices"
+	"strings"
+
+	entity "go-manage-hex/internal/core/user"
+)
+
+var ignoredAttributes = []string{"displayname", "externalid", "name.formatted"}
+
+func applyPatch(user entity.User, request PatchRequest) (userChange, error) {
+	if !slices.Contains(request.Schemas, config.SCIMSchemaPatchOp) {
+		return userChange{}, newError(http.StatusBadRequest, config.SCIMInvalidSyntax, "request must use the PatchOp schema")
+	}
+
+	change := userChange{User: user, Active: user.DeletedAt == nil}
+	for _, op := range request.Operations {
+		path := strings.ToLower(strings.TrimSpace(op.Path))
+
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if path == "" {
+				var attributes map[string]json.RawMessage
+				if err := json.Unmarshal(op.Value, &attributes); err != nil {
+					return userChange{}, newError(http.StatusBadRequest, config.SCIMInvalidValue, "operation without path requires an object value")
+				}
+				for name, value := range attributes {
+					if err := setAttribute(&change, strings.ToLower(name), value); err != nil {
+						return userChange{}, err
+					}
+				}
+				continue
+			}
+			if err := setAttribute(&change, path, op.Value); err != nil {
+				return userChange{}, err
+			}
+
+		case "remove":
+			if err := removeAttribute(&change.User, path); err != nil {
+				return userChange{}, err
+			}
+
+		default:
+			return userChange{}, newError(http.StatusBadRequest, config.SCIMInvalidSyntax, "unsupported patch operation "+op.Op)
+		}
+	}
+
+	return change, nil
+}
+
+func setAttribute(change *userChange, path string, value json.RawMessage) error {
+	user := &change.User
+
+	switch {
+	case path == "username":
+		username, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		if username != user.Username {
+			return newError(http.StatusBadRequest, config.SCIMMutability, "userName cannot be changed")
+		}
+
+	case path == "name":
+		var name Name
+		if err := json.Unmarshal(value, &name); err != nil {
+			return newError(http.StatusBadRequest, config.SCIMInvalidValue, "name must be an object")
+		}
+		if name.GivenName != "" {
+			user.Name = name.GivenName
+		}
+		if name.FamilyName != "" {
+			user.LastName = name.FamilyName
+		}
+
+	case path == "name.givenname":
+		given, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		user.Name = given
+
+	case path == "name.familyname":
+		family, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		user.LastName = family
+
+	case path == "emails":
+		var emails []Email
+		if err := json.Unmarshal(value, &emails); err != nil || len(emails) == 0 {
+			return newError(http.StatusBadRequest, config.SCIMInvalidValue, "emails must be a non-empty list")
+		}
+		user.Email = primaryEmail(emails)
+
+	case path == "emails.value" || (strings.HasPrefix(path, "emails[") && strings.HasSuffix(path, "].value")):
+		email, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		user.Email = email
+
+	case path == "password":
+		secret, err := stringValue(value)
+		if err != nil {
+			return err
+		}
+		change.Password = secret
+
+	case path == "active":
+		active, err := parseActive(value)
+		if err != nil {
+			return err
+		}
+		change.Active = active
+
+	case slices.Contains(ignoredAttributes, path):
+
+	default:
+		return newError(http.StatusBadRequest, config.SCIMInvalidPath, "unsupported attribute "+path)
+	}
+
+	return nil
+}
+
+func removeAttribute(user *entity.User, path string) error {
+	switch {
+	case path == "":
+		return newError(http.StatusBadRequest, config.SCIMNoTarget, "remove requires a path")
+	case path == "name.givenname":
+		user.Name = ""
+	case path == "name.familyname":
+		user.LastName = ""
+	case slices.Contains(ignoredAttributes, path):
+	case path == "username" || path == "emails" || path == "password" || path == "active":
+		return newError(http.StatusBadRequest, config.SCIMMutability, path+" cannot be removed")
+	default:
+		return newError(http.StatusBadRequest, config.SCIMInvalidPath, "unsupported attribute "+path)
+	}
+	return nil
+}
+
+func stringValue(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", newError(http.StatusBadRequest, config.SCIMInvalidValue, "expected a string value")
+	}
+	return value, nil
+}
@@ -0,0 +1,175 @@
+package scim
+
+import (
+	"encoding/json"
+	"go-manage-hex/cmd/config"
+	"net/http"
+	"strconv"
+	"strings"
+
+	entity "go-manage-hex/internal/core/user"
+)
+
+type Name struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Formatted  string `json:"formatted,omitempty"`
+}
+
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+type UserResource struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	UserName    string          `json:"userName"`
+	Name        *Name           `json:"name,omitempty"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Emails      []Email         `json:"emails,omitempty"`
+	Active      json.RawMessage `json:"active,omitempty"`
+	Password    string          `json:"password,omitempty"`
+	Meta        *Meta           `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+
+	status int
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+func newError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{config.SCIMSchemaError},
+		Status:   http.StatusText(status),
+		ScimType: scimType,
+		Detail:   detail,
+		status:   status,
+	}
+}
+
+func toResource(user entity.User, location string) UserResource {
+	resource := UserResource{
+		Schemas:  []string{config.SCIMSchemaUser},
+		ID:       user.ID,
+		UserName: user.Username,
+		Name: &Name{
+			GivenName:  user.Name,
+			FamilyName: user.LastName,
+			Formatted:  strings.TrimSpace(user.Name + " " + user.LastName),
+		},
+		DisplayName: strings.TrimSpace(user.Name + " " + user.LastName),
+		Active:      json.RawMessage(strconv.FormatBool(user.DeletedAt == nil)),
+		Meta: &Meta{
+			ResourceType: "User",
+			Location:     location,
+		},
+	}
+
+	if user.Email != "" {
+		resource.Emails = []Email{{Value: user.Email, Type: "work", Primary: true}}
+	}
+
+	return resource
+}
+
+// userChange is what a PUT or PATCH asks for: the new profile, an optional
+// new password and whether the user stays active.
+type userChange struct {
+	User     entity.User
+	Password string
+	Active   bool
+}
+
+func fromResource(resource UserResource) (userChange, error) {
+	if strings.TrimSpace(resource.UserName) == "" {
+		return userChange{}, newError(http.StatusBadRequest, config.SCIMInvalidValue, "userName is required")
+	}
+
+	active, err := parseActive(resource.Active)
+	if err != nil {
+		return userChange{}, err
+	}
+
+	user := entity.User{
+		Username: resource.UserName,
+		Email:    primaryEmail(resource.Emails),
+		Password: resource.Password,
+	}
+
+	if resource.Name != nil {
+		user.Name = resource.Name.GivenName
+		user.LastName = resource.Name.FamilyName
+	}
+
+	return userChange{User: user, Password: resource.Password, Active: active}, nil
+}
+
+func primaryEmail(emails []Email) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+func parseActive(raw json.RawMessage) (bool, error) {
+	if len(raw) == 0 {
+		return true, nil
+	}
+
+	var active any
+	if err := json.Unmarshal(raw, &active); err != nil {
+		return false, newError(http.StatusBadRequest, config.SCIMInvalidValue, "active must be a boolean")
+	}
+
+	switch value := active.(type) {
+	case bool:
+		return value, nil
+	case string:
+		if strings.EqualFold(value, "true") || strings.EqualFold(value, "false") {
+			return strings.EqualFold(value, "true"), nil
+		}
+	case nil:
+		return true, nil
+	}
+
+	return false, newError(http.StatusBadRequest, config.SCIMInvalidValue, "active must be a boolean")
+}
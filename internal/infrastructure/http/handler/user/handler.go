@@ -1,11 +1,14 @@
 package user
 
 import (
+	"errors"
 	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/grant"
 	"go-manage-hex/internal/app/user"
 	"net/http"
 	"strconv"
 
+	"go-manage-hex/internal/core/scope"
 	entity "go-manage-hex/internal/core/user"
 	dto "go-manage-hex/internal/infrastructure/http/dto"
 
@@ -15,12 +18,14 @@ import (
 
 type UserHandler struct {
 	Service     user.Usecases
+	Grants      grant.Usecases
 	AuthService entity.Authorization
 }
 
-func NewUserHandler(service user.Usecases, auth entity.Authorization) *UserHandler {
+func NewUserHandler(service user.Usecases, grants grant.Usecases, auth entity.Authorization) *UserHandler {
 	return &UserHandler{
 		Service:     service,
+		Grants:      grants,
 		AuthService: auth,
 	}
 }
@@ -55,6 +60,9 @@ func (uh *UserHandler) CreateUserHandler(c *gin.Context) {
 	}
 
 	created, createdErr := uh.Service.CreateUser(c, user)
+	if policyError(c, createdErr) || busyError(c, createdErr) {
+		return
+	}
 	if createdErr != nil {
 		web.NewError(c, http.StatusInternalServerError, createdErr.Error())
 		return
@@ -136,6 +144,9 @@ func (uh *UserHandler) ChangePwdHandler(c *gin.Context) {
 	}
 
 	changePwdErr := uh.Service.ChangeUserPwd(c, dto.NewPwd, dto.Username)
+	if policyError(c, changePwdErr) || busyError(c, changePwdErr) {
+		return
+	}
 	if changePwdErr != nil {
 		web.NewError(c, http.StatusInternalServerError, changePwdErr.Error())
 		return
@@ -159,12 +170,32 @@ func (uh *UserHandler) LoginUser(c *gin.Context) {
 		Password: dto.Password,
 	}
 
+	requested := scope.Parse(dto.Scope)
+	if len(requested) > 0 && scope.Validate(requested) != nil {
+		web.NewError(c, http.StatusBadRequest, config.InvalidScopeMsg)
+		return
+	}
+
 	if loginErr := uh.Service.Login(c, user.Username, user.Password); loginErr != nil {
-		web.NewError(c, http.StatusUnauthorized, "invalid credentials")
+		if !busyError(c, loginErr) {
+			web.NewError(c, http.StatusUnauthorized, "invalid credentials")
+		}
+		return
+	}
+
+	allowed, err := uh.Grants.LoginScopes(c, user.Username)
+	if err != nil {
+		web.NewError(c, http.StatusInternalServerError, config.ErrLoadingScopes)
 		return
 	}
 
-	token, err := uh.AuthService.GenerateJWT(user.Username)
+	scopes, scopeErr := scope.Narrow(requested, allowed)
+	if scopeErr != nil {
+		web.NewError(c, http.StatusForbidden, config.InsufficientScopeMsg)
+		return
+	}
+
+	token, err := uh.AuthService.GenerateJWT(user.Username, scopes)
 	if err != nil {
 		web.NewError(c, http.StatusInternalServerError, "error generating token")
 		return
@@ -173,6 +204,32 @@ func (uh *UserHandler) LoginUser(c *gin.Context) {
 	c.JSON(http.StatusOK, userResponse(http.StatusOK, "user logged", token))
 }
 
+func policyError(c *gin.Context, err error) bool {
+	var policyErr *user.PolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, &dto.PolicyErrorDTO{
+		Status:     http.StatusBadRequest,
+		Error:      config.PasswordPolicyMsg,
+		Violations: policyErr.Violations,
+	})
+	return true
+}
+
+// busyError answers 503 when the password hashing pool had no free slot in
+// time, so clients back off instead of treating it as a failure.
+func busyError(c *gin.Context, err error) bool {
+	if !errors.Is(err, config.ErrHasherBusy) {
+		return false
+	}
+
+	c.Header(config.RetryAfterHeader, config.HashBusyRetryAfter)
+	web.NewError(c, http.StatusServiceUnavailable, config.ErrHasherBusy.Error())
+	return true
+}
+
 func userResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
 	return &dto.UserResponseDTO{
 		Status:  status,
@@ -2,17 +2,25 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"go-manage-hex/cmd/config"
+	grantService "go-manage-hex/internal/app/grant"
+	"go-manage-hex/internal/app/user"
 	entity "go-manage-hex/internal/core/user"
+	"go-manage-hex/internal/infrastructure/auth"
+	"go-manage-hex/internal/infrastructure/http/middleware"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockUsecases struct {
@@ -23,26 +31,85 @@ type MockAuthService struct {
 	mock.Mock
 }
 
+type MockGrants struct {
+	mock.Mock
+}
+
+func (m *MockGrants) Grant(ctx context.Context, username string, scopes []string) error {
+	args := m.Called(ctx, username, scopes)
+	return args.Error(0)
+}
+
+func (m *MockGrants) Revoke(ctx context.Context, username string, scopes []string) error {
+	args := m.Called(ctx, username, scopes)
+	return args.Error(0)
+}
+
+func (m *MockGrants) Granted(ctx context.Context, username string) ([]string, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGrants) LoginScopes(ctx context.Context, username string) ([]string, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockUsecases) SearchUser(ctx context.Context, username string) (entity.User, error) {
 	args := m.Called(ctx, username)
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUsecases) SearchUserByID(ctx context.Context, id string) (entity.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUsecases) SearchUserByIDWithDeleted(ctx context.Context, id string) (entity.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUsecases) ListUsers(ctx context.Context, query entity.UserQuery) ([]entity.User, int, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]entity.User), args.Int(1), args.Error(2)
+}
+
 func (m *MockUsecases) CreateUser(ctx context.Context, user entity.User) (entity.User, error) {
 	args := m.Called(ctx, user)
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUsecases) PrepareUser(ctx context.Context, user entity.User, preHashed bool) (entity.User, error) {
+	args := m.Called(ctx, user, preHashed)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
+func (m *MockUsecases) CreateUsers(ctx context.Context, users []entity.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUsecases) DeleteUser(ctx context.Context, username string) error {
 	args := m.Called(ctx, username)
 	return args.Error(0)
 }
 
+func (m *MockUsecases) RestoreUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
 func (m *MockUsecases) UpdateUser(ctx context.Context, username string, user entity.User) (entity.User, error) {
 	args := m.Called(ctx, username, user)
 	return args.Get(0).(entity.User), args.Error(1)
 }
 
+func (m *MockUsecases) UpdateUserAndPwd(ctx context.Context, username string, user entity.User, newPwd string) (entity.User, error) {
+	args := m.Called(ctx, username, user, newPwd)
+	return args.Get(0).(entity.User), args.Error(1)
+}
+
 func (m *MockUsecases) ChangeUserPwd(ctx context.Context, newPwd, username string) error {
 	args := m.Called(ctx, newPwd, username)
 	return args.Error(0)
@@ -53,14 +120,14 @@ func (m *MockUsecases) Login(ctx context.Context, username, password string) err
 	return args.Error(0)
 }
 
-func (a *MockAuthService) GenerateJWT(username string) (string, error) {
-	args := a.Called(username)
+func (a *MockAuthService) GenerateJWT(username string, scopes []string) (string, error) {
+	args := a.Called(username, scopes)
 	return args.String(0), args.Error(1)
 }
 
-func (a *MockAuthService) ValidateJWT(token string) (string, error) {
+func (a *MockAuthService) ValidateJWT(token string) (entity.TokenClaims, error) {
 	args := a.Called(token)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(entity.TokenClaims), args.Error(1)
 }
 
 func TestSearchUserHandler(t *testing.T) {
@@ -296,6 +363,55 @@ func TestDeleteUserHandler(t *testing.T) {
 	}
 }
 
+// noGrants is a grant store where nobody holds a per-user grant.
+type noGrants struct{}
+
+func (noGrants) Grant(ctx context.Context, username string, scopes []string, grantedAt time.Time) error {
+	return nil
+}
+
+func (noGrants) Revoke(ctx context.Context, username string, scopes []string) error {
+	return nil
+}
+
+func (noGrants) List(ctx context.Context, username string) ([]string, error) {
+	return nil, nil
+}
+
+// A user who registered through /create and logged in with the default
+// scopes must not be able to delete accounts.
+func TestDeleteUserHandler_DefaultLoginToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockUsecases)
+	mockUsecase.On("Login", mock.Anything, "mallory", "Str0ngPassw0rd").Return(nil).Once()
+
+	jwt := auth.NewJWTService("test-secret-with-enough-entropy-1234", time.Hour)
+	handler := NewUserHandler(mockUsecase, grantService.NewGrantService(noGrants{}, nil, config.DefaultLoginScopes), jwt)
+	mw := middleware.NewMiddleware(jwt, nil)
+
+	router := gin.New()
+	router.POST("/login", handler.LoginUser)
+	router.DELETE("/delete", mw.RequireAuth, mw.RequireScope(config.ScopeUsersDelete), handler.DeleteUserHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"mallory","password":"Str0ngPassw0rd"}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var login struct {
+		Data string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/delete?username=victim&confirmation=true", nil)
+	req.Header.Set("Authorization", "Bearer "+login.Data)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockUsecase.AssertNotCalled(t, "DeleteUser", mock.Anything, "victim")
+	mockUsecase.AssertExpectations(t)
+}
+
 func TestUpdateUserHandler(t *testing.T) {
 	mockUsecase := new(MockUsecases)
 	handler := UserHandler{Service: mockUsecase}
@@ -394,6 +510,7 @@ func TestChangePwdHandler(t *testing.T) {
 		Body           string
 		MockFunc       func()
 		ExpectedStatus int
+		ExpectedBody   string
 	}{
 		{
 			Name: "Success",
@@ -423,6 +540,20 @@ func TestChangePwdHandler(t *testing.T) {
 			},
 			ExpectedStatus: http.StatusInternalServerError,
 		},
+		{
+			Name: "Policy Violation",
+			Body: `{"username":"johndoe","new_pwd":"short"}`,
+			MockFunc: func() {
+				mockUsecase.
+					On("ChangeUserPwd", mock.Anything, "short", "johndoe").
+					Return(fmt.Errorf("error changing password. Error: %w", &user.PolicyError{
+						Violations: []user.PolicyViolation{{Rule: user.RuleMinLength, Message: "too short"}},
+					})).
+					Once()
+			},
+			ExpectedStatus: http.StatusBadRequest,
+			ExpectedBody:   `{"status":400,"error":"password does not meet the password policy","violations":[{"rule":"min_length","message":"too short"}]}`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -439,6 +570,9 @@ func TestChangePwdHandler(t *testing.T) {
 			handler.ChangePwdHandler(c)
 
 			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			if tt.ExpectedBody != "" {
+				assert.JSONEq(t, tt.ExpectedBody, w.Body.String())
+			}
 		})
 	}
 }
@@ -446,8 +580,10 @@ func TestChangePwdHandler(t *testing.T) {
 func TestLoginUser(t *testing.T) {
 	mockUsecase := new(MockUsecases)
 	mockAuthService := new(MockAuthService)
+	mockGrants := new(MockGrants)
 	handler := UserHandler{
 		Service:     mockUsecase,
+		Grants:      mockGrants,
 		AuthService: mockAuthService,
 	}
 
@@ -463,12 +599,64 @@ func TestLoginUser(t *testing.T) {
 			Login: `{"username": "john", "password": "doe123"}`,
 			MockLogin: func() {
 				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return(config.AllScopes, nil).Once()
+			},
+			MockGenerateJWT: func() {
+				mockAuthService.On("GenerateJWT", "john", config.AllScopes).Return("mocked-token", nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:  "narrower scope",
+			Login: `{"username": "john", "password": "doe123", "scope": "users:read"}`,
+			MockLogin: func() {
+				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return(config.AllScopes, nil).Once()
 			},
 			MockGenerateJWT: func() {
-				mockAuthService.On("GenerateJWT", "john").Return("mocked-token", nil).Once()
+				mockAuthService.On("GenerateJWT", "john", []string{config.ScopeUsersRead}).Return("mocked-token", nil).Once()
 			},
 			ExpectedStatus: http.StatusOK,
 		},
+		{
+			Name:  "scope not granted",
+			Login: `{"username": "john", "password": "doe123", "scope": "users:delete"}`,
+			MockLogin: func() {
+				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return([]string{config.ScopeUsersRead}, nil).Once()
+			},
+			MockGenerateJWT: func() {},
+			ExpectedStatus:  http.StatusForbidden,
+		},
+		{
+			Name:  "defaults only",
+			Login: `{"username": "john", "password": "doe123"}`,
+			MockLogin: func() {
+				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return([]string{config.ScopeUsersRead}, nil).Once()
+			},
+			MockGenerateJWT: func() {
+				mockAuthService.On("GenerateJWT", "john", []string{config.ScopeUsersRead}).Return("mocked-token", nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:  "error loading scopes",
+			Login: `{"username": "john", "password": "doe123"}`,
+			MockLogin: func() {
+				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return([]string(nil), errors.New("db down")).Once()
+			},
+			MockGenerateJWT: func() {},
+			ExpectedStatus:  http.StatusInternalServerError,
+		},
+		{
+			Name:            "unknown scope",
+			Login:           `{"username": "john", "password": "doe123", "scope": "admin"}`,
+			MockLogin:       func() {},
+			MockGenerateJWT: func() {},
+			ExpectedStatus:  http.StatusBadRequest,
+		},
 		{
 			Name:            "invalid json",
 			Login:           `{"username": "john"`,
@@ -485,14 +673,24 @@ func TestLoginUser(t *testing.T) {
 			MockGenerateJWT: func() {},
 			ExpectedStatus:  http.StatusUnauthorized,
 		},
+		{
+			Name:  "hasher busy",
+			Login: `{"username": "john", "password": "doe123"}`,
+			MockLogin: func() {
+				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(config.ErrHasherBusy).Once()
+			},
+			MockGenerateJWT: func() {},
+			ExpectedStatus:  http.StatusServiceUnavailable,
+		},
 		{
 			Name:  "error generating jwt",
 			Login: `{"username": "john", "password": "doe123"}`,
 			MockLogin: func() {
 				mockUsecase.On("Login", mock.Anything, "john", "doe123").Return(nil).Once()
+				mockGrants.On("LoginScopes", mock.Anything, "john").Return(config.AllScopes, nil).Once()
 			},
 			MockGenerateJWT: func() {
-				mockAuthService.On("GenerateJWT", "john").Return("", errors.New("internal")).Once()
+				mockAuthService.On("GenerateJWT", "john", config.AllScopes).Return("", errors.New("internal")).Once()
 			},
 			ExpectedStatus: http.StatusInternalServerError,
 		},
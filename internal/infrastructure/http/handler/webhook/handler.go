@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/app/webhook"
+	"net/http"
+
+	dto "go-manage-hex/internal/infrastructure/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/web"
+)
+
+type WebhookHandler struct {
+	Service webhook.Usecases
+}
+
+func NewWebhookHandler(service webhook.Usecases) *WebhookHandler {
+	return &WebhookHandler{Service: service}
+}
+
+func (wh *WebhookHandler) CreateWebhookHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	var body dto.CreateWebhookDTO
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		web.NewError(c, http.StatusBadRequest, config.InvalidBodyMsg)
+		return
+	}
+
+	created, createErr := wh.Service.CreateSubscription(c, body.URL, body.EventTypes, body.Secret)
+	if createErr != nil {
+		web.NewError(c, statusFor(createErr), createErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookResponse(http.StatusCreated, config.WebhookCreatedMsg, created))
+}
+
+func (wh *WebhookHandler) ListWebhooksHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	subs, listErr := wh.Service.ListSubscriptions(c)
+	if listErr != nil {
+		web.NewError(c, statusFor(listErr), listErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(http.StatusOK, config.WebhooksFoundMsg, subs))
+}
+
+func (wh *WebhookHandler) DeleteWebhookHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	if deleteErr := wh.Service.DeleteSubscription(c, c.Param("id")); deleteErr != nil {
+		web.NewError(c, statusFor(deleteErr), deleteErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(http.StatusOK, config.WebhookDeletedMsg, nil))
+}
+
+func (wh *WebhookHandler) ListDeliveriesHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	deliveries, listErr := wh.Service.ListDeliveries(c, c.Param("id"))
+	if listErr != nil {
+		web.NewError(c, statusFor(listErr), listErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookResponse(http.StatusOK, config.DeliveriesFoundMsg, deliveries))
+}
+
+func (wh *WebhookHandler) RedeliverHandler(c *gin.Context) {
+	c.Header("Content-Type", "application/json")
+
+	delivery, redeliverErr := wh.Service.Redeliver(c, c.Param("id"))
+	if redeliverErr != nil {
+		web.NewError(c, statusFor(redeliverErr), redeliverErr.Error())
+		return
+	}
+
+	c.JSON(http.StatusAccepted, webhookResponse(http.StatusAccepted, config.RedeliveryQueuedMsg, delivery))
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, config.ErrWebhookNotFound),
+		errors.Is(err, config.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, config.ErrInvalidWebhookURL),
+		errors.Is(err, config.ErrInvalidEventTypes),
+		errors.Is(err, config.ErrInvalidWebhookSecret):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func webhookResponse(status int, message string, data interface{}) *dto.UserResponseDTO {
+	return &dto.UserResponseDTO{
+		Status:  status,
+		Message: message,
+		Data:    data,
+	}
+}
@@ -0,0 +1,161 @@
+package webhook
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gustyaguero21/go-core/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUsecases struct {
+	mock.Mock
+}
+
+func (m *MockUsecases) CreateSubscription(ctx context.Context, url string, eventTypes []string, secret string) (entity.Subscription, error) {
+	args := m.Called(ctx, url, eventTypes, secret)
+	return args.Get(0).(entity.Subscription), args.Error(1)
+}
+
+func (m *MockUsecases) ListSubscriptions(ctx context.Context) ([]entity.Subscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.Subscription), args.Error(1)
+}
+
+func (m *MockUsecases) DeleteSubscription(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUsecases) ListDeliveries(ctx context.Context, subscriptionID string) ([]entity.Delivery, error) {
+	args := m.Called(ctx, subscriptionID)
+	return args.Get(0).([]entity.Delivery), args.Error(1)
+}
+
+func (m *MockUsecases) Redeliver(ctx context.Context, deliveryID string) (entity.Delivery, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).(entity.Delivery), args.Error(1)
+}
+
+func newRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/webhooks", handler.CreateWebhookHandler)
+	r.GET("/webhooks", handler.ListWebhooksHandler)
+	r.DELETE("/webhooks/:id", handler.DeleteWebhookHandler)
+	r.GET("/webhooks/:id/deliveries", handler.ListDeliveriesHandler)
+	r.POST("/webhook-deliveries/:id/redeliver", handler.RedeliverHandler)
+	return r
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	tests := []struct {
+		Name           string
+		Method         string
+		Target         string
+		Body           string
+		MockFunc       func(m *MockUsecases)
+		ExpectedStatus int
+	}{
+		{
+			Name:   "Create_Success",
+			Method: http.MethodPost,
+			Target: "/webhooks",
+			Body:   `{"url":"https://partner.example.com","event_types":["user.created"]}`,
+			MockFunc: func(m *MockUsecases) {
+				m.On("CreateSubscription", mock.Anything, "https://partner.example.com", []string{config.EventUserCreated}, "").
+					Return(entity.Subscription{ID: "sub-1", Secret: "generated"}, nil).Once()
+			},
+			ExpectedStatus: http.StatusCreated,
+		},
+		{
+			Name:           "Create_MissingURL",
+			Method:         http.MethodPost,
+			Target:         "/webhooks",
+			Body:           `{"event_types":["user.created"]}`,
+			MockFunc:       func(m *MockUsecases) {},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "Create_InvalidEventTypes",
+			Method: http.MethodPost,
+			Target: "/webhooks",
+			Body:   `{"url":"https://partner.example.com","event_types":["nope"]}`,
+			MockFunc: func(m *MockUsecases) {
+				m.On("CreateSubscription", mock.Anything, "https://partner.example.com", []string{"nope"}, "").
+					Return(entity.Subscription{}, apperror.AppError(config.ErrCreatingWebhook, config.ErrInvalidEventTypes)).Once()
+			},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+		{
+			Name:   "List_Success",
+			Method: http.MethodGet,
+			Target: "/webhooks",
+			MockFunc: func(m *MockUsecases) {
+				m.On("ListSubscriptions", mock.Anything).Return([]entity.Subscription{{ID: "sub-1"}}, nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "Delete_NotFound",
+			Method: http.MethodDelete,
+			Target: "/webhooks/missing",
+			MockFunc: func(m *MockUsecases) {
+				m.On("DeleteSubscription", mock.Anything, "missing").
+					Return(apperror.AppError(config.ErrDeletingWebhook, config.ErrWebhookNotFound)).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:   "Deliveries_Success",
+			Method: http.MethodGet,
+			Target: "/webhooks/sub-1/deliveries",
+			MockFunc: func(m *MockUsecases) {
+				m.On("ListDeliveries", mock.Anything, "sub-1").Return([]entity.Delivery{{ID: "delivery-1"}}, nil).Once()
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Name:   "Redeliver_Queued",
+			Method: http.MethodPost,
+			Target: "/webhook-deliveries/delivery-1/redeliver",
+			MockFunc: func(m *MockUsecases) {
+				m.On("Redeliver", mock.Anything, "delivery-1").
+					Return(entity.Delivery{ID: "delivery-1", Status: config.DeliveryPending}, nil).Once()
+			},
+			ExpectedStatus: http.StatusAccepted,
+		},
+		{
+			Name:   "Redeliver_NotFound",
+			Method: http.MethodPost,
+			Target: "/webhook-deliveries/missing/redeliver",
+			MockFunc: func(m *MockUsecases) {
+				m.On("Redeliver", mock.Anything, "missing").
+					Return(entity.Delivery{}, apperror.AppError(config.ErrRedelivering, config.ErrDeliveryNotFound)).Once()
+			},
+			ExpectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			mockUsecase := new(MockUsecases)
+			tt.MockFunc(mockUsecase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.Method, tt.Target, strings.NewReader(tt.Body))
+			req.Header.Set("Content-Type", "application/json")
+			newRouter(NewWebhookHandler(mockUsecase)).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedStatus, w.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
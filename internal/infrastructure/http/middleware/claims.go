@@ -5,5 +5,6 @@ import "github.com/golang-jwt/jwt/v5"
 type Claims struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Scope    string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
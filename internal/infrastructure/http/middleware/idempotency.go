@@ -0,0 +1,265 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Idempotency makes retries of a mutation safe: the first response to a
+// request sent with an Idempotency-Key is stored per caller and replayed for
+// later requests with the same key instead of running the handler again.
+type Idempotency struct {
+	Store   idempotency.Store
+	Enabled bool
+	TTL     time.Duration
+	Now     func() time.Time
+}
+
+func NewIdempotency(store idempotency.Store, enabled bool, ttl time.Duration) *Idempotency {
+	return &Idempotency{Store: store, Enabled: enabled, TTL: ttl, Now: time.Now}
+}
+
+// Idempotent goes after authentication on the route, so caller can tell
+// clients apart. Requests without the header are not affected. A 5xx
+// response is not kept: the key is released and a retry runs the handler
+// again. Like the rate limiter, it lets requests through when the store
+// fails. Large bodies are spooled to disk, so a route taking uploads must
+// cap the body before this middleware.
+func (i *Idempotency) Idempotent(caller KeyFunc) gin.HandlerFunc {
+	if !i.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(config.IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > config.MaxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": config.InvalidIdempotencyKeyMsg})
+			c.Abort()
+			return
+		}
+
+		body, err := spool(c.Request.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": config.UploadTooLargeMsg})
+			} else {
+				c.JSON(http.StatusBadRequest, gin.H{"error": config.InvalidBodyMsg})
+			}
+			c.Abort()
+			return
+		}
+		defer body.Close()
+
+		now := i.Now().UTC()
+		record := idempotency.Record{
+			Caller:    caller(c),
+			Key:       key,
+			CreatedAt: now,
+			ExpiresAt: now.Add(config.IdempotencyLockTimeout),
+		}
+		record.Fingerprint, err = fingerprint(record, c.Request, body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": config.InvalidBodyMsg})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(body)
+
+		existing, reserved, err := i.Store.Reserve(c, record)
+		if err != nil {
+			slog.WarnContext(c, "idempotency store unavailable", "error", err)
+			c.Next()
+			return
+		}
+		if !reserved {
+			replay(c, record, existing)
+			return
+		}
+
+		i.record(c, record)
+	}
+}
+
+// record runs the handler and stores its response. The key is released if
+// the handler fails or panics, so the client can retry.
+func (i *Idempotency) record(c *gin.Context, record idempotency.Record) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+		if err := i.Store.Release(ctx, record.Caller, record.Key); err != nil {
+			slog.WarnContext(ctx, "error releasing idempotency key", "error", err)
+		}
+	}()
+
+	c.Next()
+
+	if recorder.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	record.Status = recorder.Status()
+	record.ContentType = recorder.Header().Get("Content-Type")
+	record.Headers = replayHeaders(recorder.Header())
+	record.Body = recorder.body.Bytes()
+	record.ExpiresAt = i.Now().UTC().Add(i.TTL)
+
+	if err := i.Store.Complete(ctx, record); err != nil {
+		slog.WarnContext(ctx, "error storing idempotent response", "error", err)
+		return
+	}
+	stored = true
+}
+
+func replay(c *gin.Context, record, existing idempotency.Record) {
+	switch {
+	case existing.Fingerprint != record.Fingerprint:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": config.IdempotencyMismatchMsg})
+	case !existing.Completed():
+		c.JSON(http.StatusConflict, gin.H{"error": config.IdempotencyInFlightMsg})
+	default:
+		for name, values := range existing.Headers {
+			// Headers set in front of this middleware, like the rate limit
+			// of this request, are newer than the stored ones.
+			if c.Writer.Header().Get(name) == "" {
+				c.Writer.Header()[name] = values
+			}
+		}
+		c.Header(config.IdempotentReplayedHeader, "true")
+		c.Data(existing.Status, existing.ContentType, existing.Body)
+	}
+	c.Abort()
+}
+
+// replayHeaders picks the headers worth sending again from a response.
+func replayHeaders(header http.Header) http.Header {
+	kept := http.Header{}
+	for _, name := range config.IdempotentReplayHeaders {
+		if values := header.Values(name); len(values) > 0 {
+			kept[http.CanonicalHeaderKey(name)] = slices.Clone(values)
+		}
+	}
+	return kept
+}
+
+// Sweep deletes expired records until ctx is cancelled.
+func (i *Idempotency) Sweep(ctx context.Context) {
+	ticker := time.NewTicker(config.IdempotencySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := i.Store.Purge(ctx, i.Now().UTC()); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "error purging idempotency keys", "error", err)
+		}
+	}
+}
+
+// fingerprint ties a key to one request: the same key sent to another route
+// or with another body is a client error, not a retry. Bodies can hold
+// passwords, so only the hash is kept, salted with the caller and key.
+// The body is rewound afterwards for the handler.
+func fingerprint(record idempotency.Record, r *http.Request, body io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	for _, part := range []string{record.Caller, record.Key, r.Method, r.URL.RequestURI()} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	if _, err := io.Copy(h, body); err != nil {
+		return "", err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// spooledBody is a request body read ahead of the handler. Close removes the
+// temporary file when the body did not fit in memory.
+type spooledBody struct {
+	io.ReadSeeker
+	file *os.File
+}
+
+func (sb *spooledBody) Close() error {
+	if sb.file == nil {
+		return nil
+	}
+	sb.file.Close()
+	return os.Remove(sb.file.Name())
+}
+
+// spool keeps bodies up to config.IdempotencyMemoryBody in memory and writes
+// larger ones, such as bulk imports, to a temporary file.
+func spool(r io.Reader) (*spooledBody, error) {
+	head, err := io.ReadAll(io.LimitReader(r, config.IdempotencyMemoryBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(head) <= config.IdempotencyMemoryBody {
+		return &spooledBody{ReadSeeker: bytes.NewReader(head)}, nil
+	}
+
+	file, err := os.CreateTemp("", config.IdempotencySpoolPattern)
+	if err != nil {
+		return nil, err
+	}
+	body := &spooledBody{ReadSeeker: file, file: file}
+	if _, err := file.Write(head); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		body.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return body, nil
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+func (rr *responseRecorder) WriteString(s string) (int, error) {
+	rr.body.WriteString(s)
+	return rr.ResponseWriter.WriteString(s)
+}
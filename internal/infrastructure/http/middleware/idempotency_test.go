@@ -0,0 +1,252 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/idempotency"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryKeys mirrors the conditions of the SQL queries.
+type memoryKeys struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+	err     error
+}
+
+func (m *memoryKeys) Reserve(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return idempotency.Record{}, false, m.err
+	}
+	id := record.Caller + "|" + record.Key
+	if existing, ok := m.records[id]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		return existing, false, nil
+	}
+	m.records[id] = record
+	return record, true, nil
+}
+
+func (m *memoryKeys) Complete(ctx context.Context, record idempotency.Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[record.Caller+"|"+record.Key] = record
+	return nil
+}
+
+func (m *memoryKeys) Release(ctx context.Context, caller, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, caller+"|"+key)
+	return nil
+}
+
+func (m *memoryKeys) Purge(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+type idempotencyTest struct {
+	router *gin.Engine
+	keys   *memoryKeys
+	calls  int
+}
+
+// newIdempotencyTest serves POST /create. The handler answers with the
+// number of times it ran and the status given in the query.
+func newIdempotencyTest(t *testing.T, enabled bool, nested func() *httptest.ResponseRecorder) *idempotencyTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	it := &idempotencyTest{keys: &memoryKeys{records: map[string]idempotency.Record{}}}
+	idem := NewIdempotency(it.keys, enabled, time.Hour)
+
+	it.router = gin.New()
+	it.router.POST("/create", idem.Idempotent(ByIP), func(c *gin.Context) {
+		it.calls++
+		if nested != nil && it.calls == 1 {
+			assert.Equal(t, http.StatusConflict, nested().Code)
+		}
+		status := http.StatusCreated
+		if c.Query("fail") != "" {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, gin.H{"calls": it.calls})
+	})
+	return it
+}
+
+func (it *idempotencyTest) send(key, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.RemoteAddr = "192.0.2.1:4321"
+	if key != "" {
+		req.Header.Set(config.IdempotencyKeyHeader, key)
+	}
+	it.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	it := newIdempotencyTest(t, true, nil)
+
+	first := it.send("key-1", "/create", `{"username":"johndoe"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(config.IdempotentReplayedHeader))
+
+	retry := it.send("key-1", "/create", `{"username":"johndoe"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(config.IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+	assert.Equal(t, 1, it.calls)
+
+	mismatch := it.send("key-1", "/create", `{"username":"janedoe"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+
+	other := it.send("key-2", "/create", `{"username":"johndoe"}`)
+	assert.Equal(t, http.StatusCreated, other.Code)
+	assert.Equal(t, 2, it.calls)
+
+	record := it.keys.records["ip:192.0.2.1|key-1"]
+	assert.True(t, record.Completed())
+}
+
+func TestIdempotency_ReplaysHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := &memoryKeys{records: map[string]idempotency.Record{}}
+	idem := NewIdempotency(keys, true, time.Hour)
+
+	remaining := 10
+	limit := func(c *gin.Context) {
+		remaining--
+		c.Header(config.RateLimitRemainingHeader, fmt.Sprint(remaining))
+		c.Next()
+	}
+
+	router := gin.New()
+	router.POST("/jobs", limit, idem.Idempotent(ByIP), func(c *gin.Context) {
+		c.Header("Location", "/jobs/1")
+		c.Header(config.RetryAfterHeader, "5")
+		c.Header(config.RateLimitPolicyHeader, `"api";q=100;w=60`)
+		c.Header("X-Debug", "first")
+		c.JSON(http.StatusAccepted, gin.H{"id": "1"})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{}`))
+		req.Header.Set(config.IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send()
+	assert.Equal(t, http.StatusAccepted, first.Code)
+
+	retry := send()
+	assert.Equal(t, http.StatusAccepted, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(config.IdempotentReplayedHeader))
+	assert.Equal(t, "/jobs/1", retry.Header().Get("Location"))
+	assert.Equal(t, "5", retry.Header().Get(config.RetryAfterHeader))
+	assert.Equal(t, `"api";q=100;w=60`, retry.Header().Get(config.RateLimitPolicyHeader))
+	assert.Equal(t, "8", retry.Header().Get(config.RateLimitRemainingHeader))
+	assert.Empty(t, retry.Header().Get("X-Debug"))
+}
+
+func TestIdempotency_ConcurrentDuplicate(t *testing.T) {
+	var it *idempotencyTest
+	it = newIdempotencyTest(t, true, func() *httptest.ResponseRecorder {
+		return it.send("key-1", "/create", `{}`)
+	})
+
+	assert.Equal(t, http.StatusCreated, it.send("key-1", "/create", `{}`).Code)
+	assert.Equal(t, 1, it.calls)
+}
+
+func TestIdempotency_ReleasesFailedRequest(t *testing.T) {
+	it := newIdempotencyTest(t, true, nil)
+
+	assert.Equal(t, http.StatusServiceUnavailable, it.send("key-1", "/create?fail=1", `{}`).Code)
+	assert.Empty(t, it.keys.records)
+
+	assert.Equal(t, http.StatusServiceUnavailable, it.send("key-1", "/create?fail=1", `{}`).Code)
+	assert.Equal(t, 2, it.calls)
+}
+
+func TestIdempotency_LargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	spoolDir := t.TempDir()
+	t.Setenv("TMPDIR", spoolDir)
+
+	idem := NewIdempotency(&memoryKeys{records: map[string]idempotency.Record{}}, true, time.Hour)
+	router := gin.New()
+	router.POST("/users/import", LimitBody(2*config.IdempotencyMemoryBody), idem.Idempotent(ByIP), func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		assert.NoError(t, err)
+		c.JSON(http.StatusAccepted, gin.H{"bytes": len(body)})
+	})
+	send := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users/import", strings.NewReader(body))
+		req.Header.Set(config.IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	large := strings.Repeat("x", config.IdempotencyMemoryBody+10)
+	first := send(large)
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.JSONEq(t, fmt.Sprintf(`{"bytes":%d}`, len(large)), first.Body.String())
+
+	retry := send(large)
+	assert.Equal(t, "true", retry.Header().Get(config.IdempotentReplayedHeader))
+	assert.Equal(t, http.StatusUnprocessableEntity, send(large+"y").Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, send(strings.Repeat("x", 2*config.IdempotencyMemoryBody+1)).Code)
+
+	spooled, err := os.ReadDir(spoolDir)
+	assert.NoError(t, err)
+	assert.Empty(t, spooled)
+}
+
+func TestIdempotency_PassThrough(t *testing.T) {
+	tests := []struct {
+		Name          string
+		Enabled       bool
+		Key           string
+		StoreErr      error
+		ExpectedCode  int
+		ExpectedCalls int
+	}{
+		{Name: "NoKey", Enabled: true, ExpectedCode: http.StatusCreated, ExpectedCalls: 2},
+		{Name: "Disabled", Key: "key-1", ExpectedCode: http.StatusCreated, ExpectedCalls: 2},
+		{Name: "StoreDown_FailsOpen", Enabled: true, Key: "key-1", StoreErr: errors.New("connection refused"), ExpectedCode: http.StatusCreated, ExpectedCalls: 2},
+		{Name: "KeyTooLong", Enabled: true, Key: strings.Repeat("k", config.MaxIdempotencyKeyLength+1), ExpectedCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			it := newIdempotencyTest(t, tt.Enabled, nil)
+			it.keys.err = tt.StoreErr
+
+			it.send(tt.Key, "/create", `{}`)
+			w := it.send(tt.Key, "/create", `{}`)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedCalls, it.calls)
+			assert.Empty(t, w.Header().Get(config.IdempotentReplayedHeader))
+		})
+	}
+}
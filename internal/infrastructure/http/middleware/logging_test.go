@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/infrastructure/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	test := []struct {
+		Name       string
+		IncomingID string
+		KeepID     bool
+	}{
+		{
+			Name:       "RequestID_Propagated",
+			IncomingID: "client-req-1",
+			KeepID:     true,
+		},
+		{
+			Name: "RequestID_Generated",
+		},
+		{
+			Name:       "RequestID_InvalidReplaced",
+			IncomingID: "bad id\nwith newline",
+		},
+		{
+			Name:       "RequestID_TooLongReplaced",
+			IncomingID: strings.Repeat("a", config.MaxRequestIDLength+1),
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := logging.NewLogger(&buf, "info")
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.ContextWithFallback = true
+			r.Use(RequestID(), AccessLog(logger))
+
+			var seenInContext string
+			r.GET("/search", func(c *gin.Context) {
+				seenInContext = logging.RequestID(c)
+				logger.InfoContext(c, "handled", "password", "hunter2")
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/search?username=johndoe", nil)
+			req.Header.Set("Authorization", "Bearer secret-token")
+			if tt.IncomingID != "" {
+				req.Header.Set(config.RequestIDHeader, tt.IncomingID)
+			}
+			r.ServeHTTP(w, req)
+
+			responseID := w.Header().Get(config.RequestIDHeader)
+			assert.NotEmpty(t, responseID)
+			assert.Equal(t, responseID, seenInContext)
+			if tt.KeepID {
+				assert.Equal(t, tt.IncomingID, responseID)
+			} else {
+				assert.NotEqual(t, tt.IncomingID, responseID)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+			assert.NotContains(t, buf.String(), "hunter2")
+			assert.NotContains(t, buf.String(), "secret-token")
+
+			var access map[string]any
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &access))
+			assert.Equal(t, responseID, access[config.LogKeyRequestID])
+			assert.Equal(t, "/search", access["path"])
+			assert.Equal(t, float64(http.StatusOK), access["status"])
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.NewLogger(&buf, "info")
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.ContextWithFallback = true
+	r.Use(RequestID(), Recovery(logger))
+	r.GET("/boom", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/boom", nil)
+	req.Header.Set(config.APIKeyHeader, "gmh_secret-key")
+	req.Header.Set("Cookie", "session=secret-cookie")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, buf.String(), "secret-key")
+	assert.NotContains(t, buf.String(), "secret-cookie")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "panic recovered", entry["msg"])
+	assert.Equal(t, "boom", entry["error"])
+	assert.Equal(t, w.Header().Get(config.RequestIDHeader), entry[config.LogKeyRequestID])
+}
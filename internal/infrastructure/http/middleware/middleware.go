@@ -1,9 +1,15 @@
 package middleware
 
 import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
+	"go-manage-hex/cmd/config"
+	apikey "go-manage-hex/internal/core/apikey"
+	"go-manage-hex/internal/core/scope"
 	auth "go-manage-hex/internal/core/user"
 
 	"github.com/gin-gonic/gin"
@@ -11,13 +17,19 @@ import (
 
 type Middleware struct {
 	AuthService auth.Authorization
+	APIKeys     apikey.Authenticator
 }
 
-func NewMiddleware(authSvc auth.Authorization) *Middleware {
-	return &Middleware{AuthService: authSvc}
+func NewMiddleware(authSvc auth.Authorization, apiKeys apikey.Authenticator) *Middleware {
+	return &Middleware{AuthService: authSvc, APIKeys: apiKeys}
 }
 
 func (m *Middleware) RequireAuth(c *gin.Context) {
+	if rawKey := c.GetHeader(config.APIKeyHeader); rawKey != "" {
+		m.authenticateAPIKey(c, rawKey)
+		return
+	}
+
 	authHeader := c.GetHeader("Authorization")
 	if authHeader == "" {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header missing"})
@@ -34,13 +46,87 @@ func (m *Middleware) RequireAuth(c *gin.Context) {
 
 	tokenString := parts[1]
 
-	username, err := m.AuthService.ValidateJWT(tokenString)
+	if strings.HasPrefix(tokenString, config.APIKeyTokenPrefix) {
+		m.authenticateAPIKey(c, tokenString)
+		return
+	}
+
+	claims, err := m.AuthService.ValidateJWT(tokenString)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 		c.Abort()
 		return
 	}
 
-	c.Set("username", username)
+	c.Set(config.CtxUsername, claims.Username)
+	c.Set(config.CtxAuthMethod, config.AuthMethodJWT)
+	c.Set(config.CtxScopes, claims.Scopes)
+	c.Next()
+}
+
+func (m *Middleware) RequireStaticToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bearer, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" || !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (m *Middleware) RequireScope(required ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, _ := c.Get(config.CtxScopes)
+		scopes, _ := granted.([]string)
+
+		if !scope.Has(scopes, required...) {
+			c.Header("WWW-Authenticate", fmt.Sprintf(
+				`Bearer error="insufficient_scope", scope=%q, error_description="the request requires the %s scope"`,
+				scope.String(required), scope.String(required),
+			))
+			c.JSON(http.StatusForbidden, gin.H{"error": config.InsufficientScopeMsg})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func (m *Middleware) authenticateAPIKey(c *gin.Context, rawKey string) {
+	if m.APIKeys == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": config.ErrInvalidAPIKey.Error()})
+		c.Abort()
+		return
+	}
+
+	key, err := m.APIKeys.Authenticate(c, rawKey)
+	if err != nil {
+		message := config.ErrInvalidAPIKey.Error()
+		if errors.Is(err, config.ErrAPIKeyExpired) || errors.Is(err, config.ErrAPIKeyRevoked) {
+			message = err.Error()
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+		c.Abort()
+		return
+	}
+
+	c.Set(config.CtxUsername, key.Username)
+	c.Set(config.CtxAuthMethod, config.AuthMethodAPIKey)
+	c.Set(config.CtxAPIKeyID, key.ID)
+	c.Set(config.CtxScopes, key.Scopes)
 	c.Next()
 }
+
+// LimitBody caps the request body at limit bytes. Reading past it fails with
+// *http.MaxBytesError, which handlers report as 413.
+func LimitBody(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"go-manage-hex/cmd/config"
+	apikey "go-manage-hex/internal/core/apikey"
+	auth "go-manage-hex/internal/core/user"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -15,20 +19,20 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (a *MockAuthService) GenerateJWT(username string) (string, error) {
-	args := a.Called(username)
+func (a *MockAuthService) GenerateJWT(username string, scopes []string) (string, error) {
+	args := a.Called(username, scopes)
 	return args.String(0), args.Error(1)
 }
 
-func (a *MockAuthService) ValidateJWT(token string) (string, error) {
+func (a *MockAuthService) ValidateJWT(token string) (auth.TokenClaims, error) {
 	args := a.Called(token)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(auth.TokenClaims), args.Error(1)
 }
 
 func TestRequireAuth(t *testing.T) {
 
 	mockAuthService := new(MockAuthService)
-	mw := NewMiddleware(mockAuthService)
+	mw := NewMiddleware(mockAuthService, nil)
 
 	tests := []struct {
 		Name           string
@@ -59,7 +63,7 @@ func TestRequireAuth(t *testing.T) {
 			Name:       "Invalid token",
 			AuthHeader: "Bearer invalidtoken",
 			MockValidate: func() {
-				mockAuthService.On("ValidateJWT", "invalidtoken").Return("", errors.New("invalid token")).Once()
+				mockAuthService.On("ValidateJWT", "invalidtoken").Return(auth.TokenClaims{}, errors.New("invalid token")).Once()
 			},
 			ExpectedCode: http.StatusUnauthorized,
 			ExpectedBody: `{"error":"invalid token"}`,
@@ -69,7 +73,7 @@ func TestRequireAuth(t *testing.T) {
 			Name:       "Valid token",
 			AuthHeader: "Bearer validtoken",
 			MockValidate: func() {
-				mockAuthService.On("ValidateJWT", "validtoken").Return("user123", nil).Once()
+				mockAuthService.On("ValidateJWT", "validtoken").Return(auth.TokenClaims{Username: "user123"}, nil).Once()
 			},
 			ExpectedCode:   http.StatusOK,
 			ExpectNext:     true,
@@ -110,3 +114,154 @@ func TestRequireAuth(t *testing.T) {
 		})
 	}
 }
+
+type MockAPIKeys struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeys) Authenticate(ctx context.Context, rawKey string) (apikey.APIKey, error) {
+	args := m.Called(ctx, rawKey)
+	return args.Get(0).(apikey.APIKey), args.Error(1)
+}
+
+func TestRequireAuth_APIKey(t *testing.T) {
+	mockAuthService := new(MockAuthService)
+	mockAPIKeys := new(MockAPIKeys)
+	mw := NewMiddleware(mockAuthService, mockAPIKeys)
+
+	tests := []struct {
+		Name         string
+		Headers      map[string]string
+		MockFunc     func()
+		ExpectedCode int
+		ExpectedBody string
+	}{
+		{
+			Name:    "X-API-Key header",
+			Headers: map[string]string{config.APIKeyHeader: "gmh_abc_secret"},
+			MockFunc: func() {
+				mockAPIKeys.On("Authenticate", mock.Anything, "gmh_abc_secret").
+					Return(apikey.APIKey{ID: "1", Username: "user123", Scopes: []string{"users:read"}}, nil).Once()
+			},
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"username":"user123","method":"api_key","scopes":["users:read"]}`,
+		},
+		{
+			Name:    "Bearer with api key prefix",
+			Headers: map[string]string{"Authorization": "Bearer gmh_abc_secret"},
+			MockFunc: func() {
+				mockAPIKeys.On("Authenticate", mock.Anything, "gmh_abc_secret").
+					Return(apikey.APIKey{ID: "1", Username: "user123", Scopes: []string{}}, nil).Once()
+			},
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `{"username":"user123","method":"api_key","scopes":[]}`,
+		},
+		{
+			Name:    "Expired api key",
+			Headers: map[string]string{config.APIKeyHeader: "gmh_abc_secret"},
+			MockFunc: func() {
+				mockAPIKeys.On("Authenticate", mock.Anything, "gmh_abc_secret").
+					Return(apikey.APIKey{}, config.ErrAPIKeyExpired).Once()
+			},
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: `{"error":"api key expired"}`,
+		},
+		{
+			Name:    "Unknown api key",
+			Headers: map[string]string{config.APIKeyHeader: "gmh_abc_secret"},
+			MockFunc: func() {
+				mockAPIKeys.On("Authenticate", mock.Anything, "gmh_abc_secret").
+					Return(apikey.APIKey{}, config.ErrInvalidAPIKey).Once()
+			},
+			ExpectedCode: http.StatusUnauthorized,
+			ExpectedBody: `{"error":"invalid api key"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.MockFunc()
+
+			router := gin.New()
+			router.Use(mw.RequireAuth)
+			router.GET("/", func(c *gin.Context) {
+				scopes, _ := c.Get(config.CtxScopes)
+				c.JSON(http.StatusOK, gin.H{
+					"username": c.GetString(config.CtxUsername),
+					"method":   c.GetString(config.CtxAuthMethod),
+					"scopes":   scopes,
+				})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.Headers {
+				req.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.JSONEq(t, tt.ExpectedBody, w.Body.String())
+		})
+	}
+
+	mockAPIKeys.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestRequireScope(t *testing.T) {
+	mw := NewMiddleware(new(MockAuthService), nil)
+
+	tests := []struct {
+		Name           string
+		Granted        []string
+		Required       []string
+		ExpectedCode   int
+		ExpectedHeader string
+	}{
+		{
+			Name:         "Scope granted",
+			Granted:      []string{config.ScopeUsersRead, config.ScopeUsersDelete},
+			Required:     []string{config.ScopeUsersDelete},
+			ExpectedCode: http.StatusOK,
+		},
+		{
+			Name:           "Scope missing",
+			Granted:        []string{config.ScopeUsersRead},
+			Required:       []string{config.ScopeUsersDelete},
+			ExpectedCode:   http.StatusForbidden,
+			ExpectedHeader: `Bearer error="insufficient_scope", scope="users:delete", error_description="the request requires the users:delete scope"`,
+		},
+		{
+			Name:           "No scopes in context",
+			Required:       []string{config.ScopeUsersRead},
+			ExpectedCode:   http.StatusForbidden,
+			ExpectedHeader: `Bearer error="insufficient_scope", scope="users:read", error_description="the request requires the users:read scope"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.Granted != nil {
+					c.Set(config.CtxScopes, tt.Granted)
+				}
+				c.Next()
+			})
+			router.DELETE("/", mw.RequireScope(tt.Required...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			assert.Equal(t, tt.ExpectedHeader, w.Header().Get("WWW-Authenticate"))
+			if tt.ExpectedCode == http.StatusForbidden {
+				assert.JSONEq(t, `{"error":"insufficient scope"}`, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyFunc picks the client a request is counted against.
+type KeyFunc func(c *gin.Context) string
+
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts requests per authenticated user and falls back to the client
+// IP before authentication has run.
+func ByUser(c *gin.Context) string {
+	if username := c.GetString(config.CtxUsername); username != "" {
+		return "user:" + username
+	}
+	return ByIP(c)
+}
+
+// ByAPIKey gives every API key its own budget, separate from the login
+// tokens of the same user.
+func ByAPIKey(c *gin.Context) string {
+	if id := c.GetString(config.CtxAPIKeyID); id != "" {
+		return "key:" + id
+	}
+	return ByUser(c)
+}
+
+type RateLimiter struct {
+	Store   ratelimit.Store
+	Enabled bool
+}
+
+func NewRateLimiter(store ratelimit.Store, enabled bool) *RateLimiter {
+	return &RateLimiter{Store: store, Enabled: enabled}
+}
+
+// Limit enforces policy on the route. If the store fails the request is let
+// through: an outage of a shared store must not take the API down with it.
+func (rl *RateLimiter) Limit(policy ratelimit.Policy, key KeyFunc) gin.HandlerFunc {
+	if !rl.Enabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		decision, err := rl.Store.Take(c, key(c), policy)
+		if err != nil {
+			slog.WarnContext(c, "rate limit store unavailable", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header(config.RateLimitLimitHeader, strconv.Itoa(decision.Limit))
+		c.Header(config.RateLimitRemainingHeader, strconv.Itoa(decision.Remaining))
+		c.Header(config.RateLimitResetHeader, seconds(decision.Reset))
+		c.Header(config.RateLimitPolicyHeader, policyHeader)
+
+		if !decision.Allowed {
+			c.Header(config.RetryAfterHeader, seconds(decision.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": config.RateLimitExceededMsg})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds rounds up so a client that waits as told is never denied again.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type storeFunc func(key string, policy ratelimit.Policy) (ratelimit.Decision, error)
+
+func (f storeFunc) Take(ctx context.Context, key string, policy ratelimit.Policy) (ratelimit.Decision, error) {
+	return f(key, policy)
+}
+
+func TestRateLimit(t *testing.T) {
+	policy := ratelimit.Policy{Name: config.RateLimitPolicyLogin, Limit: 10, Period: time.Minute}
+
+	tests := []struct {
+		Name            string
+		Enabled         bool
+		Decision        ratelimit.Decision
+		StoreErr        error
+		ExpectedCode    int
+		ExpectedHeaders map[string]string
+	}{
+		{
+			Name:         "Allowed",
+			Enabled:      true,
+			Decision:     ratelimit.Decision{Allowed: true, Limit: 10, Remaining: 9, Reset: 5500 * time.Millisecond},
+			ExpectedCode: http.StatusOK,
+			ExpectedHeaders: map[string]string{
+				config.RateLimitLimitHeader:     "10",
+				config.RateLimitRemainingHeader: "9",
+				config.RateLimitResetHeader:     "6",
+				config.RateLimitPolicyHeader:    "10;w=60",
+				config.RetryAfterHeader:         "",
+			},
+		},
+		{
+			Name:         "Denied",
+			Enabled:      true,
+			Decision:     ratelimit.Decision{Limit: 10, Reset: time.Minute, RetryAfter: 5900 * time.Millisecond},
+			ExpectedCode: http.StatusTooManyRequests,
+			ExpectedHeaders: map[string]string{
+				config.RateLimitRemainingHeader: "0",
+				config.RetryAfterHeader:         "6",
+			},
+		},
+		{
+			Name:            "StoreDown_FailsOpen",
+			Enabled:         true,
+			StoreErr:        errors.New("connection refused"),
+			ExpectedCode:    http.StatusOK,
+			ExpectedHeaders: map[string]string{config.RateLimitLimitHeader: ""},
+		},
+		{
+			Name:            "Disabled",
+			Decision:        ratelimit.Decision{Limit: 10},
+			ExpectedCode:    http.StatusOK,
+			ExpectedHeaders: map[string]string{config.RateLimitLimitHeader: ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var seenKey string
+			store := storeFunc(func(key string, p ratelimit.Policy) (ratelimit.Decision, error) {
+				seenKey = key
+				assert.Equal(t, policy, p)
+				return tt.Decision, tt.StoreErr
+			})
+
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/login", NewRateLimiter(store, tt.Enabled).Limit(policy, ByIP), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/login", nil)
+			req.RemoteAddr = "192.0.2.1:4321"
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.ExpectedCode, w.Code)
+			for header, value := range tt.ExpectedHeaders {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+			if tt.Enabled {
+				assert.Equal(t, "ip:192.0.2.1", seenKey)
+			} else {
+				assert.Empty(t, seenKey)
+			}
+		})
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Values      map[string]string
+		ExpectedKey string
+	}{
+		{
+			Name:        "Anonymous",
+			ExpectedKey: "ip:192.0.2.1",
+		},
+		{
+			Name:        "User",
+			Values:      map[string]string{config.CtxUsername: "johndoe"},
+			ExpectedKey: "user:johndoe",
+		},
+		{
+			Name:        "APIKey",
+			Values:      map[string]string{config.CtxUsername: "johndoe", config.CtxAPIKeyID: "key-1"},
+			ExpectedKey: "key:key-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/search", nil)
+			c.Request.RemoteAddr = "192.0.2.1:4321"
+			for key, value := range tt.Values {
+				c.Set(key, value)
+			}
+
+			assert.Equal(t, tt.ExpectedKey, ByAPIKey(c))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"go-manage-hex/cmd/config"
+)
+
+type Worker struct {
+	Name string
+	Run  func(ctx context.Context)
+}
+
+type Closer struct {
+	Name  string
+	Close func(ctx context.Context) error
+}
+
+// App owns the HTTP server and everything that has to be stopped with it.
+// On shutdown the server drains first, then workers stop in reverse start
+// order, then closers run in reverse registration order.
+type App struct {
+	Server          *http.Server
+	ShutdownTimeout time.Duration
+
+	workers []Worker
+	closers []Closer
+	running []runningWorker
+}
+
+type runningWorker struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewApp(handler http.Handler, cfg config.ServerConfig) *App {
+	return &App{
+		Server: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: config.DefaultReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+		},
+		ShutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+func (a *App) AddWorker(name string, run func(ctx context.Context)) {
+	a.workers = append(a.workers, Worker{Name: name, Run: run})
+}
+
+func (a *App) AddCloser(name string, close func(ctx context.Context) error) {
+	a.closers = append(a.closers, Closer{Name: name, Close: close})
+}
+
+func (a *App) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", a.Server.Addr)
+	if err != nil {
+		a.close(context.Background())
+		return err
+	}
+	return a.Serve(ctx, listener)
+}
+
+// Serve blocks until ctx is cancelled or the server fails, then shuts down
+// within ShutdownTimeout.
+func (a *App) Serve(ctx context.Context, listener net.Listener) error {
+	a.startWorkers()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- a.Server.Serve(listener) }()
+
+	slog.Info("server started", "addr", listener.Addr().String())
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", a.ShutdownTimeout.String())
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(err, a.Shutdown(shutdownCtx))
+}
+
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	if err := a.Server.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	errs = append(errs, a.stopWorkers(ctx), a.close(ctx))
+
+	return errors.Join(errs...)
+}
+
+func (a *App) startWorkers() {
+	for _, worker := range a.workers {
+		workerCtx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+			worker.Run(workerCtx)
+		}()
+
+		a.running = append(a.running, runningWorker{name: worker.Name, cancel: cancel, done: done})
+	}
+}
+
+func (a *App) stopWorkers(ctx context.Context) error {
+	var errs []error
+
+	for i := len(a.running) - 1; i >= 0; i-- {
+		worker := a.running[i]
+		worker.cancel()
+
+		select {
+		case <-worker.done:
+		case <-ctx.Done():
+			slog.Warn("worker did not stop before the shutdown deadline", "worker", worker.name)
+			errs = append(errs, ctx.Err())
+		}
+	}
+	a.running = nil
+
+	return errors.Join(errs...)
+}
+
+func (a *App) close(ctx context.Context) error {
+	var errs []error
+
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		closer := a.closers[i]
+		if err := closer.Close(ctx); err != nil {
+			slog.Error("error closing resource", "resource", closer.Name, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	a.closers = nil
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *recorder) add(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+func (r *recorder) all() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.steps...)
+}
+
+func startApp(t *testing.T, handler http.Handler, shutdownTimeout time.Duration, steps *recorder) (*App, string, context.CancelFunc, chan error) {
+	server := config.Defaults().Server
+	server.ShutdownTimeout = shutdownTimeout
+	app := NewApp(handler, server)
+
+	for _, name := range []string{"first", "second"} {
+		app.AddWorker(name, func(ctx context.Context) {
+			<-ctx.Done()
+			steps.add("worker " + name)
+		})
+	}
+	for _, name := range []string{"mysql", "tracer"} {
+		app.AddCloser(name, func(ctx context.Context) error {
+			steps.add("close " + name)
+			return nil
+		})
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Serve(ctx, listener) }()
+
+	return app, "http://" + listener.Addr().String(), cancel, done
+}
+
+func TestAppGracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	steps := &recorder{}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		steps.add("request")
+		io.WriteString(w, "done")
+	})
+
+	_, url, cancel, done := startApp(t, handler, time.Second, steps)
+
+	response := make(chan string, 1)
+	go func() {
+		res, err := http.Get(url)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-response)
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"request", "worker second", "worker first", "close tracer", "close mysql"}, steps.all())
+
+	_, err := http.Get(url)
+	assert.Error(t, err)
+}
+
+func TestAppShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	steps := &recorder{}
+	_, url, cancel, done := startApp(t, handler, 50*time.Millisecond, steps)
+
+	go http.Get(url)
+
+	<-started
+	cancel()
+
+	err := <-done
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Contains(t, steps.all(), "close mysql")
+}
+
+func TestAppServeError(t *testing.T) {
+	steps := &recorder{}
+	app := NewApp(http.NotFoundHandler(), config.Defaults().Server)
+	app.AddCloser("mysql", func(ctx context.Context) error {
+		steps.add("close mysql")
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	listener.Close()
+
+	assert.Error(t, app.Serve(context.Background(), listener))
+	assert.Equal(t, []string{"close mysql"}, steps.all())
+}
@@ -1,11 +1,51 @@
 package server
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"log/slog"
 
-func StartServer() *gin.Engine {
-	serve := gin.Default()
+	"go-manage-hex/cmd/config"
+	secretEntity "go-manage-hex/internal/core/secret"
+	"go-manage-hex/internal/infrastructure/db"
+	"go-manage-hex/internal/infrastructure/http/middleware"
+	"go-manage-hex/internal/infrastructure/metrics"
+	"go-manage-hex/internal/infrastructure/secret"
+	"go-manage-hex/internal/infrastructure/tracing"
 
-	UrlMapping(serve)
+	"github.com/gin-gonic/gin"
+)
 
-	return serve
+func NewServer(logger *slog.Logger, cfg *config.Config, secrets secretEntity.Provider) (*App, error) {
+	dbPassword := secret.NewValue(cfg.MySQL.Password)
+	conn, err := db.DatabaseConn(cfg.MySQL, dbPassword.Get)
+	if err != nil {
+		return nil, err
+	}
+
+	serve := gin.New()
+	serve.ContextWithFallback = true
+	if err := serve.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	app := NewApp(serve, cfg.Server)
+	app.AddCloser("mysql", func(ctx context.Context) error { return conn.Close() })
+
+	watcher := secret.NewWatcher(cfg.Secrets.WatchInterval)
+	watcher.Watch(config.MySQLPasswordEnv, secret.Reader(secrets, config.MySQLPasswordEnv), func(value string) error {
+		dbPassword.Set(value)
+		return nil
+	})
+	app.AddWorker("secret_watcher", watcher.Run)
+
+	appMetrics := metrics.NewMetrics()
+	serve.Use(middleware.RequestID(), tracing.Middleware(), appMetrics.Middleware(), middleware.AccessLog(logger), middleware.Recovery(logger))
+
+	if err := UrlMapping(serve, app, conn, appMetrics, watcher, secrets, cfg); err != nil {
+		app.close(context.Background())
+		return nil, err
+	}
+
+	return app, nil
 }
@@ -1,52 +1,313 @@
 package server
 
 import (
+	"context"
+	"database/sql"
 	"go-manage-hex/cmd/config"
 	"go-manage-hex/internal/infrastructure/auth"
-	"go-manage-hex/internal/infrastructure/db"
-	"time"
-
-	"log"
+	"go-manage-hex/internal/infrastructure/bulk"
+	"go-manage-hex/internal/infrastructure/federation"
+	"go-manage-hex/internal/infrastructure/hasher"
+	"go-manage-hex/internal/infrastructure/health"
+	"go-manage-hex/internal/infrastructure/metrics"
+	"go-manage-hex/internal/infrastructure/oidc"
+	"go-manage-hex/internal/infrastructure/publisher"
+	"go-manage-hex/internal/infrastructure/ratelimit"
+	"go-manage-hex/internal/infrastructure/secret"
+	"go-manage-hex/internal/infrastructure/tracing"
+	"go-manage-hex/internal/infrastructure/webhook"
+	"os"
 
+	apiKeyService "go-manage-hex/internal/app/apikey"
+	bulkService "go-manage-hex/internal/app/bulk"
+	eventService "go-manage-hex/internal/app/event"
+	federationService "go-manage-hex/internal/app/federation"
+	grantService "go-manage-hex/internal/app/grant"
+	healthService "go-manage-hex/internal/app/health"
+	jobService "go-manage-hex/internal/app/job"
+	oidcService "go-manage-hex/internal/app/oidc"
 	service "go-manage-hex/internal/app/user"
+	webhookService "go-manage-hex/internal/app/webhook"
+	federationEntity "go-manage-hex/internal/core/federation"
+	rateLimitEntity "go-manage-hex/internal/core/ratelimit"
+	secretEntity "go-manage-hex/internal/core/secret"
+	apiKeyRepository "go-manage-hex/internal/infrastructure/db/apikey"
+	federationRepository "go-manage-hex/internal/infrastructure/db/federation"
+	grantRepository "go-manage-hex/internal/infrastructure/db/grant"
+	idempotencyRepository "go-manage-hex/internal/infrastructure/db/idempotency"
+	jobRepository "go-manage-hex/internal/infrastructure/db/job"
+	"go-manage-hex/internal/infrastructure/db/migration"
+	oidcRepository "go-manage-hex/internal/infrastructure/db/oidc"
+	outboxRepository "go-manage-hex/internal/infrastructure/db/outbox"
 	repository "go-manage-hex/internal/infrastructure/db/user"
+	webhookRepository "go-manage-hex/internal/infrastructure/db/webhook"
+	apiKeyHandler "go-manage-hex/internal/infrastructure/http/handler/apikey"
+	bulkHandler "go-manage-hex/internal/infrastructure/http/handler/bulk"
+	eventHandler "go-manage-hex/internal/infrastructure/http/handler/event"
+	federationHandler "go-manage-hex/internal/infrastructure/http/handler/federation"
+	healthHandler "go-manage-hex/internal/infrastructure/http/handler/health"
+	jobHandler "go-manage-hex/internal/infrastructure/http/handler/job"
+	oidcHandler "go-manage-hex/internal/infrastructure/http/handler/oidc"
+	scimHandler "go-manage-hex/internal/infrastructure/http/handler/scim"
 	handler "go-manage-hex/internal/infrastructure/http/handler/user"
+	webhookHandler "go-manage-hex/internal/infrastructure/http/handler/webhook"
 	middleware "go-manage-hex/internal/infrastructure/http/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
-func UrlMapping(s *gin.Engine) {
+func UrlMapping(s *gin.Engine, app *App, db *sql.DB, appMetrics *metrics.Metrics, watcher *secret.Watcher, secrets secretEntity.Provider, cfg *config.Config) error {
+
+	appMetrics.RegisterDB(db, cfg.MySQL.Database)
+
+	migrator := migration.NewMigratorMysql(db)
+	if err := migrator.Up(context.Background()); err != nil {
+		return err
+	}
+
+	userRepo := tracing.NewTracedUserRepository(metrics.NewInstrumentedUserRepository(repository.NewUserMysql(db), appMetrics))
+
+	baseHasher, err := NewPasswordHasher(cfg)
+	if err != nil {
+		return err
+	}
+	pwdHasher := hasher.NewPooledHasher(metrics.NewInstrumentedHasher(baseHasher, cfg.Hashing.Algorithm, appMetrics), cfg.Hashing.PoolSize, cfg.Hashing.QueueTimeout)
+	appMetrics.RegisterHashPool(pwdHasher)
+
+	breachChecker, err := NewBreachChecker(cfg)
+	if err != nil {
+		return err
+	}
+
+	userTx := tracing.NewTracedTransactor(metrics.NewInstrumentedTransactor(outboxRepository.NewMysqlTransactor(db), appMetrics))
+	userProvisioner := service.NewUserProvisioner(userRepo, userTx)
+
+	loginSources, err := NewLoginSources(cfg, userRepo, pwdHasher, userProvisioner)
+	if err != nil {
+		return err
+	}
+
+	outboxRepo := outboxRepository.NewOutboxMysql(db)
+
+	eventPublisher, err := publisher.NewPublisher(
+		cfg.Events.Publisher,
+		cfg.Events.FilePath,
+		cfg.Events.WebhookURL,
+		cfg.Events.PublisherTimeout,
+	)
+	if err != nil {
+		return err
+	}
+
+	webhookSubs := webhookRepository.NewSubscriptionMysql(db)
+	webhookDeliveries := webhookRepository.NewDeliveryMysql(db)
+
+	webhookDispatcher := webhookService.NewDispatcher(
+		webhookSubs,
+		webhookDeliveries,
+		webhook.NewHTTPSender(cfg.Webhooks.Timeout),
+		cfg.Webhooks.MaxAttempts,
+		cfg.Webhooks.BackoffBase,
+		cfg.Webhooks.BackoffMax,
+		cfg.Webhooks.Timeout+config.WebhookClaimMargin,
+	)
+
+	eventStream := eventService.NewBroadcaster(cfg.Events.StreamBufferSize, cfg.Events.StreamClientQueue)
+
+	relay := eventService.NewRelay(
+		outboxRepo,
+		publisher.NewMultiPublisher(eventPublisher, webhookDispatcher, eventStream),
+		cfg.Events.OutboxBatchSize,
+		cfg.Events.OutboxPollInterval,
+		cfg.Events.OutboxMaxAttempts,
+		cfg.Events.OutboxBackoffBase,
+		cfg.Events.OutboxBackoffMax,
+		cfg.Events.PublisherTimeout+config.OutboxClaimMargin,
+	)
+
+	app.AddWorker("webhook_dispatcher", func(ctx context.Context) {
+		webhookDispatcher.Run(ctx, cfg.Webhooks.PollInterval)
+	})
+	app.AddWorker("outbox_relay", relay.Run)
+	app.Server.RegisterOnShutdown(eventStream.Close)
+
+	userService := tracing.NewTracedUsecases(metrics.NewInstrumentedUsecases(service.NewUserService(userRepo, pwdHasher, NewPasswordPolicy(cfg), breachChecker, userTx, loginSources...), appMetrics))
+	userGrants := grantService.NewGrantService(grantRepository.NewGrantMysql(db), userRepo, cfg.Auth.LoginScopes)
+
+	jwtService := auth.NewJWTService(cfg.Auth.JWTSecret, cfg.Auth.JWTTTL)
+	watcher.Watch(config.JWTSecretEnv, secret.Reader(secrets, config.JWTSecretEnv), jwtService.Rotate)
+
+	authService := metrics.NewInstrumentedAuthorization(jwtService, appMetrics)
+	apiKeyRepo := apiKeyRepository.NewAPIKeyMysql(db)
+
+	apiKeys := apiKeyService.NewAPIKeyService(apiKeyRepo, userRepo)
 
-	db, err := db.DatabaseConn()
+	mw := middleware.NewMiddleware(authService, apiKeys)
+	limiter := middleware.NewRateLimiter(ratelimit.NewMemoryStore(), cfg.RateLimit.Enabled)
+	idempotencyKeys := middleware.NewIdempotency(idempotencyRepository.NewIdempotencyMysql(db), cfg.Idempotency.Enabled, cfg.Idempotency.TTL)
+
+	app.AddWorker("idempotency_sweeper", idempotencyKeys.Sweep)
+
+	oidcClients := oidcRepository.NewClientMysql(db)
+
+	oidcSigner, err := oidc.LoadRSASigner(cfg.OIDC.SigningKeyPath)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if path := cfg.OIDC.SigningKeyPath; path != "" {
+		watcher.Watch(config.SigningKeySecret, func() (string, error) {
+			content, readErr := os.ReadFile(path)
+			return string(content), readErr
+		}, func(value string) error {
+			return oidcSigner.Reload([]byte(value))
+		})
+	}
+
+	oidcProvider := oidcService.NewOIDCService(oidcClients, oidc.NewMemoryCodeStore(), userService, userGrants, authService, oidcSigner, cfg.OIDC.Issuer, config.DefaultIDTokenTTL, cfg.Auth.JWTTTL)
+
+	identityRepo := federationRepository.NewIdentityMysql(db)
+
+	var upstreams []federationEntity.IdentityProvider
+	for _, provider := range cfg.Federation.Providers {
+		upstreams = append(upstreams, federation.NewOIDCProvider(
+			provider.Name,
+			provider.Issuer,
+			provider.ClientID,
+			provider.ClientSecret,
+			provider.RedirectURL,
+		))
 	}
 
-	userRepo := repository.NewUserMysql(db)
-	userRepo.CreateTable(config.GetMysqlTable())
+	federatedLogin := federationService.NewFederationService(upstreams, identityRepo, federationRepository.NewStateMysql(db), userRepo, userProvisioner, cfg.Federation.AutoProvision, cfg.Federation.LinkByEmail)
+
+	jobQueue := jobService.NewQueue(
+		jobRepository.NewJobMysql(db),
+		jobService.DefaultOwner(),
+		cfg.Jobs.Workers,
+		cfg.Jobs.MaxAttempts,
+		cfg.Jobs.LeaseTTL,
+		cfg.Jobs.PollInterval,
+		cfg.Jobs.BackoffBase,
+		cfg.Jobs.BackoffMax,
+	)
+
+	bulkUsers := bulkService.NewBulkService(userService, cfg.Bulk.BatchSize)
+	jobQueue.Register(config.JobTypeUserImport, bulk.ImportJob(bulkUsers))
+	jobQueue.Register(config.JobTypeUserExport, bulk.ExportJob(bulkUsers))
+
+	app.AddWorker("job_queue", jobQueue.Run)
+	app.AddWorker("bulk_file_sweeper", bulk.NewSweeper(cfg.Bulk.Dir, cfg.Bulk.FileTTL).Run)
+
+	userHandler := handler.NewUserHandler(userService, userGrants, authService)
+	apiKeysHandler := apiKeyHandler.NewAPIKeyHandler(apiKeys)
+	oidcProviderHandler := oidcHandler.NewOIDCHandler(oidcProvider)
+	federatedHandler := federationHandler.NewFederationHandler(federatedLogin, userGrants, authService)
+	provisioningHandler := scimHandler.NewSCIMHandler(userService, cfg.SCIM.BaseURL)
+	eventStreamHandler := eventHandler.NewEventStreamHandler(eventStream)
+	webhooksHandler := webhookHandler.NewWebhookHandler(webhookService.NewWebhookService(webhookSubs, webhookDeliveries))
+	bulkJobsHandler := bulkHandler.NewBulkHandler(jobQueue, cfg.Bulk.Dir, int64(cfg.Bulk.MaxUploadBytes))
+	jobsHandler := jobHandler.NewJobHandler(jobQueue)
+
+	probesHandler := healthHandler.NewHealthHandler(healthService.NewHealthService(
+		cfg.Health.CheckTimeout,
+		cfg.Health.CacheTTL,
+		health.NewDBCheck(db),
+		health.NewMigrationsCheck(migrator),
+		health.NewSigningKeyCheck(oidcSigner),
+	))
+
+	s.GET(config.LivenessPath, probesHandler.LivenessHandler)
+	s.GET(config.ReadinessPath, probesHandler.ReadinessHandler)
 
-	userService := service.NewUserService(userRepo)
+	if token := cfg.Metrics.Token; token != "" {
+		s.GET(config.MetricsPath, mw.RequireStaticToken(token), gin.WrapH(appMetrics.Handler()))
+	} else {
+		s.GET(config.MetricsPath, gin.WrapH(appMetrics.Handler()))
+	}
 
-	authService := auth.NewJWTService(config.GetJwtSecret(), time.Hour*1)
-	middleware := middleware.NewMiddleware(authService)
+	// Password hashing makes these routes expensive, so they are limited per
+	// client IP before any work is done.
+	loginLimit := limiter.Limit(rateLimitEntity.Policy{Name: config.RateLimitPolicyLogin, Limit: cfg.RateLimit.Login, Period: cfg.RateLimit.Period}, middleware.ByIP)
+	createLimit := limiter.Limit(rateLimitEntity.Policy{Name: config.RateLimitPolicyCreate, Limit: cfg.RateLimit.Create, Period: cfg.RateLimit.Period}, middleware.ByIP)
+	apiLimit := limiter.Limit(rateLimitEntity.Policy{Name: config.RateLimitPolicyAPI, Limit: cfg.RateLimit.API, Period: cfg.RateLimit.Period}, middleware.ByAPIKey)
 
-	userHandler := handler.NewUserHandler(userService, authService)
+	// Mutations honour Idempotency-Key. Login, token, API key and webhook
+	// creation routes are left out because their responses carry credentials
+	// or secrets that must not be stored.
+	idempotent := idempotencyKeys.Idempotent(middleware.ByAPIKey)
+	// /create has no caller to key on, so keys are scoped to the client IP.
+	// Clients behind the same NAT share that scope, but a replay also needs
+	// the same body, password included, so one client cannot read another's
+	// response; at worst a reused key is answered with 422.
+	anonymousIdempotent := idempotencyKeys.Idempotent(middleware.ByIP)
 
 	api := s.Group(config.BaseURL)
 
-	api.POST("/create", userHandler.CreateUserHandler)
-	api.POST("/login", userHandler.LoginUser)
+	api.POST("/create", createLimit, anonymousIdempotent, userHandler.CreateUserHandler)
+	api.POST("/login", loginLimit, userHandler.LoginUser)
+
+	api.GET(config.OIDCDiscoveryPath, oidcProviderHandler.DiscoveryHandler)
+	api.GET(config.OIDCJWKSPath, oidcProviderHandler.JWKSHandler)
+	api.GET(config.OIDCAuthorizePath, oidcProviderHandler.AuthorizeFormHandler)
+	api.POST(config.OIDCAuthorizePath, loginLimit, oidcProviderHandler.AuthorizeHandler)
+	api.POST(config.OIDCTokenPath, oidcProviderHandler.TokenHandler)
+	api.POST(config.OIDCRegisterPath, mw.RequireStaticToken(cfg.OIDC.RegistrationToken), oidcProviderHandler.RegisterHandler)
+
+	api.GET(config.FederatedLoginPath, federatedHandler.LoginHandler)
+	api.GET(config.FederatedCallbackPath, federatedHandler.CallbackHandler)
+
+	scim := api.Group(config.SCIMBasePath)
+	scim.Use(mw.RequireStaticToken(cfg.SCIM.Token))
+
+	scim.GET("/ServiceProviderConfig", provisioningHandler.ServiceProviderConfigHandler)
+	scim.GET("/ResourceTypes", provisioningHandler.ResourceTypesHandler)
+	scim.GET("/Schemas", provisioningHandler.SchemasHandler)
+	scim.GET("/Schemas/:id", provisioningHandler.SchemasHandler)
+	scim.GET("/Users", provisioningHandler.ListUsersHandler)
+	scim.POST("/Users", idempotent, provisioningHandler.CreateUserHandler)
+	scim.GET("/Users/:id", provisioningHandler.GetUserHandler)
+	scim.PUT("/Users/:id", provisioningHandler.ReplaceUserHandler)
+	scim.PATCH("/Users/:id", idempotent, provisioningHandler.PatchUserHandler)
+	scim.DELETE("/Users/:id", provisioningHandler.DeleteUserHandler)
+
+	api.GET(config.EventStreamPath, mw.RequireStaticToken(cfg.Admin.Token), eventStreamHandler.StreamHandler)
+
+	admin := api.Group(config.AdminBasePath)
+	admin.Use(mw.RequireStaticToken(cfg.Admin.Token))
+
+	admin.POST("/webhooks", webhooksHandler.CreateWebhookHandler)
+	admin.GET("/webhooks", webhooksHandler.ListWebhooksHandler)
+	admin.DELETE("/webhooks/:id", webhooksHandler.DeleteWebhookHandler)
+	admin.GET("/webhooks/:id/deliveries", webhooksHandler.ListDeliveriesHandler)
+	admin.POST("/webhook-deliveries/:id/redeliver", idempotent, webhooksHandler.RedeliverHandler)
+
+	admin.POST("/users/import", middleware.LimitBody(int64(cfg.Bulk.MaxUploadBytes)), idempotent, bulkJobsHandler.ImportHandler)
+	admin.POST("/users/export", idempotent, bulkJobsHandler.ExportHandler)
+	admin.GET("/jobs", jobsHandler.ListJobsHandler)
+	admin.GET("/jobs/:id", jobsHandler.GetJobHandler)
+	admin.GET("/jobs/:id/result", bulkJobsHandler.JobResultHandler)
 
 	protected := api.Group("/")
-	protected.Use(middleware.RequireAuth)
+	protected.Use(mw.RequireAuth, apiLimit)
+
+	protected.GET("/search", mw.RequireScope(config.ScopeUsersRead), userHandler.SearchUserHandler)
+
+	protected.DELETE("/delete", mw.RequireScope(config.ScopeUsersDelete), userHandler.DeleteUserHandler)
+
+	protected.PATCH("/update", mw.RequireScope(config.ScopeUsersWrite), idempotent, userHandler.UpdateUserHandler)
+
+	protected.PATCH("/change-password", mw.RequireScope(config.ScopeUsersWrite), idempotent, userHandler.ChangePwdHandler)
+
+	protected.POST("/api-keys", mw.RequireScope(config.ScopeUsersWrite), apiKeysHandler.CreateAPIKeyHandler)
 
-	protected.GET("/search", userHandler.SearchUserHandler)
+	protected.GET("/api-keys", mw.RequireScope(config.ScopeUsersRead), apiKeysHandler.ListAPIKeysHandler)
 
-	protected.DELETE("/delete", userHandler.DeleteUserHandler)
+	protected.DELETE("/api-keys", mw.RequireScope(config.ScopeUsersWrite), apiKeysHandler.RevokeAPIKeyHandler)
 
-	protected.PATCH("/update", userHandler.UpdateUserHandler)
+	protected.GET(config.OIDCUserInfoPath, mw.RequireScope(config.ScopeOpenID), oidcProviderHandler.UserInfoHandler)
+	protected.POST(config.OIDCUserInfoPath, mw.RequireScope(config.ScopeOpenID), oidcProviderHandler.UserInfoHandler)
 
-	protected.PATCH("/change-password", userHandler.ChangePwdHandler)
+	protected.GET(config.FederatedLinkPath, mw.RequireScope(config.ScopeUsersWrite), federatedHandler.LinkHandler)
 
+	return nil
 }
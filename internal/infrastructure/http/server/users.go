@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/infrastructure/breach"
+	"go-manage-hex/internal/infrastructure/hasher"
+	"go-manage-hex/internal/infrastructure/ldap"
+
+	service "go-manage-hex/internal/app/user"
+	userEntity "go-manage-hex/internal/core/user"
+)
+
+// The constructors below are shared with the admin CLI so both entry points
+// enforce the same hashing, password policy, breach and login rules.
+
+func NewPasswordHasher(cfg *config.Config) (userEntity.PasswordHasher, error) {
+	return hasher.NewPasswordHasher(
+		cfg.Hashing.Algorithm,
+		cfg.Hashing.BcryptCost,
+		hasher.Argon2Params{
+			Memory:      uint32(cfg.Hashing.Argon2MemoryKB),
+			Iterations:  uint32(cfg.Hashing.Argon2Iterations),
+			Parallelism: uint8(cfg.Hashing.Argon2Parallelism),
+			SaltLength:  config.DefaultArgon2SaltLength,
+			KeyLength:   config.DefaultArgon2KeyLength,
+		},
+	)
+}
+
+func NewPasswordPolicy(cfg *config.Config) service.PasswordPolicy {
+	return service.PasswordPolicy{
+		MinLength:        cfg.Password.MinLength,
+		MaxLength:        cfg.Password.MaxLength,
+		RequireUpper:     cfg.Password.RequireUpper,
+		RequireLower:     cfg.Password.RequireLower,
+		RequireDigit:     cfg.Password.RequireDigit,
+		RequireSymbol:    cfg.Password.RequireSymbol,
+		HistorySize:      cfg.Password.HistorySize,
+		DisallowUserInfo: cfg.Password.DisallowUserInfo,
+	}
+}
+
+func NewBreachChecker(cfg *config.Config) (userEntity.BreachedPasswordChecker, error) {
+	return breach.NewChecker(
+		cfg.Breach.Mode,
+		cfg.Breach.CorpusPath,
+		cfg.Breach.IndexPath,
+		cfg.Breach.APIURL,
+		cfg.Breach.APITimeout,
+	)
+}
+
+func NewLoginSources(cfg *config.Config, userRepo userEntity.MysqlRepository, pwdHasher userEntity.PasswordHasher, provisioner userEntity.Provisioner) ([]userEntity.AuthSource, error) {
+	authSources := map[string]userEntity.AuthSource{
+		config.AuthSourceLocal: service.NewLocalPasswordSource(userRepo, pwdHasher),
+		config.AuthSourceLDAP: ldap.NewLDAPSource(ldap.Options{
+			URL:          cfg.LDAP.URL,
+			BindDN:       cfg.LDAP.BindDN,
+			BindPassword: cfg.LDAP.BindPassword,
+			BaseDN:       cfg.LDAP.BaseDN,
+			UserFilter:   cfg.LDAP.UserFilter,
+			GroupFilter:  cfg.LDAP.GroupFilter,
+			Attributes: ldap.AttributeMap{
+				Username: cfg.LDAP.UsernameAttr,
+				Name:     cfg.LDAP.NameAttr,
+				LastName: cfg.LDAP.LastNameAttr,
+				Email:    cfg.LDAP.EmailAttr,
+			},
+			StartTLS:  cfg.LDAP.StartTLS,
+			Timeout:   cfg.LDAP.Timeout,
+			Provision: cfg.LDAP.Provision,
+		}, provisioner),
+	}
+
+	var loginSources []userEntity.AuthSource
+	for _, name := range cfg.Auth.Sources {
+		source, ok := authSources[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", config.ErrUnknownAuthSource, name)
+		}
+		loginSources = append(loginSources, source)
+	}
+	return loginSources, nil
+}
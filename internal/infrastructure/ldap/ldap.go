@@ -0,0 +1,175 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+type AttributeMap struct {
+	Username string
+	Name     string
+	LastName string
+	Email    string
+}
+
+type Options struct {
+	URL          string
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	UserFilter   string
+	GroupFilter  string
+	Attributes   AttributeMap
+	StartTLS     bool
+	Timeout      time.Duration
+	Provision    bool
+}
+
+type LDAPSource struct {
+	Options     Options
+	Provisioner mysqlUser.Provisioner
+}
+
+func NewLDAPSource(options Options, provisioner mysqlUser.Provisioner) mysqlUser.AuthSource {
+	return &LDAPSource{Options: options, Provisioner: provisioner}
+}
+
+func (ls *LDAPSource) Name() string {
+	return config.AuthSourceLDAP
+}
+
+func (ls *LDAPSource) Authenticate(ctx context.Context, username, password string) (mysqlUser.User, error) {
+	if password == "" {
+		return mysqlUser.User{}, config.ErrInvalidCredentials
+	}
+
+	conn, err := ls.dial()
+	if err != nil {
+		return mysqlUser.User{}, err
+	}
+	defer conn.Close()
+
+	if ls.Options.BindDN != "" {
+		if bindErr := conn.Bind(ls.Options.BindDN, ls.Options.BindPassword); bindErr != nil {
+			return mysqlUser.User{}, fmt.Errorf("%w: %v", config.ErrAuthSourceUnavailable, bindErr)
+		}
+	}
+
+	entry, err := ls.search(conn, username)
+	if err != nil {
+		return mysqlUser.User{}, err
+	}
+
+	if bindErr := conn.Bind(entry.DN, password); bindErr != nil {
+		if ldap.IsErrorWithCode(bindErr, ldap.LDAPResultInvalidCredentials) {
+			return mysqlUser.User{}, config.ErrInvalidCredentials
+		}
+		return mysqlUser.User{}, fmt.Errorf("%w: %v", config.ErrAuthSourceUnavailable, bindErr)
+	}
+
+	user := ls.mapEntry(entry, username)
+
+	// The directory knows nothing of local deletes, so a user deleted here
+	// must not get back in through LDAP.
+	if ls.Provisioner != nil && ls.Provisioner.IsDeleted(ctx, user.Username) {
+		return mysqlUser.User{}, config.ErrUserDeleted
+	}
+
+	if ls.Options.Provision {
+		ls.provision(ctx, user)
+	}
+
+	return user, nil
+}
+
+func (ls *LDAPSource) dial() (*ldap.Conn, error) {
+	dialer := ldap.DialWithDialer(&net.Dialer{Timeout: ls.Options.Timeout})
+
+	conn, err := ldap.DialURL(ls.Options.URL, dialer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", config.ErrAuthSourceUnavailable, err)
+	}
+	conn.SetTimeout(ls.Options.Timeout)
+
+	if ls.Options.StartTLS {
+		serverName := ""
+		if parsed, parseErr := url.Parse(ls.Options.URL); parseErr == nil {
+			serverName = parsed.Hostname()
+		}
+
+		if tlsErr := conn.StartTLS(&tls.Config{ServerName: serverName}); tlsErr != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %v", config.ErrAuthSourceUnavailable, tlsErr)
+		}
+	}
+
+	return conn, nil
+}
+
+func (ls *LDAPSource) search(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	filter := fmt.Sprintf(ls.Options.UserFilter, ldap.EscapeFilter(username))
+	if ls.Options.GroupFilter != "" {
+		filter = "(&" + filter + ls.Options.GroupFilter + ")"
+	}
+
+	attrs := ls.Options.Attributes
+	request := ldap.NewSearchRequest(
+		ls.Options.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ls.Options.Timeout.Seconds()), false,
+		filter,
+		[]string{attrs.Username, attrs.Name, attrs.LastName, attrs.Email},
+		nil,
+	)
+
+	result, err := conn.Search(request)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("%w: %v", config.ErrAuthSourceUnavailable, err)
+	}
+
+	switch {
+	case result == nil || len(result.Entries) == 0:
+		return nil, config.ErrUserNotFound
+	case len(result.Entries) > 1:
+		return nil, config.ErrAmbiguousLDAPUser
+	}
+
+	return result.Entries[0], nil
+}
+
+func (ls *LDAPSource) mapEntry(entry *ldap.Entry, username string) mysqlUser.User {
+	attrs := ls.Options.Attributes
+
+	user := mysqlUser.User{
+		Username: entry.GetAttributeValue(attrs.Username),
+		Name:     entry.GetAttributeValue(attrs.Name),
+		LastName: entry.GetAttributeValue(attrs.LastName),
+		Email:    entry.GetAttributeValue(attrs.Email),
+	}
+	if user.Username == "" {
+		user.Username = username
+	}
+
+	return user
+}
+
+func (ls *LDAPSource) provision(ctx context.Context, user mysqlUser.User) {
+	if ls.Provisioner == nil {
+		return
+	}
+
+	if _, createErr := ls.Provisioner.ProvisionUser(ctx, user); createErr != nil && !errors.Is(createErr, config.ErrUserAlreadyExists) {
+		slog.ErrorContext(ctx, "error provisioning ldap user", "username", user.Username, "error", createErr)
+	}
+}
@@ -0,0 +1,351 @@
+package ldap
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var filterTerm = regexp.MustCompile(`\(([^=()&|!]+)=([^()]*)\)`)
+
+type directoryEntry struct {
+	DN       string
+	Password string
+	Attrs    map[string]string
+}
+
+type directory struct {
+	listener     net.Listener
+	serviceDN    string
+	servicePwd   string
+	entries      []directoryEntry
+	searchFilter string
+}
+
+func newDirectory(t *testing.T, entries ...directoryEntry) *directory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	dir := &directory{
+		listener:   listener,
+		serviceDN:  "cn=service,dc=example,dc=com",
+		servicePwd: "service-secret",
+		entries:    entries,
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go dir.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return dir
+}
+
+func (d *directory) url() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *directory) serve(conn net.Conn) {
+	defer conn.Close()
+
+	bound := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		id := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if d.authenticate(dn, password) {
+				code, bound = ldap.LDAPResultSuccess, dn
+			}
+			conn.Write(result(id, ldap.ApplicationBindResponse, code).Bytes())
+
+		case ldap.ApplicationSearchRequest:
+			if bound != d.serviceDN {
+				conn.Write(result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights).Bytes())
+				continue
+			}
+
+			d.searchFilter, _ = ldap.DecompileFilter(op.Children[6])
+			for _, entry := range d.entries {
+				if matches(entry, d.searchFilter) {
+					conn.Write(searchEntry(id, entry).Bytes())
+				}
+			}
+			conn.Write(result(id, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (d *directory) authenticate(dn, password string) bool {
+	if dn == d.serviceDN {
+		return password == d.servicePwd
+	}
+	for _, entry := range d.entries {
+		if entry.DN == dn {
+			return password != "" && password == entry.Password
+		}
+	}
+	return false
+}
+
+func matches(entry directoryEntry, filter string) bool {
+	terms := filterTerm.FindAllStringSubmatch(filter, -1)
+	for _, term := range terms {
+		if entry.Attrs[term[1]] != term[2] {
+			return false
+		}
+	}
+	return len(terms) > 0
+}
+
+func envelope(id int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func octets(value string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "")
+}
+
+func result(id int64, tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(octets(""))
+	op.AppendChild(octets(""))
+	return envelope(id, op)
+}
+
+func searchEntry(id int64, entry directoryEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(octets(entry.DN))
+
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, value := range entry.Attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(octets(name))
+		values := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		values.AppendChild(octets(value))
+		attr.AppendChild(values)
+		attrs.AppendChild(attr)
+	}
+	op.AppendChild(attrs)
+
+	return envelope(id, op)
+}
+
+type memoryUsers struct {
+	users   map[string]mysqlUser.User
+	deleted map[string]bool
+}
+
+func (mu *memoryUsers) ProvisionUser(ctx context.Context, user mysqlUser.User) (mysqlUser.User, error) {
+	if _, ok := mu.users[user.Username]; ok {
+		return mysqlUser.User{}, config.ErrUserAlreadyExists
+	}
+	user.ID = uuid.NewString()
+	mu.users[user.Username] = user
+	return user, nil
+}
+
+func (mu *memoryUsers) IsDeleted(ctx context.Context, username string) bool {
+	return mu.deleted[username]
+}
+
+func testOptions(url string) Options {
+	return Options{
+		URL:          url,
+		BindDN:       "cn=service,dc=example,dc=com",
+		BindPassword: "service-secret",
+		BaseDN:       "ou=people,dc=example,dc=com",
+		UserFilter:   config.DefaultLDAPUserFilter,
+		GroupFilter:  "(memberOf=cn=staff,ou=groups,dc=example,dc=com)",
+		Attributes: AttributeMap{
+			Username: config.DefaultLDAPUsernameAttr,
+			Name:     config.DefaultLDAPNameAttr,
+			LastName: config.DefaultLDAPLastNameAttr,
+			Email:    config.DefaultLDAPEmailAttr,
+		},
+		Timeout: time.Second,
+	}
+}
+
+func TestLDAPSource_Authenticate(t *testing.T) {
+	dir := newDirectory(t,
+		directoryEntry{
+			DN:       "uid=jdoe,ou=people,dc=example,dc=com",
+			Password: "Corp0rate!",
+			Attrs: map[string]string{
+				"uid":       "jdoe",
+				"givenName": "John",
+				"sn":        "Doe",
+				"mail":      "jdoe@example.com",
+				"memberOf":  "cn=staff,ou=groups,dc=example,dc=com",
+			},
+		},
+		directoryEntry{
+			DN:       "uid=guest,ou=people,dc=example,dc=com",
+			Password: "Guest123!",
+			Attrs:    map[string]string{"uid": "guest", "memberOf": "cn=guests,ou=groups,dc=example,dc=com"},
+		},
+	)
+
+	test := []struct {
+		Name        string
+		Username    string
+		Password    string
+		Options     func(Options) Options
+		ExpectedErr error
+	}{
+		{
+			Name:     "Authenticate_Success",
+			Username: "jdoe",
+			Password: "Corp0rate!",
+		},
+		{
+			Name:        "Authenticate_WrongPassword",
+			Username:    "jdoe",
+			Password:    "wrong",
+			ExpectedErr: config.ErrInvalidCredentials,
+		},
+		{
+			Name:        "Authenticate_EmptyPassword",
+			Username:    "jdoe",
+			Password:    "",
+			ExpectedErr: config.ErrInvalidCredentials,
+		},
+		{
+			Name:        "Authenticate_UnknownUser",
+			Username:    "nobody",
+			Password:    "whatever",
+			ExpectedErr: config.ErrUserNotFound,
+		},
+		{
+			Name:        "Authenticate_NotInGroup",
+			Username:    "guest",
+			Password:    "Guest123!",
+			ExpectedErr: config.ErrUserNotFound,
+		},
+		{
+			Name:        "Authenticate_FilterInjection",
+			Username:    "*",
+			Password:    "Corp0rate!",
+			ExpectedErr: config.ErrUserNotFound,
+		},
+		{
+			Name:     "Authenticate_BadServiceCredentials",
+			Username: "jdoe",
+			Password: "Corp0rate!",
+			Options: func(o Options) Options {
+				o.BindPassword = "wrong"
+				return o
+			},
+			ExpectedErr: config.ErrAuthSourceUnavailable,
+		},
+		{
+			Name:     "Authenticate_DirectoryDown",
+			Username: "jdoe",
+			Password: "Corp0rate!",
+			Options: func(o Options) Options {
+				o.URL = "ldap://127.0.0.1:1"
+				return o
+			},
+			ExpectedErr: config.ErrAuthSourceUnavailable,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			options := testOptions(dir.url())
+			if tt.Options != nil {
+				options = tt.Options(options)
+			}
+
+			user, err := NewLDAPSource(options, nil).Authenticate(context.Background(), tt.Username, tt.Password)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "jdoe", user.Username)
+			assert.Equal(t, "John", user.Name)
+			assert.Equal(t, "Doe", user.LastName)
+			assert.Equal(t, "jdoe@example.com", user.Email)
+		})
+	}
+}
+
+func TestLDAPSource_Provision(t *testing.T) {
+	dir := newDirectory(t, directoryEntry{
+		DN:       "uid=jdoe,ou=people,dc=example,dc=com",
+		Password: "Corp0rate!",
+		Attrs:    map[string]string{"uid": "jdoe", "mail": "jdoe@example.com"},
+	})
+
+	options := testOptions(dir.url())
+	options.GroupFilter = ""
+	options.Provision = true
+
+	users := &memoryUsers{users: map[string]mysqlUser.User{}}
+	source := NewLDAPSource(options, users)
+
+	_, err := source.Authenticate(context.Background(), "jdoe", "Corp0rate!")
+	require.NoError(t, err)
+
+	provisioned, ok := users.users["jdoe"]
+	require.True(t, ok)
+	assert.NotEmpty(t, provisioned.ID)
+	assert.Empty(t, provisioned.Password)
+	assert.Equal(t, "jdoe@example.com", provisioned.Email)
+	assert.Equal(t, "(uid=jdoe)", dir.searchFilter)
+}
+
+func TestLDAPSource_DeletedLocally(t *testing.T) {
+	dir := newDirectory(t, directoryEntry{
+		DN:       "uid=jdoe,ou=people,dc=example,dc=com",
+		Password: "Corp0rate!",
+		Attrs:    map[string]string{"uid": "jdoe", "mail": "jdoe@example.com"},
+	})
+
+	options := testOptions(dir.url())
+	options.GroupFilter = ""
+
+	for _, provision := range []bool{false, true} {
+		options.Provision = provision
+		users := &memoryUsers{users: map[string]mysqlUser.User{}, deleted: map[string]bool{"jdoe": true}}
+
+		_, err := NewLDAPSource(options, users).Authenticate(context.Background(), "jdoe", "Corp0rate!")
+		assert.ErrorIs(t, err, config.ErrUserDeleted)
+		assert.Empty(t, users.users)
+	}
+}
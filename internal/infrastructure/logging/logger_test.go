@@ -0,0 +1,142 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"go-manage-hex/cmd/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]any {
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	return entry
+}
+
+func TestRedaction(t *testing.T) {
+	test := []struct {
+		Name     string
+		Attr     slog.Attr
+		Expected any
+	}{
+		{
+			Name:     "Redact_PasswordKey",
+			Attr:     slog.String("password", "hunter2"),
+			Expected: config.RedactedValue,
+		},
+		{
+			Name:     "Redact_NestedKey",
+			Attr:     slog.String("new_pwd", "hunter2"),
+			Expected: config.RedactedValue,
+		},
+		{
+			Name:     "Redact_HeaderName",
+			Attr:     slog.String("X-API-Key", "gmh_abc_def"),
+			Expected: config.RedactedValue,
+		},
+		{
+			Name:     "Redact_ClientSecret",
+			Attr:     slog.String("client_secret", "s3cr3t"),
+			Expected: config.RedactedValue,
+		},
+		{
+			Name:     "Redact_BearerInValue",
+			Attr:     slog.String("detail", "sent Bearer eyJhbGciOi.payload.sig upstream"),
+			Expected: "sent Bearer " + config.RedactedValue + " upstream",
+		},
+		{
+			Name:     "Redact_APIKeyInError",
+			Attr:     slog.Any("error", errors.New("rejected gmh_abcdef012345_secretpart")),
+			Expected: "rejected " + config.RedactedValue,
+		},
+		{
+			Name:     "Keep_PlainValue",
+			Attr:     slog.String("username", "johndoe"),
+			Expected: "johndoe",
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := NewLogger(&buf, "info")
+
+			logger.LogAttrs(context.Background(), slog.LevelInfo, "test", tt.Attr)
+
+			assert.Equal(t, tt.Expected, decode(t, &buf)[tt.Attr.Key])
+		})
+	}
+}
+
+func TestHeadersRedacted(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info")
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer abc.def.ghi")
+	header.Set("Cookie", "session=1")
+	header.Set("Accept", "application/json")
+
+	logger.Info("request", Headers(header))
+
+	headers := decode(t, &buf)["headers"].(map[string]any)
+	assert.Equal(t, config.RedactedValue, headers["Authorization"])
+	assert.Equal(t, config.RedactedValue, headers["Cookie"])
+	assert.Equal(t, "application/json", headers["Accept"])
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info").With("component", "test")
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-123"), "hello")
+
+	entry := decode(t, &buf)
+	assert.Equal(t, "req-123", entry[config.LogKeyRequestID])
+	assert.Equal(t, "test", entry["component"])
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "info")
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	logger.InfoContext(trace.ContextWithSpanContext(context.Background(), spanContext), "hello")
+
+	entry := decode(t, &buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry[config.LogKeyTraceID])
+	assert.Equal(t, "00f067aa0ba902b7", entry[config.LogKeySpanID])
+
+	buf.Reset()
+	logger.Info("no span")
+
+	entry = decode(t, &buf)
+	assert.NotContains(t, entry, config.LogKeyTraceID)
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, "warn")
+
+	logger.Info("dropped")
+	assert.Zero(t, buf.Len())
+
+	logger.Warn("kept")
+	assert.NotZero(t, buf.Len())
+
+	assert.Equal(t, slog.LevelInfo, parseLevel("verbose"))
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	userService "go-manage-hex/internal/app/user"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type InstrumentedUsecases struct {
+	userService.Usecases
+	Metrics *Metrics
+}
+
+func NewInstrumentedUsecases(next userService.Usecases, m *Metrics) userService.Usecases {
+	return &InstrumentedUsecases{Usecases: next, Metrics: m}
+}
+
+func (iu *InstrumentedUsecases) Login(ctx context.Context, username, password string) error {
+	err := iu.Usecases.Login(ctx, username, password)
+	if err == nil {
+		iu.Metrics.logins.WithLabelValues(config.MetricsResultSuccess, "").Inc()
+		return nil
+	}
+
+	iu.Metrics.logins.WithLabelValues(config.MetricsResultFailure, loginFailureReason(err)).Inc()
+	return err
+}
+
+type InstrumentedAuthorization struct {
+	mysqlUser.Authorization
+	Metrics *Metrics
+}
+
+func NewInstrumentedAuthorization(next mysqlUser.Authorization, m *Metrics) mysqlUser.Authorization {
+	return &InstrumentedAuthorization{Authorization: next, Metrics: m}
+}
+
+func (ia *InstrumentedAuthorization) ValidateJWT(tokenStr string) (mysqlUser.TokenClaims, error) {
+	claims, err := ia.Authorization.ValidateJWT(tokenStr)
+	if err != nil {
+		ia.Metrics.jwtFailures.WithLabelValues(jwtFailureReason(err)).Inc()
+	}
+	return claims, err
+}
+
+type InstrumentedHasher struct {
+	mysqlUser.PasswordHasher
+	Algorithm string
+	Metrics   *Metrics
+}
+
+func NewInstrumentedHasher(next mysqlUser.PasswordHasher, algorithm string, m *Metrics) mysqlUser.PasswordHasher {
+	return &InstrumentedHasher{PasswordHasher: next, Algorithm: algorithm, Metrics: m}
+}
+
+func (ih *InstrumentedHasher) Hash(ctx context.Context, password string) (string, error) {
+	defer ih.observe("hash", time.Now())
+	return ih.PasswordHasher.Hash(ctx, password)
+}
+
+func (ih *InstrumentedHasher) Verify(ctx context.Context, encoded, password string) (bool, error) {
+	defer ih.observe("verify", time.Now())
+	return ih.PasswordHasher.Verify(ctx, encoded, password)
+}
+
+func (ih *InstrumentedHasher) observe(operation string, start time.Time) {
+	ih.Metrics.hashDuration.WithLabelValues(ih.Algorithm, operation).Observe(time.Since(start).Seconds())
+}
+
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, config.ErrInvalidCredentials):
+		return "invalid_credentials"
+	case errors.Is(err, config.ErrUserNotFound):
+		return "unknown_user"
+	case errors.Is(err, config.ErrAuthSourceUnavailable):
+		return "source_unavailable"
+	case errors.Is(err, config.ErrHasherBusy):
+		return "hasher_busy"
+	default:
+		return "error"
+	}
+}
+
+func jwtFailureReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "expired"
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid_signature"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "not_valid_yet"
+	default:
+		return "invalid"
+	}
+}
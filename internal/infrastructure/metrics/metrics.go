@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-manage-hex/cmd/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	logins        *prometheus.CounterVec
+	jwtFailures   *prometheus.CounterVec
+	hashDuration  *prometheus.HistogramVec
+	queryDuration *prometheus.HistogramVec
+	queryErrors   *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "logins_total",
+			Help:      "Login attempts by result and failure reason.",
+		}, []string{"result", "reason"}),
+		jwtFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "jwt_validation_failures_total",
+			Help:      "Rejected JWTs by reason.",
+		}, []string{"reason"}),
+		hashDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "password_hash_duration_seconds",
+			Help:      "Password hashing and verification time by algorithm.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"algorithm", "operation"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Repository method latency.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"repository", "method"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "repository_query_errors_total",
+			Help:      "Repository method errors.",
+		}, []string{"repository", "method"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.logins,
+		m.jwtFailures,
+		m.hashDuration,
+		m.queryDuration,
+		m.queryErrors,
+	)
+
+	return m
+}
+
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// HashPool is the state of the password hashing pool exported for capacity
+// planning: a pool that is often full or rejecting calls needs more CPU.
+type HashPool interface {
+	Size() int
+	InUse() int
+	Waiting() int
+	Rejected() uint64
+}
+
+func (m *Metrics) RegisterHashPool(pool HashPool) {
+	m.Registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "password_hash_pool_size",
+			Help:      "Password hashing slots.",
+		}, func() float64 { return float64(pool.Size()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "password_hash_pool_in_use",
+			Help:      "Password hashing slots in use.",
+		}, func() float64 { return float64(pool.InUse()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "password_hash_pool_waiting",
+			Help:      "Calls waiting for a password hashing slot.",
+		}, func() float64 { return float64(pool.Waiting()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: config.MetricsNamespace,
+			Name:      "password_hash_pool_rejected_total",
+			Help:      "Calls that gave up waiting for a password hashing slot.",
+		}, func() float64 { return float64(pool.Rejected()) }),
+	)
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = config.MetricsUnmatchedRoute
+		}
+
+		m.httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) observeQuery(repository, method string, start time.Time, err error) {
+	m.queryDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.queryErrors.WithLabelValues(repository, method).Inc()
+	}
+}
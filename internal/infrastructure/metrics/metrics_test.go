@@ -0,0 +1,207 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-manage-hex/cmd/config"
+	userService "go-manage-hex/internal/app/user"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRepository struct {
+	mysqlUser.MysqlRepository
+	err error
+}
+
+func (s *stubRepository) GetByUsername(ctx context.Context, username string) (mysqlUser.User, error) {
+	return mysqlUser.User{Username: username}, s.err
+}
+
+func (s *stubRepository) NewUser(ctx context.Context, user mysqlUser.User) error {
+	return s.err
+}
+
+type stubUsecases struct {
+	userService.Usecases
+	err error
+}
+
+func (s *stubUsecases) Login(ctx context.Context, username, password string) error {
+	return s.err
+}
+
+type stubAuthorization struct {
+	mysqlUser.Authorization
+	err error
+}
+
+func (s *stubAuthorization) ValidateJWT(tokenStr string) (mysqlUser.TokenClaims, error) {
+	return mysqlUser.TokenClaims{}, s.err
+}
+
+type stubHasher struct {
+	mysqlUser.PasswordHasher
+}
+
+func (s *stubHasher) Hash(ctx context.Context, password string) (string, error) {
+	return "hash", nil
+}
+
+func (s *stubHasher) Verify(ctx context.Context, encoded, password string) (bool, error) {
+	return true, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	m := NewMetrics()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(m.Middleware())
+	r.GET("/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, target := range []string{"/users/1", "/users/2", "/missing"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/users/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, config.MetricsUnmatchedRoute, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestInstrumentedUserRepository(t *testing.T) {
+	test := []struct {
+		Name           string
+		Err            error
+		ExpectedErrors float64
+	}{
+		{
+			Name: "Repository_Success",
+		},
+		{
+			Name: "Repository_NotFoundIsNotAnError",
+			Err:  config.ErrUserNotFound,
+		},
+		{
+			Name:           "Repository_Error",
+			Err:            errors.New("connection reset"),
+			ExpectedErrors: 1,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			m := NewMetrics()
+			repo := NewInstrumentedUserRepository(&stubRepository{err: tt.Err}, m)
+
+			user, err := repo.GetByUsername(context.Background(), "johndoe")
+
+			assert.Equal(t, "johndoe", user.Username)
+			assert.Equal(t, tt.Err, err)
+			assert.Equal(t, 1, testutil.CollectAndCount(m.queryDuration))
+			assert.Equal(t, tt.ExpectedErrors, testutil.ToFloat64(m.queryErrors.WithLabelValues(userRepository, "GetByUsername")))
+		})
+	}
+}
+
+func TestLoginReasons(t *testing.T) {
+	test := []struct {
+		Name   string
+		Err    error
+		Result string
+		Reason string
+	}{
+		{Name: "Login_Success", Result: config.MetricsResultSuccess},
+		{Name: "Login_InvalidCredentials", Err: config.ErrInvalidCredentials, Result: config.MetricsResultFailure, Reason: "invalid_credentials"},
+		{Name: "Login_UnknownUser", Err: config.ErrUserNotFound, Result: config.MetricsResultFailure, Reason: "unknown_user"},
+		{Name: "Login_SourceUnavailable", Err: fmt.Errorf("%w: timeout", config.ErrAuthSourceUnavailable), Result: config.MetricsResultFailure, Reason: "source_unavailable"},
+		{Name: "Login_HasherBusy", Err: config.ErrHasherBusy, Result: config.MetricsResultFailure, Reason: "hasher_busy"},
+		{Name: "Login_Other", Err: errors.New("boom"), Result: config.MetricsResultFailure, Reason: "error"},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			m := NewMetrics()
+			service := NewInstrumentedUsecases(&stubUsecases{err: tt.Err}, m)
+
+			assert.Equal(t, tt.Err, service.Login(context.Background(), "johndoe", "secret"))
+			assert.Equal(t, float64(1), testutil.ToFloat64(m.logins.WithLabelValues(tt.Result, tt.Reason)))
+		})
+	}
+}
+
+func TestJWTFailureReasons(t *testing.T) {
+	test := []struct {
+		Name   string
+		Err    error
+		Reason string
+	}{
+		{Name: "JWT_Expired", Err: fmt.Errorf("invalid token: %w", jwt.ErrTokenExpired), Reason: "expired"},
+		{Name: "JWT_Malformed", Err: fmt.Errorf("invalid token: %w", jwt.ErrTokenMalformed), Reason: "malformed"},
+		{Name: "JWT_BadSignature", Err: fmt.Errorf("invalid token: %w", jwt.ErrTokenSignatureInvalid), Reason: "invalid_signature"},
+		{Name: "JWT_Other", Err: errors.New("invalid token"), Reason: "invalid"},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			m := NewMetrics()
+			authorization := NewInstrumentedAuthorization(&stubAuthorization{err: tt.Err}, m)
+
+			_, err := authorization.ValidateJWT("token")
+
+			assert.ErrorIs(t, err, tt.Err)
+			assert.Equal(t, float64(1), testutil.ToFloat64(m.jwtFailures.WithLabelValues(tt.Reason)))
+		})
+	}
+
+	m := NewMetrics()
+	NewInstrumentedAuthorization(&stubAuthorization{}, m).ValidateJWT("token")
+	assert.Equal(t, 0, testutil.CollectAndCount(m.jwtFailures))
+}
+
+type stubHashPool struct{}
+
+func (stubHashPool) Size() int        { return 4 }
+func (stubHashPool) InUse() int       { return 3 }
+func (stubHashPool) Waiting() int     { return 0 }
+func (stubHashPool) Rejected() uint64 { return 7 }
+
+func TestHandlerExposition(t *testing.T) {
+	m := NewMetrics()
+
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	m.RegisterDB(db, "users")
+
+	hasher := NewInstrumentedHasher(&stubHasher{}, config.HashBcrypt, m)
+	hasher.Hash(context.Background(), "secret")
+	hasher.Verify(context.Background(), "hash", "secret")
+	m.RegisterHashPool(stubHashPool{})
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, config.MetricsPath, nil))
+
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.Contains(string(body), `go_manage_hex_password_hash_duration_seconds_count{algorithm="bcrypt",operation="hash"} 1`))
+	assert.True(t, strings.Contains(string(body), `go_manage_hex_password_hash_duration_seconds_count{algorithm="bcrypt",operation="verify"} 1`))
+	assert.True(t, strings.Contains(string(body), `go_sql_open_connections{db_name="users"}`))
+	assert.True(t, strings.Contains(string(body), "go_manage_hex_password_hash_pool_in_use 3"))
+	assert.True(t, strings.Contains(string(body), "go_manage_hex_password_hash_pool_rejected_total 7"))
+	assert.True(t, strings.Contains(string(body), "go_goroutines"))
+}
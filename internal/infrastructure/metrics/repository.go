@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+)
+
+const userRepository = "user"
+
+type InstrumentedUserRepository struct {
+	Next    mysqlUser.MysqlRepository
+	Metrics *Metrics
+}
+
+func NewInstrumentedUserRepository(next mysqlUser.MysqlRepository, m *Metrics) mysqlUser.MysqlRepository {
+	return &InstrumentedUserRepository{Next: next, Metrics: m}
+}
+
+func (ir *InstrumentedUserRepository) CreateTable(tableName string) (err error) {
+	defer ir.observe("CreateTable", time.Now(), &err)
+	return ir.Next.CreateTable(tableName)
+}
+
+func (ir *InstrumentedUserRepository) GetByUsername(ctx context.Context, username string) (user mysqlUser.User, err error) {
+	defer ir.observe("GetByUsername", time.Now(), &err)
+	return ir.Next.GetByUsername(ctx, username)
+}
+
+func (ir *InstrumentedUserRepository) GetByEmail(ctx context.Context, email string) (user mysqlUser.User, err error) {
+	defer ir.observe("GetByEmail", time.Now(), &err)
+	return ir.Next.GetByEmail(ctx, email)
+}
+
+func (ir *InstrumentedUserRepository) GetByID(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	defer ir.observe("GetByID", time.Now(), &err)
+	return ir.Next.GetByID(ctx, id)
+}
+
+func (ir *InstrumentedUserRepository) GetByIDWithDeleted(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	defer ir.observe("GetByIDWithDeleted", time.Now(), &err)
+	return ir.Next.GetByIDWithDeleted(ctx, id)
+}
+
+func (ir *InstrumentedUserRepository) ListUsers(ctx context.Context, query mysqlUser.UserQuery) (users []mysqlUser.User, total int, err error) {
+	defer ir.observe("ListUsers", time.Now(), &err)
+	return ir.Next.ListUsers(ctx, query)
+}
+
+func (ir *InstrumentedUserRepository) CheckExists(ctx context.Context, username string) bool {
+	defer ir.observe("CheckExists", time.Now(), nil)
+	return ir.Next.CheckExists(ctx, username)
+}
+
+func (ir *InstrumentedUserRepository) IsDeleted(ctx context.Context, username string) bool {
+	defer ir.observe("IsDeleted", time.Now(), nil)
+	return ir.Next.IsDeleted(ctx, username)
+}
+
+func (ir *InstrumentedUserRepository) NewUser(ctx context.Context, user mysqlUser.User) (err error) {
+	defer ir.observe("NewUser", time.Now(), &err)
+	return ir.Next.NewUser(ctx, user)
+}
+
+func (ir *InstrumentedUserRepository) NewUsers(ctx context.Context, users []mysqlUser.User) (err error) {
+	defer ir.observe("NewUsers", time.Now(), &err)
+	return ir.Next.NewUsers(ctx, users)
+}
+
+func (ir *InstrumentedUserRepository) DeleteUser(ctx context.Context, username string) (err error) {
+	defer ir.observe("DeleteUser", time.Now(), &err)
+	return ir.Next.DeleteUser(ctx, username)
+}
+
+func (ir *InstrumentedUserRepository) RestoreUser(ctx context.Context, username string) (err error) {
+	defer ir.observe("RestoreUser", time.Now(), &err)
+	return ir.Next.RestoreUser(ctx, username)
+}
+
+func (ir *InstrumentedUserRepository) UpdateUser(ctx context.Context, username string, user mysqlUser.User) (err error) {
+	defer ir.observe("UpdateUser", time.Now(), &err)
+	return ir.Next.UpdateUser(ctx, username, user)
+}
+
+func (ir *InstrumentedUserRepository) ChangePwd(ctx context.Context, newPwd, username string) (err error) {
+	defer ir.observe("ChangePwd", time.Now(), &err)
+	return ir.Next.ChangePwd(ctx, newPwd, username)
+}
+
+func (ir *InstrumentedUserRepository) CreateHistoryTable(tableName string) (err error) {
+	defer ir.observe("CreateHistoryTable", time.Now(), &err)
+	return ir.Next.CreateHistoryTable(tableName)
+}
+
+func (ir *InstrumentedUserRepository) AddPwdHistory(ctx context.Context, username, hash string) (err error) {
+	defer ir.observe("AddPwdHistory", time.Now(), &err)
+	return ir.Next.AddPwdHistory(ctx, username, hash)
+}
+
+func (ir *InstrumentedUserRepository) GetPwdHistory(ctx context.Context, username string, limit int) (history []string, err error) {
+	defer ir.observe("GetPwdHistory", time.Now(), &err)
+	return ir.Next.GetPwdHistory(ctx, username, limit)
+}
+
+func (ir *InstrumentedUserRepository) observe(method string, start time.Time, err *error) {
+	var queryErr error
+	if err != nil && !errors.Is(*err, config.ErrUserNotFound) {
+		queryErr = *err
+	}
+	ir.Metrics.observeQuery(userRepository, method, start, queryErr)
+}
+
+type InstrumentedTransactor struct {
+	Next    mysqlUser.Transactor
+	Metrics *Metrics
+}
+
+func NewInstrumentedTransactor(next mysqlUser.Transactor, m *Metrics) mysqlUser.Transactor {
+	return &InstrumentedTransactor{Next: next, Metrics: m}
+}
+
+func (it *InstrumentedTransactor) WithinTx(ctx context.Context, fn func(users mysqlUser.MysqlRepository, events event.Recorder) error) error {
+	return it.Next.WithinTx(ctx, func(users mysqlUser.MysqlRepository, events event.Recorder) error {
+		return fn(NewInstrumentedUserRepository(users, it.Metrics), events)
+	})
+}
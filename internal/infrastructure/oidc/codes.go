@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/oidc"
+)
+
+// MemoryCodeStore keeps authorization codes in process memory. A code is
+// only redeemable on the replica that issued it, so the provider needs a
+// single replica or sticky sessions.
+type MemoryCodeStore struct {
+	mu    sync.Mutex
+	codes map[string]entity.AuthorizationCode
+	now   func() time.Time
+}
+
+func NewMemoryCodeStore() *MemoryCodeStore {
+	return &MemoryCodeStore{
+		codes: map[string]entity.AuthorizationCode{},
+		now:   time.Now,
+	}
+}
+
+func (ms *MemoryCodeStore) Save(code entity.AuthorizationCode) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	for k, c := range ms.codes {
+		if !now.Before(c.ExpiresAt) {
+			delete(ms.codes, k)
+		}
+	}
+
+	ms.codes[code.Code] = code
+	return nil
+}
+
+func (ms *MemoryCodeStore) Consume(code, clientID string) (entity.AuthorizationCode, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	found, ok := ms.codes[code]
+	if !ok || found.ClientID != clientID {
+		return entity.AuthorizationCode{}, config.ErrCodeNotFound
+	}
+	delete(ms.codes, code)
+
+	return found, nil
+}
@@ -0,0 +1,103 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/oidc"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRSASigner(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	pkcs1 := filepath.Join(dir, "pkcs1.pem")
+	require.NoError(t, os.WriteFile(pkcs1, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := filepath.Join(dir, "pkcs8.pem")
+	require.NoError(t, os.WriteFile(pkcs8, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	garbage := filepath.Join(dir, "garbage.pem")
+	require.NoError(t, os.WriteFile(garbage, []byte("nope"), 0o600))
+
+	for _, path := range []string{pkcs1, pkcs8} {
+		signer, err := LoadRSASigner(path)
+		require.NoError(t, err)
+
+		raw, err := signer.Sign(map[string]any{"sub": "123"})
+		require.NoError(t, err)
+
+		parsed, err := jwt.Parse(raw, func(token *jwt.Token) (any, error) {
+			assert.Equal(t, signer.KeyID, token.Header["kid"])
+			return &key.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"RS256"}))
+		require.NoError(t, err)
+		assert.True(t, parsed.Valid)
+
+		keys := signer.JWKS()["keys"].([]map[string]any)
+		assert.Equal(t, signer.KeyID, keys[0]["kid"])
+		assert.Equal(t, "AQAB", keys[0]["e"])
+	}
+
+	_, err = LoadRSASigner(garbage)
+	assert.ErrorIs(t, err, config.ErrInvalidSigningKey)
+}
+
+func TestRSASignerReload(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signer := NewRSASigner(oldKey)
+	oldID := signer.KeyID
+
+	assert.ErrorIs(t, signer.Reload([]byte("nope")), config.ErrInvalidSigningKey)
+
+	content := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(newKey)})
+	require.NoError(t, signer.Reload(content))
+	require.NoError(t, signer.Reload(content))
+
+	key, id := signer.SigningKey()
+	assert.Equal(t, newKey, key)
+	assert.NotEqual(t, oldID, id)
+
+	keys := signer.JWKS()["keys"].([]map[string]any)
+	require.Len(t, keys, 2)
+	assert.Equal(t, id, keys[0]["kid"])
+	assert.Equal(t, oldID, keys[1]["kid"])
+}
+
+func TestMemoryCodeStore(t *testing.T) {
+	store := NewMemoryCodeStore()
+	now := time.Now()
+
+	require.NoError(t, store.Save(entity.AuthorizationCode{Code: "expired", ClientID: "client-1", ExpiresAt: now.Add(-time.Second)}))
+	require.NoError(t, store.Save(entity.AuthorizationCode{Code: "valid", ClientID: "client-1", ExpiresAt: now.Add(time.Minute)}))
+
+	_, err := store.Consume("expired", "client-1")
+	assert.ErrorIs(t, err, config.ErrCodeNotFound)
+
+	_, err = store.Consume("valid", "client-2")
+	assert.ErrorIs(t, err, config.ErrCodeNotFound, "another client's code must not be consumed")
+
+	code, err := store.Consume("valid", "client-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "valid", code.Code)
+
+	_, err = store.Consume("valid", "client-1")
+	assert.ErrorIs(t, err, config.ErrCodeNotFound)
+}
@@ -0,0 +1,148 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+
+	"go-manage-hex/cmd/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type RSASigner struct {
+	Key   *rsa.PrivateKey
+	KeyID string
+
+	mu         sync.RWMutex
+	previous   *rsa.PublicKey
+	previousID string
+}
+
+func NewRSASigner(key *rsa.PrivateKey) *RSASigner {
+	return &RSASigner{
+		Key:   key,
+		KeyID: keyID(&key.PublicKey),
+	}
+}
+
+func LoadRSASigner(path string) (*RSASigner, error) {
+	if path == "" {
+		slog.Warn("OIDC_SIGNING_KEY_PATH not set, generating an ephemeral signing key")
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		return NewRSASigner(key), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseRSAKey(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRSASigner(key), nil
+}
+
+// ParseRSAKey accepts a PEM encoded PKCS#1 or PKCS#8 RSA private key.
+func ParseRSAKey(content []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, config.ErrInvalidSigningKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, config.ErrInvalidSigningKey
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, config.ErrInvalidSigningKey
+	}
+
+	return key, nil
+}
+
+// Reload swaps in a rotated PEM key. The previous public key stays in the
+// JWKS so ID tokens signed before the rotation still verify.
+func (rs *RSASigner) Reload(content []byte) error {
+	key, err := ParseRSAKey(content)
+	if err != nil {
+		return err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	id := keyID(&key.PublicKey)
+	if id == rs.KeyID {
+		return nil
+	}
+
+	rs.previous, rs.previousID = &rs.Key.PublicKey, rs.KeyID
+	rs.Key, rs.KeyID = key, id
+	return nil
+}
+
+func (rs *RSASigner) SigningKey() (*rsa.PrivateKey, string) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	return rs.Key, rs.KeyID
+}
+
+func (rs *RSASigner) Sign(claims map[string]any) (string, error) {
+	key, id := rs.SigningKey()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims(claims))
+	token.Header["kid"] = id
+
+	return token.SignedString(key)
+}
+
+func (rs *RSASigner) JWKS() map[string]any {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	keys := []map[string]any{jwk(&rs.Key.PublicKey, rs.KeyID)}
+	if rs.previous != nil {
+		keys = append(keys, jwk(rs.previous, rs.previousID))
+	}
+
+	return map[string]any{"keys": keys}
+}
+
+func jwk(key *rsa.PublicKey, id string) map[string]any {
+	return map[string]any{
+		"kty": "RSA",
+		"use": "sig",
+		"alg": "RS256",
+		"kid": id,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func keyID(key *rsa.PublicKey) string {
+	der, _ := x509.MarshalPKIXPublicKey(key)
+	sum := sha256.Sum256(der)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,41 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	entity "go-manage-hex/internal/core/event"
+)
+
+type LogPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewLogPublisher(writer io.Writer) *LogPublisher {
+	return &LogPublisher{writer: writer}
+}
+
+func OpenFilePublisher(path string) (*LogPublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return NewLogPublisher(file), nil
+}
+
+func (lp *LogPublisher) Publish(ctx context.Context, event entity.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+
+	_, err = lp.writer.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,26 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+
+	entity "go-manage-hex/internal/core/event"
+)
+
+type MultiPublisher struct {
+	Publishers []entity.Publisher
+}
+
+func NewMultiPublisher(publishers ...entity.Publisher) *MultiPublisher {
+	return &MultiPublisher{Publishers: publishers}
+}
+
+func (mp *MultiPublisher) Publish(ctx context.Context, event entity.Event) error {
+	var errs []error
+	for _, publisher := range mp.Publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
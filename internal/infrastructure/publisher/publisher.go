@@ -0,0 +1,29 @@
+package publisher
+
+import (
+	"os"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/event"
+)
+
+func NewPublisher(kind, filePath, webhookURL string, timeout time.Duration) (entity.Publisher, error) {
+	switch kind {
+	case config.PublisherLog:
+		return NewLogPublisher(os.Stdout), nil
+	case config.PublisherFile:
+		publisher, err := OpenFilePublisher(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return publisher, nil
+	case config.PublisherHTTP:
+		if webhookURL == "" {
+			return nil, config.ErrUnknownPublisher
+		}
+		return NewHTTPPublisher(webhookURL, timeout), nil
+	default:
+		return nil, config.ErrUnknownPublisher
+	}
+}
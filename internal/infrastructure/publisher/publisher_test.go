@@ -0,0 +1,107 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/event"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = entity.Event{
+	Sequence:  7,
+	ID:        "evt-1",
+	Type:      config.EventUserCreated,
+	Aggregate: "johndoe",
+	Payload:   json.RawMessage(`{"username":"johndoe"}`),
+}
+
+func TestLogPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewLogPublisher(&buf)
+
+	require.NoError(t, publisher.Publish(context.Background(), testEvent))
+	require.NoError(t, publisher.Publish(context.Background(), testEvent))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+
+	var decoded entity.Event
+	require.NoError(t, json.Unmarshal(lines[0], &decoded))
+	assert.Equal(t, testEvent.ID, decoded.ID)
+	assert.JSONEq(t, string(testEvent.Payload), string(decoded.Payload))
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	test := []struct {
+		Name        string
+		Status      int
+		ExpectedErr error
+	}{
+		{
+			Name:   "Publish_Accepted",
+			Status: http.StatusAccepted,
+		},
+		{
+			Name:        "Publish_ServerError",
+			Status:      http.StatusInternalServerError,
+			ExpectedErr: config.ErrPublishFailed,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			var received entity.Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, testEvent.Type, r.Header.Get("X-Event-Type"))
+				json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.Status)
+			}))
+			defer server.Close()
+
+			err := NewHTTPPublisher(server.URL, time.Second).Publish(context.Background(), testEvent)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, testEvent.ID, received.ID)
+		})
+	}
+}
+
+func TestNewPublisher_Unknown(t *testing.T) {
+	_, err := NewPublisher("kafka", "", "", time.Second)
+
+	assert.ErrorIs(t, err, config.ErrUnknownPublisher)
+}
+
+type stubPublisher struct {
+	err       error
+	published int
+}
+
+func (s *stubPublisher) Publish(ctx context.Context, event entity.Event) error {
+	s.published++
+	return s.err
+}
+
+func TestMultiPublisher(t *testing.T) {
+	failing := &stubPublisher{err: config.ErrPublishFailed}
+	healthy := &stubPublisher{}
+
+	err := NewMultiPublisher(failing, healthy).Publish(context.Background(), testEvent)
+
+	assert.ErrorIs(t, err, config.ErrPublishFailed)
+	assert.Equal(t, 1, failing.published)
+	assert.Equal(t, 1, healthy.published)
+}
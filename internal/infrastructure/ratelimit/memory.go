@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/ratelimit"
+)
+
+type memoryBucket struct {
+	bucket entity.Bucket
+	fullAt time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]memoryBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]memoryBucket{},
+		now:     time.Now,
+	}
+}
+
+func (ms *MemoryStore) Take(ctx context.Context, key string, policy entity.Policy) (entity.Decision, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := ms.now()
+	ms.sweep(now)
+
+	id := policy.Name + "|" + key
+	bucket, decision := ms.buckets[id].bucket.Take(policy, now)
+	ms.buckets[id] = memoryBucket{bucket: bucket, fullAt: now.Add(decision.Reset)}
+
+	return decision, nil
+}
+
+// sweep drops buckets that have refilled completely, since a missing bucket
+// starts full anyway. It keeps memory bounded by the clients of the last
+// period instead of every client ever seen.
+func (ms *MemoryStore) sweep(now time.Time) {
+	if now.Sub(ms.lastSweep) < config.RateLimitSweepInterval {
+		return
+	}
+	ms.lastSweep = now
+
+	for id, b := range ms.buckets {
+		if !now.Before(b.fullAt) {
+			delete(ms.buckets, id)
+		}
+	}
+}
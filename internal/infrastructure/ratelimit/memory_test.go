@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/ratelimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	login := entity.Policy{Name: "login", Limit: 3, Period: 3 * time.Second}
+	create := entity.Policy{Name: "create", Limit: 1, Period: time.Second}
+
+	take := func(key string, policy entity.Policy) entity.Decision {
+		t.Helper()
+		decision, err := store.Take(ctx, key, policy)
+		require.NoError(t, err)
+		return decision
+	}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		decision := take("ip:1.2.3.4", login)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, remaining, decision.Remaining)
+	}
+
+	denied := take("ip:1.2.3.4", login)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.Equal(t, 3*time.Second, denied.Reset)
+
+	assert.True(t, take("ip:5.6.7.8", login).Allowed, "other clients keep their own bucket")
+	assert.True(t, take("ip:1.2.3.4", create).Allowed, "other policies keep their own bucket")
+
+	now = now.Add(time.Second)
+	refilled := take("ip:1.2.3.4", login)
+	assert.True(t, refilled.Allowed)
+	assert.Equal(t, 0, refilled.Remaining)
+
+	now = now.Add(config.RateLimitSweepInterval)
+	take("ip:9.9.9.9", create)
+	assert.Len(t, store.buckets, 1)
+}
@@ -0,0 +1,123 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/secret"
+)
+
+type EnvProvider struct{}
+
+func NewEnvProvider() entity.Provider {
+	return &EnvProvider{}
+}
+
+func (ep *EnvProvider) Lookup(key string) (string, bool, error) {
+	value := os.Getenv(key)
+	return value, value != "", nil
+}
+
+// FileEnvProvider reads the secret from the file named by <KEY>_FILE.
+type FileEnvProvider struct{}
+
+func NewFileEnvProvider() entity.Provider {
+	return &FileEnvProvider{}
+}
+
+func (fp *FileEnvProvider) Lookup(key string) (string, bool, error) {
+	path := os.Getenv(key + config.SecretFileSuffix)
+	if path == "" {
+		return "", false, nil
+	}
+
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s%s: %w", key, config.SecretFileSuffix, err)
+	}
+	return value, true, nil
+}
+
+// DirProvider reads <dir>/<KEY> or <dir>/<key>, the layout used by mounted
+// secret volumes.
+type DirProvider struct {
+	Dir string
+}
+
+func NewDirProvider(dir string) entity.Provider {
+	return &DirProvider{Dir: dir}
+}
+
+func (dp *DirProvider) Lookup(key string) (string, bool, error) {
+	if dp.Dir == "" {
+		return "", false, nil
+	}
+
+	for _, name := range []string{key, strings.ToLower(key)} {
+		value, err := readSecretFile(filepath.Join(dp.Dir, name))
+		switch {
+		case err == nil:
+			return value, true, nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", false, err
+		}
+	}
+	return "", false, nil
+}
+
+// NewDefaultProvider checks <KEY>_FILE, then the environment, then the
+// directory named by SECRETS_DIR.
+func NewDefaultProvider() entity.Provider {
+	return NewChainProvider(
+		NewFileEnvProvider(),
+		NewEnvProvider(),
+		NewDirProvider(os.Getenv(config.SecretsDirEnv)),
+	)
+}
+
+// ChainProvider returns the first provider that has the key.
+type ChainProvider struct {
+	Providers []entity.Provider
+}
+
+func NewChainProvider(providers ...entity.Provider) entity.Provider {
+	return &ChainProvider{Providers: providers}
+}
+
+func (cp *ChainProvider) Lookup(key string) (string, bool, error) {
+	for _, provider := range cp.Providers {
+		value, found, err := provider.Lookup(key)
+		if err != nil || found {
+			return value, found, err
+		}
+	}
+	return "", false, nil
+}
+
+// Reader adapts a provider lookup to the Watcher. A key the provider does not
+// hold reports config.ErrSecretNotFound.
+func Reader(provider entity.Provider, key string) func() (string, error) {
+	return func() (string, error) {
+		value, found, err := provider.Lookup(key)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("%w: %s", config.ErrSecretNotFound, key)
+		}
+		return value, nil
+	}
+}
+
+func readSecretFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
@@ -0,0 +1,124 @@
+package secret
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-manage-hex/cmd/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecret(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestProviders(t *testing.T) {
+	dir := t.TempDir()
+	filePath := writeSecret(t, dir, "db_password", "from-file\n")
+	writeSecret(t, dir, "jwt_token_secret", "from-dir")
+
+	t.Setenv("MYSQL_ROOT_PASSWORD", "from-env")
+	t.Setenv("MYSQL_ROOT_PASSWORD_FILE", filePath)
+	t.Setenv("ADMIN_TOKEN", "admin-from-env")
+	t.Setenv("BROKEN_FILE", filepath.Join(dir, "missing"))
+
+	chain := NewChainProvider(NewFileEnvProvider(), NewEnvProvider(), NewDirProvider(dir))
+
+	tests := []struct {
+		Name          string
+		Key           string
+		ExpectedValue string
+		ExpectedFound bool
+		ExpectedError bool
+	}{
+		{
+			Name:          "FileWinsOverEnv",
+			Key:           "MYSQL_ROOT_PASSWORD",
+			ExpectedValue: "from-file",
+			ExpectedFound: true,
+		},
+		{
+			Name:          "Env",
+			Key:           "ADMIN_TOKEN",
+			ExpectedValue: "admin-from-env",
+			ExpectedFound: true,
+		},
+		{
+			Name:          "DirectoryLowercaseName",
+			Key:           "JWT_TOKEN_SECRET",
+			ExpectedValue: "from-dir",
+			ExpectedFound: true,
+		},
+		{
+			Name: "NotFound",
+			Key:  "SCIM_TOKEN",
+		},
+		{
+			Name:          "UnreadableFile",
+			Key:           "BROKEN",
+			ExpectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			value, found, err := chain.Lookup(tt.Key)
+			assert.Equal(t, tt.ExpectedError, err != nil)
+			assert.Equal(t, tt.ExpectedFound, found)
+			assert.Equal(t, tt.ExpectedValue, value)
+		})
+	}
+
+	_, _, err := NewDirProvider("").Lookup("ADMIN_TOKEN")
+	assert.NoError(t, err)
+
+	_, err = Reader(chain, "SCIM_TOKEN")()
+	assert.ErrorIs(t, err, config.ErrSecretNotFound)
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret(t, dir, "MYSQL_ROOT_PASSWORD", "v1")
+	provider := NewDirProvider(dir)
+
+	current := NewValue("v1")
+	var applied []string
+	watcher := NewWatcher(config.DefaultSecretsWatchInterval)
+	watcher.Watch("MYSQL_ROOT_PASSWORD", Reader(provider, "MYSQL_ROOT_PASSWORD"), func(value string) error {
+		if value == "rejected" {
+			return config.ErrWeakJWTSecret
+		}
+		applied = append(applied, value)
+		current.Set(value)
+		return nil
+	})
+	watcher.Watch("ADMIN_TOKEN", Reader(provider, "ADMIN_TOKEN"), func(value string) error {
+		t.Fatal("secrets missing at startup are not watched")
+		return nil
+	})
+	writeSecret(t, dir, "ADMIN_TOKEN", "appeared")
+
+	ctx := context.Background()
+	watcher.Poll(ctx)
+	assert.Empty(t, applied)
+
+	writeSecret(t, dir, "MYSQL_ROOT_PASSWORD", "v2")
+	watcher.Poll(ctx)
+	watcher.Poll(ctx)
+	assert.Equal(t, []string{"v2"}, applied)
+	assert.Equal(t, "v2", current.Get())
+
+	writeSecret(t, dir, "MYSQL_ROOT_PASSWORD", "rejected")
+	watcher.Poll(ctx)
+	assert.Equal(t, "v2", current.Get())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "MYSQL_ROOT_PASSWORD")))
+	watcher.Poll(ctx)
+	assert.Equal(t, "v2", current.Get())
+}
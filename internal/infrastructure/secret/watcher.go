@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Value holds a secret that can be swapped while it is being read.
+type Value struct {
+	value atomic.Value
+}
+
+func NewValue(initial string) *Value {
+	v := &Value{}
+	v.Set(initial)
+	return v
+}
+
+func (v *Value) Get() string {
+	return v.value.Load().(string)
+}
+
+func (v *Value) Set(value string) {
+	v.value.Store(value)
+}
+
+type watch struct {
+	name  string
+	read  func() (string, error)
+	apply func(value string) error
+	last  string
+}
+
+// Watcher polls secret sources and applies a value when it changes. Polling
+// follows the symlink swaps that mounted secret volumes use on rotation.
+// Values are never logged.
+type Watcher struct {
+	Interval time.Duration
+
+	mu      sync.Mutex
+	watches []*watch
+}
+
+func NewWatcher(interval time.Duration) *Watcher {
+	return &Watcher{Interval: interval}
+}
+
+// Watch registers a source. Sources that cannot be read at registration are
+// not managed by the provider and are skipped.
+func (w *Watcher) Watch(name string, read func() (string, error), apply func(value string) error) {
+	current, err := read()
+	if err != nil {
+		slog.Debug("secret not watched", "secret", name, "error", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.watches = append(w.watches, &watch{name: name, read: read, apply: apply, last: current})
+}
+
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Poll(ctx)
+		}
+	}
+}
+
+func (w *Watcher) Poll(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, watched := range w.watches {
+		value, err := watched.read()
+		if err != nil {
+			slog.WarnContext(ctx, "error reading secret", "secret", watched.name, "error", err)
+			continue
+		}
+		if value == watched.last {
+			continue
+		}
+
+		if applyErr := watched.apply(value); applyErr != nil {
+			slog.ErrorContext(ctx, "rejected rotated secret", "secret", watched.name, "error", applyErr)
+			continue
+		}
+
+		watched.last = value
+		slog.InfoContext(ctx, "secret rotated", "secret", watched.name)
+	}
+}
@@ -0,0 +1,150 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go-manage-hex/cmd/config"
+	"go-manage-hex/internal/core/event"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracedUserRepository struct {
+	Next mysqlUser.MysqlRepository
+}
+
+func NewTracedUserRepository(next mysqlUser.MysqlRepository) mysqlUser.MysqlRepository {
+	return &TracedUserRepository{Next: next}
+}
+
+func (tr *TracedUserRepository) CreateTable(tableName string) error {
+	return tr.Next.CreateTable(tableName)
+}
+
+func (tr *TracedUserRepository) GetByUsername(ctx context.Context, username string) (user mysqlUser.User, err error) {
+	ctx, span := startQuery(ctx, "GetByUsername")
+	defer endQuery(span, &err)
+	return tr.Next.GetByUsername(ctx, username)
+}
+
+func (tr *TracedUserRepository) GetByEmail(ctx context.Context, email string) (user mysqlUser.User, err error) {
+	ctx, span := startQuery(ctx, "GetByEmail")
+	defer endQuery(span, &err)
+	return tr.Next.GetByEmail(ctx, email)
+}
+
+func (tr *TracedUserRepository) GetByID(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	ctx, span := startQuery(ctx, "GetByID")
+	defer endQuery(span, &err)
+	return tr.Next.GetByID(ctx, id)
+}
+
+func (tr *TracedUserRepository) GetByIDWithDeleted(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	ctx, span := startQuery(ctx, "GetByIDWithDeleted")
+	defer endQuery(span, &err)
+	return tr.Next.GetByIDWithDeleted(ctx, id)
+}
+
+func (tr *TracedUserRepository) ListUsers(ctx context.Context, query mysqlUser.UserQuery) (users []mysqlUser.User, total int, err error) {
+	ctx, span := startQuery(ctx, "ListUsers")
+	defer endQuery(span, &err)
+	return tr.Next.ListUsers(ctx, query)
+}
+
+func (tr *TracedUserRepository) CheckExists(ctx context.Context, username string) bool {
+	ctx, span := startQuery(ctx, "CheckExists")
+	defer span.End()
+	return tr.Next.CheckExists(ctx, username)
+}
+
+func (tr *TracedUserRepository) IsDeleted(ctx context.Context, username string) bool {
+	ctx, span := startQuery(ctx, "IsDeleted")
+	defer span.End()
+	return tr.Next.IsDeleted(ctx, username)
+}
+
+func (tr *TracedUserRepository) NewUser(ctx context.Context, user mysqlUser.User) (err error) {
+	ctx, span := startQuery(ctx, "NewUser")
+	defer endQuery(span, &err)
+	return tr.Next.NewUser(ctx, user)
+}
+
+func (tr *TracedUserRepository) NewUsers(ctx context.Context, users []mysqlUser.User) (err error) {
+	ctx, span := startQuery(ctx, "NewUsers")
+	span.SetAttributes(attribute.Int("db.operation.batch.size", len(users)))
+	defer endQuery(span, &err)
+	return tr.Next.NewUsers(ctx, users)
+}
+
+func (tr *TracedUserRepository) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, span := startQuery(ctx, "DeleteUser")
+	defer endQuery(span, &err)
+	return tr.Next.DeleteUser(ctx, username)
+}
+
+func (tr *TracedUserRepository) RestoreUser(ctx context.Context, username string) (err error) {
+	ctx, span := startQuery(ctx, "RestoreUser")
+	defer endQuery(span, &err)
+	return tr.Next.RestoreUser(ctx, username)
+}
+
+func (tr *TracedUserRepository) UpdateUser(ctx context.Context, username string, user mysqlUser.User) (err error) {
+	ctx, span := startQuery(ctx, "UpdateUser")
+	defer endQuery(span, &err)
+	return tr.Next.UpdateUser(ctx, username, user)
+}
+
+func (tr *TracedUserRepository) ChangePwd(ctx context.Context, newPwd, username string) (err error) {
+	ctx, span := startQuery(ctx, "ChangePwd")
+	defer endQuery(span, &err)
+	return tr.Next.ChangePwd(ctx, newPwd, username)
+}
+
+func (tr *TracedUserRepository) CreateHistoryTable(tableName string) error {
+	return tr.Next.CreateHistoryTable(tableName)
+}
+
+func (tr *TracedUserRepository) AddPwdHistory(ctx context.Context, username, hash string) (err error) {
+	ctx, span := startQuery(ctx, "AddPwdHistory")
+	defer endQuery(span, &err)
+	return tr.Next.AddPwdHistory(ctx, username, hash)
+}
+
+func (tr *TracedUserRepository) GetPwdHistory(ctx context.Context, username string, limit int) (history []string, err error) {
+	ctx, span := startQuery(ctx, "GetPwdHistory")
+	defer endQuery(span, &err)
+	return tr.Next.GetPwdHistory(ctx, username, limit)
+}
+
+func startQuery(ctx context.Context, method string) (context.Context, trace.Span) {
+	return start(ctx, "UserRepository."+method,
+		attribute.String("db.system", "mysql"),
+		attribute.String("db.operation.name", method),
+	)
+}
+
+func endQuery(span trace.Span, err *error) {
+	if err != nil && errors.Is(*err, config.ErrUserNotFound) {
+		err = nil
+	}
+	end(span, err)
+}
+
+type TracedTransactor struct {
+	Next mysqlUser.Transactor
+}
+
+func NewTracedTransactor(next mysqlUser.Transactor) mysqlUser.Transactor {
+	return &TracedTransactor{Next: next}
+}
+
+func (tt *TracedTransactor) WithinTx(ctx context.Context, fn func(users mysqlUser.MysqlRepository, events event.Recorder) error) (err error) {
+	ctx, span := start(ctx, "UserRepository.WithinTx", attribute.String("db.system", "mysql"))
+	defer end(span, &err)
+	return tt.Next.WithinTx(ctx, func(users mysqlUser.MysqlRepository, events event.Recorder) error {
+		return fn(NewTracedUserRepository(users), events)
+	})
+}
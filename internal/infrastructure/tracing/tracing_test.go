@@ -0,0 +1,238 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-manage-hex/cmd/config"
+	userService "go-manage-hex/internal/app/user"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const incomingTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+type stubRepository struct {
+	mysqlUser.MysqlRepository
+	err error
+}
+
+func (s *stubRepository) GetByUsername(ctx context.Context, username string) (mysqlUser.User, error) {
+	return mysqlUser.User{Username: username}, s.err
+}
+
+type stubUsecases struct {
+	userService.Usecases
+	repo mysqlUser.MysqlRepository
+}
+
+func (s *stubUsecases) SearchUser(ctx context.Context, username string) (mysqlUser.User, error) {
+	return s.repo.GetByUsername(ctx, username)
+}
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return recorder
+}
+
+func spanByName(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	test := []struct {
+		Name           string
+		TraceParent    string
+		Target         string
+		Status         int
+		ExpectedName   string
+		ExpectedRemote bool
+		ExpectedError  bool
+	}{
+		{
+			Name:           "Honors_IncomingTraceParent",
+			TraceParent:    incomingTraceParent,
+			Target:         "/users/1",
+			Status:         http.StatusOK,
+			ExpectedName:   "GET /users/:id",
+			ExpectedRemote: true,
+		},
+		{
+			Name:         "Starts_NewTrace",
+			Target:       "/users/1",
+			Status:       http.StatusOK,
+			ExpectedName: "GET /users/:id",
+		},
+		{
+			Name:          "Marks_ServerError",
+			Target:        "/users/1",
+			Status:        http.StatusInternalServerError,
+			ExpectedName:  "GET /users/:id",
+			ExpectedError: true,
+		},
+		{
+			Name:         "Unmatched_Route",
+			Target:       "/missing",
+			Status:       http.StatusNotFound,
+			ExpectedName: "GET " + config.MetricsUnmatchedRoute,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			recorder := setupRecorder(t)
+
+			r := gin.New()
+			r.Use(Middleware())
+			r.GET("/users/:id", func(c *gin.Context) { c.Status(tt.Status) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.Target, nil)
+			if tt.TraceParent != "" {
+				req.Header.Set("traceparent", tt.TraceParent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+
+			span := spans[0]
+			assert.Equal(t, tt.ExpectedName, span.Name())
+			assert.Equal(t, tt.ExpectedRemote, span.Parent().IsRemote())
+			if tt.ExpectedRemote {
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+				assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+			}
+			assert.Equal(t, tt.ExpectedError, span.Status().Code == codes.Error)
+		})
+	}
+}
+
+func TestDecoratorsNest(t *testing.T) {
+	test := []struct {
+		Name                 string
+		RepoErr              error
+		ExpectedQueryError   bool
+		ExpectedServiceError bool
+	}{
+		{
+			Name: "Success",
+		},
+		{
+			Name:                 "NotFound_IsNotAQueryError",
+			RepoErr:              config.ErrUserNotFound,
+			ExpectedServiceError: true,
+		},
+		{
+			Name:                 "Error_Recorded",
+			RepoErr:              config.ErrAuthSourceUnavailable,
+			ExpectedQueryError:   true,
+			ExpectedServiceError: true,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			recorder := setupRecorder(t)
+
+			repo := NewTracedUserRepository(&stubRepository{err: tt.RepoErr})
+			usecases := NewTracedUsecases(&stubUsecases{repo: repo})
+
+			r := gin.New()
+			r.Use(Middleware())
+			r.GET("/users/:username", func(c *gin.Context) {
+				usecases.SearchUser(c.Request.Context(), c.Param("username"))
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users/john", nil)
+			req.Header.Set("traceparent", incomingTraceParent)
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 3)
+
+			server := spanByName(spans, "GET /users/:username")
+			service := spanByName(spans, "UserService.SearchUser")
+			query := spanByName(spans, "UserRepository.GetByUsername")
+			require.NotNil(t, server)
+			require.NotNil(t, service)
+			require.NotNil(t, query)
+
+			assert.Equal(t, server.SpanContext().SpanID(), service.Parent().SpanID())
+			assert.Equal(t, service.SpanContext().SpanID(), query.Parent().SpanID())
+			assert.Equal(t, server.SpanContext().TraceID(), query.SpanContext().TraceID())
+			assert.Equal(t, tt.ExpectedQueryError, query.Status().Code == codes.Error)
+			assert.Equal(t, tt.ExpectedServiceError, service.Status().Code == codes.Error)
+		})
+	}
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	test := []struct {
+		Name          string
+		Options       Options
+		ExpectedError error
+	}{
+		{
+			Name:    "None",
+			Options: Options{Exporter: config.TraceExporterNone, SampleRatio: 1},
+		},
+		{
+			Name:    "File",
+			Options: Options{Exporter: config.TraceExporterFile, FilePath: t.TempDir() + "/traces.json", SampleRatio: 1},
+		},
+		{
+			Name:          "Unknown",
+			Options:       Options{Exporter: "jaeger"},
+			ExpectedError: config.ErrUnknownTraceExporter,
+		},
+	}
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			provider, err := NewTracerProvider(context.Background(), tt.Options)
+			if tt.ExpectedError != nil {
+				assert.ErrorIs(t, err, tt.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tracing
+
+import (
+	"context"
+
+	"go-manage-hex/cmd/config"
+	userService "go-manage-hex/internal/app/user"
+	mysqlUser "go-manage-hex/internal/core/user"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracedUsecases struct {
+	Next userService.Usecases
+}
+
+func NewTracedUsecases(next userService.Usecases) userService.Usecases {
+	return &TracedUsecases{Next: next}
+}
+
+func (tu *TracedUsecases) SearchUser(ctx context.Context, username string) (user mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.SearchUser")
+	defer end(span, &err)
+	return tu.Next.SearchUser(ctx, username)
+}
+
+func (tu *TracedUsecases) SearchUserByID(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.SearchUserByID")
+	defer end(span, &err)
+	return tu.Next.SearchUserByID(ctx, id)
+}
+
+func (tu *TracedUsecases) SearchUserByIDWithDeleted(ctx context.Context, id string) (user mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.SearchUserByIDWithDeleted")
+	defer end(span, &err)
+	return tu.Next.SearchUserByIDWithDeleted(ctx, id)
+}
+
+func (tu *TracedUsecases) ListUsers(ctx context.Context, query mysqlUser.UserQuery) (users []mysqlUser.User, total int, err error) {
+	ctx, span := start(ctx, "UserService.ListUsers")
+	defer end(span, &err)
+	return tu.Next.ListUsers(ctx, query)
+}
+
+func (tu *TracedUsecases) CreateUser(ctx context.Context, user mysqlUser.User) (created mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.CreateUser")
+	defer end(span, &err)
+	return tu.Next.CreateUser(ctx, user)
+}
+
+func (tu *TracedUsecases) PrepareUser(ctx context.Context, user mysqlUser.User, preHashed bool) (prepared mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.PrepareUser", attribute.Bool("user.pre_hashed", preHashed))
+	defer end(span, &err)
+	return tu.Next.PrepareUser(ctx, user, preHashed)
+}
+
+func (tu *TracedUsecases) CreateUsers(ctx context.Context, users []mysqlUser.User) (err error) {
+	ctx, span := start(ctx, "UserService.CreateUsers", attribute.Int("users.count", len(users)))
+	defer end(span, &err)
+	return tu.Next.CreateUsers(ctx, users)
+}
+
+func (tu *TracedUsecases) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, span := start(ctx, "UserService.DeleteUser")
+	defer end(span, &err)
+	return tu.Next.DeleteUser(ctx, username)
+}
+
+func (tu *TracedUsecases) RestoreUser(ctx context.Context, username string) (err error) {
+	ctx, span := start(ctx, "UserService.RestoreUser")
+	defer end(span, &err)
+	return tu.Next.RestoreUser(ctx, username)
+}
+
+func (tu *TracedUsecases) UpdateUser(ctx context.Context, username string, user mysqlUser.User) (updated mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.UpdateUser")
+	defer end(span, &err)
+	return tu.Next.UpdateUser(ctx, username, user)
+}
+
+func (tu *TracedUsecases) UpdateUserAndPwd(ctx context.Context, username string, user mysqlUser.User, newPwd string) (updated mysqlUser.User, err error) {
+	ctx, span := start(ctx, "UserService.UpdateUserAndPwd")
+	defer end(span, &err)
+	return tu.Next.UpdateUserAndPwd(ctx, username, user, newPwd)
+}
+
+func (tu *TracedUsecases) ChangeUserPwd(ctx context.Context, newPwd, username string) (err error) {
+	ctx, span := start(ctx, "UserService.ChangeUserPwd")
+	defer end(span, &err)
+	return tu.Next.ChangeUserPwd(ctx, newPwd, username)
+}
+
+func (tu *TracedUsecases) Login(ctx context.Context, username, password string) (err error) {
+	ctx, span := start(ctx, "UserService.Login")
+	defer end(span, &err)
+	return tu.Next.Login(ctx, username, password)
+}
+
+func start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(config.TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func end(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
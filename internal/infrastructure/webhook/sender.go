@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+)
+
+type HTTPSender struct {
+	Client *http.Client
+	Now    func() time.Time
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{
+		Client: &http.Client{Timeout: timeout},
+		Now:    time.Now,
+	}
+}
+
+func (hs *HTTPSender) Send(ctx context.Context, sub entity.Subscription, delivery entity.Delivery) (int, error) {
+	timestamp := hs.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(config.WebhookHeaderID, delivery.ID)
+	req.Header.Set(config.WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(config.WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(config.WebhookHeaderSignature, config.WebhookSignaturePrefix+Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := hs.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", config.ErrWebhookDelivery, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: status %d", config.ErrWebhookDelivery, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>" that receivers
+// recompute to verify a delivery.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go-manage-hex/cmd/config"
+	entity "go-manage-hex/internal/core/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "partner-shared-secret"
+
+func verifyingReceiver(t *testing.T, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(config.WebhookHeaderTimestamp), 10, 64)
+		assert.NoError(t, err)
+
+		signature, found := strings.CutPrefix(r.Header.Get(config.WebhookHeaderSignature), config.WebhookSignaturePrefix)
+		assert.True(t, found)
+		assert.True(t, hmac.Equal([]byte(signature), []byte(Sign(testSecret, timestamp, body))))
+
+		assert.Equal(t, "delivery-1", r.Header.Get(config.WebhookHeaderID))
+		assert.Equal(t, config.EventUserCreated, r.Header.Get(config.WebhookHeaderEvent))
+		assert.JSONEq(t, `{"id":"evt-1"}`, string(body))
+
+		w.WriteHeader(status)
+	}))
+}
+
+func TestHTTPSender(t *testing.T) {
+	test := []struct {
+		Name        string
+		Status      int
+		ExpectedErr error
+	}{
+		{
+			Name:   "Send_Delivered",
+			Status: http.StatusNoContent,
+		},
+		{
+			Name:        "Send_ReceiverError",
+			Status:      http.StatusBadGateway,
+			ExpectedErr: config.ErrWebhookDelivery,
+		},
+	}
+
+	for _, tt := range test {
+		t.Run(tt.Name, func(t *testing.T) {
+			receiver := verifyingReceiver(t, tt.Status)
+			defer receiver.Close()
+
+			sender := NewHTTPSender(time.Second)
+			statusCode, err := sender.Send(context.Background(),
+				entity.Subscription{ID: "sub-1", URL: receiver.URL, Secret: testSecret},
+				entity.Delivery{ID: "delivery-1", EventType: config.EventUserCreated, Payload: []byte(`{"id":"evt-1"}`)},
+			)
+
+			assert.Equal(t, tt.Status, statusCode)
+			if tt.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tt.ExpectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPSender_Unreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	statusCode, err := NewHTTPSender(time.Second).Send(context.Background(),
+		entity.Subscription{URL: receiver.URL, Secret: testSecret},
+		entity.Delivery{Payload: []byte(`{}`)},
+	)
+
+	assert.Zero(t, statusCode)
+	assert.ErrorIs(t, err, config.ErrWebhookDelivery)
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+
+	assert.Equal(t, Sign(testSecret, 1700000000, body), Sign(testSecret, 1700000000, body))
+	assert.NotEqual(t, Sign(testSecret, 1700000000, body), Sign(testSecret, 1700000001, body))
+	assert.NotEqual(t, Sign(testSecret, 1700000000, body), Sign("other-secret-value", 1700000000, body))
+	assert.Len(t, Sign(testSecret, 1700000000, body), 64)
+}